@@ -3,8 +3,11 @@ package api
 import (
 	"github.com/arfan21/vocagame/config"
 	"github.com/arfan21/vocagame/internal/server"
+	"github.com/arfan21/vocagame/pkg/blobstore"
 	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
 	dbredis "github.com/arfan21/vocagame/pkg/db/redis"
+	"github.com/arfan21/vocagame/pkg/jwtkey"
+	"github.com/arfan21/vocagame/pkg/mailer"
 	"github.com/urfave/cli/v2"
 )
 
@@ -18,7 +21,12 @@ func Serve() *cli.Command {
 				return err
 			}
 
-			_, err = config.ParseConfig(config.GetViper())
+			cfg, err := config.ParseConfig(config.GetViper())
+			if err != nil {
+				return err
+			}
+
+			err = cfg.Validate()
 			if err != nil {
 				return err
 			}
@@ -33,9 +41,27 @@ func Serve() *cli.Command {
 				return err
 			}
 
+			blobStore, err := blobstore.New()
+			if err != nil {
+				return err
+			}
+
+			mailer, err := mailer.New()
+			if err != nil {
+				return err
+			}
+
+			jwtKeys, err := jwtkey.New()
+			if err != nil {
+				return err
+			}
+
 			server := server.New(
 				db,
 				dbRedis,
+				blobStore,
+				mailer,
+				jwtKeys,
 			)
 			return server.Run()
 		},
@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/arfan21/vocagame/pkg/jwtkey"
+	"github.com/urfave/cli/v2"
+)
+
+// GenerateKey writes a new signing key. To rotate, the new key becomes JWT_SIGNING_KEY_FILE and the previous
+// one is added to JWT_VERIFY_KEY_FILES until the access tokens it signed expire.
+func GenerateKey() *cli.Command {
+	return &cli.Command{
+		Name:  "generate-key",
+		Usage: "Generate a PEM private key to sign access tokens with",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "alg",
+				Usage: "Algorithm of the key (EdDSA or RS256)",
+				Value: jwtkey.AlgEdDSA,
+			},
+			&cli.StringFlag{
+				Name:     "out",
+				Usage:    "Path of the private key, the public key is written next to it with a .pub suffix",
+				Required: true,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			key, err := jwtkey.Generate(c.String("alg"))
+			if err != nil {
+				return err
+			}
+
+			private, err := jwtkey.EncodePrivate(key)
+			if err != nil {
+				return err
+			}
+
+			public, err := jwtkey.EncodePublic(key)
+			if err != nil {
+				return err
+			}
+
+			out := c.String("out")
+
+			// the private key must not be readable by other users
+			err = os.WriteFile(out, private, 0o600)
+			if err != nil {
+				return fmt.Errorf("failed to write private key: %w", err)
+			}
+
+			err = os.WriteFile(out+".pub", public, 0o644)
+			if err != nil {
+				return fmt.Errorf("failed to write public key: %w", err)
+			}
+
+			fmt.Printf("generated %s key %s\n", key.Alg, key.ID)
+
+			return nil
+		},
+	}
+}
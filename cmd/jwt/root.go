@@ -0,0 +1,16 @@
+package jwt
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+func Root() *cli.Command {
+
+	return &cli.Command{
+		Name:  "jwt",
+		Usage: "Manage the keys access tokens are signed with",
+		Subcommands: []*cli.Command{
+			GenerateKey(),
+		},
+	}
+}
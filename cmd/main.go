@@ -4,7 +4,9 @@ import (
 	"os"
 
 	"github.com/arfan21/vocagame/cmd/api"
+	"github.com/arfan21/vocagame/cmd/jwt"
 	migration "github.com/arfan21/vocagame/cmd/migrate"
+	"github.com/arfan21/vocagame/cmd/product"
 	"github.com/urfave/cli/v2"
 )
 
@@ -26,6 +28,8 @@ func main() {
 	appCli.Commands = []*cli.Command{
 		migration.Root(),
 		api.Serve(),
+		product.Root(),
+		jwt.Root(),
 	}
 
 	if err := appCli.Run(os.Args); err != nil {
@@ -0,0 +1,119 @@
+package product
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arfan21/vocagame/config"
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	auditsvc "github.com/arfan21/vocagame/internal/audit/service"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/internal/product"
+	productrepo "github.com/arfan21/vocagame/internal/product/repository"
+	productsvc "github.com/arfan21/vocagame/internal/product/service"
+	"github.com/arfan21/vocagame/pkg/blobstore"
+	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
+	dbredis "github.com/arfan21/vocagame/pkg/db/redis"
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+)
+
+// Import runs the same import as POST /api/v1/products/import for the given seller.
+func Import() *cli.Command {
+	return &cli.Command{
+		Name:  "import",
+		Usage: "Import products of a seller from a csv or ndjson file",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "user-id",
+				Usage:    "ID of the seller",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "file",
+				Usage:    "Path of the import file",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "Format of the file (csv or ndjson), taken from the file extension when omitted",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			_, err := config.LoadConfig()
+			if err != nil {
+				return err
+			}
+
+			_, err = config.ParseConfig(config.GetViper())
+			if err != nil {
+				return err
+			}
+
+			userID, err := uuid.Parse(c.String("user-id"))
+			if err != nil {
+				return fmt.Errorf("invalid user-id: %w", err)
+			}
+
+			format := c.String("format")
+			if format == "" {
+				switch strings.ToLower(filepath.Ext(c.String("file"))) {
+				case ".csv":
+					format = model.ProductFileFormatCSV
+				case ".ndjson", ".jsonl":
+					format = model.ProductFileFormatNDJSON
+				}
+			}
+
+			file, err := os.Open(c.String("file"))
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			db, err := dbpostgres.NewPgx()
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			blobStore, err := blobstore.New()
+			if err != nil {
+				return err
+			}
+
+			// the import must invalidate the catalog cached by the api
+			var productRepo product.Repository = productrepo.New(db, db)
+			if productCache := config.GetConfig().ProductCache; productCache.Enabled {
+				dbRedis, err := dbredis.New()
+				if err != nil {
+					return err
+				}
+				defer dbRedis.Close()
+
+				productRepo = productrepo.NewCache(productrepo.New(db, db), dbRedis, time.Duration(productCache.TTL)*time.Second)
+			}
+
+			auditSvc := auditsvc.New(auditrepo.New(db))
+			productSvc := productsvc.New(productRepo, auditSvc, blobStore)
+
+			res, err := productSvc.Import(c.Context, model.ProductImportRequest{
+				UserID: userID,
+				Format: format,
+				File:   file,
+			})
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(c.App.Writer)
+			encoder.SetIndent("", "  ")
+
+			return encoder.Encode(res)
+		},
+	}
+}
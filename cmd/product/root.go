@@ -0,0 +1,16 @@
+package product
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+func Root() *cli.Command {
+
+	return &cli.Command{
+		Name:  "products",
+		Usage: "Manage products",
+		Subcommands: []*cli.Command{
+			Import(),
+		},
+	}
+}
@@ -16,7 +16,20 @@ type config struct {
 	Database database `mapstructure:",squash"`
 	Redis    redis    `mapstructure:",squash"`
 	Service  service  `mapstructure:",squash"`
+	Proxy    proxy    `mapstructure:",squash"`
 	JWT      jwt      `mapstructure:",squash"`
+	Storage  storage  `mapstructure:",squash"`
+	Mail     mail     `mapstructure:",squash"`
+
+	ProductImage  productImage  `mapstructure:",squash"`
+	ProductImport productImport `mapstructure:",squash"`
+	ProductCache  productCache  `mapstructure:",squash"`
+	Reservation   reservation   `mapstructure:",squash"`
+
+	EmailVerification emailVerification `mapstructure:",squash"`
+	PasswordReset     passwordReset     `mapstructure:",squash"`
+	LoginThrottle     loginThrottle     `mapstructure:",squash"`
+	TwoFactor         twoFactor         `mapstructure:",squash"`
 }
 
 type service struct {
@@ -25,6 +38,15 @@ type service struct {
 	Version string `mapstructure:"SERVICE_VERSION"`
 }
 
+// proxy is the reverse proxy in front of the server. The client IP, which login throttling counts
+// failures of, is read from Header only for requests sent by one of TrustedProxies.
+type proxy struct {
+	// Header carries the client IP, e.g. X-Real-IP, empty when clients connect directly
+	Header string `mapstructure:"PROXY_HEADER"`
+	// TrustedProxies are comma separated IPs or CIDRs of the proxies
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+}
+
 type database struct {
 	Host     string `mapstructure:"DB_HOST"`
 	Port     string `mapstructure:"DB_PORT"`
@@ -46,12 +68,116 @@ type redis struct {
 }
 
 type jwt struct {
-	AccessTokenSecret    string `mapstructure:"JWT_ACCESS_TOKEN_SECRET"`
+	Issuer string `mapstructure:"JWT_ISSUER"`
+	// SigningKeyFile is the PEM RSA or Ed25519 private key access tokens are signed with
+	SigningKeyFile string `mapstructure:"JWT_SIGNING_KEY_FILE"`
+	// VerifyKeyFiles are comma separated PEM keys which signed before a rotation,
+	// a key can be removed once JWT_ACCESS_TOKEN_EXPIRE_IN passed since it was rotated out
+	VerifyKeyFiles       string `mapstructure:"JWT_VERIFY_KEY_FILES"`
 	AccessTokenExpireIn  int    `mapstructure:"JWT_ACCESS_TOKEN_EXPIRE_IN"`
 	RefreshTokenSecret   string `mapstructure:"JWT_REFRESH_TOKEN_SECRET"`
 	RefreshTokenExpireIn int    `mapstructure:"JWT_REFRESH_TOKEN_EXPIRE_IN"`
 }
 
+type storage struct {
+	// Driver is either local or s3
+	Driver    string `mapstructure:"STORAGE_DRIVER"`
+	LocalDir  string `mapstructure:"STORAGE_LOCAL_DIR"`
+	PublicURL string `mapstructure:"STORAGE_PUBLIC_URL"`
+
+	S3Endpoint     string `mapstructure:"STORAGE_S3_ENDPOINT"`
+	S3Region       string `mapstructure:"STORAGE_S3_REGION"`
+	S3Bucket       string `mapstructure:"STORAGE_S3_BUCKET"`
+	S3AccessKey    string `mapstructure:"STORAGE_S3_ACCESS_KEY"`
+	S3SecretKey    string `mapstructure:"STORAGE_S3_SECRET_KEY"`
+	S3UsePathStyle bool   `mapstructure:"STORAGE_S3_USE_PATH_STYLE"`
+}
+
+type mail struct {
+	// Driver is smtp, file or memory
+	Driver  string `mapstructure:"MAIL_DRIVER"`
+	From    string `mapstructure:"MAIL_FROM"`
+	FileDir string `mapstructure:"MAIL_FILE_DIR"`
+
+	SMTPHost     string `mapstructure:"MAIL_SMTP_HOST"`
+	SMTPPort     string `mapstructure:"MAIL_SMTP_PORT"`
+	SMTPUsername string `mapstructure:"MAIL_SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"MAIL_SMTP_PASSWORD"`
+}
+
+type emailVerification struct {
+	// Secret is the HS256 key of verification tokens, it is required
+	Secret string `mapstructure:"EMAIL_VERIFICATION_SECRET"`
+	// URL is the page the verification link points to, the token is added as the token query parameter
+	URL            string `mapstructure:"EMAIL_VERIFICATION_URL"`
+	ExpireIn       int    `mapstructure:"EMAIL_VERIFICATION_EXPIRE_IN"`
+	ResendInterval int    `mapstructure:"EMAIL_VERIFICATION_RESEND_INTERVAL"`
+	// Required blocks wallet operations of users with an unverified email
+	Required bool `mapstructure:"EMAIL_VERIFICATION_REQUIRED"`
+}
+
+type passwordReset struct {
+	// URL is the page the reset link points to, the token is added as the token query parameter
+	URL            string `mapstructure:"PASSWORD_RESET_URL"`
+	ExpireIn       int    `mapstructure:"PASSWORD_RESET_EXPIRE_IN"`
+	ResendInterval int    `mapstructure:"PASSWORD_RESET_RESEND_INTERVAL"`
+}
+
+// loginThrottle limits failed logins, counted per email and per IP within Window seconds.
+// A limit of zero disables it.
+type loginThrottle struct {
+	MaxAttemptsPerEmail int `mapstructure:"LOGIN_THROTTLE_MAX_ATTEMPTS_PER_EMAIL"`
+	MaxAttemptsPerIP    int `mapstructure:"LOGIN_THROTTLE_MAX_ATTEMPTS_PER_IP"`
+	Window              int `mapstructure:"LOGIN_THROTTLE_WINDOW"`
+	Lockout             int `mapstructure:"LOGIN_THROTTLE_LOCKOUT"`
+	// after DelayAfter failures of an email every failure delays the next attempt, doubling from DelayBase seconds
+	DelayAfter int `mapstructure:"LOGIN_THROTTLE_DELAY_AFTER"`
+	DelayBase  int `mapstructure:"LOGIN_THROTTLE_DELAY_BASE"`
+}
+
+type twoFactor struct {
+	// Issuer is the account name shown in authenticator apps
+	Issuer            string `mapstructure:"TWO_FACTOR_ISSUER"`
+	ChallengeExpireIn int    `mapstructure:"TWO_FACTOR_CHALLENGE_EXPIRE_IN"`
+	RecoveryCodes     int    `mapstructure:"TWO_FACTOR_RECOVERY_CODES"`
+	// WithdrawStepUpAmount is the withdrawal amount from which a user with two factor authentication
+	// has to send a code again, zero disables it
+	WithdrawStepUpAmount int64 `mapstructure:"TWO_FACTOR_WITHDRAW_STEP_UP_AMOUNT"`
+}
+
+type productImage struct {
+	MaxSize       int `mapstructure:"PRODUCT_IMAGE_MAX_SIZE"`
+	MaxCount      int `mapstructure:"PRODUCT_IMAGE_MAX_COUNT"`
+	ThumbnailSize int `mapstructure:"PRODUCT_IMAGE_THUMBNAIL_SIZE"`
+}
+
+type productImport struct {
+	MaxRows int `mapstructure:"PRODUCT_IMPORT_MAX_ROWS"`
+}
+
+type productCache struct {
+	Enabled bool `mapstructure:"PRODUCT_CACHE_ENABLED"`
+	TTL     int  `mapstructure:"PRODUCT_CACHE_TTL"`
+}
+
+type reservation struct {
+	ExpireIn      int `mapstructure:"RESERVATION_EXPIRE_IN"`
+	SweepInterval int `mapstructure:"RESERVATION_SWEEP_INTERVAL"`
+}
+
+// minSecretLength is the minimum length of an HMAC secret, a shorter one can be brute forced.
+const minSecretLength = 32
+
+// Validate checks the settings the server can not run safely without.
+func (c config) Validate() error {
+	// an empty HMAC key is accepted by jwt, anyone could sign a verification token with it
+	if len(c.EmailVerification.Secret) < minSecretLength {
+		return fmt.Errorf("config: EMAIL_VERIFICATION_SECRET must be at least %d characters", minSecretLength)
+	}
+
+	return nil
+}
+
 var configInstance *config
 var viperInstance *viper.Viper
 
@@ -135,4 +261,39 @@ func initDefaultValue(v *viper.Viper) {
 	v.SetDefault("ENV", "dev")
 	v.SetDefault("SERVICE_NAME", "vocagame")
 	v.SetDefault("SERVICE_TIMEOUT", 30)
+	v.SetDefault("JWT_ISSUER", "vocagame")
+	v.SetDefault("STORAGE_DRIVER", "local")
+	v.SetDefault("STORAGE_LOCAL_DIR", "./storage")
+	v.SetDefault("STORAGE_PUBLIC_URL", "/storage")
+	v.SetDefault("STORAGE_S3_REGION", "us-east-1")
+	v.SetDefault("STORAGE_S3_USE_PATH_STYLE", true)
+	v.SetDefault("MAIL_DRIVER", "file")
+	v.SetDefault("MAIL_FROM", "Vocagame <no-reply@vocagame.local>")
+	v.SetDefault("MAIL_FILE_DIR", "./mail")
+	v.SetDefault("MAIL_SMTP_PORT", "587")
+	v.SetDefault("EMAIL_VERIFICATION_URL", "http://localhost:8080/verify-email")
+	v.SetDefault("EMAIL_VERIFICATION_EXPIRE_IN", 86400)
+	v.SetDefault("EMAIL_VERIFICATION_RESEND_INTERVAL", 60)
+	v.SetDefault("EMAIL_VERIFICATION_REQUIRED", false)
+	v.SetDefault("PASSWORD_RESET_URL", "http://localhost:8080/reset-password")
+	v.SetDefault("PASSWORD_RESET_EXPIRE_IN", 1800)
+	v.SetDefault("PASSWORD_RESET_RESEND_INTERVAL", 60)
+	v.SetDefault("LOGIN_THROTTLE_MAX_ATTEMPTS_PER_EMAIL", 5)
+	v.SetDefault("LOGIN_THROTTLE_MAX_ATTEMPTS_PER_IP", 20)
+	v.SetDefault("LOGIN_THROTTLE_WINDOW", 900)
+	v.SetDefault("LOGIN_THROTTLE_LOCKOUT", 900)
+	v.SetDefault("LOGIN_THROTTLE_DELAY_AFTER", 3)
+	v.SetDefault("LOGIN_THROTTLE_DELAY_BASE", 1)
+	v.SetDefault("TWO_FACTOR_ISSUER", "Vocagame")
+	v.SetDefault("TWO_FACTOR_CHALLENGE_EXPIRE_IN", 300)
+	v.SetDefault("TWO_FACTOR_RECOVERY_CODES", 10)
+	v.SetDefault("TWO_FACTOR_WITHDRAW_STEP_UP_AMOUNT", 1000000)
+	v.SetDefault("PRODUCT_IMAGE_MAX_SIZE", 5<<20)
+	v.SetDefault("PRODUCT_IMAGE_MAX_COUNT", 10)
+	v.SetDefault("PRODUCT_IMAGE_THUMBNAIL_SIZE", 320)
+	v.SetDefault("PRODUCT_IMPORT_MAX_ROWS", 1000)
+	v.SetDefault("PRODUCT_CACHE_ENABLED", true)
+	v.SetDefault("PRODUCT_CACHE_TTL", 30)
+	v.SetDefault("RESERVATION_EXPIRE_IN", 600)
+	v.SetDefault("RESERVATION_SWEEP_INTERVAL", 30)
 }
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSuccess(t *testing.T) {
+	var c config
+	c.EmailVerification.Secret = strings.Repeat("s", minSecretLength)
+
+	assert.NoError(t, c.Validate())
+}
+
+func TestValidateFailedEmailVerificationSecret(t *testing.T) {
+	for _, secret := range []string{"", strings.Repeat("s", minSecretLength-1)} {
+		var c config
+		c.EmailVerification.Secret = secret
+
+		assert.Error(t, c.Validate())
+	}
+}
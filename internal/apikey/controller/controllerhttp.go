@@ -0,0 +1,127 @@
+package apikeyctrl
+
+import (
+	"github.com/arfan21/vocagame/internal/apikey"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/exception"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ControllerHTTP struct {
+	svc apikey.Service
+}
+
+func New(svc apikey.Service) *ControllerHTTP {
+	return &ControllerHTTP{svc: svc}
+}
+
+// @Summary Create API Key
+// @Description Create an API key for server to server calls. The key is only returned in this response.
+// @Description Send it in the X-API-Key header or as the bearer token.
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param body body model.APIKeyCreateRequest true "Payload Create API Key Request"
+// @Success 201 {object} pkgutil.HTTPResponse{data=model.APIKeyCreateResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/api-keys [post]
+func (ctrl ControllerHTTP) Create(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.APIKeyCreateRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+	req.UserID = uuidUserID
+
+	res, err := ctrl.svc.Create(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusCreated).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusCreated,
+		Data: res,
+	})
+}
+
+// @Summary Get API Keys
+// @Description Get the API keys of the user, revoked keys included
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Success 200 {object} pkgutil.HTTPResponse{data=[]model.APIKeyResponse}
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/api-keys [get]
+func (ctrl ControllerHTTP) GetList(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetByUserID(c.UserContext(), uuidUserID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Revoke API Key
+// @Description Revoke an API key of the user, requests with the key are rejected afterwards
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param id path string true "API Key ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/api-keys/:id [delete]
+func (ctrl ControllerHTTP) Revoke(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	uuidID, err := uuid.Parse(c.Params("id"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.Revoke(c.UserContext(), model.APIKeyRevokeRequest{
+		ID:     uuidID,
+		UserID: uuidUserID,
+	})
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
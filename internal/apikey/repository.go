@@ -0,0 +1,21 @@
+package apikey
+
+import (
+	"context"
+
+	apikeyrepo "github.com/arfan21/vocagame/internal/apikey/repository"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository interface {
+	Begin(ctx context.Context) (tx pgx.Tx, err error)
+	WithTx(tx pgx.Tx) *apikeyrepo.Repository
+
+	Create(ctx context.Context, data entity.APIKey) (res entity.APIKey, err error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) (result []entity.APIKey, err error)
+	GetByPrefix(ctx context.Context, prefix string) (data entity.APIKey, owner entity.User, err error)
+	Revoke(ctx context.Context, id uuid.UUID, userID uuid.UUID) (data entity.APIKey, err error)
+	UpdateLastUsed(ctx context.Context, id uuid.UUID, ip string) (err error)
+}
@@ -0,0 +1,197 @@
+package apikeyrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/pkg/constant"
+	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository struct {
+	db    dbpostgres.Queryer
+	rawDb dbpostgres.Raw
+}
+
+func New(raw dbpostgres.Raw, queryer dbpostgres.Queryer) *Repository {
+	return &Repository{
+		db:    queryer,
+		rawDb: raw,
+	}
+}
+
+func (r Repository) Begin(ctx context.Context) (tx pgx.Tx, err error) {
+	return r.rawDb.Begin(ctx)
+}
+
+func (r Repository) WithTx(tx pgx.Tx) *Repository {
+	r.db = tx
+	return &r
+}
+
+func (r Repository) Create(ctx context.Context, data entity.APIKey) (res entity.APIKey, err error) {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, secret_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	res = data
+	err = r.db.QueryRow(ctx, query,
+		data.UserID,
+		data.Name,
+		data.Prefix,
+		data.SecretHash,
+		data.Scopes,
+		data.ExpiresAt,
+	).Scan(&res.ID, &res.CreatedAt)
+	if err != nil {
+		err = fmt.Errorf("apikey.repository.Create: failed to create api key: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetByUserID returns the keys of the user, revoked keys included, newest first.
+func (r Repository) GetByUserID(ctx context.Context, userID uuid.UUID) (result []entity.APIKey, err error) {
+	query := `
+		SELECT id, user_id, name, prefix, secret_hash, scopes, last_used_at, last_used_ip, expires_at, revoked_at, created_at
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		err = fmt.Errorf("apikey.repository.GetByUserID: failed to get api keys: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data entity.APIKey
+
+		err = rows.Scan(
+			&data.ID,
+			&data.UserID,
+			&data.Name,
+			&data.Prefix,
+			&data.SecretHash,
+			&data.Scopes,
+			&data.LastUsedAt,
+			&data.LastUsedIP,
+			&data.ExpiresAt,
+			&data.RevokedAt,
+			&data.CreatedAt,
+		)
+		if err != nil {
+			err = fmt.Errorf("apikey.repository.GetByUserID: failed to scan api key: %w", err)
+			return
+		}
+
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("apikey.repository.GetByUserID: failed after scan api keys: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+// GetByPrefix returns the key with the prefix and the user it acts for.
+func (r Repository) GetByPrefix(ctx context.Context, prefix string) (data entity.APIKey, owner entity.User, err error) {
+	query := `
+		SELECT
+			k.id, k.user_id, k.name, k.prefix, k.secret_hash, k.scopes, k.last_used_at, k.last_used_ip, k.expires_at, k.revoked_at, k.created_at,
+			u.id, u.email, u.role, u.email_verified_at
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.prefix = $1
+	`
+
+	err = r.db.QueryRow(ctx, query, prefix).Scan(
+		&data.ID,
+		&data.UserID,
+		&data.Name,
+		&data.Prefix,
+		&data.SecretHash,
+		&data.Scopes,
+		&data.LastUsedAt,
+		&data.LastUsedIP,
+		&data.ExpiresAt,
+		&data.RevokedAt,
+		&data.CreatedAt,
+		&owner.ID,
+		&owner.Email,
+		&owner.Role,
+		&owner.EmailVerifiedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrAPIKeyNotFound
+		}
+
+		err = fmt.Errorf("apikey.repository.GetByPrefix: failed to get api key: %w", err)
+		return
+	}
+
+	return
+}
+
+// Revoke revokes the key of the user, a key revoked before is not found.
+func (r Repository) Revoke(ctx context.Context, id uuid.UUID, userID uuid.UUID) (data entity.APIKey, err error) {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = now()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+		RETURNING id, user_id, name, prefix, secret_hash, scopes, last_used_at, last_used_ip, expires_at, revoked_at, created_at
+	`
+
+	err = r.db.QueryRow(ctx, query, id, userID).Scan(
+		&data.ID,
+		&data.UserID,
+		&data.Name,
+		&data.Prefix,
+		&data.SecretHash,
+		&data.Scopes,
+		&data.LastUsedAt,
+		&data.LastUsedIP,
+		&data.ExpiresAt,
+		&data.RevokedAt,
+		&data.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrAPIKeyNotFound
+		}
+
+		err = fmt.Errorf("apikey.repository.Revoke: failed to revoke api key: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) UpdateLastUsed(ctx context.Context, id uuid.UUID, ip string) (err error) {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = now(), last_used_ip = $2
+		WHERE id = $1
+	`
+
+	_, err = r.db.Exec(ctx, query, id, ip)
+	if err != nil {
+		err = fmt.Errorf("apikey.repository.UpdateLastUsed: failed to update last used: %w", err)
+		return
+	}
+
+	return
+}
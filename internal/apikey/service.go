@@ -0,0 +1,15 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/google/uuid"
+)
+
+type Service interface {
+	Create(ctx context.Context, req model.APIKeyCreateRequest) (res model.APIKeyCreateResponse, err error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) (res []model.APIKeyResponse, err error)
+	Revoke(ctx context.Context, req model.APIKeyRevokeRequest) (err error)
+	Authenticate(ctx context.Context, key string) (claims model.JWTClaims, err error)
+}
@@ -0,0 +1,263 @@
+package apikeysvc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arfan21/vocagame/internal/apikey"
+	"github.com/arfan21/vocagame/internal/audit"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/logger"
+	"github.com/arfan21/vocagame/pkg/validation"
+	"github.com/google/uuid"
+)
+
+// lastUsedInterval limits the writes of the last use, a key used more often is updated once per interval.
+const lastUsedInterval = time.Minute
+
+type Service struct {
+	repo     apikey.Repository
+	auditSvc audit.Service
+}
+
+func New(repo apikey.Repository, auditSvc audit.Service) *Service {
+	return &Service{repo: repo, auditSvc: auditSvc}
+}
+
+func toAPIKeyResponse(data entity.APIKey) model.APIKeyResponse {
+	return model.APIKeyResponse{
+		ID:         data.ID,
+		Name:       data.Name,
+		Prefix:     data.Prefix,
+		Scopes:     data.Scopes,
+		LastUsedAt: data.LastUsedAt,
+		LastUsedIP: data.LastUsedIP,
+		ExpiresAt:  data.ExpiresAt,
+		RevokedAt:  data.RevokedAt,
+		CreatedAt:  data.CreatedAt,
+	}
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateKey returns a new key with its prefix and secret. The prefix is hex so it never contains the
+// separator, the secret may.
+func generateKey() (key string, prefix string, secret string, err error) {
+	b := make([]byte, 6+32)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = fmt.Errorf("failed to generate api key: %w", err)
+		return
+	}
+
+	prefix = hex.EncodeToString(b[:6])
+	secret = base64.RawURLEncoding.EncodeToString(b[6:])
+	key = entity.APIKeyTokenPrefix + prefix + "_" + secret
+
+	return
+}
+
+// Create creates a key for the user, the key is only in the response and can not be shown again.
+func (s Service) Create(ctx context.Context, req model.APIKeyCreateRequest) (res model.APIKeyCreateResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("apikey.service.Create: failed to validate request : %w", err)
+		return
+	}
+
+	if req.ExpiresAt.Valid && !req.ExpiresAt.Time.After(time.Now()) {
+		err = constant.ErrAPIKeyExpiresInPast
+		return
+	}
+
+	key, prefix, secret, err := generateKey()
+	if err != nil {
+		err = fmt.Errorf("apikey.service.Create: %w", err)
+		return
+	}
+
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+
+	data := entity.APIKey{
+		UserID:     req.UserID,
+		Name:       strings.TrimSpace(req.Name),
+		Prefix:     prefix,
+		SecretHash: hashSecret(secret),
+		Scopes:     slices.Compact(scopes),
+		ExpiresAt:  req.ExpiresAt,
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("apikey.service.Create: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("apikey.service.Create: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	data, err = s.repo.WithTx(tx).Create(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("apikey.service.Create: failed to create api key : %w", err)
+		return
+	}
+
+	res = model.APIKeyCreateResponse{
+		APIKeyResponse: toAPIKeyResponse(data),
+		Key:            key,
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntityAPIKey,
+		EntityID:   data.ID,
+		After:      res.APIKeyResponse,
+	})
+	if err != nil {
+		err = fmt.Errorf("apikey.service.Create: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) GetByUserID(ctx context.Context, userID uuid.UUID) (res []model.APIKeyResponse, err error) {
+	results, err := s.repo.GetByUserID(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("apikey.service.GetByUserID: failed to get api keys from db : %w", err)
+		return
+	}
+
+	res = make([]model.APIKeyResponse, 0, len(results))
+	for _, result := range results {
+		res = append(res, toAPIKeyResponse(result))
+	}
+
+	return
+}
+
+// Revoke revokes a key of the user, the next request with the key is rejected.
+func (s Service) Revoke(ctx context.Context, req model.APIKeyRevokeRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("apikey.service.Revoke: failed to validate request : %w", err)
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("apikey.service.Revoke: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("apikey.service.Revoke: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	data, err := s.repo.WithTx(tx).Revoke(ctx, req.ID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("apikey.service.Revoke: failed to revoke api key : %w", err)
+		return
+	}
+
+	after := toAPIKeyResponse(data)
+	before := after
+	before.RevokedAt.Valid = false
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionRevoke,
+		EntityType: entity.AuditEntityAPIKey,
+		EntityID:   data.ID,
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		err = fmt.Errorf("apikey.service.Revoke: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// Authenticate returns the identity of the user of key, with the scopes of the key.
+// A malformed, unknown, revoked or expired key is ErrAPIKeyInvalid.
+func (s Service) Authenticate(ctx context.Context, key string) (claims model.JWTClaims, err error) {
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(key, entity.APIKeyTokenPrefix), "_")
+	if !ok || !strings.HasPrefix(key, entity.APIKeyTokenPrefix) || prefix == "" || secret == "" {
+		err = constant.ErrAPIKeyInvalid
+		return
+	}
+
+	data, owner, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, constant.ErrAPIKeyNotFound) {
+			err = constant.ErrAPIKeyInvalid
+		}
+
+		err = fmt.Errorf("apikey.service.Authenticate: failed to get api key : %w", err)
+		return
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(data.SecretHash)) != 1 ||
+		data.RevokedAt.Valid ||
+		(data.ExpiresAt.Valid && !data.ExpiresAt.Time.After(now)) {
+		err = constant.ErrAPIKeyInvalid
+		return
+	}
+
+	if !data.LastUsedAt.Valid || now.Sub(data.LastUsedAt.Time) >= lastUsedInterval {
+		ip, _ := ctx.Value(constant.ClientIPContextKey).(string)
+
+		// a failed update only loses the last use, the request goes on
+		errUpdate := s.repo.UpdateLastUsed(ctx, data.ID, ip)
+		if errUpdate != nil {
+			logger.Log(ctx).Error().Err(errUpdate).Msg("apikey.service.Authenticate: failed to update last used")
+		}
+	}
+
+	claims = model.JWTClaims{
+		Email:         owner.Email,
+		Role:          owner.Role,
+		EmailVerified: owner.EmailVerifiedAt.Valid,
+		APIKeyID:      data.ID.String(),
+		Scopes:        data.Scopes,
+	}
+	claims.Subject = owner.ID.String()
+
+	return
+}
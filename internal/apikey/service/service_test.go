@@ -0,0 +1,226 @@
+package apikeysvc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	apikeyrepo "github.com/arfan21/vocagame/internal/apikey/repository"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+var getByPrefixQuery = regexp.QuoteMeta("WHERE k.prefix = $1")
+
+func initDepMock(t *testing.T) (*Service, pgxmock.PgxPoolIface) {
+	db, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.ExpectationsWereMet())
+	})
+
+	return New(apikeyrepo.New(db, db), nil), db
+}
+
+func newKey(t *testing.T) (key string, data entity.APIKey, owner entity.User) {
+	key, prefix, secret, err := generateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner = entity.User{
+		ID:              uuid.New(),
+		Email:           "owner@example.com",
+		Role:            entity.UserRoleUser,
+		EmailVerifiedAt: null.TimeFrom(time.Now()),
+	}
+	data = entity.APIKey{
+		ID:         uuid.New(),
+		UserID:     owner.ID,
+		Name:       "backend",
+		Prefix:     prefix,
+		SecretHash: hashSecret(secret),
+		Scopes:     []string{entity.APIKeyScopeProductsRead},
+		LastUsedAt: null.TimeFrom(time.Now()),
+		CreatedAt:  time.Now(),
+	}
+
+	return
+}
+
+func expectGetByPrefix(db pgxmock.PgxPoolIface, data entity.APIKey, owner entity.User) {
+	db.ExpectQuery(getByPrefixQuery).
+		WithArgs(data.Prefix).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"id", "user_id", "name", "prefix", "secret_hash", "scopes", "last_used_at", "last_used_ip", "expires_at",
+			"revoked_at", "created_at", "id", "email", "role", "email_verified_at",
+		}).AddRow(
+			data.ID, data.UserID, data.Name, data.Prefix, data.SecretHash, data.Scopes, data.LastUsedAt, data.LastUsedIP,
+			data.ExpiresAt, data.RevokedAt, data.CreatedAt, owner.ID, owner.Email, owner.Role, owner.EmailVerifiedAt,
+		))
+}
+
+func TestGenerateKeySuccess(t *testing.T) {
+	key, prefix, secret, err := generateKey()
+	assert.NoError(t, err)
+	assert.Equal(t, entity.APIKeyTokenPrefix+prefix+"_"+secret, key)
+
+	// the prefix is hex, so the first separator after the token prefix always ends it
+	_, err = hex.DecodeString(prefix)
+	assert.NoError(t, err)
+	assert.Len(t, prefix, 12)
+	assert.NotContains(t, prefix, "_")
+
+	b, err := base64.RawURLEncoding.DecodeString(secret)
+	assert.NoError(t, err)
+	assert.Len(t, b, 32)
+
+	parsedPrefix, parsedSecret, ok := strings.Cut(strings.TrimPrefix(key, entity.APIKeyTokenPrefix), "_")
+	assert.True(t, ok)
+	assert.Equal(t, prefix, parsedPrefix)
+	assert.Equal(t, secret, parsedSecret)
+
+	other, _, _, err := generateKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestHashSecretSuccess(t *testing.T) {
+	sum := sha256.Sum256([]byte("secret"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), hashSecret("secret"))
+	assert.NotEqual(t, hashSecret("secret"), hashSecret("Secret"))
+}
+
+func TestAuthenticateSuccess(t *testing.T) {
+	svc, db := initDepMock(t)
+	key, data, owner := newKey(t)
+	expectGetByPrefix(db, data, owner)
+
+	claims, err := svc.Authenticate(context.Background(), key)
+	assert.NoError(t, err)
+	assert.Equal(t, owner.ID.String(), claims.Subject)
+	assert.Equal(t, owner.Email, claims.Email)
+	assert.Equal(t, owner.Role, claims.Role)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, data.ID.String(), claims.APIKeyID)
+	assert.Equal(t, data.Scopes, claims.Scopes)
+}
+
+func TestAuthenticateUpdateLastUsedSuccess(t *testing.T) {
+	svc, db := initDepMock(t)
+	key, data, owner := newKey(t)
+	data.LastUsedAt = null.TimeFrom(time.Now().Add(-lastUsedInterval))
+	expectGetByPrefix(db, data, owner)
+	db.ExpectExec(regexp.QuoteMeta("SET last_used_at = now(), last_used_ip = $2")).
+		WithArgs(data.ID, "10.0.0.1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	ctx := context.WithValue(context.Background(), constant.ClientIPContextKey, "10.0.0.1")
+
+	_, err := svc.Authenticate(ctx, key)
+	assert.NoError(t, err)
+}
+
+func TestAuthenticateFailedMalformedKey(t *testing.T) {
+	svc, _ := initDepMock(t)
+	key, data, _ := newKey(t)
+
+	// none of these reach the database
+	for _, malformed := range []string{
+		"",
+		strings.TrimPrefix(key, entity.APIKeyTokenPrefix),
+		"xx_" + strings.TrimPrefix(key, entity.APIKeyTokenPrefix),
+		entity.APIKeyTokenPrefix + data.Prefix,
+		entity.APIKeyTokenPrefix + data.Prefix + "_",
+		entity.APIKeyTokenPrefix + "_secret",
+	} {
+		_, err := svc.Authenticate(context.Background(), malformed)
+		assert.ErrorIs(t, err, constant.ErrAPIKeyInvalid, malformed)
+	}
+}
+
+func TestAuthenticateFailedInvalidKey(t *testing.T) {
+	key, data, owner := newKey(t)
+
+	// the key with its last character changed
+	last := "A"
+	if strings.HasSuffix(key, last) {
+		last = "B"
+	}
+	wrongKey := key[:len(key)-1] + last
+
+	tests := []struct {
+		name  string
+		key   string
+		data  func(data entity.APIKey) entity.APIKey
+		found bool
+	}{
+		{
+			name:  "wrong secret",
+			key:   wrongKey,
+			found: true,
+		},
+		{
+			name: "secret of another key",
+			key:  entity.APIKeyTokenPrefix + data.Prefix + "_secret",
+			data: func(data entity.APIKey) entity.APIKey {
+				data.SecretHash = hashSecret("another secret")
+				return data
+			},
+			found: true,
+		},
+		{
+			name: "revoked key",
+			key:  key,
+			data: func(data entity.APIKey) entity.APIKey {
+				data.RevokedAt = null.TimeFrom(time.Now())
+				return data
+			},
+			found: true,
+		},
+		{
+			name: "expired key",
+			key:  key,
+			data: func(data entity.APIKey) entity.APIKey {
+				data.ExpiresAt = null.TimeFrom(time.Now().Add(-time.Second))
+				return data
+			},
+			found: true,
+		},
+		{
+			name: "unknown prefix",
+			key:  key,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, db := initDepMock(t)
+			switch {
+			case !tt.found:
+				db.ExpectQuery(getByPrefixQuery).WithArgs(data.Prefix).WillReturnError(pgx.ErrNoRows)
+			case tt.data != nil:
+				expectGetByPrefix(db, tt.data(data), owner)
+			default:
+				expectGetByPrefix(db, data, owner)
+			}
+
+			claims, err := svc.Authenticate(context.Background(), tt.key)
+			assert.ErrorIs(t, err, constant.ErrAPIKeyInvalid)
+			assert.Empty(t, claims.Subject)
+		})
+	}
+}
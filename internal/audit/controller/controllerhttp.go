@@ -0,0 +1,50 @@
+package auditctrl
+
+import (
+	"github.com/arfan21/vocagame/internal/audit"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/exception"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ControllerHTTP struct {
+	svc audit.Service
+}
+
+func New(svc audit.Service) *ControllerHTTP {
+	return &ControllerHTTP{svc: svc}
+}
+
+// @Summary Get Audit Logs
+// @Description Get Audit Logs, admin only
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param page query string true "Page"
+// @Param limit query string true "Limit"
+// @Param actor_id query string false "Actor ID"
+// @Param entity_type query string false "Entity type (user, product, wallet, transaction)"
+// @Param entity_id query string false "Entity ID"
+// @Param action query string false "Action (CREATE, UPDATE, DELETE)"
+// @Param from query string false "Created from (RFC3339)"
+// @Param to query string false "Created to (RFC3339)"
+// @Success 200 {object} pkgutil.HTTPResponse{data=pkgutil.PaginationResponse[[]model.AuditLogResponse]{data=[]model.AuditLogResponse}}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/audit-logs [get]
+func (ctrl ControllerHTTP) GetList(c *fiber.Ctx) error {
+	reqQuery := model.GetListAuditLogRequest{}
+	err := c.QueryParser(&reqQuery)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetList(c.UserContext(), reqQuery)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
@@ -0,0 +1,17 @@
+package audit
+
+import (
+	"context"
+
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository interface {
+	WithTx(tx pgx.Tx) *auditrepo.Repository
+
+	Create(ctx context.Context, data entity.AuditLog) (err error)
+	GetList(ctx context.Context, filter entity.ListAuditLogFilter) (result []entity.AuditLog, err error)
+	GetTotal(ctx context.Context, filter entity.ListAuditLogFilter) (result int, err error)
+}
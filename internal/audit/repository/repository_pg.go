@@ -0,0 +1,193 @@
+package auditrepo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository struct {
+	db dbpostgres.Queryer
+}
+
+func New(queryer dbpostgres.Queryer) *Repository {
+	return &Repository{
+		db: queryer,
+	}
+}
+
+func (r Repository) WithTx(tx pgx.Tx) *Repository {
+	r.db = tx
+	return &r
+}
+
+func (r Repository) Create(ctx context.Context, data entity.AuditLog) (err error) {
+	query := `
+		INSERT INTO audit_logs (actor_id, action, entity_type, entity_id, before_value, after_value, request_id, ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = r.db.Exec(ctx, query,
+		data.ActorID,
+		data.Action,
+		data.EntityType,
+		data.EntityID,
+		data.Before,
+		data.After,
+		data.RequestID,
+		data.IP,
+	)
+	if err != nil {
+		err = fmt.Errorf("audit.repository.Create: failed to create audit log: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) queryRowsWithFilter(ctx context.Context, query string, filter entity.ListAuditLogFilter, disableOffset bool) (rows pgx.Rows, err error) {
+	var filterArgs []any
+	var whereQuery string
+
+	if filter.ActorID.Valid {
+		filterArgs = append(filterArgs, filter.ActorID.UUID)
+		whereQuery += "a.actor_id = $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	if len(filter.EntityType) != 0 {
+		filterArgs = append(filterArgs, filter.EntityType)
+		whereQuery += "a.entity_type = $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	if filter.EntityID.Valid {
+		filterArgs = append(filterArgs, filter.EntityID.UUID)
+		whereQuery += "a.entity_id = $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	if len(filter.Action) != 0 {
+		filterArgs = append(filterArgs, filter.Action)
+		whereQuery += "a.action = $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	if !filter.From.IsZero() {
+		filterArgs = append(filterArgs, filter.From)
+		whereQuery += "a.created_at >= $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	if !filter.To.IsZero() {
+		filterArgs = append(filterArgs, filter.To)
+		whereQuery += "a.created_at <= $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	// if filterArgsLen  > 0, add WHERE statement and remove last AND
+	if filterArgsLen := len(filterArgs); filterArgsLen > 0 {
+		whereQuery = "WHERE " + whereQuery[:len(whereQuery)-len(" AND ")] + " "
+	}
+
+	query += whereQuery
+
+	if !disableOffset {
+		query += "ORDER BY a.id DESC "
+
+		filterArgs = append(filterArgs, filter.Limit)
+		query += "LIMIT $" + strconv.Itoa(len(filterArgs)) + " "
+
+		offset := (filter.Page - 1) * filter.Limit
+		filterArgs = append(filterArgs, offset)
+		query += "OFFSET $" + strconv.Itoa(len(filterArgs)) + " "
+	}
+
+	return r.db.Query(ctx, query, filterArgs...)
+}
+
+func (r Repository) GetList(ctx context.Context, filter entity.ListAuditLogFilter) (result []entity.AuditLog, err error) {
+	query := `
+		SELECT
+			a.id,
+			a.actor_id,
+			a.action,
+			a.entity_type,
+			a.entity_id,
+			a.before_value,
+			a.after_value,
+			COALESCE(a.request_id, ''),
+			COALESCE(a.ip, ''),
+			a.created_at
+		FROM
+			audit_logs a
+	`
+
+	rows, err := r.queryRowsWithFilter(ctx, query, filter, false)
+	if err != nil {
+		err = fmt.Errorf("audit.repository.GetList: failed to get audit logs: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data entity.AuditLog
+
+		err = rows.Scan(
+			&data.ID,
+			&data.ActorID,
+			&data.Action,
+			&data.EntityType,
+			&data.EntityID,
+			&data.Before,
+			&data.After,
+			&data.RequestID,
+			&data.IP,
+			&data.CreatedAt,
+		)
+		if err != nil {
+			err = fmt.Errorf("audit.repository.GetList: failed to scan audit log: %w", err)
+			return
+		}
+
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("audit.repository.GetList: failed after scan audit logs: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+func (r Repository) GetTotal(ctx context.Context, filter entity.ListAuditLogFilter) (result int, err error) {
+	query := `
+		SELECT
+			COUNT(a.id)
+		FROM
+			audit_logs a
+	`
+
+	rows, err := r.queryRowsWithFilter(ctx, query, filter, true)
+	if err != nil {
+		err = fmt.Errorf("audit.repository.GetTotal: failed to get total audit log: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&result)
+		if err != nil {
+			err = fmt.Errorf("audit.repository.GetTotal: failed to scan total audit log: %w", err)
+			return
+		}
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("audit.repository.GetTotal: failed after scan total audit log: %w", rows.Err())
+		return
+	}
+
+	return
+}
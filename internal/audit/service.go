@@ -0,0 +1,16 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service interface {
+	WithTx(tx pgx.Tx) Service
+
+	Record(ctx context.Context, req model.AuditLogRecordRequest) (err error)
+	GetList(ctx context.Context, req model.GetListAuditLogRequest) (res pkgutil.PaginationResponse[[]model.AuditLogResponse], err error)
+}
@@ -0,0 +1,164 @@
+package auditsvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arfan21/vocagame/internal/audit"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/arfan21/vocagame/pkg/validation"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service struct {
+	repo audit.Repository
+}
+
+func New(repo audit.Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s Service) WithTx(tx pgx.Tx) audit.Service {
+	s.repo = s.repo.WithTx(tx)
+	return &s
+}
+
+// Record writes an audit log entry, the actor, request id and client ip are taken from ctx.
+func (s Service) Record(ctx context.Context, req model.AuditLogRecordRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("audit.service.Record: failed to validate request : %w", err)
+		return
+	}
+
+	data := entity.AuditLog{
+		Action:     req.Action,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+	}
+
+	if claims, ok := ctx.Value(constant.JWTClaimsContextKey).(model.JWTClaims); ok {
+		actorID, errParse := uuid.Parse(claims.Subject)
+		if errParse == nil {
+			data.ActorID = uuid.NullUUID{UUID: actorID, Valid: true}
+		}
+	}
+
+	if requestID, ok := ctx.Value(requestid.ConfigDefault.ContextKey).(string); ok {
+		data.RequestID = requestID
+	}
+
+	if ip, ok := ctx.Value(constant.ClientIPContextKey).(string); ok {
+		data.IP = ip
+	}
+
+	if req.Before != nil {
+		data.Before, err = json.Marshal(req.Before)
+		if err != nil {
+			err = fmt.Errorf("audit.service.Record: failed to marshal before value : %w", err)
+			return
+		}
+	}
+
+	if req.After != nil {
+		data.After, err = json.Marshal(req.After)
+		if err != nil {
+			err = fmt.Errorf("audit.service.Record: failed to marshal after value : %w", err)
+			return
+		}
+	}
+
+	err = s.repo.Create(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("audit.service.Record: failed to create audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) GetList(ctx context.Context, req model.GetListAuditLogRequest) (res pkgutil.PaginationResponse[[]model.AuditLogResponse], err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("audit.service.GetList: failed to validate request : %w", err)
+		return
+	}
+
+	filter := entity.ListAuditLogFilter{
+		ActorID:    req.ActorID,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		Action:     req.Action,
+		Page:       req.Page,
+		Limit:      req.Limit,
+	}
+
+	if req.From != "" {
+		filter.From, err = time.Parse(time.RFC3339, req.From)
+		if err != nil {
+			err = fmt.Errorf("audit.service.GetList: failed to parse from : %w", err)
+			return
+		}
+	}
+
+	if req.To != "" {
+		filter.To, err = time.Parse(time.RFC3339, req.To)
+		if err != nil {
+			err = fmt.Errorf("audit.service.GetList: failed to parse to : %w", err)
+			return
+		}
+	}
+
+	results, err := s.repo.GetList(ctx, filter)
+	if err != nil {
+		err = fmt.Errorf("audit.service.GetList: failed to get audit logs from db : %w", err)
+		return
+	}
+
+	resData := make([]model.AuditLogResponse, len(results))
+
+	for i, result := range results {
+		resData[i] = model.AuditLogResponse{
+			ID:         result.ID,
+			ActorID:    result.ActorID,
+			Action:     result.Action,
+			EntityType: result.EntityType,
+			EntityID:   result.EntityID,
+			Before:     result.Before,
+			After:      result.After,
+			RequestID:  result.RequestID,
+			IP:         result.IP,
+			CreatedAt:  result.CreatedAt,
+		}
+	}
+
+	total, err := s.repo.GetTotal(ctx, filter)
+	if err != nil {
+		err = fmt.Errorf("audit.service.GetList: failed to get total audit log from db : %w", err)
+		return
+	}
+
+	totalPage := 0
+	if total%filter.Limit != 0 {
+		totalPage = total/filter.Limit + 1
+	} else {
+		totalPage = total / filter.Limit
+	}
+
+	res = pkgutil.PaginationResponse[[]model.AuditLogResponse]{
+		TotalData: total,
+		TotalPage: totalPage,
+		Page:      filter.Page,
+		Limit:     filter.Limit,
+		Data:      resData,
+	}
+
+	return
+}
@@ -0,0 +1,229 @@
+package auditsvc
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+var auditLogColumns = []string{
+	"id", "actor_id", "action", "entity_type", "entity_id", "before_value", "after_value", "request_id", "ip", "created_at",
+}
+
+func initPgMock(t *testing.T) pgxmock.PgxPoolIface {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mock
+}
+
+// requestContext is the context of a request after JWTAuth, RequestIdUser and ClientIPUser.
+func requestContext(actorID uuid.UUID) context.Context {
+	ctx := context.WithValue(context.Background(), constant.JWTClaimsContextKey, model.JWTClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: actorID.String()},
+	})
+	ctx = context.WithValue(ctx, requestid.ConfigDefault.ContextKey, "request-1")
+	ctx = context.WithValue(ctx, constant.ClientIPContextKey, "10.0.0.1")
+
+	return ctx
+}
+
+func TestRecordSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := New(auditrepo.New(dbMock))
+
+	actorID := uuid.New()
+	entityID := uuid.New()
+
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			uuid.NullUUID{UUID: actorID, Valid: true},
+			entity.AuditActionUpdate,
+			entity.AuditEntityCategory,
+			entityID,
+			[]byte(`{"name":"before"}`),
+			[]byte(`{"name":"after"}`),
+			"request-1",
+			"10.0.0.1",
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err := svc.Record(requestContext(actorID), model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityCategory,
+		EntityID:   entityID,
+		Before:     map[string]string{"name": "before"},
+		After:      map[string]string{"name": "after"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRecordWithoutRequestSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := New(auditrepo.New(dbMock))
+
+	entityID := uuid.New()
+
+	// a job has no actor, request id or ip, a create has no before value
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			uuid.NullUUID{},
+			entity.AuditActionCreate,
+			entity.AuditEntityProduct,
+			entityID,
+			[]byte(nil),
+			[]byte(`{"name":"after"}`),
+			"",
+			"",
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err := svc.Record(context.Background(), model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   entityID,
+		After:      map[string]string{"name": "after"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRecordInvalidActorSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := New(auditrepo.New(dbMock))
+
+	ctx := context.WithValue(context.Background(), constant.JWTClaimsContextKey, model.JWTClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "not a uuid"},
+	})
+
+	// an actor which is not a uuid is recorded without an actor
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			uuid.NullUUID{}, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err := svc.Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionDelete,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   uuid.New(),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRecordFailedValidation(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := New(auditrepo.New(dbMock))
+
+	err := svc.Record(context.Background(), model.AuditLogRecordRequest{EntityType: entity.AuditEntityProduct, EntityID: uuid.New()})
+	assert.Error(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetListSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := New(auditrepo.New(dbMock))
+
+	actorID := uuid.New()
+	entityID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	where := regexp.QuoteMeta("FROM audit_logs a WHERE a.actor_id = $1 AND a.entity_type = $2 AND a.entity_id = $3 " +
+		"AND a.action = $4 AND a.created_at >= $5 AND a.created_at <= $6")
+	filterArgs := []any{actorID, entity.AuditEntityProduct, entityID, entity.AuditActionUpdate, from, to}
+
+	createdAt := time.Now()
+	dbMock.ExpectQuery(where + regexp.QuoteMeta(" ORDER BY a.id DESC LIMIT $7 OFFSET $8")).
+		WithArgs(append(filterArgs, 10, 10)...).
+		WillReturnRows(
+			pgxmock.NewRows(auditLogColumns).AddRow(
+				int64(11), uuid.NullUUID{UUID: actorID, Valid: true}, entity.AuditActionUpdate, entity.AuditEntityProduct,
+				entityID, []byte(`{"price":"10"}`), []byte(`{"price":"12"}`), "request-1", "10.0.0.1", createdAt,
+			),
+		)
+	dbMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(a.id)") + " " + where + "\\s*$").
+		WithArgs(filterArgs...).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(21))
+
+	res, err := svc.GetList(context.Background(), model.GetListAuditLogRequest{
+		ActorID:    uuid.NullUUID{UUID: actorID, Valid: true},
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   uuid.NullUUID{UUID: entityID, Valid: true},
+		Action:     entity.AuditActionUpdate,
+		From:       from.Format(time.RFC3339),
+		To:         to.Format(time.RFC3339),
+		Page:       2,
+		Limit:      10,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	assert.Equal(t, 21, res.TotalData)
+	assert.Equal(t, 3, res.TotalPage)
+	assert.Equal(t, 2, res.Page)
+	assert.Equal(t, 10, res.Limit)
+	assert.Equal(t, []model.AuditLogResponse{{
+		ID:         11,
+		ActorID:    uuid.NullUUID{UUID: actorID, Valid: true},
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   entityID,
+		Before:     json.RawMessage(`{"price":"10"}`),
+		After:      json.RawMessage(`{"price":"12"}`),
+		RequestID:  "request-1",
+		IP:         "10.0.0.1",
+		CreatedAt:  createdAt,
+	}}, res.Data)
+}
+
+func TestGetListWithoutFilterSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := New(auditrepo.New(dbMock))
+
+	dbMock.ExpectQuery(regexp.QuoteMeta("FROM audit_logs a ORDER BY a.id DESC LIMIT $1 OFFSET $2")).
+		WithArgs(20, 0).
+		WillReturnRows(pgxmock.NewRows(auditLogColumns))
+	dbMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(a.id) FROM audit_logs a") + "\\s*$").
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(40))
+
+	res, err := svc.GetList(context.Background(), model.GetListAuditLogRequest{Page: 1, Limit: 20})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	assert.Equal(t, 2, res.TotalPage)
+	assert.Empty(t, res.Data)
+}
+
+func TestGetListFailedValidation(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := New(auditrepo.New(dbMock))
+
+	for _, req := range []model.GetListAuditLogRequest{
+		{Page: 0, Limit: 10},
+		{Page: 1, Limit: 101},
+		{Page: 1, Limit: 10, From: "2024-01-01"},
+	} {
+		_, err := svc.GetList(context.Background(), req)
+		assert.Error(t, err)
+	}
+
+	// nothing is queried for an invalid request
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,119 @@
+package categoryctrl
+
+import (
+	"github.com/arfan21/vocagame/internal/category"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/exception"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ControllerHTTP struct {
+	svc category.Service
+}
+
+func New(svc category.Service) *ControllerHTTP {
+	return &ControllerHTTP{svc: svc}
+}
+
+// @Summary Create Category
+// @Description Create Category, admin only. Slug is generated from name when empty
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param body body model.CategoryCreateRequest true "Payload Create Category Request"
+// @Success 201 {object} pkgutil.HTTPResponse{data=model.CategoryResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/categories [post]
+func (ctrl ControllerHTTP) Create(c *fiber.Ctx) error {
+	var req model.CategoryCreateRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.Create(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusCreated).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusCreated,
+		Data: res,
+	})
+}
+
+// @Summary Get Categories
+// @Description Get category tree
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Success 200 {object} pkgutil.HTTPResponse{data=[]model.CategoryResponse}
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/categories [get]
+func (ctrl ControllerHTTP) GetTree(c *fiber.Ctx) error {
+	res, err := ctrl.svc.GetTree(c.UserContext())
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Update Category
+// @Description Update Category, admin only
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param categoryId path string true "Category ID"
+// @Param body body model.CategoryUpdateRequest true "Payload Update Category Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/categories/:categoryId [put]
+func (ctrl ControllerHTTP) Update(c *fiber.Ctx) error {
+	var req model.CategoryUpdateRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.ID, err = uuid.Parse(c.Params("categoryId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.Update(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Delete Category
+// @Description Delete Category without sub categories, admin only
+// @Tags Category
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param categoryId path string true "Category ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/categories/:categoryId [delete]
+func (ctrl ControllerHTTP) Delete(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("categoryId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.Delete(c.UserContext(), id)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
@@ -0,0 +1,22 @@
+package category
+
+import (
+	"context"
+
+	categoryrepo "github.com/arfan21/vocagame/internal/category/repository"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository interface {
+	Begin(ctx context.Context) (tx pgx.Tx, err error)
+	WithTx(tx pgx.Tx) *categoryrepo.Repository
+
+	Create(ctx context.Context, data entity.Category) (id uuid.UUID, err error)
+	GetByID(ctx context.Context, id uuid.UUID) (data entity.Category, err error)
+	GetAll(ctx context.Context) (result []entity.Category, err error)
+	IsDescendant(ctx context.Context, id uuid.UUID, ancestorID uuid.UUID) (result bool, err error)
+	Update(ctx context.Context, data entity.Category) (err error)
+	Delete(ctx context.Context, id uuid.UUID) (err error)
+}
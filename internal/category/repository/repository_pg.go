@@ -0,0 +1,199 @@
+package categoryrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/pkg/constant"
+	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type Repository struct {
+	db    dbpostgres.Queryer
+	rawDb dbpostgres.Raw
+}
+
+func New(raw dbpostgres.Raw, queryer dbpostgres.Queryer) *Repository {
+	return &Repository{
+		db:    queryer,
+		rawDb: raw,
+	}
+}
+
+func (r Repository) Begin(ctx context.Context) (tx pgx.Tx, err error) {
+	return r.rawDb.Begin(ctx)
+}
+
+func (r Repository) WithTx(tx pgx.Tx) *Repository {
+	r.db = tx
+	return &r
+}
+
+func (r Repository) Create(ctx context.Context, data entity.Category) (id uuid.UUID, err error) {
+	query := `
+		INSERT INTO categories (parent_id, name, slug)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+
+	err = r.db.QueryRow(ctx, query, data.ParentID, data.Name, data.Slug).Scan(&id)
+	if err != nil {
+		err = categoryPgError(err)
+		err = fmt.Errorf("category.repository.Create: failed to create category: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) GetByID(ctx context.Context, id uuid.UUID) (data entity.Category, err error) {
+	query := `
+		SELECT id, parent_id, name, slug, created_at, updated_at
+		FROM categories
+		WHERE id = $1
+	`
+
+	err = r.db.QueryRow(ctx, query, id).Scan(
+		&data.ID,
+		&data.ParentID,
+		&data.Name,
+		&data.Slug,
+		&data.CreatedAt,
+		&data.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrCategoryNotFound
+		}
+
+		err = fmt.Errorf("category.repository.GetByID: failed to get category: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) GetAll(ctx context.Context) (result []entity.Category, err error) {
+	query := `
+		SELECT id, parent_id, name, slug, created_at, updated_at
+		FROM categories
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		err = fmt.Errorf("category.repository.GetAll: failed to get categories: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data entity.Category
+
+		err = rows.Scan(
+			&data.ID,
+			&data.ParentID,
+			&data.Name,
+			&data.Slug,
+			&data.CreatedAt,
+			&data.UpdatedAt,
+		)
+		if err != nil {
+			err = fmt.Errorf("category.repository.GetAll: failed to scan category: %w", err)
+			return
+		}
+
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("category.repository.GetAll: failed after scan categories: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+// IsDescendant reports whether id is ancestorID itself or one of its descendants.
+func (r Repository) IsDescendant(ctx context.Context, id uuid.UUID, ancestorID uuid.UUID) (result bool, err error) {
+	query := `
+		WITH RECURSIVE category_tree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+		)
+		SELECT EXISTS (SELECT 1 FROM category_tree WHERE id = $2)
+	`
+
+	err = r.db.QueryRow(ctx, query, ancestorID, id).Scan(&result)
+	if err != nil {
+		err = fmt.Errorf("category.repository.IsDescendant: failed to check category descendant: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) Update(ctx context.Context, data entity.Category) (err error) {
+	query := `
+		UPDATE categories
+		SET
+			parent_id = $1,
+			name = $2,
+			slug = $3
+		WHERE
+			id = $4
+	`
+
+	_, err = r.db.Exec(ctx, query, data.ParentID, data.Name, data.Slug, data.ID)
+	if err != nil {
+		err = categoryPgError(err)
+		err = fmt.Errorf("category.repository.Update: failed to update category: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	query := `
+		DELETE FROM categories
+		WHERE id = $1
+	`
+
+	_, err = r.db.Exec(ctx, query, id)
+	if err != nil {
+		var pgxError *pgconn.PgError
+		if errors.As(err, &pgxError) {
+			if pgxError.Code == constant.ErrSQLForeignKeyViolation {
+				err = constant.ErrCategoryHasChildren
+			}
+		}
+
+		err = fmt.Errorf("category.repository.Delete: failed to delete category: %w", err)
+		return
+	}
+
+	return
+}
+
+// categoryPgError maps constraint violations on insert or update of a category.
+func categoryPgError(err error) error {
+	var pgxError *pgconn.PgError
+	if errors.As(err, &pgxError) {
+		switch pgxError.Code {
+		case constant.ErrSQLUniqueViolation:
+			return constant.ErrCategorySlugAlreadyExists
+		case constant.ErrSQLForeignKeyViolation:
+			return constant.ErrCategoryParentNotFound
+		}
+	}
+
+	return err
+}
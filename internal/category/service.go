@@ -0,0 +1,15 @@
+package category
+
+import (
+	"context"
+
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/google/uuid"
+)
+
+type Service interface {
+	Create(ctx context.Context, req model.CategoryCreateRequest) (res model.CategoryResponse, err error)
+	GetTree(ctx context.Context) (res []model.CategoryResponse, err error)
+	Update(ctx context.Context, req model.CategoryUpdateRequest) (err error)
+	Delete(ctx context.Context, id uuid.UUID) (err error)
+}
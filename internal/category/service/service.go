@@ -0,0 +1,271 @@
+package categorysvc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/arfan21/vocagame/internal/audit"
+	"github.com/arfan21/vocagame/internal/category"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/validation"
+	"github.com/google/uuid"
+)
+
+type Service struct {
+	repo     category.Repository
+	auditSvc audit.Service
+}
+
+func New(repo category.Repository, auditSvc audit.Service) *Service {
+	return &Service{repo: repo, auditSvc: auditSvc}
+}
+
+// slugify turns name into a lowercase slug, e.g. "Mobile Legends" becomes "mobile-legends".
+func slugify(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.ToLower(strings.Join(words, "-"))
+}
+
+func toCategoryResponse(data entity.Category) model.CategoryResponse {
+	return model.CategoryResponse{
+		ID:       data.ID,
+		ParentID: data.ParentID,
+		Name:     data.Name,
+		Slug:     data.Slug,
+	}
+}
+
+func (s Service) Create(ctx context.Context, req model.CategoryCreateRequest) (res model.CategoryResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("category.service.Create: failed to validate request : %w", err)
+		return
+	}
+
+	if req.Slug == "" {
+		req.Slug = req.Name
+	}
+
+	data := entity.Category{
+		ParentID: req.ParentID,
+		Name:     strings.TrimSpace(req.Name),
+		Slug:     slugify(req.Slug),
+	}
+
+	if data.Slug == "" {
+		err = constant.ErrCategoryInvalidSlug
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("category.service.Create: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("category.service.Create: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	data.ID, err = s.repo.WithTx(tx).Create(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("category.service.Create: failed to create category : %w", err)
+		return
+	}
+
+	res = toCategoryResponse(data)
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntityCategory,
+		EntityID:   data.ID,
+		After:      res,
+	})
+	if err != nil {
+		err = fmt.Errorf("category.service.Create: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// GetTree returns the root categories with their descendants nested as children.
+func (s Service) GetTree(ctx context.Context) (res []model.CategoryResponse, err error) {
+	results, err := s.repo.GetAll(ctx)
+	if err != nil {
+		err = fmt.Errorf("category.service.GetTree: failed to get categories from db : %w", err)
+		return
+	}
+
+	childrenByParent := make(map[uuid.UUID][]entity.Category)
+	for _, result := range results {
+		if result.ParentID.Valid {
+			childrenByParent[result.ParentID.UUID] = append(childrenByParent[result.ParentID.UUID], result)
+		}
+	}
+
+	var build func(data entity.Category) model.CategoryResponse
+	build = func(data entity.Category) model.CategoryResponse {
+		node := toCategoryResponse(data)
+		for _, child := range childrenByParent[data.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+
+		return node
+	}
+
+	res = make([]model.CategoryResponse, 0)
+	for _, result := range results {
+		if !result.ParentID.Valid {
+			res = append(res, build(result))
+		}
+	}
+
+	return
+}
+
+func (s Service) Update(ctx context.Context, req model.CategoryUpdateRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("category.service.Update: failed to validate request : %w", err)
+		return
+	}
+
+	before, err := s.repo.GetByID(ctx, req.ID)
+	if err != nil {
+		err = fmt.Errorf("category.service.Update: failed to get category : %w", err)
+		return
+	}
+
+	if req.Slug == "" {
+		req.Slug = req.Name
+	}
+
+	data := entity.Category{
+		ID:       req.ID,
+		ParentID: req.ParentID,
+		Name:     strings.TrimSpace(req.Name),
+		Slug:     slugify(req.Slug),
+	}
+
+	if data.Slug == "" {
+		err = constant.ErrCategoryInvalidSlug
+		return
+	}
+
+	// moving the category under its own subtree would create a cycle
+	if data.ParentID.Valid {
+		isDescendant, errCheck := s.repo.IsDescendant(ctx, data.ParentID.UUID, data.ID)
+		if errCheck != nil {
+			err = fmt.Errorf("category.service.Update: failed to check parent category : %w", errCheck)
+			return
+		}
+
+		if isDescendant {
+			err = constant.ErrCategoryInvalidParent
+			return
+		}
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("category.service.Update: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("category.service.Update: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).Update(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("category.service.Update: failed to update category : %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityCategory,
+		EntityID:   data.ID,
+		Before:     toCategoryResponse(before),
+		After:      toCategoryResponse(data),
+	})
+	if err != nil {
+		err = fmt.Errorf("category.service.Update: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// Delete removes a category without sub categories, products only lose the category assignment.
+func (s Service) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	before, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		err = fmt.Errorf("category.service.Delete: failed to get category : %w", err)
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("category.service.Delete: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("category.service.Delete: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).Delete(ctx, id)
+	if err != nil {
+		err = fmt.Errorf("category.service.Delete: failed to delete category : %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionDelete,
+		EntityType: entity.AuditEntityCategory,
+		EntityID:   id,
+		Before:     toCategoryResponse(before),
+	})
+	if err != nil {
+		err = fmt.Errorf("category.service.Delete: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,135 @@
+package categorysvc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	auditsvc "github.com/arfan21/vocagame/internal/audit/service"
+	categoryrepo "github.com/arfan21/vocagame/internal/category/repository"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+var categoryColumns = []string{"id", "parent_id", "name", "slug", "created_at", "updated_at"}
+
+func initPgMock(t *testing.T) pgxmock.PgxPoolIface {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mock
+}
+
+func initDepMock(db pgxmock.PgxPoolIface) (svc *Service) {
+	auditSvc := auditsvc.New(auditrepo.New(db))
+	svc = New(categoryrepo.New(db, db), auditSvc)
+
+	return
+}
+
+func expectGetCategory(dbMock pgxmock.PgxPoolIface, id uuid.UUID, parentID uuid.NullUUID) {
+	dbMock.ExpectQuery("SELECT (.+) FROM categories WHERE id = (.+)").
+		WithArgs(id).
+		WillReturnRows(
+			pgxmock.NewRows(categoryColumns).
+				AddRow(id, parentID, "Mobile Legends", "mobile-legends", time.Now(), time.Now()),
+		)
+}
+
+func expectIsDescendant(dbMock pgxmock.PgxPoolIface, id uuid.UUID, ancestorID uuid.UUID, result bool) {
+	dbMock.ExpectQuery("WITH RECURSIVE category_tree AS (.+) SELECT EXISTS (.+)").
+		WithArgs(ancestorID, id).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(result))
+}
+
+func TestUpdateCategoryMoveUnderOtherCategorySuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	id := uuid.New()
+	parentID := uuid.New()
+	actorID := uuid.New()
+
+	expectGetCategory(dbMock, id, uuid.NullUUID{})
+	expectIsDescendant(dbMock, parentID, id, false)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec("UPDATE categories SET (.+) WHERE (.+)").
+		WithArgs(uuid.NullUUID{UUID: parentID, Valid: true}, "Mobile Legends", "mobile-legends", id).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			uuid.NullUUID{UUID: actorID, Valid: true},
+			entity.AuditActionUpdate,
+			entity.AuditEntityCategory,
+			id,
+			[]byte(fmt.Sprintf(`{"id":"%s","parent_id":null,"name":"Mobile Legends","slug":"mobile-legends"}`, id)),
+			[]byte(fmt.Sprintf(`{"id":"%s","parent_id":"%s","name":"Mobile Legends","slug":"mobile-legends"}`, id, parentID)),
+			"request-1",
+			"10.0.0.1",
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	dbMock.ExpectCommit()
+
+	ctx := context.WithValue(context.Background(), constant.JWTClaimsContextKey, model.JWTClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: actorID.String()},
+	})
+	ctx = context.WithValue(ctx, requestid.ConfigDefault.ContextKey, "request-1")
+	ctx = context.WithValue(ctx, constant.ClientIPContextKey, "10.0.0.1")
+
+	err := svc.Update(ctx, model.CategoryUpdateRequest{
+		ID:       id,
+		ParentID: uuid.NullUUID{UUID: parentID, Valid: true},
+		Name:     "Mobile Legends",
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateCategoryFailedParentIsDescendant(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	id := uuid.New()
+	grandchildID := uuid.New()
+
+	expectGetCategory(dbMock, id, uuid.NullUUID{})
+	expectIsDescendant(dbMock, grandchildID, id, true)
+
+	err := svc.Update(context.Background(), model.CategoryUpdateRequest{
+		ID:       id,
+		ParentID: uuid.NullUUID{UUID: grandchildID, Valid: true},
+		Name:     "Mobile Legends",
+	})
+	assert.ErrorIs(t, err, constant.ErrCategoryInvalidParent)
+	// nothing is written once a cycle is detected
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateCategoryFailedParentIsItself(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	id := uuid.New()
+
+	expectGetCategory(dbMock, id, uuid.NullUUID{})
+	expectIsDescendant(dbMock, id, id, true)
+
+	err := svc.Update(context.Background(), model.CategoryUpdateRequest{
+		ID:       id,
+		ParentID: uuid.NullUUID{UUID: id, Valid: true},
+		Name:     "Mobile Legends",
+	})
+	assert.ErrorIs(t, err, constant.ErrCategoryInvalidParent)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
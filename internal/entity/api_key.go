@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// APIKeyTokenPrefix starts every API key, a key is "vk_<prefix>_<secret>".
+const APIKeyTokenPrefix = "vk_"
+
+const (
+	APIKeyScopeProductsRead      = "products:read"
+	APIKeyScopeProductsWrite     = "products:write"
+	APIKeyScopeTransactionsRead  = "transactions:read"
+	APIKeyScopeTransactionsWrite = "transactions:write"
+	APIKeyScopeWalletsRead       = "wallets:read"
+)
+
+// APIKey lets the backend of a user call the api without logging in. Only the hash of the secret is stored,
+// the key is found by its prefix.
+type APIKey struct {
+	ID         uuid.UUID   `json:"id"`
+	UserID     uuid.UUID   `json:"user_id"`
+	Name       string      `json:"name"`
+	Prefix     string      `json:"prefix"`
+	SecretHash string      `json:"secret_hash"`
+	Scopes     []string    `json:"scopes"`
+	LastUsedAt null.Time   `json:"last_used_at"`
+	LastUsedIP null.String `json:"last_used_ip"`
+	ExpiresAt  null.Time   `json:"expires_at"`
+	RevokedAt  null.Time   `json:"revoked_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionCreate         = "CREATE"
+	AuditActionUpdate         = "UPDATE"
+	AuditActionDelete         = "DELETE"
+	AuditActionDelist         = "DELIST"
+	AuditActionRestore        = "RESTORE"
+	AuditActionPublish        = "PUBLISH"
+	AuditActionUnpublish      = "UNPUBLISH"
+	AuditActionPasswordChange = "PASSWORD_CHANGE"
+	AuditActionUnlock         = "UNLOCK"
+	AuditActionEnable2FA      = "ENABLE_2FA"
+	AuditActionDisable2FA     = "DISABLE_2FA"
+	AuditActionRevoke         = "REVOKE"
+)
+
+const (
+	AuditEntityUser        = "user"
+	AuditEntityProduct     = "product"
+	AuditEntityWallet      = "wallet"
+	AuditEntityTransaction = "transaction"
+	AuditEntityCategory    = "category"
+	AuditEntityReservation = "reservation"
+	AuditEntityReview      = "review"
+	AuditEntitySale        = "product_sale"
+	AuditEntityAPIKey      = "api_key"
+)
+
+type AuditLog struct {
+	ID         int64         `json:"id"`
+	ActorID    uuid.NullUUID `json:"actor_id"`
+	Action     string        `json:"action"`
+	EntityType string        `json:"entity_type"`
+	EntityID   uuid.UUID     `json:"entity_id"`
+	Before     []byte        `json:"before"`
+	After      []byte        `json:"after"`
+	RequestID  string        `json:"request_id"`
+	IP         string        `json:"ip"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+type ListAuditLogFilter struct {
+	ActorID    uuid.NullUUID `json:"actor_id"`
+	EntityType string        `json:"entity_type"`
+	EntityID   uuid.NullUUID `json:"entity_id"`
+	Action     string        `json:"action"`
+	From       time.Time     `json:"from"`
+	To         time.Time     `json:"to"`
+	Page       int           `json:"page"`
+	Limit      int           `json:"limit"`
+}
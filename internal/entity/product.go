@@ -5,29 +5,138 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"gopkg.in/guregu/null.v4"
 )
 
 type Product struct {
-	ID          uuid.UUID       `json:"id"`
-	UserID      uuid.UUID       `json:"user_id"`
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	Stok        int             `json:"stok"`
-	Price       decimal.Decimal `json:"price"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	User        User            `json:"user"`
+	ID          uuid.UUID   `json:"id"`
+	UserID      uuid.UUID   `json:"user_id"`
+	SKU         null.String `json:"sku"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Stok        int         `json:"stok"`
+	// ReservedStok is the part of stok held by active reservations
+	ReservedStok int              `json:"reserved_stok"`
+	Price        decimal.Decimal  `json:"price"`
+	FinalPrice   decimal.Decimal  `json:"final_price"`
+	Sale         *ProductSale     `json:"sale"`
+	SoldCount    int              `json:"sold_count"`
+	RatingCount  int              `json:"rating_count"`
+	RatingSum    int              `json:"rating_sum"`
+	Version      int              `json:"version"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+	DeletedAt    null.Time        `json:"deleted_at"`
+	DelistedAt   null.Time        `json:"delisted_at"`
+	PublishedAt  null.Time        `json:"published_at"`
+	User         User             `json:"user"`
+	Categories   []Category       `json:"categories"`
+	Tags         []string         `json:"tags"`
+	Images       []ProductImage   `json:"images"`
+	Variants     []ProductVariant `json:"variants"`
+	HasVariants  bool             `json:"has_variants"`
+	Search       ProductSearch    `json:"search"`
+}
+
+// ProductSearch is only filled when products are listed with a full-text search query.
+type ProductSearch struct {
+	Rank                 float64 `json:"rank"`
+	NameHighlight        string  `json:"name_highlight"`
+	DescriptionHighlight string  `json:"description_highlight"`
 }
 
 func (Product) TableName() string {
 	return "products"
 }
 
+type ProductImage struct {
+	ID           uuid.UUID `json:"id"`
+	ProductID    uuid.UUID `json:"product_id"`
+	ObjectKey    string    `json:"object_key"`
+	ThumbnailKey string    `json:"thumbnail_key"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Position     int       `json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (ProductImage) TableName() string {
+	return "product_images"
+}
+
+// ProductVariant is a purchasable option of a product, e.g. a diamond pack size.
+// Stok and price of a product with variants are derived from its variants.
+type ProductVariant struct {
+	ID           uuid.UUID       `json:"id"`
+	ProductID    uuid.UUID       `json:"product_id"`
+	SKU          string          `json:"sku"`
+	Name         string          `json:"name"`
+	Price        decimal.Decimal `json:"price"`
+	Stok         int             `json:"stok"`
+	ReservedStok int             `json:"reserved_stok"`
+	SoldCount    int             `json:"sold_count"`
+	Sale         *ProductSale    `json:"sale"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	DeletedAt    null.Time       `json:"deleted_at"`
+}
+
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// Lifecycle states of a product, a product is only visible to buyers when it is published.
+const (
+	ProductStatusDraft     = "draft"
+	ProductStatusScheduled = "scheduled"
+	ProductStatusPublished = "published"
+	ProductStatusUnlisted  = "unlisted"
+)
+
+const (
+	ProductSortNewest      = "newest"
+	ProductSortPriceAsc    = "price_asc"
+	ProductSortPriceDesc   = "price_desc"
+	ProductSortBestSelling = "best_selling"
+	ProductSortRelevance   = "relevance"
+)
+
 type ListProductFilter struct {
-	ID            uuid.NullUUID `json:"id"`
-	UserID        uuid.NullUUID `jsonL:"user_id"`
-	Name          string        `query:"name" json:"name"`
-	Page          int           `query:"page" json:"page" validate:"min=1"`
-	Limit         int           `query:"limit" json:"limit" validate:"min=1"`
-	DisableOffset bool          `json:"-"`
+	ID              uuid.NullUUID       `json:"id"`
+	IDs             []uuid.UUID         `json:"ids"`
+	UserID          uuid.NullUUID       `jsonL:"user_id"`
+	Name            string              `query:"name" json:"name"`
+	Query           string              `query:"q" json:"q"`
+	Fuzzy           bool                `query:"fuzzy" json:"fuzzy"`
+	MinPrice        decimal.NullDecimal `json:"min_price"`
+	MaxPrice        decimal.NullDecimal `json:"max_price"`
+	InStock         bool                `json:"in_stock"`
+	CategoryID      uuid.NullUUID       `json:"category_id"`
+	Tag             string              `json:"tag"`
+	Archived        bool                `json:"archived"`
+	IncludeDelisted bool                `json:"include_delisted"`
+	Status          string              `json:"status"`
+	CreatedFrom     time.Time           `json:"created_from"`
+	CreatedTo       time.Time           `json:"created_to"`
+	Sort            string              `json:"sort"`
+	Cursor          *ProductCursor      `json:"cursor"`
+	Page            int                 `query:"page" json:"page" validate:"min=1"`
+	Limit           int                 `query:"limit" json:"limit" validate:"min=1"`
+	DisableOffset   bool                `json:"-"`
+}
+
+// ProductCursor is the sort key of the last product on the previous page, used for keyset pagination.
+type ProductCursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// ProductCacheStats are counters of the product catalog cache of an instance.
+type ProductCacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+	Errors int64 `json:"errors"`
 }
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockReservationStatus string
+
+const (
+	StockReservationStatusActive   StockReservationStatus = "ACTIVE"
+	StockReservationStatusConsumed StockReservationStatus = "CONSUMED"
+	StockReservationStatusReleased StockReservationStatus = "RELEASED"
+	StockReservationStatusExpired  StockReservationStatus = "EXPIRED"
+)
+
+// StockReservation holds stok of products for a user until it expires or is consumed by a checkout.
+type StockReservation struct {
+	ID            uuid.UUID              `json:"id"`
+	UserID        uuid.UUID              `json:"user_id"`
+	Status        StockReservationStatus `json:"status"`
+	ExpiresAt     time.Time              `json:"expires_at"`
+	TransactionID uuid.NullUUID          `json:"transaction_id"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	// IsExpired is evaluated by the database, which also sets expires_at
+	IsExpired bool                   `json:"-"`
+	Items     []StockReservationItem `json:"items"`
+}
+
+func (StockReservation) TableName() string {
+	return "stock_reservations"
+}
+
+type StockReservationItem struct {
+	ID            uuid.UUID     `json:"id"`
+	ReservationID uuid.UUID     `json:"reservation_id"`
+	ProductID     uuid.UUID     `json:"product_id"`
+	VariantID     uuid.NullUUID `json:"variant_id"`
+	Qty           int           `json:"qty"`
+}
+
+func (StockReservationItem) TableName() string {
+	return "stock_reservation_items"
+}
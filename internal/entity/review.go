@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type ProductReview struct {
+	ID                  uuid.UUID   `json:"id"`
+	ProductID           uuid.UUID   `json:"product_id"`
+	TransactionDetailID uuid.UUID   `json:"transaction_detail_id"`
+	UserID              uuid.UUID   `json:"user_id"`
+	Rating              int         `json:"rating"`
+	Comment             string      `json:"comment"`
+	Reply               null.String `json:"reply"`
+	RepliedAt           null.Time   `json:"replied_at"`
+	CreatedAt           time.Time   `json:"created_at"`
+	UpdatedAt           time.Time   `json:"updated_at"`
+	User                User        `json:"user"`
+	// SellerID is the owner of the reviewed product
+	SellerID uuid.UUID `json:"seller_id"`
+}
+
+func (ProductReview) TableName() string {
+	return "product_reviews"
+}
+
+// ReviewPurchase is a purchased line of a buyer, only lines of completed transactions can be reviewed.
+type ReviewPurchase struct {
+	TransactionDetailID uuid.UUID         `json:"transaction_detail_id"`
+	ProductID           uuid.UUID         `json:"product_id"`
+	Status              TransactionStatus `json:"status"`
+}
+
+const (
+	ProductReviewSortNewest     = "newest"
+	ProductReviewSortOldest     = "oldest"
+	ProductReviewSortRatingDesc = "rating_desc"
+	ProductReviewSortRatingAsc  = "rating_asc"
+)
+
+type ListProductReviewFilter struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Rating    int       `json:"rating"`
+	Sort      string    `json:"sort"`
+	Page      int       `json:"page"`
+	Limit     int       `json:"limit"`
+}
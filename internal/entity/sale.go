@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gopkg.in/guregu/null.v4"
+)
+
+// ProductSale is a discounted price of a product or one of its variants between StartsAt and EndsAt.
+// A sale with a quota is a flash sale, it ends once Sold reaches the quota.
+type ProductSale struct {
+	ID           uuid.UUID       `json:"id"`
+	ProductID    uuid.UUID       `json:"product_id"`
+	VariantID    uuid.NullUUID   `json:"variant_id"`
+	SalePrice    decimal.Decimal `json:"sale_price"`
+	StartsAt     time.Time       `json:"starts_at"`
+	EndsAt       time.Time       `json:"ends_at"`
+	Quota        null.Int        `json:"quota"`
+	Sold         int             `json:"sold"`
+	PerUserLimit null.Int        `json:"per_user_limit"`
+	CreatedBy    uuid.UUID       `json:"created_by"`
+	CancelledAt  null.Time       `json:"cancelled_at"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+func (ProductSale) TableName() string {
+	return "product_sales"
+}
+
+type PriceHistoryReason string
+
+const (
+	PriceHistoryReasonPriceChange   PriceHistoryReason = "PRICE_CHANGE"
+	PriceHistoryReasonSaleScheduled PriceHistoryReason = "SALE_SCHEDULED"
+	PriceHistoryReasonSaleCancelled PriceHistoryReason = "SALE_CANCELLED"
+)
+
+// ProductPriceHistory records a change of the base price or a sale of a product or one of its variants.
+type ProductPriceHistory struct {
+	ID        uuid.UUID          `json:"id"`
+	ProductID uuid.UUID          `json:"product_id"`
+	VariantID uuid.NullUUID      `json:"variant_id"`
+	Reason    PriceHistoryReason `json:"reason"`
+	Price     decimal.Decimal    `json:"price"`
+	SaleID    uuid.NullUUID      `json:"sale_id"`
+	StartsAt  null.Time          `json:"starts_at"`
+	EndsAt    null.Time          `json:"ends_at"`
+	ActorID   uuid.NullUUID      `json:"actor_id"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+func (ProductPriceHistory) TableName() string {
+	return "product_price_history"
+}
+
+type ListProductPriceHistoryFilter struct {
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+	Page      int
+	Limit     int
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type StockMovementReason string
+
+const (
+	StockMovementReasonSale       StockMovementReason = "SALE"
+	StockMovementReasonRestock    StockMovementReason = "RESTOCK"
+	StockMovementReasonAdjustment StockMovementReason = "ADJUSTMENT"
+	StockMovementReasonReturn     StockMovementReason = "RETURN"
+)
+
+// StockMovement records a change of the stok of a product or one of its variants.
+type StockMovement struct {
+	ID            uuid.UUID           `json:"id"`
+	ProductID     uuid.UUID           `json:"product_id"`
+	VariantID     uuid.NullUUID       `json:"variant_id"`
+	Delta         int                 `json:"delta"`
+	Reason        StockMovementReason `json:"reason"`
+	StokAfter     int                 `json:"stok_after"`
+	ActorID       uuid.NullUUID       `json:"actor_id"`
+	TransactionID uuid.NullUUID       `json:"transaction_id"`
+	Note          null.String         `json:"note"`
+	CreatedAt     time.Time           `json:"created_at"`
+}
+
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
+
+type ListStockMovementFilter struct {
+	ProductID uuid.UUID
+	VariantID uuid.NullUUID
+	Reason    string
+	Page      int
+	Limit     int
+}
@@ -43,13 +43,16 @@ func (TransactionType) TableName() string {
 }
 
 type TransactionDetail struct {
-	ID            uuid.NullUUID      `json:"id"`
-	TransactionID uuid.NullUUID      `json:"transaction_id"`
-	ProductID     uuid.NullUUID      `json:"product_id"`
-	Qty           null.Int           `json:"qty"`
-	CreatedAt     null.Time          `json:"created_at"`
-	UpdatedAt     null.Time          `json:"updated_at"`
-	Product       TransactionProduct `json:"product"`
+	ID            uuid.NullUUID       `json:"id"`
+	TransactionID uuid.NullUUID       `json:"transaction_id"`
+	ProductID     uuid.NullUUID       `json:"product_id"`
+	VariantID     uuid.NullUUID       `json:"variant_id"`
+	Qty           null.Int            `json:"qty"`
+	Price         decimal.NullDecimal `json:"price"`
+	SaleID        uuid.NullUUID       `json:"sale_id"`
+	CreatedAt     null.Time           `json:"created_at"`
+	UpdatedAt     null.Time           `json:"updated_at"`
+	Product       TransactionProduct  `json:"product"`
 }
 
 func (TransactionDetail) TableName() string {
@@ -57,6 +60,8 @@ func (TransactionDetail) TableName() string {
 }
 
 type TransactionProduct struct {
-	Name  null.String         `json:"name"`
-	Price decimal.NullDecimal `json:"price"`
+	Name        null.String         `json:"name"`
+	Price       decimal.NullDecimal `json:"price"`
+	VariantName null.String         `json:"variant_name"`
+	VariantSKU  null.String         `json:"variant_sku"`
 }
@@ -4,22 +4,65 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
 )
 
 type User struct {
-	ID        uuid.UUID `json:"id"`
-	Fullname  string    `json:"fullname"`
-	Email     string    `json:"email"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uuid.UUID   `json:"id"`
+	Fullname        string      `json:"fullname"`
+	Email           string      `json:"email"`
+	Password        string      `json:"password"`
+	Role            string      `json:"role"`
+	EmailVerifiedAt null.Time   `json:"email_verified_at"`
+	AvatarURL       null.String `json:"avatar_url"`
+	Phone           null.String `json:"phone"`
+	Language        string      `json:"language"`
+	// PendingEmail replaces Email once it is verified
+	PendingEmail null.String `json:"pending_email"`
+	// TOTPSecret is set on enrollment, two factor authentication is on once TOTPEnabledAt is set
+	TOTPSecret    null.String `json:"totp_secret"`
+	TOTPEnabledAt null.Time   `json:"totp_enabled_at"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
 }
 
 func (User) TableName() string {
 	return "users"
 }
 
+// UserRefreshToken is the payload of a refresh token. Every refresh rotates the token,
+// the tokens rotated from the same login share FamilyID.
 type UserRefreshToken struct {
-	ID    uuid.UUID `json:"id"`
-	Email string    `json:"email"`
+	ID            uuid.UUID `json:"id"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	FamilyID      uuid.UUID `json:"family_id"`
+}
+
+// UserSession is a login of the user on a device. Its ID is the family of its refresh tokens,
+// it ends when the refresh token family is revoked or expires.
+type UserSession struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// UserLoginChallenge is a login which passed the password and waits for the two factor code.
+// Email is the email the failed attempts are counted on.
+type UserLoginChallenge struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Email      string    `json:"email"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
 }
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHeader carries the API key of a server to server call, the key may be sent as the bearer token too.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator returns the identity of the user of an API key.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (claims model.JWTClaims, err error)
+}
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// UseAPIKeyAuthenticator makes Auth accept API keys, it must be called before the server starts.
+func UseAPIKeyAuthenticator(authenticator APIKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
+// Auth accepts an API key or a JWT, both end in the same claims as JWTAuth.
+// A route using Auth must check the scope of API keys with RequireScope.
+func Auth(c *fiber.Ctx) error {
+	key := c.Get(APIKeyHeader)
+	if key == "" {
+		if token, ok := strings.CutPrefix(c.Get("Authorization"), "Bearer "); ok && strings.HasPrefix(token, entity.APIKeyTokenPrefix) {
+			key = token
+		}
+	}
+
+	if key == "" || apiKeyAuthenticator == nil {
+		return JWTAuth(c)
+	}
+
+	claims, err := apiKeyAuthenticator.Authenticate(c.UserContext(), key)
+	if err != nil {
+		return err
+	}
+
+	setClaims(c, claims)
+	return c.Next()
+}
+
+// RequireScope must be placed after Auth. An API key needs the scope, a JWT has every scope of its user.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+				Code:    fiber.StatusUnauthorized,
+				Message: "invalid or expired token",
+			})
+		}
+
+		if claims.APIKeyID != "" && !slices.Contains(claims.Scopes, scope) {
+			return c.Status(fiber.StatusForbidden).JSON(pkgutil.HTTPResponse{
+				Code:    fiber.StatusForbidden,
+				Message: "api key is missing scope " + scope,
+			})
+		}
+
+		return c.Next()
+	}
+}
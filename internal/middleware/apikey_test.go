@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// requireScope calls a route needing scope as claims, without claims when claims is nil.
+func requireScope(t *testing.T, scope string, claims *model.JWTClaims) int {
+	app := fiber.New()
+	app.Get("/products", func(c *fiber.Ctx) error {
+		if claims != nil {
+			c.Locals(constant.JWTClaimsContextKey, *claims)
+		}
+		return c.Next()
+	}, RequireScope(scope), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/products", nil), -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.StatusCode
+}
+
+func apiKeyClaims(scopes ...string) *model.JWTClaims {
+	return &model.JWTClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.NewString()},
+		APIKeyID:         uuid.NewString(),
+		Scopes:           scopes,
+	}
+}
+
+func TestRequireScopeSuccess(t *testing.T) {
+	claims := apiKeyClaims(entity.APIKeyScopeProductsRead, entity.APIKeyScopeProductsWrite)
+	assert.Equal(t, fiber.StatusOK, requireScope(t, entity.APIKeyScopeProductsWrite, claims))
+
+	// a JWT has every scope of its user
+	jwtClaims := &model.JWTClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.NewString()}}
+	assert.Equal(t, fiber.StatusOK, requireScope(t, entity.APIKeyScopeProductsWrite, jwtClaims))
+}
+
+func TestRequireScopeFailed(t *testing.T) {
+	claims := apiKeyClaims(entity.APIKeyScopeProductsRead)
+	assert.Equal(t, fiber.StatusForbidden, requireScope(t, entity.APIKeyScopeProductsWrite, claims))
+
+	// a key without scopes is denied every scope
+	assert.Equal(t, fiber.StatusForbidden, requireScope(t, entity.APIKeyScopeProductsRead, apiKeyClaims()))
+
+	assert.Equal(t, fiber.StatusUnauthorized, requireScope(t, entity.APIKeyScopeProductsRead, nil))
+}
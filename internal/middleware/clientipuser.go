@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/gofiber/fiber/v2"
+)
+
+func ClientIPUser() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userCtx := c.UserContext()
+		userCtx = context.WithValue(userCtx, constant.ClientIPContextKey, c.IP())
+		c.SetUserContext(userCtx)
+
+		return c.Next()
+	}
+}
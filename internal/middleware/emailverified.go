@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/arfan21/vocagame/config"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireVerifiedEmail must be placed after JWTAuth or Auth, it only blocks when EMAIL_VERIFICATION_REQUIRED is set.
+// The claim is updated on refresh, so a user has to refresh the access token after verifying.
+func RequireVerifiedEmail(c *fiber.Ctx) error {
+	if !config.GetConfig().EmailVerification.Required {
+		return c.Next()
+	}
+
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	if !claims.EmailVerified {
+		return c.Status(fiber.StatusForbidden).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusForbidden,
+			Message: constant.ErrEmailNotVerified.Error(),
+		})
+	}
+
+	return c.Next()
+}
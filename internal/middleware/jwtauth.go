@@ -1,18 +1,40 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/arfan21/vocagame/config"
 	"github.com/arfan21/vocagame/internal/model"
 	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/jwtkey"
 	"github.com/arfan21/vocagame/pkg/logger"
 	"github.com/arfan21/vocagame/pkg/pkgutil"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenRevocation reports whether a valid access token was revoked before it expired.
+type TokenRevocation interface {
+	IsAccessTokenRevoked(ctx context.Context, claims model.JWTClaims) (revoked bool, err error)
+}
+
+var (
+	tokenRevocation TokenRevocation
+	jwtKeys         *jwtkey.KeySet
+)
+
+// UseTokenRevocation makes JWTAuth reject revoked access tokens, it must be called before the server starts.
+func UseTokenRevocation(revocation TokenRevocation) {
+	tokenRevocation = revocation
+}
+
+// UseJWTKeys sets the keys JWTAuth verifies access tokens with, it must be called before the server starts.
+func UseJWTKeys(keys *jwtkey.KeySet) {
+	jwtKeys = keys
+}
+
 func JWTAuth(c *fiber.Ctx) error {
 	// fetch token
 	head := c.Get("Authorization", "")
@@ -32,13 +54,13 @@ func JWTAuth(c *fiber.Ctx) error {
 	}
 
 	// validate token
-	t, err := jwt.ParseWithClaims(token[1], &model.JWTClaims{}, func(t *jwt.Token) (interface{}, error) {
-		if t.Method.Alg() != jwt.SigningMethodHS256.Name {
-			return nil, fmt.Errorf("middleware: invalid token signing algorithm")
-		}
-
-		return []byte(config.GetConfig().JWT.AccessTokenSecret), nil
-	})
+	t, err := jwt.ParseWithClaims(
+		token[1],
+		&model.JWTClaims{},
+		jwtKeys.Keyfunc,
+		jwt.WithValidMethods(jwtKeys.Algs()),
+		jwt.WithIssuer(config.GetConfig().JWT.Issuer),
+	)
 	if err != nil {
 		logger.Log(c.UserContext()).Error().Msgf("middleware: failed to parse jwt token: %v", err)
 		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
@@ -49,7 +71,21 @@ func JWTAuth(c *fiber.Ctx) error {
 
 	claims, ok := t.Claims.(*model.JWTClaims)
 	if ok && t.Valid && claims != nil {
-		c.Locals(constant.JWTClaimsContextKey, *claims)
+		if tokenRevocation != nil {
+			revoked, err := tokenRevocation.IsAccessTokenRevoked(c.UserContext(), *claims)
+			if err != nil {
+				return fmt.Errorf("middleware: failed to check token revocation: %w", err)
+			}
+
+			if revoked {
+				return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+					Code:    fiber.StatusUnauthorized,
+					Message: "invalid or expired token",
+				})
+			}
+		}
+
+		setClaims(c, *claims)
 		return c.Next()
 	}
 
@@ -59,3 +95,11 @@ func JWTAuth(c *fiber.Ctx) error {
 		Message: "invalid or expired token",
 	})
 }
+
+// setClaims makes claims the identity of the request, handlers read it from the locals and services from the user context.
+func setClaims(c *fiber.Ctx, claims model.JWTClaims) {
+	c.Locals(constant.JWTClaimsContextKey, claims)
+
+	userCtx := context.WithValue(c.UserContext(), constant.JWTClaimsContextKey, claims)
+	c.SetUserContext(userCtx)
+}
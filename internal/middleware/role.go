@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireRole must be placed after JWTAuth.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+				Code:    fiber.StatusUnauthorized,
+				Message: "invalid or expired token",
+			})
+		}
+
+		if !slices.Contains(roles, claims.Role) {
+			return c.Status(fiber.StatusForbidden).JSON(pkgutil.HTTPResponse{
+				Code:    fiber.StatusForbidden,
+				Message: "forbidden access",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arfan21/vocagame/config"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TwoFactorCodeHeader carries the two factor code of a step up.
+const TwoFactorCodeHeader = "X-2FA-Code"
+
+// StepUpVerifier verifies the two factor code of a user again before a sensitive action.
+type StepUpVerifier interface {
+	VerifyStepUp(ctx context.Context, userID uuid.UUID, code string) (err error)
+}
+
+var stepUpVerifier StepUpVerifier
+
+// UseStepUpVerifier enables RequireWithdrawStepUp, it must be called before the server starts.
+func UseStepUpVerifier(verifier StepUpVerifier) {
+	stepUpVerifier = verifier
+}
+
+// RequireWithdrawStepUp must be placed after JWTAuth or Auth. A withdrawal of at least TWO_FACTOR_WITHDRAW_STEP_UP_AMOUNT
+// by a user with two factor authentication needs a code in the X-2FA-Code header.
+func RequireWithdrawStepUp(c *fiber.Ctx) error {
+	threshold := config.GetConfig().TwoFactor.WithdrawStepUpAmount
+	if stepUpVerifier == nil || threshold <= 0 {
+		return c.Next()
+	}
+
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	// a malformed body is left to the handler to report
+	var req model.CreateWithdrawTransactionRequest
+	if err := c.BodyParser(&req); err != nil || req.Amount.LessThan(decimal.NewFromInt(threshold)) {
+		return c.Next()
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("middleware: failed to parse subject: %w", err)
+	}
+
+	err = stepUpVerifier.VerifyStepUp(c.UserContext(), userID, c.Get(TwoFactorCodeHeader))
+	if err != nil {
+		return err
+	}
+
+	return c.Next()
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/arfan21/vocagame/config"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/exception"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// codeVerifier accepts code and records the codes it was asked to verify.
+type codeVerifier struct {
+	code  string
+	codes []string
+}
+
+func (v *codeVerifier) VerifyStepUp(ctx context.Context, userID uuid.UUID, code string) (err error) {
+	v.codes = append(v.codes, code)
+	if code == "" {
+		return constant.ErrTwoFactorRequired
+	}
+
+	if code != v.code {
+		return constant.ErrTwoFactorCodeInvalid
+	}
+
+	return
+}
+
+func withdraw(t *testing.T, amount string, code string) int {
+	app := fiber.New(fiber.Config{ErrorHandler: exception.FiberErrorHandler})
+	app.Post("/withdraw", func(c *fiber.Ctx) error {
+		c.Locals(constant.JWTClaimsContextKey, model.JWTClaims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: uuid.NewString()},
+		})
+		return c.Next()
+	}, RequireWithdrawStepUp, func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/withdraw", strings.NewReader(`{"amount":`+amount+`}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if code != "" {
+		req.Header.Set(TwoFactorCodeHeader, code)
+	}
+
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.StatusCode
+}
+
+func TestRequireWithdrawStepUpSuccess(t *testing.T) {
+	config.GetConfig().TwoFactor.WithdrawStepUpAmount = 1000
+
+	verifier := &codeVerifier{code: "123456"}
+	UseStepUpVerifier(verifier)
+	defer UseStepUpVerifier(nil)
+
+	// a withdrawal below the amount is not asked for a code
+	assert.Equal(t, fiber.StatusCreated, withdraw(t, "999", ""))
+	assert.Empty(t, verifier.codes)
+
+	assert.Equal(t, fiber.StatusCreated, withdraw(t, "1000", "123456"))
+	assert.Equal(t, []string{"123456"}, verifier.codes)
+}
+
+func TestRequireWithdrawStepUpFailed(t *testing.T) {
+	config.GetConfig().TwoFactor.WithdrawStepUpAmount = 1000
+
+	verifier := &codeVerifier{code: "123456"}
+	UseStepUpVerifier(verifier)
+	defer UseStepUpVerifier(nil)
+
+	assert.Equal(t, fiber.StatusForbidden, withdraw(t, "1000", ""))
+	assert.Equal(t, fiber.StatusUnauthorized, withdraw(t, "5000", "654321"))
+}
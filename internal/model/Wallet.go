@@ -20,7 +20,8 @@ type WalletResponse struct {
 }
 
 type UpdateBalanceRequest struct {
-	ID      uuid.UUID       `json:"id" validate:"required"`
-	Balance decimal.Decimal `json:"balance" validate:"required,dgt=0"`
-	UserID  uuid.UUID       `json:"user_id" validate:"required"`
+	ID              uuid.UUID       `json:"id" validate:"required"`
+	Balance         decimal.Decimal `json:"balance" validate:"required,dgt=0"`
+	PreviousBalance decimal.Decimal `json:"previous_balance"`
+	UserID          uuid.UUID       `json:"user_id" validate:"required"`
 }
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type APIKeyCreateRequest struct {
+	UserID    uuid.UUID `json:"-" validate:"required"`
+	Name      string    `json:"name" validate:"required,max=100"`
+	Scopes    []string  `json:"scopes" validate:"required,min=1,dive,oneof=products:read products:write transactions:read transactions:write wallets:read"`
+	ExpiresAt null.Time `json:"expires_at" swaggertype:"string"`
+}
+
+type APIKeyResponse struct {
+	ID         uuid.UUID   `json:"id" swaggertype:"string"`
+	Name       string      `json:"name"`
+	Prefix     string      `json:"prefix"`
+	Scopes     []string    `json:"scopes"`
+	LastUsedAt null.Time   `json:"last_used_at" swaggertype:"string"`
+	LastUsedIP null.String `json:"last_used_ip" swaggertype:"string"`
+	ExpiresAt  null.Time   `json:"expires_at" swaggertype:"string"`
+	RevokedAt  null.Time   `json:"revoked_at" swaggertype:"string"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type APIKeyCreateResponse struct {
+	APIKeyResponse
+	// Key is only returned once, it can not be shown again
+	Key string `json:"key"`
+}
+
+type APIKeyRevokeRequest struct {
+	ID     uuid.UUID `json:"-" validate:"required"`
+	UserID uuid.UUID `json:"-" validate:"required"`
+}
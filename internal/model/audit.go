@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditLogRecordRequest struct {
+	Action     string    `json:"action" validate:"required"`
+	EntityType string    `json:"entity_type" validate:"required"`
+	EntityID   uuid.UUID `json:"entity_id" validate:"required"`
+	Before     any       `json:"before"`
+	After      any       `json:"after"`
+}
+
+type GetListAuditLogRequest struct {
+	ActorID    uuid.NullUUID `query:"actor_id" json:"actor_id"`
+	EntityType string        `query:"entity_type" json:"entity_type"`
+	EntityID   uuid.NullUUID `query:"entity_id" json:"entity_id"`
+	Action     string        `query:"action" json:"action"`
+	From       string        `query:"from" json:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To         string        `query:"to" json:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Page       int           `query:"page" json:"page" validate:"min=1"`
+	Limit      int           `query:"limit" json:"limit" validate:"min=1,max=100"`
+}
+
+type AuditLogResponse struct {
+	ID         int64           `json:"id"`
+	ActorID    uuid.NullUUID   `json:"actor_id" swaggertype:"string"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   uuid.UUID       `json:"entity_id" swaggertype:"string"`
+	Before     json.RawMessage `json:"before" swaggertype:"object"`
+	After      json.RawMessage `json:"after" swaggertype:"object"`
+	RequestID  string          `json:"request_id"`
+	IP         string          `json:"ip"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"github.com/google/uuid"
+)
+
+type CategoryCreateRequest struct {
+	ParentID uuid.NullUUID `json:"parent_id" swaggertype:"string"`
+	Name     string        `json:"name" validate:"required,max=100"`
+	Slug     string        `json:"slug" validate:"omitempty,max=100"`
+}
+
+type CategoryUpdateRequest struct {
+	ID       uuid.UUID     `json:"-" validate:"required"`
+	ParentID uuid.NullUUID `json:"parent_id" swaggertype:"string"`
+	Name     string        `json:"name" validate:"required,max=100"`
+	Slug     string        `json:"slug" validate:"omitempty,max=100"`
+}
+
+type CategoryResponse struct {
+	ID       uuid.UUID          `json:"id" swaggertype:"string"`
+	ParentID uuid.NullUUID      `json:"parent_id" swaggertype:"string"`
+	Name     string             `json:"name"`
+	Slug     string             `json:"slug"`
+	Children []CategoryResponse `json:"children,omitempty"`
+}
+
+type CategoryFacetResponse struct {
+	ID       uuid.UUID     `json:"id" swaggertype:"string"`
+	ParentID uuid.NullUUID `json:"parent_id" swaggertype:"string"`
+	Name     string        `json:"name"`
+	Slug     string        `json:"slug"`
+	Count    int           `json:"count"`
+}
+
+type TagFacetResponse struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
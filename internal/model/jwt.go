@@ -3,6 +3,23 @@ package model
 import "github.com/golang-jwt/jwt/v5"
 
 type JWTClaims struct {
+	Email string `json:"email"`
+	Role  string `json:"role,omitempty"`
+	// TokenVersion is the token version of the user when the token was issued,
+	// logging out everywhere bumps the version and revokes every older token
+	TokenVersion int `json:"ver,omitempty"`
+	// SessionID is the session the token was issued for, revoking the session revokes the token
+	SessionID     string `json:"sid,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+	// APIKeyID and Scopes are set when the request is authenticated with an API key, they are never part of a token
+	APIKeyID string   `json:"-"`
+	Scopes   []string `json:"-"`
+	jwt.RegisteredClaims
+}
+
+// EmailVerificationClaims are the claims of the token in the link sent to verify the email of a user.
+// The token only verifies Email, it is invalid once the user changes the email.
+type EmailVerificationClaims struct {
 	Email string `json:"email"`
 	jwt.RegisteredClaims
 }
@@ -1,34 +1,203 @@
 package model
 
 import (
+	"mime/multipart"
+	"time"
+
+	"github.com/arfan21/vocagame/pkg/pkgutil"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"gopkg.in/guregu/null.v4"
 )
 
 type ProductCreateRequest struct {
+	// SKU identifies the product in the catalog of the seller, e.g. for bulk import
+	SKU         string          `json:"sku" validate:"max=100"`
 	Name        string          `json:"name" validate:"required"`
-	Stok        int             `json:"stok" validate:"required"`
+	Stok        int             `json:"stok" validate:"required_without=Variants"`
 	Description string          `json:"description" validate:"required"`
 	Price       decimal.Decimal `json:"price" validate:"required" swaggertype:"string"`
-	UserID      uuid.UUID       `json:"user_id" validate:"required"`
+	CategoryIDs []uuid.UUID     `json:"category_ids" validate:"max=10"`
+	Tags        []string        `json:"tags" validate:"max=20,dive,required,max=50"`
+	// Variants replace stok and price of the product, which become the total stok and lowest price of the variants
+	Variants []ProductVariantRequest `json:"variants" validate:"max=50,dive"`
+	UserID   uuid.UUID               `json:"user_id" validate:"required"`
+	// Status is published when omitted, a draft is only visible to its owner until it is published.
+	// PublishAt schedules when a published product goes live, it is ignored for a draft
+	Status    string     `json:"status" validate:"omitempty,oneof=draft published"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// ProductPublishRequest publishes a draft or unlisted product, at PublishAt when it is in the future or else now.
+type ProductPublishRequest struct {
+	ID        uuid.UUID  `json:"-" validate:"required"`
+	UserID    uuid.UUID  `json:"-" validate:"required"`
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+type ProductVariantRequest struct {
+	SKU   string          `json:"sku" validate:"required,max=100"`
+	Name  string          `json:"name" validate:"required,max=255"`
+	Price decimal.Decimal `json:"price" validate:"required,dgt=0" swaggertype:"string"`
+	Stok  int             `json:"stok" validate:"min=0"`
+}
+
+type ProductVariantCreateRequest struct {
+	ProductID uuid.UUID       `json:"-" validate:"required"`
+	UserID    uuid.UUID       `json:"-" validate:"required"`
+	SKU       string          `json:"sku" validate:"required,max=100"`
+	Name      string          `json:"name" validate:"required,max=255"`
+	Price     decimal.Decimal `json:"price" validate:"required,dgt=0" swaggertype:"string"`
+	Stok      int             `json:"stok" validate:"min=0"`
+}
+
+type ProductVariantUpdateRequest struct {
+	ID        uuid.UUID       `json:"-" validate:"required"`
+	ProductID uuid.UUID       `json:"-" validate:"required"`
+	UserID    uuid.UUID       `json:"-" validate:"required"`
+	SKU       string          `json:"sku" validate:"required,max=100"`
+	Name      string          `json:"name" validate:"required,max=255"`
+	Price     decimal.Decimal `json:"price" validate:"required,dgt=0" swaggertype:"string"`
+	Stok      int             `json:"stok" validate:"min=0"`
+}
+
+type ProductVariantDeleteRequest struct {
+	ID        uuid.UUID `json:"-" validate:"required"`
+	ProductID uuid.UUID `json:"-" validate:"required"`
+	UserID    uuid.UUID `json:"-" validate:"required"`
+}
+
+type ProductVariantResponse struct {
+	ID        uuid.UUID       `json:"id" swaggertype:"string"`
+	ProductID uuid.UUID       `json:"product_id" swaggertype:"string"`
+	SKU       string          `json:"sku"`
+	Name      string          `json:"name"`
+	Price     decimal.Decimal `json:"price" swaggertype:"string"`
+	Stok      int             `json:"stok"`
+	// AvailableStok is the stok not held by reservations
+	AvailableStok int `json:"available_stok"`
+	SoldCount     int `json:"sold_count"`
+	// FinalPrice is the price with the active sale applied
+	FinalPrice decimal.Decimal      `json:"final_price" swaggertype:"string"`
+	Sale       *ProductSaleResponse `json:"sale,omitempty"`
 }
 
 type GetListProductRequest struct {
-	Name      string        `query:"name" json:"name"`
-	Page      int           `query:"page" json:"page" validate:"min=1"`
-	Limit     int           `query:"limit" json:"limit" validate:"min=1"`
-	OwnerID   uuid.NullUUID `query:"owner_id" json:"owner_id"`
-	ProductID uuid.NullUUID `query:"product_id" json:"product_id"`
+	Name        string              `query:"name" json:"name"`
+	Q           string              `query:"q" json:"q" validate:"max=100"`
+	Fuzzy       bool                `query:"fuzzy" json:"fuzzy"`
+	Page        int                 `query:"page" json:"page" validate:"min=1"`
+	Limit       int                 `query:"limit" json:"limit" validate:"min=1"`
+	OwnerID     uuid.NullUUID       `query:"owner_id" json:"owner_id"`
+	ProductID   uuid.NullUUID       `query:"product_id" json:"product_id"`
+	ProductIDs  []uuid.UUID         `query:"product_ids" json:"product_ids" validate:"max=100"`
+	MinPrice    decimal.NullDecimal `query:"min_price" json:"min_price"`
+	MaxPrice    decimal.NullDecimal `query:"max_price" json:"max_price"`
+	InStock     bool                `query:"in_stock" json:"in_stock"`
+	CategoryID  uuid.NullUUID       `query:"category_id" json:"category_id"`
+	Tag         string              `query:"tag" json:"tag" validate:"max=50"`
+	CreatedFrom string              `query:"created_from" json:"created_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CreatedTo   string              `query:"created_to" json:"created_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Sort        string              `query:"sort" json:"sort" validate:"omitempty,oneof=newest price_asc price_desc best_selling relevance"`
+	Cursor      string              `query:"cursor" json:"cursor"`
+	SkipTotal   bool                `query:"skip_total" json:"skip_total"`
+	SkipFacets  bool                `query:"skip_facets" json:"skip_facets"`
+}
+
+// GetListOwnProductRequest lists the products of the user in every lifecycle status.
+type GetListOwnProductRequest struct {
+	Status    string `query:"status" json:"status" validate:"omitempty,oneof=draft scheduled published unlisted"`
+	Page      int    `query:"page" json:"page" validate:"min=1"`
+	Limit     int    `query:"limit" json:"limit" validate:"min=1"`
+	SkipTotal bool   `query:"skip_total" json:"skip_total"`
+}
+
+type GetListProductResponse struct {
+	pkgutil.PaginationResponse[[]GetProductResponse]
+	Facets *ProductFacetsResponse `json:"facets,omitempty"`
+}
+
+type ProductFacetsResponse struct {
+	Categories []CategoryFacetResponse `json:"categories"`
+	Tags       []TagFacetResponse      `json:"tags"`
+}
+
+type ProductCacheStatsResponse struct {
+	Enabled bool  `json:"enabled"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	// Errors counts failed redis calls, the catalog is read from the database instead
+	Errors   int64   `json:"errors"`
+	HitRatio float64 `json:"hit_ratio"`
 }
 
 type GetProductResponse struct {
-	ID          uuid.UUID       `json:"id" swaggertype:"string"`
-	Name        string          `json:"name"`
-	Description string          `json:"description"`
-	Stok        int             `json:"stok"`
-	Price       decimal.Decimal `json:"price" swaggertype:"string"`
-	OwnerID     uuid.UUID       `json:"owner_id" swaggertype:"string"`
-	OwnerName   string          `json:"owner_name"`
+	ID          uuid.UUID   `json:"id" swaggertype:"string"`
+	SKU         null.String `json:"sku" swaggertype:"string"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Stok        int         `json:"stok"`
+	// AvailableStok is the stok not held by reservations
+	AvailableStok int                      `json:"available_stok"`
+	Price         decimal.Decimal          `json:"price" swaggertype:"string"`
+	FinalPrice    decimal.Decimal          `json:"final_price" swaggertype:"string"`
+	Sale          *ProductSaleResponse     `json:"sale,omitempty"`
+	OwnerID       uuid.UUID                `json:"owner_id" swaggertype:"string"`
+	OwnerName     string                   `json:"owner_name"`
+	SoldCount     int                      `json:"sold_count"`
+	RatingAverage float64                  `json:"rating_average"`
+	RatingCount   int                      `json:"rating_count"`
+	Version       int                      `json:"version"`
+	Status        string                   `json:"status"`
+	PublishedAt   null.Time                `json:"published_at" swaggertype:"string"`
+	CreatedAt     time.Time                `json:"created_at"`
+	DeletedAt     null.Time                `json:"deleted_at" swaggertype:"string"`
+	DelistedAt    null.Time                `json:"delisted_at" swaggertype:"string"`
+	Categories    []ProductCategory        `json:"categories"`
+	Tags          []string                 `json:"tags"`
+	Images        []ProductImageResponse   `json:"images"`
+	HasVariants   bool                     `json:"has_variants"`
+	Variants      []ProductVariantResponse `json:"variants"`
+	Search        *ProductSearchResponse   `json:"search,omitempty"`
+}
+
+type ProductCategory struct {
+	ID   uuid.UUID `json:"id" swaggertype:"string"`
+	Name string    `json:"name"`
+	Slug string    `json:"slug"`
+}
+
+type ProductImageResponse struct {
+	ID           uuid.UUID `json:"id" swaggertype:"string"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Position     int       `json:"position"`
+}
+
+type ProductImageUploadRequest struct {
+	ProductID uuid.UUID               `json:"-" validate:"required"`
+	UserID    uuid.UUID               `json:"-" validate:"required"`
+	Files     []*multipart.FileHeader `json:"-" validate:"required,min=1"`
+}
+
+type ProductImageDeleteRequest struct {
+	ProductID uuid.UUID `json:"-" validate:"required"`
+	UserID    uuid.UUID `json:"-" validate:"required"`
+	ImageID   uuid.UUID `json:"-" validate:"required"`
+}
+
+type ProductImageReorderRequest struct {
+	ProductID uuid.UUID   `json:"-" validate:"required"`
+	UserID    uuid.UUID   `json:"-" validate:"required"`
+	ImageIDs  []uuid.UUID `json:"image_ids" validate:"required,min=1"`
+}
+
+type ProductSearchResponse struct {
+	Rank                 float64 `json:"rank"`
+	NameHighlight        string  `json:"name_highlight"`
+	DescriptionHighlight string  `json:"description_highlight"`
 }
 
 type ProductUpdateRequest struct {
@@ -38,9 +207,42 @@ type ProductUpdateRequest struct {
 	Stok        int             `json:"stok" validate:"required"`
 	Description string          `json:"description" validate:"required"`
 	Price       decimal.Decimal `json:"price" validate:"required" swaggertype:"string"`
+	// SKU, CategoryIDs and Tags are left unchanged when omitted, an empty value clears them
+	SKU         *string     `json:"sku" validate:"omitempty,max=100"`
+	CategoryIDs []uuid.UUID `json:"category_ids" validate:"max=10"`
+	Tags        []string    `json:"tags" validate:"max=20,dive,required,max=50"`
+	// Version is the version the update is based on, taken from If-Match. It is not checked when zero
+	Version int `json:"-"`
+}
+
+// ProductPatchRequest changes only the fields which are sent.
+type ProductPatchRequest struct {
+	ID          uuid.UUID        `json:"-" validate:"required"`
+	UserID      uuid.UUID        `json:"-" validate:"required"`
+	Name        *string          `json:"name" validate:"omitnil,min=1"`
+	Stok        *int             `json:"stok" validate:"omitnil,min=0"`
+	Description *string          `json:"description" validate:"omitnil,min=1"`
+	Price       *decimal.Decimal `json:"price" validate:"omitnil,dgt=0" swaggertype:"string"`
+	SKU         *string          `json:"sku" validate:"omitnil,max=100"`
+	CategoryIDs []uuid.UUID      `json:"category_ids" validate:"max=10"`
+	Tags        []string         `json:"tags" validate:"max=20,dive,required,max=50"`
+	// Version is the version the patch is based on, taken from If-Match.
+	// When it is zero the patch is applied to the version read before it
+	Version int `json:"-"`
 }
 
 type ReduceStokRequest struct {
-	ID       uuid.UUID `json:"id"`
-	ReduceBy int       `json:"reduce_by"`
+	ID        uuid.UUID     `json:"id"`
+	VariantID uuid.NullUUID `json:"variant_id"`
+	ReduceBy  int           `json:"reduce_by"`
+	// Reserved consumes stok held by a reservation instead of unreserved stok
+	Reserved      bool          `json:"reserved"`
+	TransactionID uuid.NullUUID `json:"transaction_id"`
+	UserID        uuid.NullUUID `json:"user_id"`
+}
+
+type ReserveStokRequest struct {
+	ID        uuid.UUID     `json:"id"`
+	VariantID uuid.NullUUID `json:"variant_id"`
+	Qty       int           `json:"qty"`
 }
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"io"
+
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	ProductFileFormatCSV    = "csv"
+	ProductFileFormatNDJSON = "ndjson"
+)
+
+const (
+	ProductImportStatusCreated = "created"
+	ProductImportStatusUpdated = "updated"
+	ProductImportStatusFailed  = "failed"
+)
+
+type ProductImportRequest struct {
+	UserID uuid.UUID `json:"-" validate:"required"`
+	Format string    `json:"-" validate:"required,oneof=csv ndjson"`
+	File   io.Reader `json:"-" validate:"required"`
+}
+
+// ProductImportRow is a line of the import file, in a csv file tags are separated by "|".
+type ProductImportRow struct {
+	SKU         string          `json:"sku" validate:"required,max=100"`
+	Name        string          `json:"name" validate:"required,max=255"`
+	Description string          `json:"description" validate:"required"`
+	Price       decimal.Decimal `json:"price" validate:"required,dgt=0" swaggertype:"string"`
+	Stok        int             `json:"stok" validate:"min=0"`
+	// Tags of an existing product are left unchanged when omitted
+	Tags []string `json:"tags" validate:"max=20,dive,required,max=50"`
+}
+
+type ProductImportResponse struct {
+	Total   int                        `json:"total"`
+	Created int                        `json:"created"`
+	Updated int                        `json:"updated"`
+	Failed  int                        `json:"failed"`
+	Rows    []ProductImportRowResponse `json:"rows"`
+}
+
+type ProductImportRowResponse struct {
+	// Row is the line number in the import file
+	Row       int                             `json:"row"`
+	SKU       string                          `json:"sku"`
+	Status    string                          `json:"status"`
+	ProductID uuid.NullUUID                   `json:"product_id" swaggertype:"string"`
+	Message   string                          `json:"message,omitempty"`
+	Errors    []pkgutil.ErrValidationResponse `json:"errors,omitempty"`
+}
+
+type ProductExportRequest struct {
+	UserID uuid.UUID `json:"-" validate:"required"`
+	Format string    `json:"format" validate:"required,oneof=csv ndjson"`
+}
+
+// ProductExportRow uses the columns of ProductImportRow, so an export can be imported again.
+type ProductExportRow struct {
+	ID          uuid.UUID       `json:"id" swaggertype:"string"`
+	SKU         string          `json:"sku"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Price       decimal.Decimal `json:"price" swaggertype:"string"`
+	Stok        int             `json:"stok"`
+	Tags        []string        `json:"tags"`
+}
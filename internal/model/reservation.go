@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReservationCreateRequest struct {
+	UserID uuid.UUID                `json:"-" validate:"required"`
+	Items  []ReservationItemRequest `json:"items" validate:"required,min=1,max=50,dive"`
+	// ExpiresInMinutes defaults to RESERVATION_EXPIRE_IN
+	ExpiresInMinutes int `json:"expires_in_minutes" validate:"omitempty,min=1,max=60"`
+}
+
+type ReservationItemRequest struct {
+	ProductID uuid.UUID     `json:"product_id" validate:"required"`
+	VariantID uuid.NullUUID `json:"variant_id" swaggertype:"string"`
+	Qty       int           `json:"qty" validate:"required,min=1"`
+}
+
+type GetReservationRequest struct {
+	ID     uuid.UUID `json:"-" validate:"required"`
+	UserID uuid.UUID `json:"-" validate:"required"`
+}
+
+type ReservationResponse struct {
+	ID            uuid.UUID                 `json:"id" swaggertype:"string"`
+	Status        string                    `json:"status"`
+	ExpiresAt     time.Time                 `json:"expires_at"`
+	TransactionID uuid.NullUUID             `json:"transaction_id" swaggertype:"string"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	Items         []ReservationItemResponse `json:"items"`
+}
+
+type ReservationItemResponse struct {
+	ProductID uuid.UUID     `json:"product_id" swaggertype:"string"`
+	VariantID uuid.NullUUID `json:"variant_id" swaggertype:"string"`
+	Qty       int           `json:"qty"`
+}
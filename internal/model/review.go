@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type ProductReviewCreateRequest struct {
+	ProductID uuid.UUID `json:"-" validate:"required"`
+	UserID    uuid.UUID `json:"-" validate:"required"`
+	// TransactionDetailID is the purchased line being reviewed, every line can be reviewed once
+	TransactionDetailID uuid.UUID `json:"transaction_detail_id" validate:"required" swaggertype:"string"`
+	Rating              int       `json:"rating" validate:"required,min=1,max=5"`
+	Comment             string    `json:"comment" validate:"max=2000"`
+}
+
+type ProductReviewReplyRequest struct {
+	ProductID uuid.UUID `json:"-" validate:"required"`
+	ReviewID  uuid.UUID `json:"-" validate:"required"`
+	UserID    uuid.UUID `json:"-" validate:"required"`
+	Reply     string    `json:"reply" validate:"required,max=2000"`
+}
+
+type GetListProductReviewRequest struct {
+	ProductID uuid.UUID `query:"-" json:"-" validate:"required"`
+	Rating    int       `query:"rating" json:"rating" validate:"omitempty,min=1,max=5"`
+	Sort      string    `query:"sort" json:"sort" validate:"omitempty,oneof=newest oldest rating_desc rating_asc"`
+	Page      int       `query:"page" json:"page" validate:"min=1"`
+	Limit     int       `query:"limit" json:"limit" validate:"min=1,max=100"`
+}
+
+type ProductReviewResponse struct {
+	ID        uuid.UUID   `json:"id" swaggertype:"string"`
+	ProductID uuid.UUID   `json:"product_id" swaggertype:"string"`
+	UserID    uuid.UUID   `json:"user_id" swaggertype:"string"`
+	UserName  string      `json:"user_name"`
+	Rating    int         `json:"rating"`
+	Comment   string      `json:"comment"`
+	Reply     null.String `json:"reply" swaggertype:"string"`
+	RepliedAt null.Time   `json:"replied_at" swaggertype:"string"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	ProductSaleStatusScheduled = "scheduled"
+	ProductSaleStatusActive    = "active"
+	ProductSaleStatusSoldOut   = "sold_out"
+	ProductSaleStatusEnded     = "ended"
+	ProductSaleStatusCancelled = "cancelled"
+)
+
+type ProductSaleCreateRequest struct {
+	ProductID uuid.UUID `json:"-" validate:"required"`
+	UserID    uuid.UUID `json:"-" validate:"required"`
+	// VariantID is required when the product has variants
+	VariantID uuid.NullUUID   `json:"variant_id" swaggertype:"string"`
+	SalePrice decimal.Decimal `json:"sale_price" validate:"required,dgt=0" swaggertype:"string"`
+	StartsAt  time.Time       `json:"starts_at" validate:"required"`
+	EndsAt    time.Time       `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	// Quota makes the sale a flash sale, the sale ends once quota items are sold
+	Quota        *int `json:"quota" validate:"omitempty,min=1"`
+	PerUserLimit *int `json:"per_user_limit" validate:"omitempty,min=1"`
+}
+
+type ProductSaleCancelRequest struct {
+	ID        uuid.UUID `json:"-" validate:"required"`
+	ProductID uuid.UUID `json:"-" validate:"required"`
+	UserID    uuid.UUID `json:"-" validate:"required"`
+}
+
+type ProductSaleResponse struct {
+	ID        uuid.UUID       `json:"id" swaggertype:"string"`
+	ProductID uuid.UUID       `json:"product_id" swaggertype:"string"`
+	VariantID uuid.NullUUID   `json:"variant_id" swaggertype:"string"`
+	SalePrice decimal.Decimal `json:"sale_price" swaggertype:"string"`
+	StartsAt  time.Time       `json:"starts_at"`
+	EndsAt    time.Time       `json:"ends_at"`
+	Quota     null.Int        `json:"quota" swaggertype:"integer"`
+	Sold      int             `json:"sold"`
+	// Remaining is the quota left of a flash sale
+	Remaining    null.Int  `json:"remaining" swaggertype:"integer"`
+	PerUserLimit null.Int  `json:"per_user_limit" swaggertype:"integer"`
+	Status       string    `json:"status"`
+	CancelledAt  null.Time `json:"cancelled_at" swaggertype:"string"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AppliedSaleResponse is the price charged for an item of a checkout, SaleID is set when a sale is applied.
+type AppliedSaleResponse struct {
+	SaleID uuid.NullUUID
+	Price  decimal.Decimal
+}
+
+type GetListPriceHistoryRequest struct {
+	ProductID uuid.UUID     `query:"-" json:"-" validate:"required"`
+	UserID    uuid.UUID     `query:"-" json:"-" validate:"required"`
+	VariantID uuid.NullUUID `query:"variant_id" json:"variant_id"`
+	Page      int           `query:"page" json:"page" validate:"min=1"`
+	Limit     int           `query:"limit" json:"limit" validate:"min=1,max=100"`
+}
+
+type PriceHistoryResponse struct {
+	ID        uuid.UUID       `json:"id" swaggertype:"string"`
+	ProductID uuid.UUID       `json:"product_id" swaggertype:"string"`
+	VariantID uuid.NullUUID   `json:"variant_id" swaggertype:"string"`
+	Reason    string          `json:"reason"`
+	Price     decimal.Decimal `json:"price" swaggertype:"string"`
+	SaleID    uuid.NullUUID   `json:"sale_id" swaggertype:"string"`
+	StartsAt  null.Time       `json:"starts_at" swaggertype:"string"`
+	EndsAt    null.Time       `json:"ends_at" swaggertype:"string"`
+	ActorID   uuid.NullUUID   `json:"actor_id" swaggertype:"string"`
+	CreatedAt time.Time       `json:"created_at"`
+}
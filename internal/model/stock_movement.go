@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+type StockAdjustmentRequest struct {
+	ProductID uuid.UUID     `json:"-" validate:"required"`
+	UserID    uuid.UUID     `json:"-" validate:"required"`
+	VariantID uuid.NullUUID `json:"variant_id" swaggertype:"string"`
+	// Delta is added to the stok, a negative delta decrements it
+	Delta  int    `json:"delta" validate:"required"`
+	Reason string `json:"reason" validate:"required,oneof=RESTOCK ADJUSTMENT RETURN"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+type GetListStockMovementRequest struct {
+	ProductID uuid.UUID     `query:"-" json:"-" validate:"required"`
+	UserID    uuid.UUID     `query:"-" json:"-" validate:"required"`
+	VariantID uuid.NullUUID `query:"variant_id" json:"variant_id"`
+	Reason    string        `query:"reason" json:"reason" validate:"omitempty,oneof=SALE RESTOCK ADJUSTMENT RETURN"`
+	Page      int           `query:"page" json:"page" validate:"min=1"`
+	Limit     int           `query:"limit" json:"limit" validate:"min=1,max=100"`
+}
+
+type StockMovementResponse struct {
+	ID            uuid.UUID     `json:"id" swaggertype:"string"`
+	ProductID     uuid.UUID     `json:"product_id" swaggertype:"string"`
+	VariantID     uuid.NullUUID `json:"variant_id" swaggertype:"string"`
+	Delta         int           `json:"delta"`
+	Reason        string        `json:"reason"`
+	StokAfter     int           `json:"stok_after"`
+	ActorID       uuid.NullUUID `json:"actor_id" swaggertype:"string"`
+	TransactionID uuid.NullUUID `json:"transaction_id" swaggertype:"string"`
+	Note          null.String   `json:"note" swaggertype:"string"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
@@ -40,17 +40,25 @@ type GetTransactionResponse struct {
 type TransactionDetailResponse struct {
 	ID           uuid.UUID       `json:"id"`
 	ProductID    uuid.UUID       `json:"product_id"`
+	VariantID    uuid.NullUUID   `json:"variant_id" swaggertype:"string"`
 	Qty          int             `json:"qty"`
 	ProductName  string          `json:"product_name"`
+	VariantName  string          `json:"variant_name,omitempty"`
+	VariantSKU   string          `json:"variant_sku,omitempty"`
 	ProductPrice decimal.Decimal `json:"product_price"`
+	SaleID       uuid.NullUUID   `json:"sale_id" swaggertype:"string"`
 }
 
 type CheckoutTransactionRequest struct {
 	UserID   uuid.UUID                `json:"user_id" validate:"required"`
-	Products []CheckoutProductRequest `json:"products" validate:"required,min=1,dive,required"`
+	Products []CheckoutProductRequest `json:"products" validate:"omitempty,min=1,dive,required"`
+	// ReservationID checks out the products of the reservation, products must be empty then
+	ReservationID uuid.NullUUID `json:"reservation_id" swaggertype:"string"`
 }
 
 type CheckoutProductRequest struct {
 	ProductID uuid.UUID `json:"product_id" validate:"required"`
-	Qty       int       `json:"qty" validate:"required,min=1"`
+	// VariantID is required when the product has variants
+	VariantID uuid.NullUUID `json:"variant_id" swaggertype:"string"`
+	Qty       int           `json:"qty" validate:"required,min=1"`
 }
@@ -1,5 +1,13 @@
 package model
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gopkg.in/guregu/null.v4"
+)
+
 type UserRegisterRequest struct {
 	Fullname string `json:"fullname" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
@@ -9,14 +17,73 @@ type UserRegisterRequest struct {
 type UserLoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8,max=20"`
+	// DeviceName is shown in the sessions of the user, e.g. "Pixel 8" or "Work laptop"
+	DeviceName string `json:"device_name" validate:"max=100"`
+	UserAgent  string `json:"-"`
+	IP         string `json:"-"`
 }
 
+// UserLoginResponse has no tokens when TwoFactorRequired is set, the login is finished
+// by sending ChallengeToken with a two factor code to /users/login/2fa.
 type UserLoginResponse struct {
-	AccessToken           string `json:"access_token"`
-	ExpiresIn             int    `json:"expires_in"`
-	TokenType             string `json:"token_type"`
+	AccessToken           string `json:"access_token,omitempty"`
+	ExpiresIn             int    `json:"expires_in,omitempty"`
+	TokenType             string `json:"token_type,omitempty"`
 	RefreshToken          string `json:"refresh_token,omitempty"`
 	ExpiresInRefreshToken int    `json:"expires_in_refresh_token,omitempty"`
+	TwoFactorRequired     bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken        string `json:"challenge_token,omitempty"`
+	ExpiresInChallenge    int    `json:"expires_in_challenge,omitempty"`
+}
+
+// UserLoginTwoFactorRequest finishes a login, Code is a totp code or an unused recovery code.
+type UserLoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+	IP             string `json:"-"`
+}
+
+type UserTwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth URI of the secret, it is shown as a QR code to scan with an authenticator app
+	URI string `json:"uri"`
+}
+
+type UserTwoFactorConfirmRequest struct {
+	UserID uuid.UUID `json:"-" validate:"required"`
+	Code   string    `json:"code" validate:"required,len=6,numeric"`
+}
+
+// UserTwoFactorRecoveryCodesResponse is the only time the recovery codes are shown, each of them works once.
+type UserTwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type UserTwoFactorDisableRequest struct {
+	UserID   uuid.UUID `json:"-" validate:"required"`
+	Password string    `json:"password" validate:"required"`
+	Code     string    `json:"code" validate:"required"`
+}
+
+type UserVerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UserForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type UserResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=20"`
+}
+
+type UserChangePasswordRequest struct {
+	UserID          uuid.UUID `json:"-" validate:"required"`
+	CurrentPassword string    `json:"current_password" validate:"required"`
+	NewPassword     string    `json:"new_password" validate:"required,min=8,max=20"`
+	// SessionID is the session of the request, it stays logged in while the other sessions are logged out
+	SessionID string `json:"-"`
 }
 
 type UserRefreshTokenRequest struct {
@@ -25,4 +92,65 @@ type UserRefreshTokenRequest struct {
 
 type UserLogoutRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
+	// AccessToken is the claims of the access token sent with the request, it is revoked too
+	AccessToken JWTClaims `json:"-"`
+}
+
+type UserSessionResponse struct {
+	ID         uuid.UUID `json:"id" swaggertype:"string"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	// Current is true for the session of the access token sent with the request
+	Current bool `json:"current"`
+}
+
+type UserSessionRevokeRequest struct {
+	ID     uuid.UUID `json:"-" validate:"required"`
+	UserID uuid.UUID `json:"-" validate:"required"`
+}
+
+type UserResponse struct {
+	ID       uuid.UUID `json:"id" swaggertype:"string"`
+	Fullname string    `json:"fullname"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+}
+
+type UserProfileResponse struct {
+	ID            uuid.UUID `json:"id" swaggertype:"string"`
+	Fullname      string    `json:"fullname"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	// PendingEmail is the new email of the user, it replaces email once it is verified
+	PendingEmail     null.String                `json:"pending_email" swaggertype:"string"`
+	Role             string                     `json:"role"`
+	AvatarURL        null.String                `json:"avatar_url" swaggertype:"string"`
+	Phone            null.String                `json:"phone" swaggertype:"string"`
+	Language         string                     `json:"language"`
+	TwoFactorEnabled bool                       `json:"two_factor_enabled"`
+	Wallet           *UserWalletSummaryResponse `json:"wallet"`
+	ProductCount     int                        `json:"product_count"`
+	CreatedAt        time.Time                  `json:"created_at"`
+	UpdatedAt        time.Time                  `json:"updated_at"`
+}
+
+// UserWalletSummaryResponse is null in the profile when the user has no wallet yet.
+type UserWalletSummaryResponse struct {
+	ID      uuid.UUID       `json:"id" swaggertype:"string"`
+	Balance decimal.Decimal `json:"balance" swaggertype:"string"`
+}
+
+// UserUpdateProfileRequest replaces the profile of the user, an empty avatar_url or phone clears it.
+// A different email is kept as the pending email until the link sent to it is opened.
+type UserUpdateProfileRequest struct {
+	UserID    uuid.UUID `json:"-" validate:"required"`
+	Fullname  string    `json:"fullname" validate:"required,max=255"`
+	Email     string    `json:"email" validate:"required,email,max=255"`
+	AvatarURL string    `json:"avatar_url" validate:"omitempty,url,max=2048"`
+	Phone     string    `json:"phone" validate:"omitempty,e164"`
+	// Language is a BCP 47 tag such as "en" or "id", it is "en" when omitted
+	Language string `json:"language" validate:"omitempty,bcp47_language_tag,max=35"`
 }
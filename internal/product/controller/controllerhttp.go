@@ -1,10 +1,18 @@
 package productctrl
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"mime"
+	"strconv"
+	"strings"
+
 	"github.com/arfan21/vocagame/internal/model"
 	"github.com/arfan21/vocagame/internal/product"
 	"github.com/arfan21/vocagame/pkg/constant"
 	"github.com/arfan21/vocagame/pkg/exception"
+	"github.com/arfan21/vocagame/pkg/logger"
 	"github.com/arfan21/vocagame/pkg/pkgutil"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -59,12 +67,26 @@ func (ctrl ControllerHTTP) Create(c *fiber.Ctx) error {
 // @Tags Product
 // @Accept json
 // @Produce json
-// @Param page query string true "Page"
+// @Param page query string false "Page, required without cursor"
 // @Param limit query string true "Limit"
 // @Param name query string false "Name of product"
+// @Param q query string false "Full-text search on name and description, prefix matched and ordered by relevance"
+// @Param fuzzy query bool false "Also match names with typos, only used with q"
 // @Param owner_id query string false "Owner ID"
 // @Param product_id query string false "Product ID"
-// @Success 200 {object} pkgutil.HTTPResponse{data=pkgutil.PaginationResponse[[]model.GetProductResponse]{data=[]model.GetProductResponse}}
+// @Param product_ids query []string false "Product IDs, comma separated" collectionFormat(csv)
+// @Param min_price query string false "Minimum price"
+// @Param max_price query string false "Maximum price"
+// @Param in_stock query bool false "Only products with stok"
+// @Param category_id query string false "Category ID, includes products of its sub categories"
+// @Param tag query string false "Tag"
+// @Param created_from query string false "Created from (RFC3339)"
+// @Param created_to query string false "Created to (RFC3339)"
+// @Param sort query string false "Sort" Enums(newest, price_asc, price_desc, best_selling, relevance)
+// @Param cursor query string false "Cursor from next_cursor of the previous page, replaces page"
+// @Param skip_total query bool false "Skip counting total_data and total_page"
+// @Param skip_facets query bool false "Skip counting products per category and tag"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.GetListProductResponse}
 // @Failure 500 {object} pkgutil.HTTPResponse
 // @Router /api/v1/products [get]
 func (ctrl ControllerHTTP) GetProducts(c *fiber.Ctx) error {
@@ -81,16 +103,70 @@ func (ctrl ControllerHTTP) GetProducts(c *fiber.Ctx) error {
 	})
 }
 
+// productETag formats the version of a product as a strong etag.
+func productETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseIfMatch returns the product version of the If-Match header, zero when the header is missing or "*".
+func parseIfMatch(c *fiber.Ctx) (version int, err error) {
+	value := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	unquoted, err := strconv.Unquote(value)
+	if err != nil {
+		return 0, constant.ErrInvalidIfMatch
+	}
+
+	version, err = strconv.Atoi(unquoted)
+	if err != nil || version < 1 {
+		return 0, constant.ErrInvalidIfMatch
+	}
+
+	return
+}
+
+// @Summary Get Product
+// @Description Get a listed product, the ETag header is the version of the product to send as If-Match when updating it
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.GetProductResponse}
+// @Header 200 {string} ETag "Version of the product"
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId [get]
+func (ctrl ControllerHTTP) GetProduct(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetProduct(c.UserContext(), id)
+	exception.PanicIfNeeded(err)
+
+	c.Set(fiber.HeaderETag, productETag(res.Version))
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
 // @Summary Update Product
-// @Description Update Product
+// @Description Update Product, with If-Match the product is only updated when it is still at that version
 // @Tags Product
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "With the bearer started"
+// @Param If-Match header string false "ETag of the product"
 // @Param body body model.ProductUpdateRequest true "Payload Update Product Request"
 // @Success 200 {object} pkgutil.HTTPResponse
+// @Header 200 {string} ETag "New version of the product"
 // @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
 // @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 412 {object} pkgutil.HTTPResponse
 // @Failure 500 {object} pkgutil.HTTPResponse
 // @Router /api/v1/products/:productId [put]
 func (ctrl ControllerHTTP) Update(c *fiber.Ctx) error {
@@ -122,16 +198,71 @@ func (ctrl ControllerHTTP) Update(c *fiber.Ctx) error {
 	exception.PanicIfNeeded(err)
 	req.ID = uuidID
 
-	err = ctrl.svc.Update(c.UserContext(), req)
+	req.Version, err = parseIfMatch(c)
+	exception.PanicIfNeeded(err)
+
+	version, err := ctrl.svc.Update(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	c.Set(fiber.HeaderETag, productETag(version))
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Patch Product
+// @Description Update only the fields sent. The product is only updated when it is still at the version of If-Match,
+// @Description or at the version read by the request when If-Match is omitted
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param If-Match header string false "ETag of the product"
+// @Param productId path string true "Product ID"
+// @Param body body model.ProductPatchRequest true "Payload Patch Product Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Header 200 {string} ETag "New version of the product"
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 412 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId [patch]
+func (ctrl ControllerHTTP) Patch(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductPatchRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	req.Version, err = parseIfMatch(c)
 	exception.PanicIfNeeded(err)
 
+	version, err := ctrl.svc.Patch(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	c.Set(fiber.HeaderETag, productETag(version))
+
 	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
 		Code: fiber.StatusOK,
 	})
 }
 
 // @Summary Delete Product
-// @Description Delete Product
+// @Description Soft delete product, it can be restored later
 // @Tags Product
 // @Accept json
 // @Produce json
@@ -171,3 +302,868 @@ func (ctrl ControllerHTTP) Delete(c *fiber.Ctx) error {
 		Code: fiber.StatusOK,
 	})
 }
+
+// @Summary Delist Product
+// @Description Hide product from listing and checkout without deleting it
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/delist [post]
+func (ctrl ControllerHTTP) Delist(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	uuidID, err := uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.Delist(c.UserContext(), uuidID, uuidUserID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Restore Product
+// @Description Restore deleted or delisted product
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/restore [post]
+func (ctrl ControllerHTTP) Restore(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	uuidID, err := uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.Restore(c.UserContext(), uuidID, uuidUserID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Get Archived Products
+// @Description Get deleted or delisted products of the logged in seller
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param page query string false "Page, required without cursor"
+// @Param limit query string true "Limit"
+// @Param name query string false "Name of product"
+// @Param sort query string false "Sort" Enums(newest, price_asc, price_desc, best_selling)
+// @Param cursor query string false "Cursor from next_cursor of the previous page, replaces page"
+// @Param skip_total query bool false "Skip counting total_data and total_page"
+// @Success 200 {object} pkgutil.HTTPResponse{data=pkgutil.PaginationResponse[[]model.GetProductResponse]{data=[]model.GetProductResponse}}
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/archived [get]
+func (ctrl ControllerHTTP) GetArchivedProducts(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	reqQuery := model.GetListProductRequest{}
+	err := c.QueryParser(&reqQuery)
+	exception.PanicIfNeeded(err)
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetArchivedProducts(c.UserContext(), uuidUserID, reqQuery)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Get Own Products
+// @Description Get products of the logged in seller in every lifecycle status, deleted products are in archived
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param status query string false "Status" Enums(draft, scheduled, published, unlisted)
+// @Param page query string true "Page"
+// @Param limit query string true "Limit"
+// @Param skip_total query bool false "Skip counting total_data and total_page"
+// @Success 200 {object} pkgutil.HTTPResponse{data=pkgutil.PaginationResponse[[]model.GetProductResponse]{data=[]model.GetProductResponse}}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/mine [get]
+func (ctrl ControllerHTTP) GetOwnProducts(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	reqQuery := model.GetListOwnProductRequest{}
+	err := c.QueryParser(&reqQuery)
+	exception.PanicIfNeeded(err)
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetOwnProducts(c.UserContext(), uuidUserID, reqQuery)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Publish Product
+// @Description Publish a draft or unlisted product now, or schedule it with publish_at
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param body body model.ProductPublishRequest false "Payload Publish Product Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/publish [post]
+func (ctrl ControllerHTTP) Publish(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductPublishRequest
+	var err error
+	// the body is optional, the product is published now without it
+	if len(c.Body()) != 0 {
+		err = c.BodyParser(&req)
+		exception.PanicIfNeeded(err)
+	}
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.Publish(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Unpublish Product
+// @Description Turn the product back into a draft, only the owner can see it until it is published again
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/unpublish [post]
+func (ctrl ControllerHTTP) Unpublish(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	uuidID, err := uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.Unpublish(c.UserContext(), uuidID, uuidUserID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Upload Product Images
+// @Description Upload jpeg or png images of the product, a thumbnail is generated for every image
+// @Tags Product
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param images formData file true "Images, can be sent multiple times"
+// @Success 201 {object} pkgutil.HTTPResponse{data=[]model.ProductImageResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/images [post]
+func (ctrl ControllerHTTP) UploadImages(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	form, err := c.MultipartForm()
+	exception.PanicIfNeeded(err)
+
+	var req model.ProductImageUploadRequest
+	req.Files = form.File["images"]
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.UploadImages(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusCreated).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusCreated,
+		Data: res,
+	})
+}
+
+// @Summary Reorder Product Images
+// @Description Set the order of the product images, image_ids must contain every image of the product
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param body body model.ProductImageReorderRequest true "Payload Reorder Product Images Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/images/order [put]
+func (ctrl ControllerHTTP) ReorderImages(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductImageReorderRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.ReorderImages(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Delete Product Image
+// @Description Delete Product Image
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param imageId path string true "Image ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/images/:imageId [delete]
+func (ctrl ControllerHTTP) DeleteImage(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductImageDeleteRequest
+	var err error
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	req.ImageID, err = uuid.Parse(c.Params("imageId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.DeleteImage(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Create Product Variant
+// @Description Create Product Variant, stok and price of the product become the total stok and lowest price of its variants
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param body body model.ProductVariantCreateRequest true "Payload Create Product Variant Request"
+// @Success 201 {object} pkgutil.HTTPResponse{data=model.ProductVariantResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/variants [post]
+func (ctrl ControllerHTTP) CreateVariant(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductVariantCreateRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.CreateVariant(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusCreated).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusCreated,
+		Data: res,
+	})
+}
+
+// @Summary Update Product Variant
+// @Description Update Product Variant
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Param body body model.ProductVariantUpdateRequest true "Payload Update Product Variant Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/variants/:variantId [put]
+func (ctrl ControllerHTTP) UpdateVariant(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductVariantUpdateRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	req.ID, err = uuid.Parse(c.Params("variantId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.UpdateVariant(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Delete Product Variant
+// @Description Delete Product Variant
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param variantId path string true "Variant ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/variants/:variantId [delete]
+func (ctrl ControllerHTTP) DeleteVariant(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductVariantDeleteRequest
+	var err error
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	req.ID, err = uuid.Parse(c.Params("variantId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.DeleteVariant(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Adjust Product Stok
+// @Description Increment or decrement the stok atomically, variant_id is required for a product with variants
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param body body model.StockAdjustmentRequest true "Payload Stock Adjustment Request"
+// @Success 201 {object} pkgutil.HTTPResponse{data=model.StockMovementResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/stock-adjustments [post]
+func (ctrl ControllerHTTP) AdjustStok(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.StockAdjustmentRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+	req.UserID = uuidUserID
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.AdjustStok(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusCreated).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusCreated,
+		Data: res,
+	})
+}
+
+// @Summary Get Product Stock Movements
+// @Description Get the stok history of the product, owner only
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param page query string true "Page"
+// @Param limit query string true "Limit"
+// @Param variant_id query string false "Variant ID"
+// @Param reason query string false "Reason" Enums(SALE, RESTOCK, ADJUSTMENT, RETURN)
+// @Success 200 {object} pkgutil.HTTPResponse{data=pkgutil.PaginationResponse[[]model.StockMovementResponse]{data=[]model.StockMovementResponse}}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/stock-movements [get]
+func (ctrl ControllerHTTP) GetStockMovements(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	reqQuery := model.GetListStockMovementRequest{}
+	err := c.QueryParser(&reqQuery)
+	exception.PanicIfNeeded(err)
+
+	reqQuery.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	reqQuery.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetStockMovements(c.UserContext(), reqQuery)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Create Product Sale
+// @Description Schedule a sale price between starts_at and ends_at, owner only. variant_id is required for a product with variants.
+// @Description A sale with a quota is a flash sale, it ends once the quota is sold. Sales of the same product or variant can not overlap
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param body body model.ProductSaleCreateRequest true "Payload Product Sale Create Request"
+// @Success 201 {object} pkgutil.HTTPResponse{data=model.ProductSaleResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/sales [post]
+func (ctrl ControllerHTTP) CreateSale(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductSaleCreateRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.CreateSale(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusCreated).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusCreated,
+		Data: res,
+	})
+}
+
+// @Summary Get Product Sales
+// @Description Get every sale of the product including ended and cancelled sales, owner only
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Success 200 {object} pkgutil.HTTPResponse{data=[]model.ProductSaleResponse}
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/sales [get]
+func (ctrl ControllerHTTP) GetSales(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	productID, err := uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetSales(c.UserContext(), productID, userID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Cancel Product Sale
+// @Description Cancel a scheduled or active sale, owner only
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param saleId path string true "Sale ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/sales/:saleId [delete]
+func (ctrl ControllerHTTP) CancelSale(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductSaleCancelRequest
+	var err error
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	req.ID, err = uuid.Parse(c.Params("saleId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.CancelSale(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Get Product Price History
+// @Description Get the price changes and sales of the product, owner only
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param page query string true "Page"
+// @Param limit query string true "Limit"
+// @Param variant_id query string false "Variant ID"
+// @Success 200 {object} pkgutil.HTTPResponse{data=pkgutil.PaginationResponse[[]model.PriceHistoryResponse]{data=[]model.PriceHistoryResponse}}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/price-history [get]
+func (ctrl ControllerHTTP) GetPriceHistory(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	reqQuery := model.GetListPriceHistoryRequest{}
+	err := c.QueryParser(&reqQuery)
+	exception.PanicIfNeeded(err)
+
+	reqQuery.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	reqQuery.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetPriceHistory(c.UserContext(), reqQuery)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Import Products
+// @Description Create or update products of the logged in seller by sku from a csv or ndjson file sent as the request body.
+// @Description The csv file must have a header with the columns sku, name, description, price, stok and tags, tags are separated by "|".
+// @Description Every row is imported on its own, failed rows are reported with their errors
+// @Tags Product
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param format query string false "Format of the file, taken from the Content-Type when omitted" Enums(csv, ndjson)
+// @Param body body string true "Import file"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.ProductImportResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/import [post]
+func (ctrl ControllerHTTP) Import(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var err error
+	req := model.ProductImportRequest{
+		Format: c.Query("format"),
+		File:   bytes.NewReader(c.Body()),
+	}
+
+	if req.Format == "" {
+		switch mediaType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType)); mediaType {
+		case "text/csv":
+			req.Format = model.ProductFileFormatCSV
+		case "application/x-ndjson", "application/ndjson":
+			req.Format = model.ProductFileFormatNDJSON
+		default:
+			exception.PanicIfNeeded(constant.ErrProductImportInvalidFormat)
+		}
+	}
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.Import(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Export Products
+// @Description Download every product of the logged in seller, the file can be imported again
+// @Tags Product
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param Authorization header string true "With the bearer started"
+// @Param format query string false "Format of the file, default csv" Enums(csv, ndjson)
+// @Success 200 {file} file
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/export [get]
+func (ctrl ControllerHTTP) Export(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var err error
+	req := model.ProductExportRequest{
+		Format: c.Query("format", model.ProductFileFormatCSV),
+	}
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	// the body is written after the handler returns, the request context is canceled by then
+	ctx := context.WithoutCancel(c.UserContext())
+
+	stream, err := ctrl.svc.Export(ctx, req)
+	exception.PanicIfNeeded(err)
+
+	contentType := "text/csv"
+	if req.Format == model.ProductFileFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Attachment("products." + req.Format)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		err := stream(w)
+		if err != nil {
+			logger.Log(ctx).Error().Err(err).Msg("product.controller.Export: failed to stream products")
+		}
+	})
+
+	return nil
+}
+
+// @Summary Get Product Cache Stats
+// @Description Hit and miss counters of the product catalog cache of the instance serving the request, admin only
+// @Tags Product
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.ProductCacheStatsResponse}
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/cache-stats [get]
+func (ctrl ControllerHTTP) GetCacheStats(c *fiber.Ctx) error {
+	res := ctrl.svc.GetCacheStats(c.UserContext())
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
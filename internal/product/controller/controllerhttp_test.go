@@ -0,0 +1,263 @@
+package productctrl
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	auditsvc "github.com/arfan21/vocagame/internal/audit/service"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	productrepo "github.com/arfan21/vocagame/internal/product/repository"
+	productsvc "github.com/arfan21/vocagame/internal/product/service"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/exception"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+var productColumns = []string{
+	"id", "sku", "name", "stok", "reserved_stok", "price", "description", "sold_count", "rating_count", "rating_sum",
+	"version", "created_at", "deleted_at", "delisted_at", "published_at", "owner_id", "owner_name",
+	"categories", "tags", "images", "variants", "final_price", "sale",
+}
+
+func initPgMock(t *testing.T) pgxmock.PgxPoolIface {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mock
+}
+
+// initApp serves the product routes like the server, requests are authenticated as userID.
+func initApp(db pgxmock.PgxPoolIface, userID uuid.UUID) *fiber.App {
+	auditSvc := auditsvc.New(auditrepo.New(db))
+	ctrl := New(productsvc.New(productrepo.New(db, db), auditSvc, nil))
+
+	app := fiber.New(fiber.Config{ErrorHandler: exception.FiberErrorHandler})
+	app.Use(recover.New())
+
+	auth := func(c *fiber.Ctx) error {
+		c.Locals(constant.JWTClaimsContextKey, model.JWTClaims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: userID.String()},
+		})
+		return c.Next()
+	}
+
+	app.Get("/products/mine", auth, ctrl.GetOwnProducts)
+	app.Get("/products/:productId", ctrl.GetProduct)
+	app.Put("/products/:productId", auth, ctrl.Update)
+	app.Patch("/products/:productId", auth, ctrl.Patch)
+	app.Post("/products/:productId/publish", auth, ctrl.Publish)
+
+	return app
+}
+
+func expectRecordAuditLog(dbMock pgxmock.PgxPoolIface) {
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func expectGetProducts(dbMock pgxmock.PgxPoolIface, args []any, data ...entity.Product) {
+	rows := pgxmock.NewRows(productColumns)
+	for _, v := range data {
+		rows.AddRow(
+			v.ID, v.SKU, v.Name, v.Stok, v.ReservedStok, v.Price, v.Description, v.SoldCount, v.RatingCount, v.RatingSum,
+			v.Version, v.CreatedAt, v.DeletedAt, v.DelistedAt, v.PublishedAt, v.User.ID, v.User.Fullname,
+			v.Categories, v.Tags, v.Images, v.Variants, v.FinalPrice, v.Sale,
+		)
+	}
+
+	dbMock.ExpectQuery("SELECT (.+) FROM products p JOIN users u (.+)").
+		WithArgs(args...).
+		WillReturnRows(rows)
+}
+
+func newProduct(ownerID uuid.UUID) entity.Product {
+	return entity.Product{
+		ID:          uuid.New(),
+		Name:        "product 1",
+		Description: "description 1",
+		Stok:        10,
+		Price:       decimal.NewFromInt(1000),
+		FinalPrice:  decimal.NewFromInt(1000),
+		Version:     3,
+		CreatedAt:   time.Now(),
+		PublishedAt: null.TimeFrom(time.Now().Add(-time.Hour)),
+		User:        entity.User{ID: ownerID, Fullname: "owner"},
+		Categories:  []entity.Category{},
+		Tags:        []string{},
+		Images:      []entity.ProductImage{},
+		Variants:    []entity.ProductVariant{},
+	}
+}
+
+func sendRequest(t *testing.T, app *fiber.App, method, target, body string, header map[string]string) *http.Response {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	res, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res
+}
+
+func TestGetProductETagSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	product := newProduct(uuid.New())
+	app := initApp(dbMock, uuid.New())
+
+	expectGetProducts(dbMock, []any{product.ID, 1, 0}, product)
+
+	res := sendRequest(t, app, fiber.MethodGet, "/products/"+product.ID.String(), "", nil)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.Equal(t, `"3"`, res.Header.Get(fiber.HeaderETag))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPatchProductFailedIfMatchVersionMismatch(t *testing.T) {
+	dbMock := initPgMock(t)
+	userID := uuid.New()
+	product := newProduct(userID)
+	app := initApp(dbMock, userID)
+
+	// the product was updated to version 3 after the client read version 2
+	expectGetProducts(dbMock, []any{product.ID, 1, 0}, product)
+
+	res := sendRequest(t, app, fiber.MethodPatch, "/products/"+product.ID.String(), `{"name":"renamed"}`, map[string]string{
+		fiber.HeaderIfMatch: `"2"`,
+	})
+	assert.Equal(t, fiber.StatusPreconditionFailed, res.StatusCode)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateProductFailedConcurrentUpdate(t *testing.T) {
+	dbMock := initPgMock(t)
+	userID := uuid.New()
+	product := newProduct(userID)
+	app := initApp(dbMock, userID)
+
+	expectGetProducts(dbMock, []any{product.ID, 1, 0}, product)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT stok FROM products WHERE (.+) FOR UPDATE").
+		WithArgs(product.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"stok"}).AddRow(product.Stok))
+	// another update committed between the read and the update
+	dbMock.ExpectQuery("UPDATE products SET (.+) RETURNING version").
+		WithArgs("renamed", product.Description, 5, product.Price, product.ID, product.Version).
+		WillReturnRows(pgxmock.NewRows([]string{"version"}))
+	dbMock.ExpectRollback()
+
+	body := `{"name":"renamed","description":"description 1","stok":5,"price":"1000"}`
+	res := sendRequest(t, app, fiber.MethodPut, "/products/"+product.ID.String(), body, map[string]string{
+		fiber.HeaderIfMatch: `"3"`,
+	})
+	assert.Equal(t, fiber.StatusPreconditionFailed, res.StatusCode)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPatchProductFailedInvalidIfMatch(t *testing.T) {
+	dbMock := initPgMock(t)
+	userID := uuid.New()
+	app := initApp(dbMock, userID)
+
+	for _, ifMatch := range []string{"3", `"abc"`, `"0"`, `W/"3"`} {
+		res := sendRequest(t, app, fiber.MethodPatch, "/products/"+uuid.NewString(), `{"name":"renamed"}`, map[string]string{
+			fiber.HeaderIfMatch: ifMatch,
+		})
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode, ifMatch)
+	}
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPatchProductKeepStokSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	userID := uuid.New()
+	product := newProduct(userID)
+	app := initApp(dbMock, userID)
+
+	expectGetProducts(dbMock, []any{product.ID, 1, 0}, product)
+	dbMock.ExpectBegin()
+	// 3 were sold since the product was read, the patch must not bring them back
+	dbMock.ExpectQuery("SELECT stok FROM products WHERE (.+) FOR UPDATE").
+		WithArgs(product.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"stok"}).AddRow(7))
+	dbMock.ExpectQuery("UPDATE products SET (.+) RETURNING version").
+		WithArgs("renamed", product.Description, 7, product.Price, product.ID, product.Version).
+		WillReturnRows(pgxmock.NewRows([]string{"version"}).AddRow(4))
+	// no stock movement nor price history, neither changed
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	res := sendRequest(t, app, fiber.MethodPatch, "/products/"+product.ID.String(), `{"name":"renamed"}`, map[string]string{
+		fiber.HeaderIfMatch: `"3"`,
+	})
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.Equal(t, `"4"`, res.Header.Get(fiber.HeaderETag))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPublishProductScheduledSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	userID := uuid.New()
+	product := newProduct(userID)
+	product.PublishedAt = null.Time{}
+	app := initApp(dbMock, userID)
+
+	publishAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	expectGetProducts(dbMock, []any{product.ID, 1, 0}, product)
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec("UPDATE products SET published_at = (.+) WHERE (.+)").
+		WithArgs(publishAt, product.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	res := sendRequest(t, app, fiber.MethodPost, "/products/"+product.ID.String()+"/publish", `{"publish_at":"`+publishAt.Format(time.RFC3339)+`"}`, nil)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	// the owner sees the product as scheduled until publish_at
+	product.PublishedAt = null.TimeFrom(publishAt)
+	expectGetProducts(dbMock, []any{userID, 10, 0}, product)
+
+	res = sendRequest(t, app, fiber.MethodGet, "/products/mine?status=scheduled&page=1&limit=10&skip_total=true", "", nil)
+	assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	var resBody struct {
+		Data struct {
+			Data []model.GetProductResponse `json:"data"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resBody))
+	assert.Len(t, resBody.Data.Data, 1)
+	assert.Equal(t, entity.ProductStatusScheduled, resBody.Data.Data[0].Status)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -2,22 +2,76 @@ package product
 
 import (
 	"context"
+	"time"
 
 	"github.com/arfan21/vocagame/internal/entity"
 	productrepo "github.com/arfan21/vocagame/internal/product/repository"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"gopkg.in/guregu/null.v4"
 )
 
 type Repository interface {
 	Begin(ctx context.Context) (tx pgx.Tx, err error)
 	WithTx(tx pgx.Tx) *productrepo.Repository
 
-	Create(ctx context.Context, data entity.Product) (err error)
+	Create(ctx context.Context, data entity.Product) (id uuid.UUID, err error)
 	GetProducts(ctx context.Context, filter entity.ListProductFilter) (result []entity.Product, err error)
 	GetTotalProduct(ctx context.Context, filter entity.ListProductFilter) (result int, err error)
-	Update(ctx context.Context, data entity.Product) (err error)
+	GetCategoryFacets(ctx context.Context, filter entity.ListProductFilter) (result []entity.CategoryFacet, err error)
+	GetTagFacets(ctx context.Context, filter entity.ListProductFilter, limit int) (result []entity.TagFacet, err error)
+	SetCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) (err error)
+	SetTags(ctx context.Context, productID uuid.UUID, tags []string) (err error)
+	SetSKU(ctx context.Context, id uuid.UUID, sku null.String) (err error)
+	GetIDBySKU(ctx context.Context, userID uuid.UUID, sku string) (id uuid.UUID, err error)
+	GetProductsForExport(ctx context.Context, userID uuid.UUID, afterID uuid.NullUUID, limit int) (result []entity.Product, err error)
+	CreateVariant(ctx context.Context, data entity.ProductVariant) (id uuid.UUID, err error)
+	UpdateVariant(ctx context.Context, data entity.ProductVariant) (err error)
+	DeleteVariant(ctx context.Context, productID uuid.UUID, id uuid.UUID) (err error)
+	GetVariantsByIDs(ctx context.Context, ids []uuid.UUID) (result map[uuid.UUID]entity.ProductVariant, err error)
+	ReduceVariantStok(ctx context.Context, id uuid.UUID, reduceBy int) (err error)
+	ReserveVariantStok(ctx context.Context, id uuid.UUID, qty int) (err error)
+	ReleaseVariantStok(ctx context.Context, id uuid.UUID, qty int) (err error)
+	ConsumeReservedVariantStok(ctx context.Context, id uuid.UUID, qty int) (err error)
+	SyncVariantTotals(ctx context.Context, productID uuid.UUID) (err error)
+	CreateImage(ctx context.Context, data entity.ProductImage) (err error)
+	GetImages(ctx context.Context, productID uuid.UUID) (result []entity.ProductImage, err error)
+	DeleteImage(ctx context.Context, productID uuid.UUID, id uuid.UUID) (data entity.ProductImage, err error)
+	UpdateImagePositions(ctx context.Context, productID uuid.UUID, ids []uuid.UUID) (err error)
+	Update(ctx context.Context, data entity.Product) (version int, err error)
 	Delete(ctx context.Context, id uuid.UUID) (err error)
+	Delist(ctx context.Context, id uuid.UUID) (err error)
+	Restore(ctx context.Context, id uuid.UUID) (err error)
+	Publish(ctx context.Context, id uuid.UUID, publishedAt time.Time) (err error)
+	Unpublish(ctx context.Context, id uuid.UUID) (err error)
 	ReduceStok(ctx context.Context, id uuid.UUID, reduceBy int) (err error)
+	ReserveStok(ctx context.Context, id uuid.UUID, qty int) (err error)
+	ReleaseStok(ctx context.Context, id uuid.UUID, qty int) (err error)
+	ConsumeReservedStok(ctx context.Context, id uuid.UUID, qty int) (err error)
+	AddRating(ctx context.Context, id uuid.UUID, rating int) (err error)
 	GetByIDs(ctx context.Context, ids []uuid.UUID) (result map[uuid.UUID]entity.Product, err error)
+	GetStokForUpdate(ctx context.Context, id uuid.UUID) (stok int, err error)
+	GetVariantStokForUpdate(ctx context.Context, id uuid.UUID) (stok int, err error)
+	AdjustStok(ctx context.Context, id uuid.UUID, delta int) (stok int, err error)
+	AdjustVariantStok(ctx context.Context, id uuid.UUID, delta int) (stok int, err error)
+	CreateStockMovement(ctx context.Context, data entity.StockMovement) (result entity.StockMovement, err error)
+	GetStockMovements(ctx context.Context, filter entity.ListStockMovementFilter) (result []entity.StockMovement, err error)
+	GetTotalStockMovement(ctx context.Context, filter entity.ListStockMovementFilter) (result int, err error)
+	CreateSale(ctx context.Context, data entity.ProductSale) (result entity.ProductSale, err error)
+	HasOverlappingSale(ctx context.Context, productID uuid.UUID, variantID uuid.NullUUID, startsAt, endsAt time.Time) (exists bool, err error)
+	GetSales(ctx context.Context, productID uuid.UUID) (result []entity.ProductSale, err error)
+	GetActiveSales(ctx context.Context, productIDs []uuid.UUID) (result []entity.ProductSale, err error)
+	GetSaleByID(ctx context.Context, productID uuid.UUID, id uuid.UUID) (result entity.ProductSale, err error)
+	CancelSale(ctx context.Context, productID uuid.UUID, id uuid.UUID) (cancelledAt time.Time, err error)
+	UseSaleQuota(ctx context.Context, id uuid.UUID, qty int) (err error)
+	AddSaleUsage(ctx context.Context, id uuid.UUID, userID uuid.UUID, qty int, limit int) (err error)
+	CreatePriceHistory(ctx context.Context, data entity.ProductPriceHistory) (err error)
+	GetPriceHistory(ctx context.Context, filter entity.ListProductPriceHistoryFilter) (result []entity.ProductPriceHistory, err error)
+	GetTotalPriceHistory(ctx context.Context, filter entity.ListProductPriceHistoryFilter) (result int, err error)
+}
+
+// RepositoryCache is a Repository caching the product catalog.
+type RepositoryCache interface {
+	Repository
+	Stats() entity.ProductCacheStats
 }
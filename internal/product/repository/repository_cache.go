@@ -0,0 +1,227 @@
+package productrepo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
+	"github.com/arfan21/vocagame/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	productCacheKeyPrefix      = "product:cache:"
+	productCacheVersionKey     = productCacheKeyPrefix + "version"
+	productCacheInvalidateHook = "product_cache_invalidate"
+)
+
+// RepositoryCache is a read-through cache in redis of the public catalog queries of Repository.
+// Keys contain a catalog version which is bumped on every write, so a write makes every cached entry
+// unreachable at once, old entries are left to expire after ttl.
+// Owner queries (archived or including delisted products) are read before writes and never cached.
+type RepositoryCache struct {
+	*Repository
+	client *redis.Client
+	ttl    time.Duration
+	group  *singleflight.Group
+	stats  *productCacheStats
+}
+
+type productCacheStats struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// NewCache wraps repo, writes through repo invalidate the cache from now on.
+// Transactions begun through repo are *dbpostgres.Tx, so their writes invalidate on commit.
+func NewCache(repo *Repository, client *redis.Client, ttl time.Duration) *RepositoryCache {
+	cache := &RepositoryCache{
+		Repository: repo,
+		client:     client,
+		ttl:        ttl,
+		group:      &singleflight.Group{},
+		stats:      &productCacheStats{},
+	}
+
+	repo.rawDb = dbpostgres.WithAfterCommit(repo.rawDb)
+	repo.invalidate = cache.Invalidate
+
+	return cache
+}
+
+// Invalidate bumps the catalog version, a failure is only logged since entries expire anyway.
+func (r RepositoryCache) Invalidate(ctx context.Context) {
+	err := r.client.Incr(context.WithoutCancel(ctx), productCacheVersionKey).Err()
+	if err != nil {
+		r.stats.errors.Add(1)
+		logger.Log(ctx).Error().Err(err).Msg("product.repository_cache.Invalidate: failed to bump catalog version")
+	}
+}
+
+// Stats returns the counters of this instance since it started.
+func (r RepositoryCache) Stats() entity.ProductCacheStats {
+	return entity.ProductCacheStats{
+		Hits:   r.stats.hits.Load(),
+		Misses: r.stats.misses.Load(),
+		Errors: r.stats.errors.Load(),
+	}
+}
+
+func (r RepositoryCache) GetProducts(ctx context.Context, filter entity.ListProductFilter) (result []entity.Product, err error) {
+	if !isProductFilterCacheable(filter) {
+		return r.Repository.GetProducts(ctx, filter)
+	}
+
+	return readThrough(ctx, r, "products", productCacheFilter(filter, true), r.Repository.GetProducts, filter)
+}
+
+func (r RepositoryCache) GetTotalProduct(ctx context.Context, filter entity.ListProductFilter) (result int, err error) {
+	if !isProductFilterCacheable(filter) {
+		return r.Repository.GetTotalProduct(ctx, filter)
+	}
+
+	return readThrough(ctx, r, "total", productCacheFilter(filter, false), r.Repository.GetTotalProduct, filter)
+}
+
+func (r RepositoryCache) GetCategoryFacets(ctx context.Context, filter entity.ListProductFilter) (result []entity.CategoryFacet, err error) {
+	if !isProductFilterCacheable(filter) {
+		return r.Repository.GetCategoryFacets(ctx, filter)
+	}
+
+	return readThrough(ctx, r, "category_facets", productCacheFilter(filter, false), r.Repository.GetCategoryFacets, filter)
+}
+
+func (r RepositoryCache) GetTagFacets(ctx context.Context, filter entity.ListProductFilter, limit int) (result []entity.TagFacet, err error) {
+	if !isProductFilterCacheable(filter) {
+		return r.Repository.GetTagFacets(ctx, filter, limit)
+	}
+
+	load := func(ctx context.Context, filter entity.ListProductFilter) ([]entity.TagFacet, error) {
+		return r.Repository.GetTagFacets(ctx, filter, limit)
+	}
+
+	return readThrough(ctx, r, fmt.Sprintf("tag_facets:%d", limit), productCacheFilter(filter, false), load, filter)
+}
+
+func isProductFilterCacheable(filter entity.ListProductFilter) bool {
+	return !filter.Archived && !filter.IncludeDelisted
+}
+
+// productCacheFilter normalizes filter so equal queries share an entry, withPage is false for
+// queries ignoring the sort and pagination.
+func productCacheFilter(filter entity.ListProductFilter, withPage bool) any {
+	filter.Name = strings.ToLower(filter.Name)
+	filter.IDs = slices.Clone(filter.IDs)
+	slices.SortFunc(filter.IDs, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+
+	if !withPage {
+		filter.Sort = ""
+		filter.Cursor = nil
+		filter.Page = 0
+		filter.Limit = 0
+	}
+
+	return struct {
+		Filter        entity.ListProductFilter `json:"filter"`
+		DisableOffset bool                     `json:"disable_offset"`
+	}{filter, filter.DisableOffset}
+}
+
+// readThrough returns the cached result of load, on a miss only one caller per key runs load.
+// Redis errors are counted and fall back to load.
+func readThrough[T any](
+	ctx context.Context,
+	r RepositoryCache,
+	name string,
+	keyFilter any,
+	load func(ctx context.Context, filter entity.ListProductFilter) (T, error),
+	filter entity.ListProductFilter,
+) (result T, err error) {
+	key, err := r.key(ctx, name, keyFilter)
+	if err != nil {
+		r.stats.errors.Add(1)
+		logger.Log(ctx).Error().Err(err).Msg("product.repository_cache.readThrough: failed to build key")
+		return load(ctx, filter)
+	}
+
+	cached, err := r.client.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		errJson := json.Unmarshal(cached, &result)
+		if errJson == nil {
+			r.stats.hits.Add(1)
+			return result, nil
+		}
+
+		logger.Log(ctx).Error().Err(errJson).Msg("product.repository_cache.readThrough: failed to unmarshal entry")
+	case !errors.Is(err, redis.Nil):
+		r.stats.errors.Add(1)
+		logger.Log(ctx).Error().Err(err).Msg("product.repository_cache.readThrough: failed to get entry")
+		return load(ctx, filter)
+	}
+
+	r.stats.misses.Add(1)
+
+	// the result is shared with the callers waiting on the same key,
+	// so canceling the first caller must not fail the others
+	loaded, err, _ := r.group.Do(key, func() (any, error) {
+		ctx := context.WithoutCancel(ctx)
+
+		result, err := load(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			logger.Log(ctx).Error().Err(err).Msg("product.repository_cache.readThrough: failed to marshal entry")
+			return result, nil
+		}
+
+		err = r.client.Set(ctx, key, data, r.ttl).Err()
+		if err != nil {
+			r.stats.errors.Add(1)
+			logger.Log(ctx).Error().Err(err).Msg("product.repository_cache.readThrough: failed to set entry")
+		}
+
+		return result, nil
+	})
+	if err != nil {
+		return
+	}
+
+	return loaded.(T), nil
+}
+
+func (r RepositoryCache) key(ctx context.Context, name string, keyFilter any) (key string, err error) {
+	version, err := r.client.Get(ctx, productCacheVersionKey).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		err = fmt.Errorf("product.repository_cache.key: failed to get catalog version: %w", err)
+		return
+	}
+
+	data, err := json.Marshal(keyFilter)
+	if err != nil {
+		err = fmt.Errorf("product.repository_cache.key: failed to marshal filter: %w", err)
+		return
+	}
+
+	sum := sha256.Sum256(data)
+
+	return fmt.Sprintf("%s%d:%s:%s", productCacheKeyPrefix, version, name, hex.EncodeToString(sum[:])), nil
+}
@@ -0,0 +1,278 @@
+package productrepo
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis answers the commands used by RepositoryCache from memory, the client never dials.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func (f *fakeRedis) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (f *fakeRedis) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func (f *fakeRedis) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		args := cmd.Args()
+		key, _ := args[1].(string)
+
+		switch c := cmd.(type) {
+		case *redis.StringCmd:
+			value, ok := f.data[key]
+			if !ok {
+				c.SetErr(redis.Nil)
+				return redis.Nil
+			}
+
+			c.SetVal(value)
+		case *redis.StatusCmd:
+			switch value := args[2].(type) {
+			case []byte:
+				f.data[key] = string(value)
+			case string:
+				f.data[key] = value
+			}
+
+			c.SetVal("OK")
+		case *redis.IntCmd:
+			value, _ := strconv.ParseInt(f.data[key], 10, 64)
+			value++
+
+			f.data[key] = strconv.FormatInt(value, 10)
+			c.SetVal(value)
+		}
+
+		return nil
+	}
+}
+
+func (f *fakeRedis) get(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.data[key]
+}
+
+func initCacheMock(t *testing.T) (pgxmock.PgxPoolIface, *RepositoryCache, *fakeRedis) {
+	dbMock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeRedis{data: map[string]string{}}
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	client.AddHook(fake)
+
+	return dbMock, NewCache(New(dbMock, dbMock), client, time.Minute), fake
+}
+
+func TestProductCacheKeyNormalizedSuccess(t *testing.T) {
+	_, cache, _ := initCacheMock(t)
+	ctx := context.Background()
+
+	a, b := uuid.New(), uuid.New()
+
+	keyOf := func(filter entity.ListProductFilter, withPage bool) string {
+		key, err := cache.key(ctx, "products", productCacheFilter(filter, withPage))
+		assert.NoError(t, err)
+		return key
+	}
+
+	// the name is matched case insensitive and the order of ids does not matter
+	assert.Equal(t,
+		keyOf(entity.ListProductFilter{Name: "Voucher", IDs: []uuid.UUID{a, b}, Page: 1, Limit: 10}, true),
+		keyOf(entity.ListProductFilter{Name: "voucher", IDs: []uuid.UUID{b, a}, Page: 1, Limit: 10}, true),
+	)
+
+	// the page is part of a listing but not of a total
+	assert.NotEqual(t,
+		keyOf(entity.ListProductFilter{Page: 1, Limit: 10}, true),
+		keyOf(entity.ListProductFilter{Page: 2, Limit: 10}, true),
+	)
+	assert.Equal(t,
+		keyOf(entity.ListProductFilter{Page: 1, Limit: 10, Sort: entity.ProductSortNewest}, false),
+		keyOf(entity.ListProductFilter{Page: 2, Limit: 20, Sort: entity.ProductSortPriceAsc}, false),
+	)
+
+	assert.NotEqual(t,
+		keyOf(entity.ListProductFilter{Name: "voucher"}, false),
+		keyOf(entity.ListProductFilter{Name: "diamond"}, false),
+	)
+
+	// the key of the normalized filter does not sort the ids of the caller
+	ids := []uuid.UUID{b, a}
+	productCacheFilter(entity.ListProductFilter{IDs: ids}, true)
+	assert.Equal(t, []uuid.UUID{b, a}, ids)
+}
+
+func TestRepositoryCacheGetTotalProductHitAndMissSuccess(t *testing.T) {
+	dbMock, cache, _ := initCacheMock(t)
+	ctx := context.Background()
+
+	userID := uuid.New()
+	filter := entity.ListProductFilter{UserID: uuid.NullUUID{UUID: userID, Valid: true}}
+
+	// only the miss reads the db
+	dbMock.ExpectQuery("SELECT COUNT(.+) FROM products p (.+)").
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(3))
+
+	total, err := cache.GetTotalProduct(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, entity.ProductCacheStats{Misses: 1}, cache.Stats())
+
+	total, err = cache.GetTotalProduct(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Equal(t, entity.ProductCacheStats{Hits: 1, Misses: 1}, cache.Stats())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	// a write makes the cached total unreachable
+	cache.Invalidate(ctx)
+
+	dbMock.ExpectQuery("SELECT COUNT(.+) FROM products p (.+)").
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(4))
+
+	total, err = cache.GetTotalProduct(ctx, filter)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, entity.ProductCacheStats{Hits: 1, Misses: 2}, cache.Stats())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRepositoryCacheGetProductsOwnerQueriesNotCachedSuccess(t *testing.T) {
+	dbMock, cache, fake := initCacheMock(t)
+
+	productID := uuid.New()
+	filter := entity.ListProductFilter{ID: uuid.NullUUID{UUID: productID, Valid: true}, IncludeDelisted: true, Page: 1, Limit: 1}
+
+	for i := 0; i < 2; i++ {
+		dbMock.ExpectQuery("SELECT (.+) FROM products p JOIN users u (.+)").
+			WithArgs(productID, 1, 0).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}))
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.GetProducts(context.Background(), filter)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, entity.ProductCacheStats{}, cache.Stats())
+	assert.Empty(t, fake.data)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRepositoryCacheInvalidateAfterCommitSuccess(t *testing.T) {
+	dbMock, cache, fake := initCacheMock(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	tests := []struct {
+		name    string
+		begin   func(ctx context.Context) (pgx.Tx, error)
+		version string
+	}{
+		{
+			name:    "transaction of the product repository",
+			begin:   cache.Begin,
+			version: "1",
+		},
+		{
+			// e.g. a checkout writes the stok in a transaction of the transaction repository
+			name:    "transaction of another repository",
+			begin:   dbpostgres.WithAfterCommit(dbMock).Begin,
+			version: "2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbMock.ExpectBegin()
+			for i := 0; i < 2; i++ {
+				dbMock.ExpectExec("UPDATE products SET reserved_stok = (.+) WHERE (.+)").
+					WithArgs(1, productID).
+					WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+			}
+			dbMock.ExpectCommit()
+
+			version := fake.get(productCacheVersionKey)
+
+			tx, err := tt.begin(ctx)
+			assert.NoError(t, err)
+
+			for i := 0; i < 2; i++ {
+				assert.NoError(t, cache.WithTx(tx).ReserveStok(ctx, productID, 1))
+			}
+
+			// readers still see the old catalog, so caching it again is harmless
+			assert.Equal(t, version, fake.get(productCacheVersionKey))
+
+			assert.NoError(t, tx.Commit(ctx))
+
+			// the version is bumped once per transaction
+			assert.Equal(t, tt.version, fake.get(productCacheVersionKey))
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepositoryCacheRollbackNotInvalidatedSuccess(t *testing.T) {
+	dbMock, cache, fake := initCacheMock(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec("UPDATE products SET reserved_stok = (.+) WHERE (.+)").
+		WithArgs(1, productID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	dbMock.ExpectRollback()
+
+	tx, err := cache.Begin(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, cache.WithTx(tx).ReserveStok(ctx, productID, 1))
+	assert.NoError(t, tx.Rollback(ctx))
+
+	assert.Empty(t, fake.get(productCacheVersionKey))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRepositoryCacheWriteWithoutTransactionInvalidatedSuccess(t *testing.T) {
+	dbMock, cache, fake := initCacheMock(t)
+	ctx := context.Background()
+
+	productID := uuid.New()
+
+	dbMock.ExpectExec("UPDATE products SET reserved_stok = (.+) WHERE (.+)").
+		WithArgs(1, productID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	assert.NoError(t, cache.ReserveStok(ctx, productID, 1))
+	assert.Equal(t, "1", fake.get(productCacheVersionKey))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
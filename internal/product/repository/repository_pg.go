@@ -2,20 +2,27 @@ package productrepo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/arfan21/vocagame/internal/entity"
 	"github.com/arfan21/vocagame/pkg/constant"
 	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gopkg.in/guregu/null.v4"
 )
 
 type Repository struct {
 	db    dbpostgres.Queryer
 	rawDb dbpostgres.Raw
+	// invalidate is set by NewCache, called after writes changing the product catalog
+	invalidate func(ctx context.Context)
 }
 
 func New(raw dbpostgres.Raw, queryer dbpostgres.Queryer) *Repository {
@@ -34,32 +41,154 @@ func (r Repository) WithTx(tx pgx.Tx) *Repository {
 	return &r
 }
 
-func (r Repository) Create(ctx context.Context, data entity.Product) (err error) {
+// changed invalidates the cached catalog, writes of a transaction invalidate it once the
+// transaction is committed, whichever repository began it.
+func (r Repository) changed(ctx context.Context) {
+	if r.invalidate == nil {
+		return
+	}
+
+	if tx, ok := r.db.(*dbpostgres.Tx); ok {
+		tx.AfterCommit(productCacheInvalidateHook, r.invalidate)
+		return
+	}
+
+	r.invalidate(ctx)
+}
+
+func (r Repository) Create(ctx context.Context, data entity.Product) (id uuid.UUID, err error) {
 	query := `
-		INSERT INTO products (user_id, name, description, stok, price)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO products (user_id, name, description, stok, price, sku, published_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
 	`
 
-	_, err = r.db.Exec(ctx, query,
+	err = r.db.QueryRow(ctx, query,
 		data.UserID,
 		data.Name,
 		data.Description,
 		data.Stok,
 		data.Price,
-	)
+		data.SKU,
+		data.PublishedAt,
+	).Scan(&id)
 
 	if err != nil {
+		err = productSKUPgError(err)
 		err = fmt.Errorf("product.repository.Create: failed to create product: %w", err)
 		return
 	}
 
+	r.changed(ctx)
+
 	return
 }
 
+// toPrefixTsQuery turns free text into a tsquery where every term is prefix matched,
+// e.g. "mobile leg" becomes "mobile:* & leg:*".
+func toPrefixTsQuery(q string) string {
+	terms := strings.FieldsFunc(q, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = strings.ToLower(term) + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+func productSearchRankColumn(filter entity.ListProductFilter) string {
+	if filter.Fuzzy {
+		return "GREATEST(ts_rank(p.search_vector, search.query), similarity(LOWER(p.name), search.raw))"
+	}
+
+	return "ts_rank(p.search_vector, search.query)"
+}
+
+// productActiveSaleCondition matches the sales of product_sales ps which are running now and not sold out.
+const productActiveSaleCondition = "ps.cancelled_at IS NULL AND ps.starts_at <= now() AND ps.ends_at > now() AND (ps.quota IS NULL OR ps.sold < ps.quota)"
+
+// productFinalPriceColumn is the lowest price the product can be bought for now,
+// for a product with variants it is the lowest price of its variants including their sales.
+const productFinalPriceColumn = "LEAST(p.price, (SELECT MIN(ps.sale_price) FROM product_sales ps WHERE ps.product_id = p.id AND " + productActiveSaleCondition + "))"
+
+// productSaleJSON builds an entity.ProductSale of product_sales ps, the timestamps are formatted
+// as UTC like pgx reads TIMESTAMP columns.
+const productSaleJSON = `json_build_object(
+	'id', ps.id,
+	'product_id', ps.product_id,
+	'variant_id', ps.variant_id,
+	'sale_price', ps.sale_price,
+	'starts_at', to_char(ps.starts_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+	'ends_at', to_char(ps.ends_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+	'quota', ps.quota,
+	'sold', ps.sold,
+	'per_user_limit', ps.per_user_limit,
+	'created_by', ps.created_by,
+	'created_at', to_char(ps.created_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+	'updated_at', to_char(ps.updated_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+)`
+
+// productSortKey returns the column expression, its sql type and direction used to order products.
+func productSortKey(filter entity.ListProductFilter) (column string, castType string, desc bool) {
+	switch filter.Sort {
+	case entity.ProductSortPriceAsc:
+		return productFinalPriceColumn, "decimal", false
+	case entity.ProductSortPriceDesc:
+		return productFinalPriceColumn, "decimal", true
+	case entity.ProductSortBestSelling:
+		return "p.sold_count", "int", true
+	case entity.ProductSortRelevance:
+		if len(filter.Query) != 0 {
+			return productSearchRankColumn(filter), "real", true
+		}
+	}
+
+	return "p.created_at", "timestamp", true
+}
+
 func (r Repository) queryRowsProductWithFilter(ctx context.Context, query string, filter entity.ListProductFilter) (rows pgx.Rows, err error) {
+	query, filterArgs := buildProductFilterQuery(query, filter)
+	return r.db.Query(ctx, query, filterArgs...)
+}
+
+// buildProductFilterQuery appends the joins, where, order and limit clauses of filter to query.
+func buildProductFilterQuery(query string, filter entity.ListProductFilter) (string, []any) {
 	var filterArgs []any
 	var whereQuery string
 
+	switch {
+	case filter.Archived:
+		whereQuery += "(p.deleted_at IS NOT NULL OR p.delisted_at IS NOT NULL) AND "
+	case filter.IncludeDelisted:
+		whereQuery += "p.deleted_at IS NULL AND "
+	default:
+		whereQuery += "p.deleted_at IS NULL AND p.delisted_at IS NULL AND p.published_at <= now() AND "
+	}
+
+	switch filter.Status {
+	case entity.ProductStatusDraft:
+		whereQuery += "p.published_at IS NULL AND p.delisted_at IS NULL AND "
+	case entity.ProductStatusScheduled:
+		whereQuery += "p.published_at > now() AND p.delisted_at IS NULL AND "
+	case entity.ProductStatusPublished:
+		whereQuery += "p.published_at <= now() AND p.delisted_at IS NULL AND "
+	case entity.ProductStatusUnlisted:
+		whereQuery += "p.delisted_at IS NOT NULL AND "
+	}
+
+	if len(filter.Query) != 0 {
+		filterArgs = append(filterArgs, toPrefixTsQuery(filter.Query), strings.ToLower(filter.Query))
+		query += "CROSS JOIN (SELECT to_tsquery('simple', $1) AS query, $2::text AS raw) search "
+
+		if filter.Fuzzy {
+			whereQuery += "(p.search_vector @@ search.query OR LOWER(p.name) % search.raw) AND "
+		} else {
+			whereQuery += "p.search_vector @@ search.query AND "
+		}
+	}
+
 	if len(filter.Name) != 0 {
 		filterName := "%" + strings.ToLower(filter.Name) + "%"
 		filterArgs = append(filterArgs, filterName)
@@ -76,35 +205,178 @@ func (r Repository) queryRowsProductWithFilter(ctx context.Context, query string
 		whereQuery += "p.id = $" + strconv.Itoa(len(filterArgs)) + " AND "
 	}
 
-	// if filterArgsLen  > 0, add WHERE statement and remove last AND
-	if filterArgsLen := len(filterArgs); filterArgsLen > 0 {
+	if len(filter.IDs) != 0 {
+		filterArgs = append(filterArgs, filter.IDs)
+		whereQuery += "p.id = ANY($" + strconv.Itoa(len(filterArgs)) + ") AND "
+	}
+
+	if filter.MinPrice.Valid {
+		filterArgs = append(filterArgs, filter.MinPrice.Decimal)
+		whereQuery += productFinalPriceColumn + " >= $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	if filter.MaxPrice.Valid {
+		filterArgs = append(filterArgs, filter.MaxPrice.Decimal)
+		whereQuery += productFinalPriceColumn + " <= $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	if filter.InStock {
+		whereQuery += "p.stok - p.reserved_stok > 0 AND "
+	}
+
+	// products in the category or any of its descendants
+	if filter.CategoryID.Valid {
+		filterArgs = append(filterArgs, filter.CategoryID.UUID)
+		whereQuery += `EXISTS (
+			SELECT 1 FROM product_categories pc
+			WHERE pc.product_id = p.id AND pc.category_id IN (
+				WITH RECURSIVE category_tree AS (
+					SELECT id FROM categories WHERE id = $` + strconv.Itoa(len(filterArgs)) + `
+					UNION ALL
+					SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+				)
+				SELECT id FROM category_tree
+			)
+		) AND `
+	}
+
+	if len(filter.Tag) != 0 {
+		filterArgs = append(filterArgs, filter.Tag)
+		whereQuery += "EXISTS (SELECT 1 FROM product_tags pt WHERE pt.product_id = p.id AND pt.tag = $" + strconv.Itoa(len(filterArgs)) + ") AND "
+	}
+
+	if !filter.CreatedFrom.IsZero() {
+		filterArgs = append(filterArgs, filter.CreatedFrom)
+		whereQuery += "p.created_at >= $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	if !filter.CreatedTo.IsZero() {
+		filterArgs = append(filterArgs, filter.CreatedTo)
+		whereQuery += "p.created_at <= $" + strconv.Itoa(len(filterArgs)) + " AND "
+	}
+
+	sortColumn, sortCastType, sortDesc := productSortKey(filter)
+
+	// keyset pagination, only rows after the cursor sort key
+	if filter.Cursor != nil && !filter.DisableOffset {
+		operator := ">"
+		if sortDesc {
+			operator = "<"
+		}
+
+		filterArgs = append(filterArgs, filter.Cursor.Value)
+		cursorValue := "$" + strconv.Itoa(len(filterArgs)) + "::" + sortCastType
+
+		filterArgs = append(filterArgs, filter.Cursor.ID)
+		cursorID := "$" + strconv.Itoa(len(filterArgs))
+
+		whereQuery += "(" + sortColumn + ", p.id) " + operator + " (" + cursorValue + ", " + cursorID + ") AND "
+	}
+
+	// if whereQuery not empty, add WHERE statement and remove last AND
+	if len(whereQuery) > 0 {
 		whereQuery = "WHERE " + whereQuery[:len(whereQuery)-len(" AND ")] + " "
 	}
 
 	query += whereQuery
 
 	if !filter.DisableOffset {
+		direction := "ASC"
+		if sortDesc {
+			direction = "DESC"
+		}
+		query += "ORDER BY " + sortColumn + " " + direction + ", p.id " + direction + " "
+
 		filterArgs = append(filterArgs, filter.Limit)
 		query += "LIMIT $" + strconv.Itoa(len(filterArgs)) + " "
 
-		offset := (filter.Page - 1) * filter.Limit
-		filterArgs = append(filterArgs, offset)
-		query += "OFFSET $" + strconv.Itoa(len(filterArgs)) + " "
+		if filter.Cursor == nil {
+			offset := (filter.Page - 1) * filter.Limit
+			filterArgs = append(filterArgs, offset)
+			query += "OFFSET $" + strconv.Itoa(len(filterArgs)) + " "
+		}
 	}
 
-	return r.db.Query(ctx, query, filterArgs...)
+	return query, filterArgs
 }
 
 func (r Repository) GetProducts(ctx context.Context, filter entity.ListProductFilter) (result []entity.Product, err error) {
 	query := `
 		SELECT
 			p.id,
+			p.sku,
 			p.name,
 			p.stok,
+			p.reserved_stok,
 			p.price,
 			p.description,
+			p.sold_count,
+			p.rating_count,
+			p.rating_sum,
+			p.version,
+			p.created_at,
+			p.deleted_at,
+			p.delisted_at,
+			p.published_at,
 			u.id AS owner_id,
-			u.fullname AS owner_name
+			u.fullname AS owner_name,
+			COALESCE((
+				SELECT json_agg(json_build_object('id', c.id, 'name', c.name, 'slug', c.slug) ORDER BY c.name)
+				FROM product_categories pc JOIN categories c ON c.id = pc.category_id
+				WHERE pc.product_id = p.id
+			), '[]') AS categories,
+			ARRAY(SELECT pt.tag FROM product_tags pt WHERE pt.product_id = p.id ORDER BY pt.tag) AS tags,
+			COALESCE((
+				SELECT json_agg(json_build_object(
+					'id', pi.id,
+					'object_key', pi.object_key,
+					'thumbnail_key', pi.thumbnail_key,
+					'width', pi.width,
+					'height', pi.height,
+					'position', pi.position
+				) ORDER BY pi.position, pi.created_at)
+				FROM product_images pi
+				WHERE pi.product_id = p.id
+			), '[]') AS images,
+			COALESCE((
+				SELECT json_agg(json_build_object(
+					'id', pv.id,
+					'product_id', pv.product_id,
+					'sku', pv.sku,
+					'name', pv.name,
+					'price', pv.price,
+					'stok', pv.stok,
+					'reserved_stok', pv.reserved_stok,
+					'sold_count', pv.sold_count,
+					'sale', (
+						SELECT ` + productSaleJSON + `
+						FROM product_sales ps
+						WHERE ps.variant_id = pv.id AND ` + productActiveSaleCondition + `
+						LIMIT 1
+					)
+				) ORDER BY pv.price, pv.sku)
+				FROM product_variants pv
+				WHERE pv.product_id = p.id AND pv.deleted_at IS NULL
+			), '[]') AS variants,
+			` + productFinalPriceColumn + ` AS final_price,
+			(
+				SELECT ` + productSaleJSON + `
+				FROM product_sales ps
+				WHERE ps.product_id = p.id AND ps.variant_id IS NULL AND ` + productActiveSaleCondition + `
+				LIMIT 1
+			) AS sale
+	`
+
+	isSearch := len(filter.Query) != 0
+	if isSearch {
+		query += `,
+			` + productSearchRankColumn(filter) + ` AS rank,
+			ts_headline('simple', p.name, search.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS name_highlight,
+			ts_headline('simple', COALESCE(p.description, ''), search.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2') AS description_highlight
+		`
+	}
+
+	query += `
 		FROM
 			products p
 			JOIN users u ON u.id = p.user_id
@@ -121,16 +393,41 @@ func (r Repository) GetProducts(ctx context.Context, filter entity.ListProductFi
 	for rows.Next() {
 		var product entity.Product
 
-		err = rows.Scan(
+		dest := []any{
 			&product.ID,
+			&product.SKU,
 			&product.Name,
 			&product.Stok,
+			&product.ReservedStok,
 			&product.Price,
 			&product.Description,
+			&product.SoldCount,
+			&product.RatingCount,
+			&product.RatingSum,
+			&product.Version,
+			&product.CreatedAt,
+			&product.DeletedAt,
+			&product.DelistedAt,
+			&product.PublishedAt,
 			&product.User.ID,
 			&product.User.Fullname,
-		)
+			&product.Categories,
+			&product.Tags,
+			&product.Images,
+			&product.Variants,
+			&product.FinalPrice,
+			&product.Sale,
+		}
+
+		if isSearch {
+			dest = append(dest,
+				&product.Search.Rank,
+				&product.Search.NameHighlight,
+				&product.Search.DescriptionHighlight,
+			)
+		}
 
+		err = rows.Scan(dest...)
 		if err != nil {
 			err = fmt.Errorf("product.repository.GetProducts: failed to scan product: %w", err)
 			return
@@ -176,114 +473,1580 @@ func (r Repository) GetTotalProduct(ctx context.Context, filter entity.ListProdu
 	return
 }
 
-func (r Repository) Update(ctx context.Context, data entity.Product) (err error) {
+// GetCategoryFacets counts the filtered products per category, a product is also counted in every ancestor of its categories.
+func (r Repository) GetCategoryFacets(ctx context.Context, filter entity.ListProductFilter) (result []entity.CategoryFacet, err error) {
+	filter.DisableOffset = true
+	filteredQuery, filterArgs := buildProductFilterQuery(`
+		SELECT p.id
+		FROM
+			products p
+			JOIN users u ON u.id = p.user_id
+	`, filter)
+
 	query := `
-		UPDATE products
-		SET
-			name = $1,
-			description = $2,
-			stok = $3,
-			price = $4
-		WHERE
-			id = $5
+		WITH RECURSIVE filtered AS (` + filteredQuery + `),
+		category_paths AS (
+			SELECT id AS root_id, id FROM categories
+			UNION ALL
+			SELECT cp.root_id, c.id FROM categories c JOIN category_paths cp ON c.parent_id = cp.id
+		)
+		SELECT
+			c.id,
+			c.parent_id,
+			c.name,
+			c.slug,
+			COUNT(DISTINCT pc.product_id)
+		FROM
+			category_paths cp
+			JOIN categories c ON c.id = cp.root_id
+			JOIN product_categories pc ON pc.category_id = cp.id
+			JOIN filtered f ON f.id = pc.product_id
+		GROUP BY c.id
+		ORDER BY c.name
 	`
 
-	_, err = r.db.Exec(ctx, query,
+	rows, err := r.db.Query(ctx, query, filterArgs...)
+	if err != nil {
+		err = fmt.Errorf("product.repository.GetCategoryFacets: failed to get category facets: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var facet entity.CategoryFacet
+
+		err = rows.Scan(
+			&facet.ID,
+			&facet.ParentID,
+			&facet.Name,
+			&facet.Slug,
+			&facet.Count,
+		)
+		if err != nil {
+			err = fmt.Errorf("product.repository.GetCategoryFacets: failed to scan category facet: %w", err)
+			return
+		}
+
+		result = append(result, facet)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.GetCategoryFacets: failed after scan category facets: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+// GetTagFacets counts the filtered products per tag, only the most used tags are returned.
+func (r Repository) GetTagFacets(ctx context.Context, filter entity.ListProductFilter, limit int) (result []entity.TagFacet, err error) {
+	filter.DisableOffset = true
+	filteredQuery, filterArgs := buildProductFilterQuery(`
+		SELECT p.id
+		FROM
+			products p
+			JOIN users u ON u.id = p.user_id
+	`, filter)
+
+	filterArgs = append(filterArgs, limit)
+	query := `
+		WITH filtered AS (` + filteredQuery + `)
+		SELECT
+			pt.tag,
+			COUNT(pt.product_id) AS total
+		FROM
+			product_tags pt
+			JOIN filtered f ON f.id = pt.product_id
+		GROUP BY pt.tag
+		ORDER BY total DESC, pt.tag
+		LIMIT $` + strconv.Itoa(len(filterArgs))
+
+	rows, err := r.db.Query(ctx, query, filterArgs...)
+	if err != nil {
+		err = fmt.Errorf("product.repository.GetTagFacets: failed to get tag facets: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var facet entity.TagFacet
+
+		err = rows.Scan(&facet.Tag, &facet.Count)
+		if err != nil {
+			err = fmt.Errorf("product.repository.GetTagFacets: failed to scan tag facet: %w", err)
+			return
+		}
+
+		result = append(result, facet)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.GetTagFacets: failed after scan tag facets: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+// SetCategories replaces the categories of the product.
+func (r Repository) SetCategories(ctx context.Context, productID uuid.UUID, categoryIDs []uuid.UUID) (err error) {
+	_, err = r.db.Exec(ctx, "DELETE FROM product_categories WHERE product_id = $1", productID)
+	if err != nil {
+		err = fmt.Errorf("product.repository.SetCategories: failed to delete product categories: %w", err)
+		return
+	}
+
+	if len(categoryIDs) == 0 {
+		return
+	}
+
+	query := `
+		INSERT INTO product_categories (product_id, category_id)
+		SELECT $1, category_id FROM unnest($2::uuid[]) AS category_id
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err = r.db.Exec(ctx, query, productID, categoryIDs)
+	if err != nil {
+		var pgxError *pgconn.PgError
+		if errors.As(err, &pgxError) {
+			if pgxError.Code == constant.ErrSQLForeignKeyViolation {
+				err = constant.ErrProductCategoryNotFound
+			}
+		}
+
+		err = fmt.Errorf("product.repository.SetCategories: failed to insert product categories: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// SetTags replaces the tags of the product.
+func (r Repository) SetTags(ctx context.Context, productID uuid.UUID, tags []string) (err error) {
+	_, err = r.db.Exec(ctx, "DELETE FROM product_tags WHERE product_id = $1", productID)
+	if err != nil {
+		err = fmt.Errorf("product.repository.SetTags: failed to delete product tags: %w", err)
+		return
+	}
+
+	if len(tags) == 0 {
+		return
+	}
+
+	query := `
+		INSERT INTO product_tags (product_id, tag)
+		SELECT $1, tag FROM unnest($2::text[]) AS tag
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err = r.db.Exec(ctx, query, productID, tags)
+	if err != nil {
+		err = fmt.Errorf("product.repository.SetTags: failed to insert product tags: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+func (r Repository) CreateVariant(ctx context.Context, data entity.ProductVariant) (id uuid.UUID, err error) {
+	query := `
+		INSERT INTO product_variants (product_id, sku, name, price, stok)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	err = r.db.QueryRow(ctx, query,
+		data.ProductID,
+		data.SKU,
 		data.Name,
-		data.Description,
-		data.Stok,
 		data.Price,
-		data.ID,
-	)
-
+		data.Stok,
+	).Scan(&id)
 	if err != nil {
-		err = fmt.Errorf("product.repository.Update: failed to update product: %w", err)
+		var pgxError *pgconn.PgError
+		if errors.As(err, &pgxError) {
+			if pgxError.Code == constant.ErrSQLUniqueViolation {
+				err = constant.ErrProductVariantSKUAlreadyExists
+			}
+		}
+
+		err = fmt.Errorf("product.repository.CreateVariant: failed to create product variant: %w", err)
 		return
 	}
 
+	r.changed(ctx)
+
 	return
 }
 
-func (r Repository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+func (r Repository) UpdateVariant(ctx context.Context, data entity.ProductVariant) (err error) {
 	query := `
-		DELETE FROM products
-		WHERE id = $1
+		UPDATE product_variants
+		SET
+			sku = $1,
+			name = $2,
+			price = $3,
+			stok = $4
+		WHERE
+			id = $5 AND product_id = $6 AND deleted_at IS NULL
 	`
 
-	_, err = r.db.Exec(ctx, query, id)
+	cmd, err := r.db.Exec(ctx, query,
+		data.SKU,
+		data.Name,
+		data.Price,
+		data.Stok,
+		data.ID,
+		data.ProductID,
+	)
 	if err != nil {
-		err = fmt.Errorf("product.repository.Delete: failed to delete product: %w", err)
+		var pgxError *pgconn.PgError
+		if errors.As(err, &pgxError) {
+			if pgxError.Code == constant.ErrSQLUniqueViolation {
+				err = constant.ErrProductVariantSKUAlreadyExists
+			}
+		}
+
+		err = fmt.Errorf("product.repository.UpdateVariant: failed to update product variant: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("product.repository.UpdateVariant: nothing updated: %w", constant.ErrProductVariantNotFound)
 		return
 	}
 
+	r.changed(ctx)
+
 	return
 }
 
-func (r Repository) ReduceStok(ctx context.Context, id uuid.UUID, reduceBy int) (err error) {
+// DeleteVariant soft deletes the variant, transaction details keep referencing it.
+func (r Repository) DeleteVariant(ctx context.Context, productID uuid.UUID, id uuid.UUID) (err error) {
 	query := `
-		UPDATE products
-		SET stok = stok - $1
-		WHERE id = $2 AND (stok - $1) >= 0
+		UPDATE product_variants
+		SET deleted_at = now()
+		WHERE id = $1 AND product_id = $2 AND deleted_at IS NULL
 	`
 
-	cmd, err := r.db.Exec(ctx, query, reduceBy, id)
+	cmd, err := r.db.Exec(ctx, query, id, productID)
 	if err != nil {
-		err = fmt.Errorf("product.repository.BatchUpdateStok: failed to reduce stok: %w", err)
-		return err
+		err = fmt.Errorf("product.repository.DeleteVariant: failed to delete product variant: %w", err)
+		return
 	}
 
 	if cmd.RowsAffected() == 0 {
-		err = fmt.Errorf("product.repository.BatchUpdateStok: nothing updated: %w", constant.ErrProductNotFoundOrStok)
-		return err
+		err = fmt.Errorf("product.repository.DeleteVariant: nothing deleted: %w", constant.ErrProductVariantNotFound)
+		return
 	}
 
+	r.changed(ctx)
+
 	return
 }
 
-func (r Repository) GetByIDs(ctx context.Context, ids []uuid.UUID) (result map[uuid.UUID]entity.Product, err error) {
+// GetVariantsByIDs returns the stok that is not reserved, because it is what can still be bought.
+func (r Repository) GetVariantsByIDs(ctx context.Context, ids []uuid.UUID) (result map[uuid.UUID]entity.ProductVariant, err error) {
 	query := `
-		SELECT
-			p.id,
-			p.name,
-			p.stok,
-			p.price,
-			p.user_id
-		FROM
-			products p
-		WHERE p.id = ANY($1)
+		SELECT id, product_id, sku, name, price, stok - reserved_stok AS stok, sold_count
+		FROM product_variants
+		WHERE id = ANY($1) AND deleted_at IS NULL
 	`
 
 	rows, err := r.db.Query(ctx, query, ids)
 	if err != nil {
-		err = fmt.Errorf("product.repository.GetByIDs: failed to get products by ids: %w", err)
+		err = fmt.Errorf("product.repository.GetVariantsByIDs: failed to get product variants by ids: %w", err)
 		return
 	}
 
 	defer rows.Close()
 
-	result = make(map[uuid.UUID]entity.Product)
+	result = make(map[uuid.UUID]entity.ProductVariant)
 
 	for rows.Next() {
-		var product entity.Product
+		var variant entity.ProductVariant
 
 		err = rows.Scan(
-			&product.ID,
-			&product.Name,
-			&product.Stok,
-			&product.Price,
-			&product.UserID,
+			&variant.ID,
+			&variant.ProductID,
+			&variant.SKU,
+			&variant.Name,
+			&variant.Price,
+			&variant.Stok,
+			&variant.SoldCount,
 		)
-
 		if err != nil {
-			err = fmt.Errorf("product.repository.GetByIDs: failed to scan product: %w", err)
+			err = fmt.Errorf("product.repository.GetVariantsByIDs: failed to scan product variant: %w", err)
 			return
 		}
 
-		result[product.ID] = product
+		result[variant.ID] = variant
 	}
 
 	if rows.Err() != nil {
-		err = fmt.Errorf("product.repository.GetByIDs: failed after scan products: %w", err)
+		err = fmt.Errorf("product.repository.GetVariantsByIDs: failed after scan product variants: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+func (r Repository) ReduceVariantStok(ctx context.Context, id uuid.UUID, reduceBy int) (err error) {
+	query := `
+		UPDATE product_variants
+		SET stok = stok - $1, sold_count = sold_count + $1
+		WHERE id = $2 AND (stok - reserved_stok - $1) >= 0 AND deleted_at IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, reduceBy, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.ReduceVariantStok: failed to reduce stok: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("product.repository.ReduceVariantStok: nothing updated: %w", constant.ErrProductNotFoundOrStok)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// ReserveVariantStok holds stok of the variant, it fails when the unreserved stok is not enough.
+func (r Repository) ReserveVariantStok(ctx context.Context, id uuid.UUID, qty int) (err error) {
+	query := `
+		UPDATE product_variants
+		SET reserved_stok = reserved_stok + $1
+		WHERE id = $2 AND (stok - reserved_stok - $1) >= 0 AND deleted_at IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, qty, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.ReserveVariantStok: failed to reserve stok: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("product.repository.ReserveVariantStok: nothing updated: %w", constant.ErrProductNotFoundOrStok)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+func (r Repository) ReleaseVariantStok(ctx context.Context, id uuid.UUID, qty int) (err error) {
+	query := `
+		UPDATE product_variants
+		SET reserved_stok = GREATEST(reserved_stok - $1, 0)
+		WHERE id = $2
+	`
+
+	_, err = r.db.Exec(ctx, query, qty, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.ReleaseVariantStok: failed to release stok: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// ConsumeReservedVariantStok reduces the stok that was held by a reservation.
+func (r Repository) ConsumeReservedVariantStok(ctx context.Context, id uuid.UUID, qty int) (err error) {
+	query := `
+		UPDATE product_variants
+		SET stok = stok - $1, reserved_stok = GREATEST(reserved_stok - $1, 0), sold_count = sold_count + $1
+		WHERE id = $2 AND (stok - $1) >= 0 AND deleted_at IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, qty, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.ConsumeReservedVariantStok: failed to reduce stok: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("product.repository.ConsumeReservedVariantStok: nothing updated: %w", constant.ErrProductNotFoundOrStok)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// SyncVariantTotals sets the product stok and reserved stok to the totals and the price to the lowest price of its variants.
+func (r Repository) SyncVariantTotals(ctx context.Context, productID uuid.UUID) (err error) {
+	query := `
+		UPDATE products p
+		SET stok = v.stok, reserved_stok = v.reserved_stok, price = v.price
+		FROM (
+			SELECT
+				COALESCE(SUM(stok), 0) AS stok,
+				COALESCE(SUM(reserved_stok), 0) AS reserved_stok,
+				MIN(price) AS price,
+				COUNT(id) AS total
+			FROM product_variants
+			WHERE product_id = $1 AND deleted_at IS NULL
+		) v
+		WHERE p.id = $1 AND v.total > 0
+	`
+
+	_, err = r.db.Exec(ctx, query, productID)
+	if err != nil {
+		err = fmt.Errorf("product.repository.SyncVariantTotals: failed to sync variant totals: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+func (r Repository) CreateImage(ctx context.Context, data entity.ProductImage) (err error) {
+	query := `
+		INSERT INTO product_images (id, product_id, object_key, thumbnail_key, content_type, size, width, height, position)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8,
+			(SELECT COALESCE(MAX(position), -1) + 1 FROM product_images WHERE product_id = $2)
+		)
+	`
+
+	_, err = r.db.Exec(ctx, query,
+		data.ID,
+		data.ProductID,
+		data.ObjectKey,
+		data.ThumbnailKey,
+		data.ContentType,
+		data.Size,
+		data.Width,
+		data.Height,
+	)
+	if err != nil {
+		err = fmt.Errorf("product.repository.CreateImage: failed to create product image: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+func (r Repository) GetImages(ctx context.Context, productID uuid.UUID) (result []entity.ProductImage, err error) {
+	query := `
+		SELECT id, product_id, object_key, thumbnail_key, content_type, size, width, height, position, created_at
+		FROM product_images
+		WHERE product_id = $1
+		ORDER BY position, created_at
+	`
+
+	rows, err := r.db.Query(ctx, query, productID)
+	if err != nil {
+		err = fmt.Errorf("product.repository.GetImages: failed to get product images: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var image entity.ProductImage
+
+		err = rows.Scan(
+			&image.ID,
+			&image.ProductID,
+			&image.ObjectKey,
+			&image.ThumbnailKey,
+			&image.ContentType,
+			&image.Size,
+			&image.Width,
+			&image.Height,
+			&image.Position,
+			&image.CreatedAt,
+		)
+		if err != nil {
+			err = fmt.Errorf("product.repository.GetImages: failed to scan product image: %w", err)
+			return
+		}
+
+		result = append(result, image)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.GetImages: failed after scan product images: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+func (r Repository) DeleteImage(ctx context.Context, productID uuid.UUID, id uuid.UUID) (data entity.ProductImage, err error) {
+	query := `
+		DELETE FROM product_images
+		WHERE id = $1 AND product_id = $2
+		RETURNING id, product_id, object_key, thumbnail_key
+	`
+
+	err = r.db.QueryRow(ctx, query, id, productID).Scan(
+		&data.ID,
+		&data.ProductID,
+		&data.ObjectKey,
+		&data.ThumbnailKey,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrProductImageNotFound
+		}
+
+		err = fmt.Errorf("product.repository.DeleteImage: failed to delete product image: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// UpdateImagePositions sets the position of every image to its index in ids.
+func (r Repository) UpdateImagePositions(ctx context.Context, productID uuid.UUID, ids []uuid.UUID) (err error) {
+	query := `
+		UPDATE product_images pi
+		SET position = o.position - 1
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, position)
+		WHERE pi.id = o.id AND pi.product_id = $1
+	`
+
+	_, err = r.db.Exec(ctx, query, productID, ids)
+	if err != nil {
+		err = fmt.Errorf("product.repository.UpdateImagePositions: failed to update product image positions: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// Update increments the version of the product, when data.Version is set the product is only updated
+// if it is still at that version.
+func (r Repository) Update(ctx context.Context, data entity.Product) (version int, err error) {
+	query := `
+		UPDATE products
+		SET
+			name = $1,
+			description = $2,
+			stok = $3,
+			price = $4,
+			version = version + 1
+		WHERE
+			id = $5 AND ($6::int = 0 OR version = $6::int)
+		RETURNING version
+	`
+
+	err = r.db.QueryRow(ctx, query,
+		data.Name,
+		data.Description,
+		data.Stok,
+		data.Price,
+		data.ID,
+		data.Version,
+	).Scan(&version)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrProductNotFound
+			if data.Version != 0 {
+				err = constant.ErrProductVersionMismatch
+			}
+		}
+
+		err = fmt.Errorf("product.repository.Update: failed to update product: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+func (r Repository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	query := `
+		UPDATE products
+		SET deleted_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err = r.db.Exec(ctx, query, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.Delete: failed to delete product: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+func (r Repository) Delist(ctx context.Context, id uuid.UUID) (err error) {
+	query := `
+		UPDATE products
+		SET delisted_at = now()
+		WHERE id = $1 AND delisted_at IS NULL
+	`
+
+	_, err = r.db.Exec(ctx, query, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.Delist: failed to delist product: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// Publish lists the product from publishedAt on, an unlisted product is listed again.
+func (r Repository) Publish(ctx context.Context, id uuid.UUID, publishedAt time.Time) (err error) {
+	query := `
+		UPDATE products
+		SET published_at = $1, delisted_at = NULL
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	_, err = r.db.Exec(ctx, query, publishedAt, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.Publish: failed to publish product: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// Unpublish turns the product back into a draft.
+func (r Repository) Unpublish(ctx context.Context, id uuid.UUID) (err error) {
+	query := `
+		UPDATE products
+		SET published_at = NULL, delisted_at = NULL
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	_, err = r.db.Exec(ctx, query, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.Unpublish: failed to unpublish product: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+func (r Repository) Restore(ctx context.Context, id uuid.UUID) (err error) {
+	query := `
+		UPDATE products
+		SET deleted_at = NULL, delisted_at = NULL
+		WHERE id = $1
+	`
+
+	_, err = r.db.Exec(ctx, query, id)
+	if err != nil {
+		err = productSKUPgError(err)
+		err = fmt.Errorf("product.repository.Restore: failed to restore product: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+func (r Repository) ReduceStok(ctx context.Context, id uuid.UUID, reduceBy int) (err error) {
+	query := `
+		UPDATE products
+		SET stok = stok - $1, sold_count = sold_count + $1
+		WHERE id = $2 AND (stok - reserved_stok - $1) >= 0 AND deleted_at IS NULL AND delisted_at IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, reduceBy, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.BatchUpdateStok: failed to reduce stok: %w", err)
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("product.repository.BatchUpdateStok: nothing updated: %w", constant.ErrProductNotFoundOrStok)
+		return err
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// ReserveStok holds stok of the product, it fails when the unreserved stok is not enough.
+func (r Repository) ReserveStok(ctx context.Context, id uuid.UUID, qty int) (err error) {
+	query := `
+		UPDATE products
+		SET reserved_stok = reserved_stok + $1
+		WHERE id = $2 AND (stok - reserved_stok - $1) >= 0 AND deleted_at IS NULL AND delisted_at IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, qty, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.ReserveStok: failed to reserve stok: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("product.repository.ReserveStok: nothing updated: %w", constant.ErrProductNotFoundOrStok)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+func (r Repository) ReleaseStok(ctx context.Context, id uuid.UUID, qty int) (err error) {
+	query := `
+		UPDATE products
+		SET reserved_stok = GREATEST(reserved_stok - $1, 0)
+		WHERE id = $2
+	`
+
+	_, err = r.db.Exec(ctx, query, qty, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.ReleaseStok: failed to release stok: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// ConsumeReservedStok reduces the stok that was held by a reservation.
+func (r Repository) ConsumeReservedStok(ctx context.Context, id uuid.UUID, qty int) (err error) {
+	query := `
+		UPDATE products
+		SET stok = stok - $1, reserved_stok = GREATEST(reserved_stok - $1, 0), sold_count = sold_count + $1
+		WHERE id = $2 AND (stok - $1) >= 0 AND deleted_at IS NULL AND delisted_at IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, qty, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.ConsumeReservedStok: failed to reduce stok: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("product.repository.ConsumeReservedStok: nothing updated: %w", constant.ErrProductNotFoundOrStok)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// AddRating adds a review rating to the rating aggregates of the product.
+func (r Repository) AddRating(ctx context.Context, id uuid.UUID, rating int) (err error) {
+	query := `
+		UPDATE products
+		SET rating_count = rating_count + 1, rating_sum = rating_sum + $1
+		WHERE id = $2
+	`
+
+	_, err = r.db.Exec(ctx, query, rating, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.AddRating: failed to add rating: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// GetByIDs returns the stok that is not reserved, because it is what can still be bought.
+func (r Repository) GetByIDs(ctx context.Context, ids []uuid.UUID) (result map[uuid.UUID]entity.Product, err error) {
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			p.stok - p.reserved_stok AS stok,
+			p.price,
+			p.user_id,
+			EXISTS (
+				SELECT 1 FROM product_variants pv
+				WHERE pv.product_id = p.id AND pv.deleted_at IS NULL
+			) AS has_variants
+		FROM
+			products p
+		WHERE p.id = ANY($1) AND p.deleted_at IS NULL AND p.delisted_at IS NULL AND p.published_at <= now()
+	`
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		err = fmt.Errorf("product.repository.GetByIDs: failed to get products by ids: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	result = make(map[uuid.UUID]entity.Product)
+
+	for rows.Next() {
+		var product entity.Product
+
+		err = rows.Scan(
+			&product.ID,
+			&product.Name,
+			&product.Stok,
+			&product.Price,
+			&product.UserID,
+			&product.HasVariants,
+		)
+
+		if err != nil {
+			err = fmt.Errorf("product.repository.GetByIDs: failed to scan product: %w", err)
+			return
+		}
+
+		result[product.ID] = product
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.GetByIDs: failed after scan products: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetStokForUpdate locks the product until the transaction ends, so a stok change can be recorded with its delta.
+func (r Repository) GetStokForUpdate(ctx context.Context, id uuid.UUID) (stok int, err error) {
+	query := `
+		SELECT stok
+		FROM products
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	err = r.db.QueryRow(ctx, query, id).Scan(&stok)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrProductNotFound
+		}
+
+		err = fmt.Errorf("product.repository.GetStokForUpdate: failed to get product stok: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetVariantStokForUpdate locks the variant like GetStokForUpdate.
+func (r Repository) GetVariantStokForUpdate(ctx context.Context, id uuid.UUID) (stok int, err error) {
+	query := `
+		SELECT stok
+		FROM product_variants
+		WHERE id = $1 AND deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	err = r.db.QueryRow(ctx, query, id).Scan(&stok)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrProductVariantNotFound
+		}
+
+		err = fmt.Errorf("product.repository.GetVariantStokForUpdate: failed to get product variant stok: %w", err)
+		return
+	}
+
+	return
+}
+
+// AdjustStok adds delta to the stok in place, so concurrent adjustments do not overwrite each other.
+func (r Repository) AdjustStok(ctx context.Context, id uuid.UUID, delta int) (stok int, err error) {
+	query := `
+		UPDATE products
+		SET stok = stok + $1
+		WHERE id = $2 AND (stok + $1) >= reserved_stok AND deleted_at IS NULL
+		RETURNING stok
+	`
+
+	err = r.db.QueryRow(ctx, query, delta, id).Scan(&stok)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrStockBelowReserved
+		}
+
+		err = fmt.Errorf("product.repository.AdjustStok: failed to adjust stok: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// AdjustVariantStok adds delta to the stok of the variant like AdjustStok.
+func (r Repository) AdjustVariantStok(ctx context.Context, id uuid.UUID, delta int) (stok int, err error) {
+	query := `
+		UPDATE product_variants
+		SET stok = stok + $1
+		WHERE id = $2 AND (stok + $1) >= reserved_stok AND deleted_at IS NULL
+		RETURNING stok
+	`
+
+	err = r.db.QueryRow(ctx, query, delta, id).Scan(&stok)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrStockBelowReserved
+		}
+
+		err = fmt.Errorf("product.repository.AdjustVariantStok: failed to adjust variant stok: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// CreateStockMovement records a stok change, stok_after is read from the product or the variant after the change.
+func (r Repository) CreateStockMovement(ctx context.Context, data entity.StockMovement) (result entity.StockMovement, err error) {
+	query := `
+		INSERT INTO stock_movements (product_id, variant_id, delta, reason, actor_id, transaction_id, note, stok_after)
+		VALUES (
+			$1, $2, $3, $4, $5, $6, $7,
+			COALESCE(
+				(SELECT stok FROM product_variants WHERE id = $2),
+				(SELECT stok FROM products WHERE id = $1)
+			)
+		)
+		RETURNING id, stok_after, created_at
+	`
+
+	result = data
+	err = r.db.QueryRow(ctx, query,
+		data.ProductID,
+		data.VariantID,
+		data.Delta,
+		data.Reason,
+		data.ActorID,
+		data.TransactionID,
+		data.Note,
+	).Scan(&result.ID, &result.StokAfter, &result.CreatedAt)
+	if err != nil {
+		err = fmt.Errorf("product.repository.CreateStockMovement: failed to create stock movement: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) queryRowsStockMovementWithFilter(ctx context.Context, query string, filter entity.ListStockMovementFilter, disableOffset bool) (rows pgx.Rows, err error) {
+	filterArgs := []any{filter.ProductID}
+	query += "WHERE sm.product_id = $1 "
+
+	if filter.VariantID.Valid {
+		filterArgs = append(filterArgs, filter.VariantID.UUID)
+		query += "AND sm.variant_id = $" + strconv.Itoa(len(filterArgs)) + " "
+	}
+
+	if len(filter.Reason) != 0 {
+		filterArgs = append(filterArgs, filter.Reason)
+		query += "AND sm.reason = $" + strconv.Itoa(len(filterArgs)) + " "
+	}
+
+	if !disableOffset {
+		query += "ORDER BY sm.created_at DESC, sm.id "
+
+		filterArgs = append(filterArgs, filter.Limit)
+		query += "LIMIT $" + strconv.Itoa(len(filterArgs)) + " "
+
+		offset := (filter.Page - 1) * filter.Limit
+		filterArgs = append(filterArgs, offset)
+		query += "OFFSET $" + strconv.Itoa(len(filterArgs)) + " "
+	}
+
+	return r.db.Query(ctx, query, filterArgs...)
+}
+
+func (r Repository) GetStockMovements(ctx context.Context, filter entity.ListStockMovementFilter) (result []entity.StockMovement, err error) {
+	query := `
+		SELECT
+			sm.id,
+			sm.product_id,
+			sm.variant_id,
+			sm.delta,
+			sm.reason,
+			sm.stok_after,
+			sm.actor_id,
+			sm.transaction_id,
+			sm.note,
+			sm.created_at
+		FROM
+			stock_movements sm
+	`
+
+	rows, err := r.queryRowsStockMovementWithFilter(ctx, query, filter, false)
+	if err != nil {
+		err = fmt.Errorf("product.repository.GetStockMovements: failed to get stock movements: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data entity.StockMovement
+
+		err = rows.Scan(
+			&data.ID,
+			&data.ProductID,
+			&data.VariantID,
+			&data.Delta,
+			&data.Reason,
+			&data.StokAfter,
+			&data.ActorID,
+			&data.TransactionID,
+			&data.Note,
+			&data.CreatedAt,
+		)
+		if err != nil {
+			err = fmt.Errorf("product.repository.GetStockMovements: failed to scan stock movement: %w", err)
+			return
+		}
+
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.GetStockMovements: failed after scan stock movements: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+func (r Repository) GetTotalStockMovement(ctx context.Context, filter entity.ListStockMovementFilter) (result int, err error) {
+	query := `
+		SELECT
+			COUNT(sm.id)
+		FROM
+			stock_movements sm
+	`
+
+	rows, err := r.queryRowsStockMovementWithFilter(ctx, query, filter, true)
+	if err != nil {
+		err = fmt.Errorf("product.repository.GetTotalStockMovement: failed to get total stock movement: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&result)
+		if err != nil {
+			err = fmt.Errorf("product.repository.GetTotalStockMovement: failed to scan total stock movement: %w", err)
+			return
+		}
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.GetTotalStockMovement: failed after scan total stock movement: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+// productSKUPgError maps the unique violation of the seller sku index.
+func productSKUPgError(err error) error {
+	var pgxError *pgconn.PgError
+	if errors.As(err, &pgxError) {
+		if pgxError.Code == constant.ErrSQLUniqueViolation {
+			return constant.ErrProductSKUAlreadyExists
+		}
+	}
+
+	return err
+}
+
+func (r Repository) SetSKU(ctx context.Context, id uuid.UUID, sku null.String) (err error) {
+	query := `
+		UPDATE products
+		SET sku = $1
+		WHERE id = $2
+	`
+
+	_, err = r.db.Exec(ctx, query, sku, id)
+	if err != nil {
+		err = productSKUPgError(err)
+		err = fmt.Errorf("product.repository.SetSKU: failed to set product sku: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// GetIDBySKU finds a product of the seller by sku, deleted products are skipped.
+func (r Repository) GetIDBySKU(ctx context.Context, userID uuid.UUID, sku string) (id uuid.UUID, err error) {
+	query := `
+		SELECT id
+		FROM products
+		WHERE user_id = $1 AND sku = $2 AND deleted_at IS NULL
+	`
+
+	err = r.db.QueryRow(ctx, query, userID, sku).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrProductNotFound
+		}
+
+		err = fmt.Errorf("product.repository.GetIDBySKU: failed to get product by sku: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetProductsForExport returns the products of the seller after afterID ordered by id, deleted products are skipped.
+func (r Repository) GetProductsForExport(ctx context.Context, userID uuid.UUID, afterID uuid.NullUUID, limit int) (result []entity.Product, err error) {
+	query := `
+		SELECT
+			p.id,
+			p.sku,
+			p.name,
+			p.description,
+			p.price,
+			p.stok,
+			ARRAY(SELECT pt.tag FROM product_tags pt WHERE pt.product_id = p.id ORDER BY pt.tag) AS tags
+		FROM
+			products p
+		WHERE p.user_id = $1 AND p.deleted_at IS NULL AND ($2::uuid IS NULL OR p.id > $2)
+		ORDER BY p.id
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(ctx, query, userID, afterID, limit)
+	if err != nil {
+		err = fmt.Errorf("product.repository.GetProductsForExport: failed to get products: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var product entity.Product
+
+		err = rows.Scan(
+			&product.ID,
+			&product.SKU,
+			&product.Name,
+			&product.Description,
+			&product.Price,
+			&product.Stok,
+			&product.Tags,
+		)
+		if err != nil {
+			err = fmt.Errorf("product.repository.GetProductsForExport: failed to scan product: %w", err)
+			return
+		}
+
+		result = append(result, product)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.GetProductsForExport: failed after scan products: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+func (r Repository) CreateSale(ctx context.Context, data entity.ProductSale) (result entity.ProductSale, err error) {
+	query := `
+		INSERT INTO product_sales (product_id, variant_id, sale_price, starts_at, ends_at, quota, per_user_limit, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	result = data
+	err = r.db.QueryRow(ctx, query,
+		data.ProductID,
+		data.VariantID,
+		data.SalePrice,
+		data.StartsAt,
+		data.EndsAt,
+		data.Quota,
+		data.PerUserLimit,
+		data.CreatedBy,
+	).Scan(&result.ID, &result.CreatedAt, &result.UpdatedAt)
+	if err != nil {
+		err = fmt.Errorf("product.repository.CreateSale: failed to create product sale: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// HasOverlappingSale reports whether a sale of the product or variant which is not cancelled
+// overlaps the period from startsAt to endsAt.
+func (r Repository) HasOverlappingSale(ctx context.Context, productID uuid.UUID, variantID uuid.NullUUID, startsAt, endsAt time.Time) (exists bool, err error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM product_sales
+			WHERE
+				product_id = $1
+				AND variant_id IS NOT DISTINCT FROM $2
+				AND cancelled_at IS NULL
+				AND starts_at < $4
+				AND ends_at > $3
+		)
+	`
+
+	err = r.db.QueryRow(ctx, query, productID, variantID, startsAt, endsAt).Scan(&exists)
+	if err != nil {
+		err = fmt.Errorf("product.repository.HasOverlappingSale: failed to check overlapping sale: %w", err)
+		return
+	}
+
+	return
+}
+
+func scanProductSale(row pgx.Row) (data entity.ProductSale, err error) {
+	err = row.Scan(
+		&data.ID,
+		&data.ProductID,
+		&data.VariantID,
+		&data.SalePrice,
+		&data.StartsAt,
+		&data.EndsAt,
+		&data.Quota,
+		&data.Sold,
+		&data.PerUserLimit,
+		&data.CreatedBy,
+		&data.CancelledAt,
+		&data.CreatedAt,
+		&data.UpdatedAt,
+	)
+
+	return
+}
+
+const productSaleColumns = `
+	ps.id,
+	ps.product_id,
+	ps.variant_id,
+	ps.sale_price,
+	ps.starts_at,
+	ps.ends_at,
+	ps.quota,
+	ps.sold,
+	ps.per_user_limit,
+	ps.created_by,
+	ps.cancelled_at,
+	ps.created_at,
+	ps.updated_at
+`
+
+func (r Repository) queryProductSales(ctx context.Context, fn string, query string, args ...any) (result []entity.ProductSale, err error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		err = fmt.Errorf("product.repository.%s: failed to get product sales: %w", fn, err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data entity.ProductSale
+
+		data, err = scanProductSale(rows)
+		if err != nil {
+			err = fmt.Errorf("product.repository.%s: failed to scan product sale: %w", fn, err)
+			return
+		}
+
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.%s: failed after scan product sales: %w", fn, rows.Err())
+		return
+	}
+
+	return
+}
+
+// GetSales returns every sale of the product, the latest starting first.
+func (r Repository) GetSales(ctx context.Context, productID uuid.UUID) (result []entity.ProductSale, err error) {
+	query := `
+		SELECT ` + productSaleColumns + `
+		FROM product_sales ps
+		WHERE ps.product_id = $1
+		ORDER BY ps.starts_at DESC, ps.id
+	`
+
+	return r.queryProductSales(ctx, "GetSales", query, productID)
+}
+
+// GetActiveSales returns the sales of the products which can be bought now.
+func (r Repository) GetActiveSales(ctx context.Context, productIDs []uuid.UUID) (result []entity.ProductSale, err error) {
+	query := `
+		SELECT ` + productSaleColumns + `
+		FROM product_sales ps
+		WHERE ps.product_id = ANY($1) AND ` + productActiveSaleCondition + `
+	`
+
+	return r.queryProductSales(ctx, "GetActiveSales", query, productIDs)
+}
+
+func (r Repository) GetSaleByID(ctx context.Context, productID uuid.UUID, id uuid.UUID) (result entity.ProductSale, err error) {
+	query := `
+		SELECT ` + productSaleColumns + `
+		FROM product_sales ps
+		WHERE ps.id = $1 AND ps.product_id = $2
+	`
+
+	result, err = scanProductSale(r.db.QueryRow(ctx, query, id, productID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrSaleNotFound
+		}
+
+		err = fmt.Errorf("product.repository.GetSaleByID: failed to get product sale: %w", err)
+		return
+	}
+
+	return
+}
+
+// CancelSale cancels a sale which has not ended yet.
+func (r Repository) CancelSale(ctx context.Context, productID uuid.UUID, id uuid.UUID) (cancelledAt time.Time, err error) {
+	query := `
+		UPDATE product_sales
+		SET cancelled_at = now()
+		WHERE id = $1 AND product_id = $2 AND cancelled_at IS NULL AND ends_at > now()
+		RETURNING cancelled_at
+	`
+
+	err = r.db.QueryRow(ctx, query, id, productID).Scan(&cancelledAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrSaleNotCancellable
+		}
+
+		err = fmt.Errorf("product.repository.CancelSale: failed to cancel product sale: %w", err)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// UseSaleQuota counts qty as sold by the sale, it fails when the sale is no longer active
+// or the remaining quota is lower than qty.
+func (r Repository) UseSaleQuota(ctx context.Context, id uuid.UUID, qty int) (err error) {
+	query := `
+		UPDATE product_sales ps
+		SET sold = ps.sold + $1
+		WHERE ps.id = $2 AND ` + productActiveSaleCondition + ` AND (ps.quota IS NULL OR ps.sold + $1 <= ps.quota)
+	`
+
+	cmd, err := r.db.Exec(ctx, query, qty, id)
+	if err != nil {
+		err = fmt.Errorf("product.repository.UseSaleQuota: failed to use sale quota: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("product.repository.UseSaleQuota: nothing updated: %w", constant.ErrSaleQuotaExceeded)
+		return
+	}
+
+	r.changed(ctx)
+
+	return
+}
+
+// AddSaleUsage adds qty to what the user bought in the sale, it fails when the total would exceed limit.
+func (r Repository) AddSaleUsage(ctx context.Context, id uuid.UUID, userID uuid.UUID, qty int, limit int) (err error) {
+	query := `
+		INSERT INTO product_sale_usages (sale_id, user_id, qty)
+		SELECT $1::uuid, $2::uuid, $3::int
+		WHERE $3::int <= $4::int
+		ON CONFLICT (sale_id, user_id) DO UPDATE
+		SET qty = product_sale_usages.qty + EXCLUDED.qty
+		WHERE product_sale_usages.qty + EXCLUDED.qty <= $4
+	`
+
+	cmd, err := r.db.Exec(ctx, query, id, userID, qty, limit)
+	if err != nil {
+		err = fmt.Errorf("product.repository.AddSaleUsage: failed to add sale usage: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("product.repository.AddSaleUsage: nothing inserted: %w", constant.ErrSaleUserLimitExceeded)
+		return
+	}
+
+	return
+}
+
+func (r Repository) CreatePriceHistory(ctx context.Context, data entity.ProductPriceHistory) (err error) {
+	query := `
+		INSERT INTO product_price_history (product_id, variant_id, reason, price, sale_id, starts_at, ends_at, actor_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err = r.db.Exec(ctx, query,
+		data.ProductID,
+		data.VariantID,
+		data.Reason,
+		data.Price,
+		data.SaleID,
+		data.StartsAt,
+		data.EndsAt,
+		data.ActorID,
+	)
+	if err != nil {
+		err = fmt.Errorf("product.repository.CreatePriceHistory: failed to create price history: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) queryRowsPriceHistoryWithFilter(ctx context.Context, query string, filter entity.ListProductPriceHistoryFilter, disableOffset bool) (rows pgx.Rows, err error) {
+	filterArgs := []any{filter.ProductID}
+	query += "WHERE ph.product_id = $1 "
+
+	if filter.VariantID.Valid {
+		filterArgs = append(filterArgs, filter.VariantID.UUID)
+		query += "AND ph.variant_id = $" + strconv.Itoa(len(filterArgs)) + " "
+	}
+
+	if !disableOffset {
+		query += "ORDER BY ph.created_at DESC, ph.id "
+
+		filterArgs = append(filterArgs, filter.Limit)
+		query += "LIMIT $" + strconv.Itoa(len(filterArgs)) + " "
+
+		offset := (filter.Page - 1) * filter.Limit
+		filterArgs = append(filterArgs, offset)
+		query += "OFFSET $" + strconv.Itoa(len(filterArgs)) + " "
+	}
+
+	return r.db.Query(ctx, query, filterArgs...)
+}
+
+func (r Repository) GetPriceHistory(ctx context.Context, filter entity.ListProductPriceHistoryFilter) (result []entity.ProductPriceHistory, err error) {
+	query := `
+		SELECT
+			ph.id,
+			ph.product_id,
+			ph.variant_id,
+			ph.reason,
+			ph.price,
+			ph.sale_id,
+			ph.starts_at,
+			ph.ends_at,
+			ph.actor_id,
+			ph.created_at
+		FROM
+			product_price_history ph
+	`
+
+	rows, err := r.queryRowsPriceHistoryWithFilter(ctx, query, filter, false)
+	if err != nil {
+		err = fmt.Errorf("product.repository.GetPriceHistory: failed to get price history: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data entity.ProductPriceHistory
+
+		err = rows.Scan(
+			&data.ID,
+			&data.ProductID,
+			&data.VariantID,
+			&data.Reason,
+			&data.Price,
+			&data.SaleID,
+			&data.StartsAt,
+			&data.EndsAt,
+			&data.ActorID,
+			&data.CreatedAt,
+		)
+		if err != nil {
+			err = fmt.Errorf("product.repository.GetPriceHistory: failed to scan price history: %w", err)
+			return
+		}
+
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.GetPriceHistory: failed after scan price history: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+func (r Repository) GetTotalPriceHistory(ctx context.Context, filter entity.ListProductPriceHistoryFilter) (result int, err error) {
+	query := `
+		SELECT
+			COUNT(ph.id)
+		FROM
+			product_price_history ph
+	`
+
+	rows, err := r.queryRowsPriceHistoryWithFilter(ctx, query, filter, true)
+	if err != nil {
+		err = fmt.Errorf("product.repository.GetTotalPriceHistory: failed to get total price history: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&result)
+		if err != nil {
+			err = fmt.Errorf("product.repository.GetTotalPriceHistory: failed to scan total price history: %w", err)
+			return
+		}
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("product.repository.GetTotalPriceHistory: failed after scan total price history: %w", rows.Err())
 		return
 	}
 
@@ -0,0 +1,155 @@
+package productrepo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToPrefixTsQuerySuccess(t *testing.T) {
+	tests := []struct {
+		name string
+		q    string
+		want string
+	}{
+		{name: "single term", q: "mobile", want: "mobile:*"},
+		{name: "terms", q: "Mobile Legends", want: "mobile:* & legends:*"},
+		{name: "whitespace", q: " \t mobile \n  leg  ", want: "mobile:* & leg:*"},
+		{name: "punctuation", q: "diamond, (86)... ml-starlight!", want: "diamond:* & 86:* & ml:* & starlight:*"},
+		{name: "tsquery operators", q: "a&b | !c <-> d:*B (e)", want: "a:* & b:* & c:* & d:* & b:* & e:*"},
+		{name: "quotes", q: `'mobile' "legends" it''s`, want: "mobile:* & legends:* & it:* & s:*"},
+		{name: "backslash", q: `mobile\:*`, want: "mobile:*"},
+		{name: "unicode letters", q: "Café Überweisung", want: "café:* & überweisung:*"},
+		{name: "whitespace only", q: " \t\n ", want: ""},
+		{name: "operators only", q: "&|!:*()'\"", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, toPrefixTsQuery(tt.q))
+		})
+	}
+}
+
+func TestBuildProductFilterQuerySearchSuccess(t *testing.T) {
+	userID := uuid.New()
+
+	filter := entity.ListProductFilter{
+		Query:  "Mobile Leg",
+		UserID: uuid.NullUUID{UUID: userID, Valid: true},
+		Sort:   entity.ProductSortRelevance,
+		Page:   1,
+		Limit:  10,
+	}
+
+	query, args := buildProductFilterQuery("SELECT p.id FROM products p ", filter)
+	assert.Contains(t, query, "CROSS JOIN (SELECT to_tsquery('simple', $1) AS query, $2::text AS raw) search WHERE ")
+	assert.Contains(t, query, "p.search_vector @@ search.query AND p.user_id = $3")
+	assert.NotContains(t, query, "%")
+	assert.NotContains(t, query, "similarity")
+	assert.Contains(t, query, "ORDER BY ts_rank(p.search_vector, search.query) DESC, p.id DESC LIMIT $4 OFFSET $5")
+	// the text is only sent as arguments, never in the query
+	assert.NotContains(t, query, "mobile")
+	assert.Equal(t, []any{"mobile:* & leg:*", "mobile leg", userID, 10, 0}, args)
+}
+
+func TestBuildProductFilterQueryFuzzySearchSuccess(t *testing.T) {
+	filter := entity.ListProductFilter{
+		Query: "Mobile Legnds",
+		Fuzzy: true,
+		Sort:  entity.ProductSortRelevance,
+		Page:  2,
+		Limit: 10,
+	}
+
+	query, args := buildProductFilterQuery("SELECT p.id FROM products p ", filter)
+	assert.Contains(t, query, "(p.search_vector @@ search.query OR LOWER(p.name) % search.raw)")
+	assert.Contains(t, query, "ORDER BY GREATEST(ts_rank(p.search_vector, search.query), similarity(LOWER(p.name), search.raw)) DESC, p.id DESC")
+	assert.Equal(t, []any{"mobile:* & legnds:*", "mobile legnds", 10, 10}, args)
+
+	// fuzzy has no effect without a search
+	filter.Query = ""
+
+	query, args = buildProductFilterQuery("SELECT p.id FROM products p ", filter)
+	assert.NotContains(t, query, "search")
+	assert.NotContains(t, query, "%")
+	assert.True(t, strings.HasSuffix(query, "ORDER BY p.created_at DESC, p.id DESC LIMIT $1 OFFSET $2 "))
+	assert.Equal(t, []any{10, 10}, args)
+}
+
+func TestBuildProductFilterQueryLifecycleSuccess(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter entity.ListProductFilter
+		where  string
+	}{
+		{
+			name:  "public listing",
+			where: "WHERE p.deleted_at IS NULL AND p.delisted_at IS NULL AND p.published_at <= now() ORDER BY",
+		},
+		{
+			name:   "owner listing with unlisted and unpublished products",
+			filter: entity.ListProductFilter{IncludeDelisted: true},
+			where:  "WHERE p.deleted_at IS NULL ORDER BY",
+		},
+		{
+			name:   "archived listing",
+			filter: entity.ListProductFilter{Archived: true, IncludeDelisted: true},
+			where:  "WHERE (p.deleted_at IS NOT NULL OR p.delisted_at IS NOT NULL) ORDER BY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Page = 1
+			tt.filter.Limit = 10
+
+			query, args := buildProductFilterQuery("SELECT p.id FROM products p ", tt.filter)
+			assert.Contains(t, query, tt.where)
+			assert.Equal(t, []any{10, 0}, args)
+		})
+	}
+}
+
+func TestBuildProductFilterQueryStatusSuccess(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		status string
+		where  string
+	}{
+		{status: "", where: "WHERE p.deleted_at IS NULL AND p.user_id = $1 ORDER BY"},
+		{status: entity.ProductStatusDraft, where: "WHERE p.deleted_at IS NULL AND p.published_at IS NULL AND p.delisted_at IS NULL AND p.user_id = $1 ORDER BY"},
+		{status: entity.ProductStatusScheduled, where: "WHERE p.deleted_at IS NULL AND p.published_at > now() AND p.delisted_at IS NULL AND p.user_id = $1 ORDER BY"},
+		{status: entity.ProductStatusPublished, where: "WHERE p.deleted_at IS NULL AND p.published_at <= now() AND p.delisted_at IS NULL AND p.user_id = $1 ORDER BY"},
+		{status: entity.ProductStatusUnlisted, where: "WHERE p.deleted_at IS NULL AND p.delisted_at IS NOT NULL AND p.user_id = $1 ORDER BY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			// the owner listing
+			query, args := buildProductFilterQuery("SELECT p.id FROM products p ", entity.ListProductFilter{
+				UserID:          uuid.NullUUID{UUID: userID, Valid: true},
+				IncludeDelisted: true,
+				Status:          tt.status,
+				Page:            1,
+				Limit:           10,
+			})
+			assert.Contains(t, query, tt.where)
+			assert.Equal(t, []any{userID, 10, 0}, args)
+		})
+	}
+
+	// drafts and scheduled products are never in the public listing, whatever else is filtered
+	query, _ := buildProductFilterQuery("SELECT p.id FROM products p ", entity.ListProductFilter{
+		UserID:     uuid.NullUUID{UUID: userID, Valid: true},
+		Query:      "diamond",
+		CategoryID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		Page:       1,
+		Limit:      10,
+	})
+	assert.Contains(t, query, "WHERE p.deleted_at IS NULL AND p.delisted_at IS NULL AND p.published_at <= now() AND ")
+}
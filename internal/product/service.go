@@ -2,6 +2,7 @@ package product
 
 import (
 	"context"
+	"io"
 
 	"github.com/arfan21/vocagame/internal/model"
 	"github.com/arfan21/vocagame/pkg/pkgutil"
@@ -13,9 +14,37 @@ type Service interface {
 	WithTx(tx pgx.Tx) Service
 
 	Create(ctx context.Context, req model.ProductCreateRequest) (err error)
-	GetProducts(ctx context.Context, req model.GetListProductRequest) (res pkgutil.PaginationResponse[[]model.GetProductResponse], err error)
-	Update(ctx context.Context, req model.ProductUpdateRequest) (err error)
+	GetProducts(ctx context.Context, req model.GetListProductRequest) (res model.GetListProductResponse, err error)
+	GetProduct(ctx context.Context, id uuid.UUID) (res model.GetProductResponse, err error)
+	GetArchivedProducts(ctx context.Context, userID uuid.UUID, req model.GetListProductRequest) (res pkgutil.PaginationResponse[[]model.GetProductResponse], err error)
+	Update(ctx context.Context, req model.ProductUpdateRequest) (version int, err error)
+	Patch(ctx context.Context, req model.ProductPatchRequest) (version int, err error)
 	Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (err error)
+	Delist(ctx context.Context, id uuid.UUID, userID uuid.UUID) (err error)
+	Restore(ctx context.Context, id uuid.UUID, userID uuid.UUID) (err error)
+	GetOwnProducts(ctx context.Context, userID uuid.UUID, req model.GetListOwnProductRequest) (res pkgutil.PaginationResponse[[]model.GetProductResponse], err error)
+	Publish(ctx context.Context, req model.ProductPublishRequest) (err error)
+	Unpublish(ctx context.Context, id uuid.UUID, userID uuid.UUID) (err error)
+	CreateVariant(ctx context.Context, req model.ProductVariantCreateRequest) (res model.ProductVariantResponse, err error)
+	UpdateVariant(ctx context.Context, req model.ProductVariantUpdateRequest) (err error)
+	DeleteVariant(ctx context.Context, req model.ProductVariantDeleteRequest) (err error)
+	GetVariantsByIDs(ctx context.Context, ids []uuid.UUID) (res map[uuid.UUID]model.ProductVariantResponse, err error)
+	UploadImages(ctx context.Context, req model.ProductImageUploadRequest) (res []model.ProductImageResponse, err error)
+	DeleteImage(ctx context.Context, req model.ProductImageDeleteRequest) (err error)
+	ReorderImages(ctx context.Context, req model.ProductImageReorderRequest) (err error)
 	BatchReduceStok(ctx context.Context, req []model.ReduceStokRequest) (err error)
+	BatchReserveStok(ctx context.Context, req []model.ReserveStokRequest) (err error)
+	BatchReleaseStok(ctx context.Context, req []model.ReserveStokRequest) (err error)
+	AddRating(ctx context.Context, id uuid.UUID, rating int) (err error)
 	GetByIDs(ctx context.Context, ids []uuid.UUID) (res map[uuid.UUID]model.GetProductResponse, err error)
+	AdjustStok(ctx context.Context, req model.StockAdjustmentRequest) (res model.StockMovementResponse, err error)
+	Import(ctx context.Context, req model.ProductImportRequest) (res model.ProductImportResponse, err error)
+	Export(ctx context.Context, req model.ProductExportRequest) (stream func(w io.Writer) error, err error)
+	GetStockMovements(ctx context.Context, req model.GetListStockMovementRequest) (res pkgutil.PaginationResponse[[]model.StockMovementResponse], err error)
+	GetCacheStats(ctx context.Context) (res model.ProductCacheStatsResponse)
+	CreateSale(ctx context.Context, req model.ProductSaleCreateRequest) (res model.ProductSaleResponse, err error)
+	GetSales(ctx context.Context, productID uuid.UUID, userID uuid.UUID) (res []model.ProductSaleResponse, err error)
+	CancelSale(ctx context.Context, req model.ProductSaleCancelRequest) (err error)
+	ApplySales(ctx context.Context, userID uuid.UUID, items []model.CheckoutProductRequest) (res []model.AppliedSaleResponse, err error)
+	GetPriceHistory(ctx context.Context, req model.GetListPriceHistoryRequest) (res pkgutil.PaginationResponse[[]model.PriceHistoryResponse], err error)
 }
@@ -0,0 +1,393 @@
+package productsvc
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/arfan21/vocagame/config"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/logger"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/arfan21/vocagame/pkg/validation"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// productExportBatchSize is how many products are read from the db per query while exporting
+	productExportBatchSize = 500
+	// productImportMaxLineSize limits a single ndjson line
+	productImportMaxLineSize = 1 << 20
+	productTagSeparator      = "|"
+)
+
+var productCSVColumns = []string{"id", "sku", "name", "description", "price", "stok", "tags"}
+
+// productImportLine is a parsed line of the import file, err is set when the line could not be parsed.
+type productImportLine struct {
+	line int
+	row  model.ProductImportRow
+	err  error
+}
+
+// Import upserts the products of the seller by sku, every row is imported in its own transaction
+// so a failed row does not stop the others.
+func (s Service) Import(ctx context.Context, req model.ProductImportRequest) (res model.ProductImportResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.Import: failed to validate request : %w", err)
+		return
+	}
+
+	lines, err := readProductImportLines(req.Format, req.File)
+	if err != nil {
+		err = fmt.Errorf("product.service.Import: failed to read import file : %w", err)
+		return
+	}
+
+	if len(lines) > config.GetConfig().ProductImport.MaxRows {
+		err = constant.ErrProductImportTooManyRows
+		return
+	}
+
+	res.Total = len(lines)
+	res.Rows = make([]model.ProductImportRowResponse, len(lines))
+
+	for i, line := range lines {
+		rowRes := model.ProductImportRowResponse{
+			Row: line.line,
+			SKU: line.row.SKU,
+		}
+
+		errRow := line.err
+		if errRow == nil {
+			errRow = validation.Validate(line.row)
+		}
+
+		if errRow == nil {
+			var id uuid.UUID
+			var created bool
+			id, created, errRow = s.importRow(ctx, req.UserID, line.row)
+			if errRow == nil {
+				rowRes.ProductID = uuid.NullUUID{UUID: id, Valid: true}
+				rowRes.Status = model.ProductImportStatusUpdated
+				if created {
+					rowRes.Status = model.ProductImportStatusCreated
+				}
+			}
+		}
+
+		switch {
+		case errRow != nil:
+			rowRes.Status = model.ProductImportStatusFailed
+			rowRes.Message, rowRes.Errors = productImportRowError(ctx, errRow)
+			res.Failed++
+		case rowRes.Status == model.ProductImportStatusCreated:
+			res.Created++
+		default:
+			res.Updated++
+		}
+
+		res.Rows[i] = rowRes
+	}
+
+	return
+}
+
+func (s Service) importRow(ctx context.Context, userID uuid.UUID, row model.ProductImportRow) (id uuid.UUID, created bool, err error) {
+	id, err = s.repo.GetIDBySKU(ctx, userID, row.SKU)
+	if errors.Is(err, constant.ErrProductNotFound) {
+		id, err = s.create(ctx, model.ProductCreateRequest{
+			SKU:         row.SKU,
+			Name:        row.Name,
+			Stok:        row.Stok,
+			Description: row.Description,
+			Price:       row.Price,
+			Tags:        row.Tags,
+			UserID:      userID,
+		})
+		return id, true, err
+	}
+
+	if err != nil {
+		return
+	}
+
+	before, err := s.getOwnedProduct(ctx, id, userID)
+	if err != nil {
+		return
+	}
+
+	_, err = s.update(ctx, before, false, model.ProductUpdateRequest{
+		ID:          id,
+		UserID:      userID,
+		Name:        row.Name,
+		Stok:        row.Stok,
+		Description: row.Description,
+		Price:       row.Price,
+		Tags:        row.Tags,
+	})
+
+	return
+}
+
+// productImportRowError turns err into the message and field errors of a failed row,
+// unexpected errors are logged and not exposed.
+func productImportRowError(ctx context.Context, err error) (message string, fields []pkgutil.ErrValidationResponse) {
+	var errValidation *constant.ErrValidation
+	if errors.As(err, &errValidation) {
+		errJson := json.Unmarshal([]byte(errValidation.Message), &fields)
+		if errJson == nil {
+			return "invalid row", fields
+		}
+	}
+
+	var badRequestError *constant.ErrBadRequest
+	if errors.As(err, &badRequestError) {
+		return badRequestError.Message, nil
+	}
+
+	var notFoundError *constant.ErrNotFound
+	if errors.As(err, &notFoundError) {
+		return notFoundError.Message, nil
+	}
+
+	var forbiddenError *constant.ErrForbidden
+	if errors.As(err, &forbiddenError) {
+		return forbiddenError.Message, nil
+	}
+
+	var conflictError *constant.ErrConflict
+	if errors.As(err, &conflictError) {
+		return conflictError.Message, nil
+	}
+
+	logger.Log(ctx).Error().Err(err).Msg("product.service: failed to import row")
+
+	return "failed to import row", nil
+}
+
+func readProductImportLines(format string, r io.Reader) (lines []productImportLine, err error) {
+	if format == model.ProductFileFormatCSV {
+		return readProductImportCSV(r)
+	}
+
+	return readProductImportNDJSON(r)
+}
+
+// readProductImportCSV reads rows by the column names of the header, unknown columns are ignored.
+func readProductImportCSV(r io.Reader) (lines []productImportLine, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+
+		return
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for {
+		var record []string
+		record, err = reader.Read()
+		if errors.Is(err, io.EOF) {
+			return lines, nil
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return
+			}
+
+			lines = append(lines, productImportLine{line: parseErr.Line, err: &constant.ErrBadRequest{Message: parseErr.Err.Error()}})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		lines = append(lines, parseProductImportRecord(line, columns, record))
+	}
+}
+
+func parseProductImportRecord(line int, columns map[string]int, record []string) (res productImportLine) {
+	res.line = line
+
+	value := func(name string) (string, bool) {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return "", false
+		}
+
+		return strings.TrimSpace(record[i]), true
+	}
+
+	res.row.SKU, _ = value("sku")
+	res.row.Name, _ = value("name")
+	res.row.Description, _ = value("description")
+
+	var fields []pkgutil.ErrValidationResponse
+
+	if price, ok := value("price"); ok && price != "" {
+		var errParse error
+		res.row.Price, errParse = decimal.NewFromString(price)
+		if errParse != nil {
+			fields = append(fields, pkgutil.ErrValidationResponse{Field: "price", Message: "price must be a decimal"})
+		}
+	}
+
+	if stok, ok := value("stok"); ok && stok != "" {
+		var errParse error
+		res.row.Stok, errParse = strconv.Atoi(stok)
+		if errParse != nil {
+			fields = append(fields, pkgutil.ErrValidationResponse{Field: "stok", Message: "stok must be a number"})
+		}
+	}
+
+	if tags, ok := value("tags"); ok {
+		res.row.Tags = []string{}
+		if tags != "" {
+			res.row.Tags = strings.Split(tags, productTagSeparator)
+		}
+	}
+
+	if len(fields) != 0 {
+		jsonMessage, _ := json.Marshal(fields)
+		res.err = &constant.ErrValidation{Message: string(jsonMessage)}
+	}
+
+	return
+}
+
+// readProductImportNDJSON reads a json object per line, blank lines are skipped.
+func readProductImportNDJSON(r io.Reader) (lines []productImportLine, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), productImportMaxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		res := productImportLine{line: line}
+
+		errJson := json.Unmarshal([]byte(text), &res.row)
+		if errJson != nil {
+			res.err = &constant.ErrBadRequest{Message: "invalid json: " + errJson.Error()}
+		}
+
+		lines = append(lines, res)
+	}
+
+	return lines, scanner.Err()
+}
+
+// Export returns a function writing the catalog of the seller to w, products are read in batches
+// so the catalog is never fully loaded in memory. The request is validated before anything is written.
+func (s Service) Export(ctx context.Context, req model.ProductExportRequest) (stream func(w io.Writer) error, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.Export: failed to validate request : %w", err)
+		return
+	}
+
+	stream = func(w io.Writer) (err error) {
+		var csvWriter *csv.Writer
+		encoder := json.NewEncoder(w)
+
+		if req.Format == model.ProductFileFormatCSV {
+			csvWriter = csv.NewWriter(w)
+
+			err = csvWriter.Write(productCSVColumns)
+			if err != nil {
+				err = fmt.Errorf("product.service.Export: failed to write csv header : %w", err)
+				return
+			}
+		}
+
+		afterID := uuid.NullUUID{}
+		for {
+			results, errGet := s.repo.GetProductsForExport(ctx, req.UserID, afterID, productExportBatchSize)
+			if errGet != nil {
+				err = fmt.Errorf("product.service.Export: failed to get products : %w", errGet)
+				return
+			}
+
+			for _, result := range results {
+				row := model.ProductExportRow{
+					ID:          result.ID,
+					SKU:         result.SKU.String,
+					Name:        result.Name,
+					Description: result.Description,
+					Price:       result.Price,
+					Stok:        result.Stok,
+					Tags:        result.Tags,
+				}
+
+				if csvWriter != nil {
+					err = csvWriter.Write([]string{
+						row.ID.String(),
+						row.SKU,
+						row.Name,
+						row.Description,
+						row.Price.String(),
+						strconv.Itoa(row.Stok),
+						strings.Join(row.Tags, productTagSeparator),
+					})
+				} else {
+					err = encoder.Encode(row)
+				}
+
+				if err != nil {
+					err = fmt.Errorf("product.service.Export: failed to write product : %w", err)
+					return
+				}
+			}
+
+			if csvWriter != nil {
+				csvWriter.Flush()
+				err = csvWriter.Error()
+				if err != nil {
+					err = fmt.Errorf("product.service.Export: failed to flush csv : %w", err)
+					return
+				}
+			}
+
+			// send the batch to the client before reading the next one
+			if flusher, ok := w.(interface{ Flush() error }); ok {
+				err = flusher.Flush()
+				if err != nil {
+					err = fmt.Errorf("product.service.Export: failed to flush : %w", err)
+					return
+				}
+			}
+
+			if len(results) < productExportBatchSize {
+				return
+			}
+
+			afterID = uuid.NullUUID{UUID: results[len(results)-1].ID, Valid: true}
+		}
+	}
+
+	return
+}
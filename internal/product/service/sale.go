@@ -0,0 +1,416 @@
+package productsvc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/arfan21/vocagame/pkg/validation"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gopkg.in/guregu/null.v4"
+)
+
+func toProductSaleResponse(data entity.ProductSale, now time.Time) model.ProductSaleResponse {
+	res := model.ProductSaleResponse{
+		ID:           data.ID,
+		ProductID:    data.ProductID,
+		VariantID:    data.VariantID,
+		SalePrice:    data.SalePrice,
+		StartsAt:     data.StartsAt,
+		EndsAt:       data.EndsAt,
+		Quota:        data.Quota,
+		Sold:         data.Sold,
+		PerUserLimit: data.PerUserLimit,
+		CancelledAt:  data.CancelledAt,
+		CreatedAt:    data.CreatedAt,
+	}
+
+	if data.Quota.Valid {
+		res.Remaining = null.IntFrom(max(data.Quota.Int64-int64(data.Sold), 0))
+	}
+
+	switch {
+	case data.CancelledAt.Valid:
+		res.Status = model.ProductSaleStatusCancelled
+	case !data.EndsAt.After(now):
+		res.Status = model.ProductSaleStatusEnded
+	case data.Quota.Valid && int64(data.Sold) >= data.Quota.Int64:
+		res.Status = model.ProductSaleStatusSoldOut
+	case data.StartsAt.After(now):
+		res.Status = model.ProductSaleStatusScheduled
+	default:
+		res.Status = model.ProductSaleStatusActive
+	}
+
+	return res
+}
+
+func newPriceHistory(productID uuid.UUID, variantID uuid.NullUUID, price decimal.Decimal, actorID uuid.UUID) entity.ProductPriceHistory {
+	return entity.ProductPriceHistory{
+		ProductID: productID,
+		VariantID: variantID,
+		Reason:    entity.PriceHistoryReasonPriceChange,
+		Price:     price,
+		ActorID:   uuid.NullUUID{UUID: actorID, Valid: true},
+	}
+}
+
+func newSalePriceHistory(sale entity.ProductSale, reason entity.PriceHistoryReason, actorID uuid.UUID) entity.ProductPriceHistory {
+	return entity.ProductPriceHistory{
+		ProductID: sale.ProductID,
+		VariantID: sale.VariantID,
+		Reason:    reason,
+		Price:     sale.SalePrice,
+		SaleID:    uuid.NullUUID{UUID: sale.ID, Valid: true},
+		StartsAt:  null.TimeFrom(sale.StartsAt),
+		EndsAt:    null.TimeFrom(sale.EndsAt),
+		ActorID:   uuid.NullUUID{UUID: actorID, Valid: true},
+	}
+}
+
+// CreateSale schedules a sale price of a product or one of its variants, sales of the same product
+// or variant can not overlap.
+func (s Service) CreateSale(ctx context.Context, req model.ProductSaleCreateRequest) (res model.ProductSaleResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateSale: failed to validate request : %w", err)
+		return
+	}
+
+	if !req.EndsAt.After(time.Now()) {
+		err = constant.ErrSaleEndsInPast
+		return
+	}
+
+	resultProduct, err := s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateSale: failed to get product : %w", err)
+		return
+	}
+
+	price := resultProduct.Price
+	if req.VariantID.Valid {
+		found := false
+		for _, v := range resultProduct.Variants {
+			if v.ID == req.VariantID.UUID {
+				price = v.Price
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			err = constant.ErrProductVariantNotFound
+			return
+		}
+	} else if resultProduct.HasVariants {
+		err = constant.ErrProductVariantRequired
+		return
+	}
+
+	if !req.SalePrice.LessThan(price) {
+		err = constant.ErrSalePriceNotLower
+		return
+	}
+
+	data := entity.ProductSale{
+		ProductID: req.ProductID,
+		VariantID: req.VariantID,
+		SalePrice: req.SalePrice,
+		StartsAt:  req.StartsAt.UTC(),
+		EndsAt:    req.EndsAt.UTC(),
+		CreatedBy: req.UserID,
+	}
+
+	if req.Quota != nil {
+		data.Quota = null.IntFrom(int64(*req.Quota))
+	}
+
+	if req.PerUserLimit != nil {
+		data.PerUserLimit = null.IntFrom(int64(*req.PerUserLimit))
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateSale: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.CreateSale: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	// the product is locked, so concurrent requests can not schedule overlapping sales
+	_, err = s.repo.WithTx(tx).GetStokForUpdate(ctx, req.ProductID)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateSale: failed to lock product : %w", err)
+		return
+	}
+
+	overlap, err := s.repo.WithTx(tx).HasOverlappingSale(ctx, req.ProductID, req.VariantID, data.StartsAt, data.EndsAt)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateSale: failed to check overlapping sale : %w", err)
+		return
+	}
+
+	if overlap {
+		err = constant.ErrSaleOverlap
+		return
+	}
+
+	data, err = s.repo.WithTx(tx).CreateSale(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateSale: failed to create sale : %w", err)
+		return
+	}
+
+	err = s.repo.WithTx(tx).CreatePriceHistory(ctx, newSalePriceHistory(data, entity.PriceHistoryReasonSaleScheduled, req.UserID))
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateSale: failed to record price history : %w", err)
+		return
+	}
+
+	res = toProductSaleResponse(data, time.Now())
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntitySale,
+		EntityID:   data.ID,
+		After:      res,
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateSale: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// GetSales returns every sale of a product to its owner, including ended and cancelled sales.
+func (s Service) GetSales(ctx context.Context, productID uuid.UUID, userID uuid.UUID) (res []model.ProductSaleResponse, err error) {
+	_, err = s.getOwnedProduct(ctx, productID, userID)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetSales: failed to get product : %w", err)
+		return
+	}
+
+	results, err := s.repo.GetSales(ctx, productID)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetSales: failed to get sales from db : %w", err)
+		return
+	}
+
+	now := time.Now()
+	res = make([]model.ProductSaleResponse, len(results))
+	for i, result := range results {
+		res[i] = toProductSaleResponse(result, now)
+	}
+
+	return
+}
+
+// CancelSale cancels a scheduled or active sale, what was sold at the sale price is kept.
+func (s Service) CancelSale(ctx context.Context, req model.ProductSaleCancelRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.CancelSale: failed to validate request : %w", err)
+		return
+	}
+
+	_, err = s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.CancelSale: failed to get product : %w", err)
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.CancelSale: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.CancelSale: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	sale, err := s.repo.WithTx(tx).GetSaleByID(ctx, req.ProductID, req.ID)
+	if err != nil {
+		err = fmt.Errorf("product.service.CancelSale: failed to get sale : %w", err)
+		return
+	}
+
+	now := time.Now()
+	before := toProductSaleResponse(sale, now)
+
+	cancelledAt, err := s.repo.WithTx(tx).CancelSale(ctx, req.ProductID, req.ID)
+	if err != nil {
+		err = fmt.Errorf("product.service.CancelSale: failed to cancel sale : %w", err)
+		return
+	}
+
+	err = s.repo.WithTx(tx).CreatePriceHistory(ctx, newSalePriceHistory(sale, entity.PriceHistoryReasonSaleCancelled, req.UserID))
+	if err != nil {
+		err = fmt.Errorf("product.service.CancelSale: failed to record price history : %w", err)
+		return
+	}
+
+	sale.CancelledAt = null.TimeFrom(cancelledAt)
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntitySale,
+		EntityID:   sale.ID,
+		Before:     before,
+		After:      toProductSaleResponse(sale, now),
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.CancelSale: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+type productSaleKey struct {
+	productID uuid.UUID
+	variantID uuid.NullUUID
+}
+
+// ApplySales uses the quota of the active sales of the checkout items, it must run in the checkout transaction.
+// The result has the sale applied to every item, SaleID is not set for an item without an active sale.
+func (s Service) ApplySales(ctx context.Context, userID uuid.UUID, items []model.CheckoutProductRequest) (res []model.AppliedSaleResponse, err error) {
+	productIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+
+	sales, err := s.repo.GetActiveSales(ctx, productIDs)
+	if err != nil {
+		err = fmt.Errorf("product.service.ApplySales: failed to get active sales : %w", err)
+		return
+	}
+
+	activeSales := make(map[productSaleKey]entity.ProductSale, len(sales))
+	for _, sale := range sales {
+		activeSales[productSaleKey{productID: sale.ProductID, variantID: sale.VariantID}] = sale
+	}
+
+	res = make([]model.AppliedSaleResponse, len(items))
+	for i, item := range items {
+		sale, ok := activeSales[productSaleKey{productID: item.ProductID, variantID: item.VariantID}]
+		if !ok {
+			continue
+		}
+
+		err = s.repo.UseSaleQuota(ctx, sale.ID, item.Qty)
+		if err != nil {
+			err = fmt.Errorf("product.service.ApplySales: failed to use sale quota : %w", err)
+			return
+		}
+
+		if sale.PerUserLimit.Valid {
+			err = s.repo.AddSaleUsage(ctx, sale.ID, userID, item.Qty, int(sale.PerUserLimit.Int64))
+			if err != nil {
+				err = fmt.Errorf("product.service.ApplySales: failed to add sale usage : %w", err)
+				return
+			}
+		}
+
+		res[i] = model.AppliedSaleResponse{
+			SaleID: uuid.NullUUID{UUID: sale.ID, Valid: true},
+			Price:  sale.SalePrice,
+		}
+	}
+
+	return
+}
+
+// GetPriceHistory returns the price changes and sales of a product to its owner, newest first.
+func (s Service) GetPriceHistory(ctx context.Context, req model.GetListPriceHistoryRequest) (res pkgutil.PaginationResponse[[]model.PriceHistoryResponse], err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetPriceHistory: failed to validate request : %w", err)
+		return
+	}
+
+	_, err = s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetPriceHistory: failed to get product : %w", err)
+		return
+	}
+
+	filter := entity.ListProductPriceHistoryFilter{
+		ProductID: req.ProductID,
+		VariantID: req.VariantID,
+		Page:      req.Page,
+		Limit:     req.Limit,
+	}
+
+	results, err := s.repo.GetPriceHistory(ctx, filter)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetPriceHistory: failed to get price history from db : %w", err)
+		return
+	}
+
+	resData := make([]model.PriceHistoryResponse, len(results))
+	for i, result := range results {
+		resData[i] = model.PriceHistoryResponse{
+			ID:        result.ID,
+			ProductID: result.ProductID,
+			VariantID: result.VariantID,
+			Reason:    string(result.Reason),
+			Price:     result.Price,
+			SaleID:    result.SaleID,
+			StartsAt:  result.StartsAt,
+			EndsAt:    result.EndsAt,
+			ActorID:   result.ActorID,
+			CreatedAt: result.CreatedAt,
+		}
+	}
+
+	total, err := s.repo.GetTotalPriceHistory(ctx, filter)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetPriceHistory: failed to get total price history from db : %w", err)
+		return
+	}
+
+	totalPage := 0
+	if total%filter.Limit != 0 {
+		totalPage = total/filter.Limit + 1
+	} else {
+		totalPage = total / filter.Limit
+	}
+
+	res = pkgutil.PaginationResponse[[]model.PriceHistoryResponse]{
+		TotalData: total,
+		TotalPage: totalPage,
+		Page:      filter.Page,
+		Limit:     filter.Limit,
+		Data:      resData,
+	}
+
+	return
+}
@@ -1,29 +1,52 @@
 package productsvc
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/arfan21/vocagame/config"
+	"github.com/arfan21/vocagame/internal/audit"
 	"github.com/arfan21/vocagame/internal/entity"
 	"github.com/arfan21/vocagame/internal/model"
 	"github.com/arfan21/vocagame/internal/product"
+	"github.com/arfan21/vocagame/pkg/blobstore"
 	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/imageutil"
+	"github.com/arfan21/vocagame/pkg/logger"
 	"github.com/arfan21/vocagame/pkg/pkgutil"
 	"github.com/arfan21/vocagame/pkg/validation"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+	"gopkg.in/guregu/null.v4"
 )
 
 type Service struct {
-	repo product.Repository
+	repo      product.Repository
+	auditSvc  audit.Service
+	blobStore blobstore.BlobStore
 }
 
-func New(repo product.Repository) *Service {
-	return &Service{repo: repo}
+func New(repo product.Repository, auditSvc audit.Service, blobStore blobstore.BlobStore) *Service {
+	return &Service{repo: repo, auditSvc: auditSvc, blobStore: blobStore}
 }
 
 func (s Service) WithTx(tx pgx.Tx) product.Service {
 	s.repo = s.repo.WithTx(tx)
+	s.auditSvc = s.auditSvc.WithTx(tx)
 	return &s
 }
 
@@ -34,24 +57,159 @@ func (s Service) Create(ctx context.Context, req model.ProductCreateRequest) (er
 		return
 	}
 
+	_, err = s.create(ctx, req)
+	return
+}
+
+// create stores a validated product with its categories, tags and variants.
+func (s Service) create(ctx context.Context, req model.ProductCreateRequest) (id uuid.UUID, err error) {
 	data := entity.Product{
 		UserID:      req.UserID,
+		SKU:         null.NewString(req.SKU, req.SKU != ""),
 		Name:        req.Name,
 		Description: req.Description,
 		Stok:        req.Stok,
 		Price:       req.Price,
+		Tags:        normalizeTags(req.Tags),
+	}
+
+	if req.Status != entity.ProductStatusDraft {
+		data.PublishedAt = null.TimeFrom(publishTime(req.PublishAt, time.Now()))
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.Create: failed to begin transaction : %w", err)
+		return
 	}
 
-	err = s.repo.Create(ctx, data)
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.Create: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	id, err = s.repo.WithTx(tx).Create(ctx, data)
 	if err != nil {
 		err = fmt.Errorf("product.service.Create: failed to create new product : %w", err)
 		return
 	}
 
-	return nil
+	if len(req.Variants) == 0 && data.Stok != 0 {
+		_, err = s.repo.WithTx(tx).CreateStockMovement(ctx, newStockMovement(id, uuid.NullUUID{}, data.Stok, entity.StockMovementReasonRestock, req.UserID))
+		if err != nil {
+			err = fmt.Errorf("product.service.Create: failed to record stock movement : %w", err)
+			return
+		}
+	}
+
+	if len(req.Variants) == 0 {
+		err = s.repo.WithTx(tx).CreatePriceHistory(ctx, newPriceHistory(id, uuid.NullUUID{}, data.Price, req.UserID))
+		if err != nil {
+			err = fmt.Errorf("product.service.Create: failed to record price history : %w", err)
+			return
+		}
+	}
+
+	if len(req.CategoryIDs) != 0 {
+		err = s.repo.WithTx(tx).SetCategories(ctx, id, req.CategoryIDs)
+		if err != nil {
+			err = fmt.Errorf("product.service.Create: failed to set product categories : %w", err)
+			return
+		}
+	}
+
+	if len(data.Tags) != 0 {
+		err = s.repo.WithTx(tx).SetTags(ctx, id, data.Tags)
+		if err != nil {
+			err = fmt.Errorf("product.service.Create: failed to set product tags : %w", err)
+			return
+		}
+	}
+
+	variants := make([]model.ProductVariantResponse, len(req.Variants))
+	for i, v := range req.Variants {
+		variantID, errCreate := s.repo.WithTx(tx).CreateVariant(ctx, entity.ProductVariant{
+			ProductID: id,
+			SKU:       v.SKU,
+			Name:      v.Name,
+			Price:     v.Price,
+			Stok:      v.Stok,
+		})
+		if errCreate != nil {
+			err = fmt.Errorf("product.service.Create: failed to create product variant : %w", errCreate)
+			return
+		}
+
+		if v.Stok != 0 {
+			movement := newStockMovement(id, uuid.NullUUID{UUID: variantID, Valid: true}, v.Stok, entity.StockMovementReasonRestock, req.UserID)
+			_, err = s.repo.WithTx(tx).CreateStockMovement(ctx, movement)
+			if err != nil {
+				err = fmt.Errorf("product.service.Create: failed to record stock movement : %w", err)
+				return
+			}
+		}
+
+		err = s.repo.WithTx(tx).CreatePriceHistory(ctx, newPriceHistory(id, uuid.NullUUID{UUID: variantID, Valid: true}, v.Price, req.UserID))
+		if err != nil {
+			err = fmt.Errorf("product.service.Create: failed to record price history : %w", err)
+			return
+		}
+
+		variants[i] = model.ProductVariantResponse{
+			ID:        variantID,
+			ProductID: id,
+			SKU:       v.SKU,
+			Name:      v.Name,
+			Price:     v.Price,
+			Stok:      v.Stok,
+		}
+	}
+
+	if len(variants) != 0 {
+		err = s.repo.WithTx(tx).SyncVariantTotals(ctx, id)
+		if err != nil {
+			err = fmt.Errorf("product.service.Create: failed to sync variant totals : %w", err)
+			return
+		}
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   id,
+		After: model.GetProductResponse{
+			ID:          id,
+			SKU:         data.SKU,
+			Name:        data.Name,
+			Description: data.Description,
+			Stok:        data.Stok,
+			Price:       data.Price,
+			OwnerID:     data.UserID,
+			Categories:  productCategoryIDs(req.CategoryIDs),
+			Tags:        data.Tags,
+			HasVariants: len(variants) != 0,
+			Variants:    variants,
+			Status:      productStatus(data.PublishedAt, null.Time{}, time.Now()),
+			PublishedAt: data.PublishedAt,
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.Create: failed to record audit log : %w", err)
+		return
+	}
+
+	return
 }
 
-func (s Service) getProducts(ctx context.Context, filter entity.ListProductFilter) (res pkgutil.PaginationResponse[[]model.GetProductResponse], err error) {
+func (s Service) getProducts(ctx context.Context, filter entity.ListProductFilter, withTotal bool) (res pkgutil.PaginationResponse[[]model.GetProductResponse], err error) {
 	results, err := s.repo.GetProducts(ctx, filter)
 	if err != nil {
 		err = fmt.Errorf("product.service.GetProducts: failed to get products from db : %w", err)
@@ -61,15 +219,74 @@ func (s Service) getProducts(ctx context.Context, filter entity.ListProductFilte
 	var resData []model.GetProductResponse
 
 	resData = make([]model.GetProductResponse, len(results))
+	now := time.Now()
 
 	for i, result := range results {
 		resData[i].ID = result.ID
+		resData[i].SKU = result.SKU
 		resData[i].Name = result.Name
 		resData[i].Description = result.Description
 		resData[i].Stok = result.Stok
+		resData[i].AvailableStok = availableStok(result.Stok, result.ReservedStok)
 		resData[i].Price = result.Price
+		resData[i].FinalPrice = result.FinalPrice
 		resData[i].OwnerID = result.User.ID
 		resData[i].OwnerName = result.User.Fullname
+		resData[i].SoldCount = result.SoldCount
+		resData[i].RatingAverage = ratingAverage(result.RatingSum, result.RatingCount)
+		resData[i].RatingCount = result.RatingCount
+		resData[i].Version = result.Version
+		resData[i].CreatedAt = result.CreatedAt
+		resData[i].DeletedAt = result.DeletedAt
+		resData[i].DelistedAt = result.DelistedAt
+		resData[i].PublishedAt = result.PublishedAt
+		resData[i].Status = productStatus(result.PublishedAt, result.DelistedAt, now)
+		resData[i].Tags = result.Tags
+
+		resData[i].Images = s.toProductImageResponses(result.Images)
+		resData[i].Variants = toProductVariantResponses(result.Variants)
+		resData[i].HasVariants = len(result.Variants) != 0
+
+		if result.Sale != nil {
+			sale := toProductSaleResponse(*result.Sale, now)
+			resData[i].Sale = &sale
+		}
+
+		resData[i].Categories = make([]model.ProductCategory, len(result.Categories))
+		for j, category := range result.Categories {
+			resData[i].Categories[j] = model.ProductCategory{
+				ID:   category.ID,
+				Name: category.Name,
+				Slug: category.Slug,
+			}
+		}
+
+		if len(filter.Query) != 0 {
+			resData[i].Search = &model.ProductSearchResponse{
+				Rank:                 result.Search.Rank,
+				NameHighlight:        result.Search.NameHighlight,
+				DescriptionHighlight: result.Search.DescriptionHighlight,
+			}
+		}
+	}
+
+	res = pkgutil.PaginationResponse[[]model.GetProductResponse]{
+		Page:  filter.Page,
+		Limit: filter.Limit,
+		Data:  resData,
+	}
+
+	// a full page means there might be more products after the last one
+	if len(results) != 0 && len(results) == filter.Limit {
+		res.NextCursor, err = encodeProductCursor(results[len(results)-1], filter.Sort)
+		if err != nil {
+			err = fmt.Errorf("product.service.GetProducts: failed to encode cursor : %w", err)
+			return
+		}
+	}
+
+	if !withTotal {
+		return
 	}
 
 	total, err := s.repo.GetTotalProduct(ctx, filter)
@@ -85,90 +302,85 @@ func (s Service) getProducts(ctx context.Context, filter entity.ListProductFilte
 		totalPage = total / filter.Limit
 	}
 
-	res = pkgutil.PaginationResponse[[]model.GetProductResponse]{
-		TotalData: total,
-		TotalPage: totalPage,
-		Page:      filter.Page,
-		Limit:     filter.Limit,
-		Data:      resData,
-	}
+	res.TotalData = total
+	res.TotalPage = totalPage
 
 	return
 }
 
-func (s Service) GetProducts(ctx context.Context, req model.GetListProductRequest) (res pkgutil.PaginationResponse[[]model.GetProductResponse], err error) {
-	err = validation.Validate(req)
+// productTagFacetLimit is the number of most used tags returned as facets.
+const productTagFacetLimit = 20
+
+func (s Service) GetProducts(ctx context.Context, req model.GetListProductRequest) (res model.GetListProductResponse, err error) {
+	filter, err := listProductFilterFromRequest(req)
 	if err != nil {
-		err = fmt.Errorf("product.service.GetProducts: failed to validate request : %w", err)
+		err = fmt.Errorf("product.service.GetProducts: %w", err)
 		return
 	}
 
-	filter := entity.ListProductFilter{
-		Name:   req.Name,
-		Page:   req.Page,
-		Limit:  req.Limit,
-		UserID: req.OwnerID,
-		ID:     req.ProductID,
+	res.PaginationResponse, err = s.getProducts(ctx, filter, !req.SkipTotal)
+	if err != nil {
+		return
 	}
 
-	return s.getProducts(ctx, filter)
-}
+	if req.SkipFacets {
+		return
+	}
 
-func (s Service) Update(ctx context.Context, req model.ProductUpdateRequest) (err error) {
-	err = validation.Validate(req)
+	res.Facets, err = s.getFacets(ctx, filter)
 	if err != nil {
-		err = fmt.Errorf("product.service.Update: failed to validate request : %w", err)
+		err = fmt.Errorf("product.service.GetProducts: failed to get facets : %w", err)
 		return
 	}
 
-	// check if product exist
-	resultProduct, err := s.getProducts(ctx, entity.ListProductFilter{
-		ID:    uuid.NullUUID{UUID: req.ID, Valid: true},
-		Limit: 1,
-		Page:  1,
-	})
+	return
+}
+
+func (s Service) getFacets(ctx context.Context, filter entity.ListProductFilter) (res *model.ProductFacetsResponse, err error) {
+	categoryFacets, err := s.repo.GetCategoryFacets(ctx, filter)
 	if err != nil {
-		err = fmt.Errorf("product.service.Update: failed to get product : %w", err)
 		return
 	}
 
-	if len(resultProduct.Data) == 0 {
-		err = constant.ErrProductNotFound
+	tagFacets, err := s.repo.GetTagFacets(ctx, filter, productTagFacetLimit)
+	if err != nil {
 		return
 	}
 
-	// check if user is owner of product
-	if resultProduct.Data[0].OwnerID != req.UserID {
-		err = constant.ErrCannotUpdateNotOwner
-		return
+	res = &model.ProductFacetsResponse{
+		Categories: make([]model.CategoryFacetResponse, len(categoryFacets)),
+		Tags:       make([]model.TagFacetResponse, len(tagFacets)),
 	}
 
-	data := entity.Product{
-		ID:          req.ID,
-		Name:        req.Name,
-		Description: req.Description,
-		Stok:        req.Stok,
-		Price:       req.Price,
+	for i, facet := range categoryFacets {
+		res.Categories[i] = model.CategoryFacetResponse{
+			ID:       facet.ID,
+			ParentID: facet.ParentID,
+			Name:     facet.Name,
+			Slug:     facet.Slug,
+			Count:    facet.Count,
+		}
 	}
 
-	err = s.repo.Update(ctx, data)
-	if err != nil {
-		err = fmt.Errorf("product.service.Update: failed to update product : %w", err)
-		return
+	for i, facet := range tagFacets {
+		res.Tags[i] = model.TagFacetResponse{
+			Tag:   facet.Tag,
+			Count: facet.Count,
+		}
 	}
 
 	return
 }
 
-func (s Service) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (err error) {
-	// check if product exist
+// GetProduct returns a listed product.
+func (s Service) GetProduct(ctx context.Context, id uuid.UUID) (res model.GetProductResponse, err error) {
 	resultProduct, err := s.getProducts(ctx, entity.ListProductFilter{
 		ID:    uuid.NullUUID{UUID: id, Valid: true},
 		Limit: 1,
 		Page:  1,
-	})
+	}, false)
 	if err != nil {
-		err = fmt.Errorf("product.service.Delete: failed to get product : %w", err)
+		err = fmt.Errorf("product.service.GetProduct: failed to get product : %w", err)
 		return
 	}
 
@@ -177,70 +389,1853 @@ func (s Service) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (er
 		return
 	}
 
-	// check if user is owner of product
-	if resultProduct.Data[0].OwnerID != userID {
-		err = constant.ErrCannotDeleteNotOwner
+	return resultProduct.Data[0], nil
+}
+
+// GetArchivedProducts lists deleted or delisted products of the owner.
+func (s Service) GetArchivedProducts(ctx context.Context, userID uuid.UUID, req model.GetListProductRequest) (res pkgutil.PaginationResponse[[]model.GetProductResponse], err error) {
+	req.OwnerID = uuid.NullUUID{UUID: userID, Valid: true}
+
+	filter, err := listProductFilterFromRequest(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetArchivedProducts: %w", err)
 		return
 	}
 
-	err = s.repo.Delete(ctx, id)
+	filter.Archived = true
+
+	return s.getProducts(ctx, filter, !req.SkipTotal)
+}
+
+// GetOwnProducts lists the products of userID in every lifecycle status, or only in req.Status when it is set.
+func (s Service) GetOwnProducts(ctx context.Context, userID uuid.UUID, req model.GetListOwnProductRequest) (res pkgutil.PaginationResponse[[]model.GetProductResponse], err error) {
+	err = validation.Validate(req)
 	if err != nil {
-		err = fmt.Errorf("product.service.Delete: failed to delete product : %w", err)
+		err = fmt.Errorf("product.service.GetOwnProducts: failed to validate request : %w", err)
 		return
 	}
 
-	return
+	return s.getProducts(ctx, entity.ListProductFilter{
+		UserID:          uuid.NullUUID{UUID: userID, Valid: true},
+		IncludeDelisted: true,
+		Status:          req.Status,
+		Page:            req.Page,
+		Limit:           req.Limit,
+	}, !req.SkipTotal)
 }
 
-func (s Service) BatchReduceStok(ctx context.Context, req []model.ReduceStokRequest) (err error) {
-	tx, err := s.repo.Begin(ctx)
+func listProductFilterFromRequest(req model.GetListProductRequest) (filter entity.ListProductFilter, err error) {
+	// page is meaningless when paginating with cursor
+	if req.Cursor != "" && req.Page == 0 {
+		req.Page = 1
+	}
+
+	err = validation.Validate(req)
 	if err != nil {
-		err = fmt.Errorf("product.service.BatchUpdateStok: failed to begin transaction : %w", err)
+		err = fmt.Errorf("failed to validate request : %w", err)
 		return
 	}
 
-	defer func() {
+	if req.MinPrice.Valid && req.MaxPrice.Valid && req.MinPrice.Decimal.GreaterThan(req.MaxPrice.Decimal) {
+		err = constant.ErrProductInvalidPriceRange
+		return
+	}
+
+	filter = entity.ListProductFilter{
+		Name:     req.Name,
+		Query:    strings.TrimSpace(req.Q),
+		Fuzzy:    req.Fuzzy,
+		Page:     req.Page,
+		Limit:    req.Limit,
+		UserID:   req.OwnerID,
+		ID:       req.ProductID,
+		IDs:      req.ProductIDs,
+		MinPrice: req.MinPrice,
+		MaxPrice: req.MaxPrice,
+		InStock:  req.InStock,
+		Sort:     req.Sort,
+
+		CategoryID: req.CategoryID,
+		Tag:        strings.ToLower(strings.TrimSpace(req.Tag)),
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = entity.ProductSortNewest
+		if len(filter.Query) != 0 {
+			filter.Sort = entity.ProductSortRelevance
+		}
+	}
+
+	// only search results have a rank, without a search relevance is sorted by newest like the repository does,
+	// so the cursor is encoded with the sort key the products were ordered by
+	if filter.Sort == entity.ProductSortRelevance && len(filter.Query) == 0 {
+		filter.Sort = entity.ProductSortNewest
+	}
+
+	if req.CreatedFrom != "" {
+		filter.CreatedFrom, err = time.Parse(time.RFC3339, req.CreatedFrom)
 		if err != nil {
-			tx.Rollback(ctx)
+			err = fmt.Errorf("failed to parse created_from : %w", err)
 			return
 		}
+		filter.CreatedFrom = filter.CreatedFrom.UTC()
+	}
 
-		err = tx.Commit(ctx)
+	if req.CreatedTo != "" {
+		filter.CreatedTo, err = time.Parse(time.RFC3339, req.CreatedTo)
 		if err != nil {
-			err = fmt.Errorf("product.service.BatchUpdateStok: failed to commit transaction : %w", err)
+			err = fmt.Errorf("failed to parse created_to : %w", err)
 			return
 		}
-	}()
+		filter.CreatedTo = filter.CreatedTo.UTC()
+	}
 
-	for _, v := range req {
-		err = s.repo.ReduceStok(ctx, v.ID, v.ReduceBy)
+	if req.Cursor != "" {
+		filter.Cursor, err = decodeProductCursor(req.Cursor)
 		if err != nil {
-			err = fmt.Errorf("product.service.BatchUpdateStok: failed to update batch stok : %w", err)
-			return err
+			err = fmt.Errorf("failed to decode cursor : %w", err)
+			return
+		}
+
+		// cursor value is only comparable with the sort it was created for
+		if filter.Cursor.Sort != filter.Sort {
+			err = constant.ErrInvalidCursor
+			return
 		}
 	}
 
 	return
 }
 
-func (s Service) GetByIDs(ctx context.Context, ids []uuid.UUID) (res map[uuid.UUID]model.GetProductResponse, err error) {
-	results, err := s.repo.GetByIDs(ctx, ids)
+// productCursorTimestampLayout matches the TIMESTAMP columns, which have no time zone.
+const productCursorTimestampLayout = "2006-01-02 15:04:05.999999"
+
+func encodeProductCursor(product entity.Product, sort string) (cursor string, err error) {
+	data := entity.ProductCursor{Sort: sort, ID: product.ID}
+
+	switch sort {
+	case entity.ProductSortPriceAsc, entity.ProductSortPriceDesc:
+		data.Value = product.FinalPrice.String()
+	case entity.ProductSortBestSelling:
+		data.Value = strconv.Itoa(product.SoldCount)
+	case entity.ProductSortRelevance:
+		data.Value = strconv.FormatFloat(product.Search.Rank, 'g', -1, 32)
+	default:
+		data.Value = product.CreatedAt.Format(productCursorTimestampLayout)
+	}
+
+	dataJson, err := json.Marshal(data)
 	if err != nil {
-		err = fmt.Errorf("product.service.GetByIDs: failed to get products by ids : %w", err)
 		return
 	}
 
-	res = make(map[uuid.UUID]model.GetProductResponse)
+	return base64.RawURLEncoding.EncodeToString(dataJson), nil
+}
 
-	for k, v := range results {
-		res[k] = model.GetProductResponse{
-			ID:          v.ID,
-			Name:        v.Name,
-			Description: v.Description,
-			Stok:        v.Stok,
-			Price:       v.Price,
-			OwnerID:     v.UserID,
+func decodeProductCursor(cursor string) (data *entity.ProductCursor, err error) {
+	dataJson, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		err = constant.ErrInvalidCursor
+		return
+	}
+
+	data = &entity.ProductCursor{}
+	err = json.Unmarshal(dataJson, data)
+	if err != nil || data.ID == uuid.Nil {
+		err = constant.ErrInvalidCursor
+		return nil, err
+	}
+
+	// the value is cast to the sql type of the sort key, it is parsed as that type and written back
+	// in the form encodeProductCursor writes so the database never gets a value it rejects
+	switch data.Sort {
+	case entity.ProductSortNewest:
+		var createdAt time.Time
+		createdAt, err = time.Parse(productCursorTimestampLayout, data.Value)
+		// postgres has no year 0
+		if createdAt.Year() < 1 {
+			err = constant.ErrInvalidCursor
+		}
+		data.Value = createdAt.Format(productCursorTimestampLayout)
+	case entity.ProductSortPriceAsc, entity.ProductSortPriceDesc:
+		// an exponent could make the written back value arbitrarily long
+		if strings.ContainsAny(data.Value, "eE") {
+			err = constant.ErrInvalidCursor
+			break
 		}
+
+		var price decimal.Decimal
+		price, err = decimal.NewFromString(data.Value)
+		data.Value = price.String()
+	case entity.ProductSortBestSelling:
+		var soldCount int64
+		soldCount, err = strconv.ParseInt(data.Value, 10, 32)
+		data.Value = strconv.FormatInt(soldCount, 10)
+	case entity.ProductSortRelevance:
+		var rank float64
+		rank, err = strconv.ParseFloat(data.Value, 32)
+		if math.IsNaN(rank) || math.IsInf(rank, 0) {
+			err = constant.ErrInvalidCursor
+		}
+		data.Value = strconv.FormatFloat(rank, 'g', -1, 32)
+	default:
+		err = constant.ErrInvalidCursor
+	}
+	if err != nil {
+		err = constant.ErrInvalidCursor
+		return nil, err
+	}
+
+	return
+}
+
+func (s Service) Update(ctx context.Context, req model.ProductUpdateRequest) (version int, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.Update: failed to validate request : %w", err)
+		return
+	}
+
+	// check if product exist
+	resultProduct, err := s.getProducts(ctx, entity.ListProductFilter{
+		ID:              uuid.NullUUID{UUID: req.ID, Valid: true},
+		IncludeDelisted: true,
+		Limit:           1,
+		Page:            1,
+	}, false)
+	if err != nil {
+		err = fmt.Errorf("product.service.Update: failed to get product : %w", err)
+		return
+	}
+
+	if len(resultProduct.Data) == 0 {
+		err = constant.ErrProductNotFound
+		return
+	}
+
+	// check if user is owner of product
+	if resultProduct.Data[0].OwnerID != req.UserID {
+		err = constant.ErrCannotUpdateNotOwner
+		return
+	}
+
+	if req.Version != 0 && req.Version != resultProduct.Data[0].Version {
+		err = constant.ErrProductVersionMismatch
+		return
+	}
+
+	return s.update(ctx, resultProduct.Data[0], false, req)
+}
+
+// Patch updates only the fields sent in req. The unsent fields are taken from the product as it was read,
+// so the patch is only applied when the product is still at that version.
+func (s Service) Patch(ctx context.Context, req model.ProductPatchRequest) (version int, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.Patch: failed to validate request : %w", err)
+		return
+	}
+
+	before, err := s.getOwnedProduct(ctx, req.ID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.Patch: failed to get product : %w", err)
+		return
+	}
+
+	if req.Version != 0 && req.Version != before.Version {
+		err = constant.ErrProductVersionMismatch
+		return
+	}
+
+	update := model.ProductUpdateRequest{
+		ID:          req.ID,
+		UserID:      req.UserID,
+		Name:        before.Name,
+		Stok:        before.Stok,
+		Description: before.Description,
+		Price:       before.Price,
+		SKU:         req.SKU,
+		CategoryIDs: req.CategoryIDs,
+		Tags:        req.Tags,
+		Version:     before.Version,
+	}
+
+	if req.Name != nil {
+		update.Name = *req.Name
+	}
+
+	if req.Stok != nil {
+		update.Stok = *req.Stok
+	}
+
+	if req.Description != nil {
+		update.Description = *req.Description
+	}
+
+	if req.Price != nil {
+		update.Price = *req.Price
+	}
+
+	return s.update(ctx, before, req.Stok == nil, update)
+}
+
+// update applies a validated update to before, the product as it is stored now.
+// keepStok leaves the stok as it is when the update is applied, since purchases change it without a new version.
+// It returns the new version of the product.
+func (s Service) update(ctx context.Context, before model.GetProductResponse, keepStok bool, req model.ProductUpdateRequest) (version int, err error) {
+	data := entity.Product{
+		ID:          req.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Stok:        req.Stok,
+		Price:       req.Price,
+		Version:     req.Version,
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.Update: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.Update: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	// the stok before the update is locked, so the recorded delta is exact
+	var previousStok int
+	if !before.HasVariants {
+		previousStok, err = s.repo.WithTx(tx).GetStokForUpdate(ctx, data.ID)
+		if err != nil {
+			err = fmt.Errorf("product.service.Update: failed to get product stok : %w", err)
+			return
+		}
+
+		if keepStok {
+			data.Stok = previousStok
+		}
+	}
+
+	version, err = s.repo.WithTx(tx).Update(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("product.service.Update: failed to update product : %w", err)
+		return
+	}
+
+	if !before.HasVariants && data.Stok != previousStok {
+		movement := newStockMovement(data.ID, uuid.NullUUID{}, data.Stok-previousStok, entity.StockMovementReasonAdjustment, req.UserID)
+		_, err = s.repo.WithTx(tx).CreateStockMovement(ctx, movement)
+		if err != nil {
+			err = fmt.Errorf("product.service.Update: failed to record stock movement : %w", err)
+			return
+		}
+	}
+
+	if !before.HasVariants && !data.Price.Equal(before.Price) {
+		err = s.repo.WithTx(tx).CreatePriceHistory(ctx, newPriceHistory(data.ID, uuid.NullUUID{}, data.Price, req.UserID))
+		if err != nil {
+			err = fmt.Errorf("product.service.Update: failed to record price history : %w", err)
+			return
+		}
+	}
+
+	after := before
+	after.Name = data.Name
+	after.Description = data.Description
+	after.Stok = data.Stok
+	after.Price = data.Price
+	after.Version = version
+
+	// stok and price of a product with variants stay derived from the variants
+	if before.HasVariants {
+		err = s.repo.WithTx(tx).SyncVariantTotals(ctx, data.ID)
+		if err != nil {
+			err = fmt.Errorf("product.service.Update: failed to sync variant totals : %w", err)
+			return
+		}
+
+		after.Stok = before.Stok
+		after.Price = before.Price
+	}
+
+	if req.CategoryIDs != nil {
+		err = s.repo.WithTx(tx).SetCategories(ctx, data.ID, req.CategoryIDs)
+		if err != nil {
+			err = fmt.Errorf("product.service.Update: failed to set product categories : %w", err)
+			return
+		}
+
+		after.Categories = productCategoryIDs(req.CategoryIDs)
+	}
+
+	if req.SKU != nil {
+		after.SKU = null.NewString(*req.SKU, *req.SKU != "")
+
+		err = s.repo.WithTx(tx).SetSKU(ctx, data.ID, after.SKU)
+		if err != nil {
+			err = fmt.Errorf("product.service.Update: failed to set product sku : %w", err)
+			return
+		}
+	}
+
+	if req.Tags != nil {
+		after.Tags = normalizeTags(req.Tags)
+
+		err = s.repo.WithTx(tx).SetTags(ctx, data.ID, after.Tags)
+		if err != nil {
+			err = fmt.Errorf("product.service.Update: failed to set product tags : %w", err)
+			return
+		}
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   data.ID,
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.Update: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// normalizeTags lowercases and trims the tags and removes duplicates.
+func normalizeTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		result = append(result, tag)
+	}
+
+	return result
+}
+
+func productCategoryIDs(ids []uuid.UUID) []model.ProductCategory {
+	result := make([]model.ProductCategory, len(ids))
+	for i, id := range ids {
+		result[i] = model.ProductCategory{ID: id}
+	}
+
+	return result
+}
+
+func (s Service) Delete(ctx context.Context, id uuid.UUID, userID uuid.UUID) (err error) {
+	// check if product exist
+	resultProduct, err := s.getProducts(ctx, entity.ListProductFilter{
+		ID:              uuid.NullUUID{UUID: id, Valid: true},
+		IncludeDelisted: true,
+		Limit:           1,
+		Page:            1,
+	}, false)
+	if err != nil {
+		err = fmt.Errorf("product.service.Delete: failed to get product : %w", err)
+		return
+	}
+
+	if len(resultProduct.Data) == 0 {
+		err = constant.ErrProductNotFound
+		return
+	}
+
+	// check if user is owner of product
+	if resultProduct.Data[0].OwnerID != userID {
+		err = constant.ErrCannotDeleteNotOwner
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.Delete: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.Delete: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).Delete(ctx, id)
+	if err != nil {
+		err = fmt.Errorf("product.service.Delete: failed to delete product : %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionDelete,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   id,
+		Before:     resultProduct.Data[0],
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.Delete: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) Delist(ctx context.Context, id uuid.UUID, userID uuid.UUID) (err error) {
+	// check if product exist and still listed
+	resultProduct, err := s.getProducts(ctx, entity.ListProductFilter{
+		ID:    uuid.NullUUID{UUID: id, Valid: true},
+		Limit: 1,
+		Page:  1,
+	}, false)
+	if err != nil {
+		err = fmt.Errorf("product.service.Delist: failed to get product : %w", err)
+		return
+	}
+
+	if len(resultProduct.Data) == 0 {
+		err = constant.ErrProductNotFound
+		return
+	}
+
+	// check if user is owner of product
+	if resultProduct.Data[0].OwnerID != userID {
+		err = constant.ErrCannotDelistNotOwner
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.Delist: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.Delist: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).Delist(ctx, id)
+	if err != nil {
+		err = fmt.Errorf("product.service.Delist: failed to delist product : %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionDelist,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   id,
+		Before:     resultProduct.Data[0],
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.Delist: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) Restore(ctx context.Context, id uuid.UUID, userID uuid.UUID) (err error) {
+	// check if product exist and archived
+	resultProduct, err := s.getProducts(ctx, entity.ListProductFilter{
+		ID:       uuid.NullUUID{UUID: id, Valid: true},
+		Archived: true,
+		Limit:    1,
+		Page:     1,
+	}, false)
+	if err != nil {
+		err = fmt.Errorf("product.service.Restore: failed to get product : %w", err)
+		return
+	}
+
+	if len(resultProduct.Data) == 0 {
+		err = constant.ErrProductNotFound
+		return
+	}
+
+	// check if user is owner of product
+	if resultProduct.Data[0].OwnerID != userID {
+		err = constant.ErrCannotRestoreNotOwner
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.Restore: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.Restore: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).Restore(ctx, id)
+	if err != nil {
+		err = fmt.Errorf("product.service.Restore: failed to restore product : %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionRestore,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   id,
+		Before:     resultProduct.Data[0],
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.Restore: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// Publish makes a draft or unlisted product visible to buyers, now or at req.PublishAt.
+// Publishing a scheduled product again moves its publish time.
+func (s Service) Publish(ctx context.Context, req model.ProductPublishRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.Publish: failed to validate request : %w", err)
+		return
+	}
+
+	before, err := s.getOwnedProduct(ctx, req.ID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.Publish: failed to get product : %w", err)
+		return
+	}
+
+	publishedAt := publishTime(req.PublishAt, time.Now())
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.Publish: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.Publish: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).Publish(ctx, req.ID, publishedAt)
+	if err != nil {
+		err = fmt.Errorf("product.service.Publish: failed to publish product : %w", err)
+		return
+	}
+
+	after := before
+	after.DelistedAt = null.Time{}
+	after.PublishedAt = null.TimeFrom(publishedAt)
+	after.Status = productStatus(after.PublishedAt, after.DelistedAt, time.Now())
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionPublish,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   req.ID,
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.Publish: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// Unpublish turns the product back into a draft, it is hidden from buyers until it is published again.
+func (s Service) Unpublish(ctx context.Context, id uuid.UUID, userID uuid.UUID) (err error) {
+	before, err := s.getOwnedProduct(ctx, id, userID)
+	if err != nil {
+		err = fmt.Errorf("product.service.Unpublish: failed to get product : %w", err)
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.Unpublish: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.Unpublish: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).Unpublish(ctx, id)
+	if err != nil {
+		err = fmt.Errorf("product.service.Unpublish: failed to unpublish product : %w", err)
+		return
+	}
+
+	after := before
+	after.DelistedAt = null.Time{}
+	after.PublishedAt = null.Time{}
+	after.Status = entity.ProductStatusDraft
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUnpublish,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   id,
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.Unpublish: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// publishTime is publishAt when it is in the future, or else now. Publish times are stored in UTC.
+func publishTime(publishAt *time.Time, now time.Time) time.Time {
+	if publishAt != nil && publishAt.After(now) {
+		return publishAt.UTC()
+	}
+
+	return now.UTC()
+}
+
+// productStatus derives the lifecycle status of a product from its publish time and whether it is unlisted.
+func productStatus(publishedAt null.Time, delistedAt null.Time, now time.Time) string {
+	switch {
+	case delistedAt.Valid:
+		return entity.ProductStatusUnlisted
+	case !publishedAt.Valid:
+		return entity.ProductStatusDraft
+	case publishedAt.Time.After(now):
+		return entity.ProductStatusScheduled
+	default:
+		return entity.ProductStatusPublished
+	}
+}
+
+func toProductVariantResponses(variants []entity.ProductVariant) []model.ProductVariantResponse {
+	res := make([]model.ProductVariantResponse, len(variants))
+	for i, v := range variants {
+		res[i] = model.ProductVariantResponse{
+			ID:            v.ID,
+			ProductID:     v.ProductID,
+			SKU:           v.SKU,
+			Name:          v.Name,
+			Price:         v.Price,
+			Stok:          v.Stok,
+			AvailableStok: availableStok(v.Stok, v.ReservedStok),
+			SoldCount:     v.SoldCount,
+			FinalPrice:    v.Price,
+		}
+
+		if v.Sale != nil {
+			sale := toProductSaleResponse(*v.Sale, time.Now())
+			res[i].Sale = &sale
+			res[i].FinalPrice = decimal.Min(v.Price, v.Sale.SalePrice)
+		}
+	}
+
+	return res
+}
+
+// availableStok never goes below zero, the seller can lower the stok under the reserved stok.
+func availableStok(stok int, reserved int) int {
+	return max(stok-reserved, 0)
+}
+
+func ratingAverage(sum int, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+
+	return math.Round(float64(sum)/float64(count)*100) / 100
+}
+
+func (s Service) CreateVariant(ctx context.Context, req model.ProductVariantCreateRequest) (res model.ProductVariantResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateVariant: failed to validate request : %w", err)
+		return
+	}
+
+	_, err = s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateVariant: failed to get product : %w", err)
+		return
+	}
+
+	data := entity.ProductVariant{
+		ProductID: req.ProductID,
+		SKU:       req.SKU,
+		Name:      req.Name,
+		Price:     req.Price,
+		Stok:      req.Stok,
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateVariant: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.CreateVariant: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	data.ID, err = s.repo.WithTx(tx).CreateVariant(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateVariant: failed to create product variant : %w", err)
+		return
+	}
+
+	if data.Stok != 0 {
+		movement := newStockMovement(req.ProductID, uuid.NullUUID{UUID: data.ID, Valid: true}, data.Stok, entity.StockMovementReasonRestock, req.UserID)
+		_, err = s.repo.WithTx(tx).CreateStockMovement(ctx, movement)
+		if err != nil {
+			err = fmt.Errorf("product.service.CreateVariant: failed to record stock movement : %w", err)
+			return
+		}
+	}
+
+	err = s.repo.WithTx(tx).CreatePriceHistory(ctx, newPriceHistory(req.ProductID, uuid.NullUUID{UUID: data.ID, Valid: true}, data.Price, req.UserID))
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateVariant: failed to record price history : %w", err)
+		return
+	}
+
+	err = s.repo.WithTx(tx).SyncVariantTotals(ctx, req.ProductID)
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateVariant: failed to sync variant totals : %w", err)
+		return
+	}
+
+	res = toProductVariantResponses([]entity.ProductVariant{data})[0]
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   req.ProductID,
+		After:      map[string]any{"created_variant": res},
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.CreateVariant: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) UpdateVariant(ctx context.Context, req model.ProductVariantUpdateRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.UpdateVariant: failed to validate request : %w", err)
+		return
+	}
+
+	resultProduct, err := s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.UpdateVariant: failed to get product : %w", err)
+		return
+	}
+
+	var before *model.ProductVariantResponse
+	for i := range resultProduct.Variants {
+		if resultProduct.Variants[i].ID == req.ID {
+			before = &resultProduct.Variants[i]
+			break
+		}
+	}
+
+	if before == nil {
+		err = constant.ErrProductVariantNotFound
+		return
+	}
+
+	data := entity.ProductVariant{
+		ID:        req.ID,
+		ProductID: req.ProductID,
+		SKU:       req.SKU,
+		Name:      req.Name,
+		Price:     req.Price,
+		Stok:      req.Stok,
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.UpdateVariant: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.UpdateVariant: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	previousStok, err := s.repo.WithTx(tx).GetVariantStokForUpdate(ctx, data.ID)
+	if err != nil {
+		err = fmt.Errorf("product.service.UpdateVariant: failed to get product variant stok : %w", err)
+		return
+	}
+
+	err = s.repo.WithTx(tx).UpdateVariant(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("product.service.UpdateVariant: failed to update product variant : %w", err)
+		return
+	}
+
+	if data.Stok != previousStok {
+		movement := newStockMovement(req.ProductID, uuid.NullUUID{UUID: data.ID, Valid: true}, data.Stok-previousStok, entity.StockMovementReasonAdjustment, req.UserID)
+		_, err = s.repo.WithTx(tx).CreateStockMovement(ctx, movement)
+		if err != nil {
+			err = fmt.Errorf("product.service.UpdateVariant: failed to record stock movement : %w", err)
+			return
+		}
+	}
+
+	if !data.Price.Equal(before.Price) {
+		err = s.repo.WithTx(tx).CreatePriceHistory(ctx, newPriceHistory(req.ProductID, uuid.NullUUID{UUID: data.ID, Valid: true}, data.Price, req.UserID))
+		if err != nil {
+			err = fmt.Errorf("product.service.UpdateVariant: failed to record price history : %w", err)
+			return
+		}
+	}
+
+	err = s.repo.WithTx(tx).SyncVariantTotals(ctx, req.ProductID)
+	if err != nil {
+		err = fmt.Errorf("product.service.UpdateVariant: failed to sync variant totals : %w", err)
+		return
+	}
+
+	after := *before
+	after.SKU = data.SKU
+	after.Name = data.Name
+	after.Price = data.Price
+	after.Stok = data.Stok
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   req.ProductID,
+		Before:     map[string]any{"variant": before},
+		After:      map[string]any{"variant": after},
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.UpdateVariant: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) DeleteVariant(ctx context.Context, req model.ProductVariantDeleteRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteVariant: failed to validate request : %w", err)
+		return
+	}
+
+	resultProduct, err := s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteVariant: failed to get product : %w", err)
+		return
+	}
+
+	var before *model.ProductVariantResponse
+	for i := range resultProduct.Variants {
+		if resultProduct.Variants[i].ID == req.ID {
+			before = &resultProduct.Variants[i]
+			break
+		}
+	}
+
+	if before == nil {
+		err = constant.ErrProductVariantNotFound
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteVariant: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.DeleteVariant: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).DeleteVariant(ctx, req.ProductID, req.ID)
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteVariant: failed to delete product variant : %w", err)
+		return
+	}
+
+	err = s.repo.WithTx(tx).SyncVariantTotals(ctx, req.ProductID)
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteVariant: failed to sync variant totals : %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   req.ProductID,
+		Before:     map[string]any{"deleted_variant": before},
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteVariant: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) GetVariantsByIDs(ctx context.Context, ids []uuid.UUID) (res map[uuid.UUID]model.ProductVariantResponse, err error) {
+	results, err := s.repo.GetVariantsByIDs(ctx, ids)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetVariantsByIDs: failed to get product variants by ids : %w", err)
+		return
+	}
+
+	res = make(map[uuid.UUID]model.ProductVariantResponse)
+
+	for k, v := range results {
+		res[k] = toProductVariantResponses([]entity.ProductVariant{v})[0]
+	}
+
+	return
+}
+
+func (s Service) toProductImageResponses(images []entity.ProductImage) []model.ProductImageResponse {
+	res := make([]model.ProductImageResponse, len(images))
+	for i, img := range images {
+		res[i] = model.ProductImageResponse{
+			ID:           img.ID,
+			URL:          s.blobStore.URL(img.ObjectKey),
+			ThumbnailURL: s.blobStore.URL(img.ThumbnailKey),
+			Width:        img.Width,
+			Height:       img.Height,
+			Position:     img.Position,
+		}
+	}
+
+	return res
+}
+
+// getOwnedProduct returns the product, including a delisted one, when it is owned by userID.
+func (s Service) getOwnedProduct(ctx context.Context, id uuid.UUID, userID uuid.UUID) (res model.GetProductResponse, err error) {
+	resultProduct, err := s.getProducts(ctx, entity.ListProductFilter{
+		ID:              uuid.NullUUID{UUID: id, Valid: true},
+		IncludeDelisted: true,
+		Limit:           1,
+		Page:            1,
+	}, false)
+	if err != nil {
+		return
+	}
+
+	if len(resultProduct.Data) == 0 {
+		err = constant.ErrProductNotFound
+		return
+	}
+
+	if resultProduct.Data[0].OwnerID != userID {
+		err = constant.ErrCannotUpdateNotOwner
+		return
+	}
+
+	return resultProduct.Data[0], nil
+}
+
+// maxProductImagePixels guards against decompression bombs, images are decoded fully in memory.
+const maxProductImagePixels = 50_000_000
+
+type productImageUpload struct {
+	image     entity.ProductImage
+	data      []byte
+	thumbnail []byte
+}
+
+// processProductImage checks the type and size of the uploaded file and creates its thumbnail.
+func processProductImage(productID uuid.UUID, file *multipart.FileHeader) (res productImageUpload, err error) {
+	cfg := config.GetConfig().ProductImage
+
+	if file.Size > int64(cfg.MaxSize) {
+		err = constant.ErrProductImageTooLarge
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		err = fmt.Errorf("failed to open file : %w", err)
+		return
+	}
+
+	defer f.Close()
+
+	res.data, err = io.ReadAll(io.LimitReader(f, int64(cfg.MaxSize)+1))
+	if err != nil {
+		err = fmt.Errorf("failed to read file : %w", err)
+		return
+	}
+
+	if len(res.data) > cfg.MaxSize {
+		err = constant.ErrProductImageTooLarge
+		return
+	}
+
+	// the content type sent by the client is not trusted
+	contentType := http.DetectContentType(res.data)
+
+	var ext string
+	switch contentType {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/png":
+		ext = ".png"
+	default:
+		err = constant.ErrProductImageInvalidType
+		return
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(res.data))
+	if err != nil {
+		err = constant.ErrProductImageInvalidType
+		return
+	}
+
+	if imageConfig.Width*imageConfig.Height > maxProductImagePixels {
+		err = constant.ErrProductImageTooLarge
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(res.data))
+	if err != nil {
+		err = constant.ErrProductImageInvalidType
+		return
+	}
+
+	thumbnail := imageutil.Thumbnail(img, cfg.ThumbnailSize)
+
+	var thumbnailBuf bytes.Buffer
+	if ext == ".png" {
+		err = png.Encode(&thumbnailBuf, thumbnail)
+	} else {
+		err = jpeg.Encode(&thumbnailBuf, thumbnail, &jpeg.Options{Quality: 80})
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to encode thumbnail : %w", err)
+		return
+	}
+
+	res.thumbnail = thumbnailBuf.Bytes()
+
+	id := uuid.New()
+	res.image = entity.ProductImage{
+		ID:           id,
+		ProductID:    productID,
+		ObjectKey:    "products/" + productID.String() + "/" + id.String() + ext,
+		ThumbnailKey: "products/" + productID.String() + "/" + id.String() + "_thumb" + ext,
+		ContentType:  contentType,
+		Size:         int64(len(res.data)),
+		Width:        imageConfig.Width,
+		Height:       imageConfig.Height,
+	}
+
+	return
+}
+
+// deleteBlobs removes stored objects, failures are only logged because the database is already consistent.
+func (s Service) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		err := s.blobStore.Delete(ctx, key)
+		if err != nil {
+			logger.Log(ctx).Error().Err(err).Str("key", key).Msg("product.service: failed to delete blob")
+		}
+	}
+}
+
+func (s Service) UploadImages(ctx context.Context, req model.ProductImageUploadRequest) (res []model.ProductImageResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.UploadImages: failed to validate request : %w", err)
+		return
+	}
+
+	_, err = s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.UploadImages: failed to get product : %w", err)
+		return
+	}
+
+	existingImages, err := s.repo.GetImages(ctx, req.ProductID)
+	if err != nil {
+		err = fmt.Errorf("product.service.UploadImages: failed to get product images : %w", err)
+		return
+	}
+
+	if len(existingImages)+len(req.Files) > config.GetConfig().ProductImage.MaxCount {
+		err = constant.ErrProductImageLimitExceeded
+		return
+	}
+
+	// every file is checked before anything is stored
+	uploads := make([]productImageUpload, len(req.Files))
+	for i, file := range req.Files {
+		uploads[i], err = processProductImage(req.ProductID, file)
+		if err != nil {
+			err = fmt.Errorf("product.service.UploadImages: failed to process image %s : %w", file.Filename, err)
+			return
+		}
+	}
+
+	// stored objects are removed again when the images cannot be saved
+	var storedKeys []string
+	defer func() {
+		if err != nil {
+			s.deleteBlobs(ctx, storedKeys)
+		}
+	}()
+
+	for _, upload := range uploads {
+		err = s.blobStore.Put(ctx, upload.image.ObjectKey, bytes.NewReader(upload.data), int64(len(upload.data)), upload.image.ContentType)
+		if err != nil {
+			err = fmt.Errorf("product.service.UploadImages: failed to store image : %w", err)
+			return
+		}
+		storedKeys = append(storedKeys, upload.image.ObjectKey)
+
+		err = s.blobStore.Put(ctx, upload.image.ThumbnailKey, bytes.NewReader(upload.thumbnail), int64(len(upload.thumbnail)), upload.image.ContentType)
+		if err != nil {
+			err = fmt.Errorf("product.service.UploadImages: failed to store thumbnail : %w", err)
+			return
+		}
+		storedKeys = append(storedKeys, upload.image.ThumbnailKey)
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.UploadImages: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.UploadImages: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	images := make([]entity.ProductImage, len(uploads))
+	for i, upload := range uploads {
+		err = s.repo.WithTx(tx).CreateImage(ctx, upload.image)
+		if err != nil {
+			err = fmt.Errorf("product.service.UploadImages: failed to create product image : %w", err)
+			return
+		}
+
+		images[i] = upload.image
+		images[i].Position = len(existingImages) + i
+	}
+
+	res = s.toProductImageResponses(images)
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   req.ProductID,
+		After:      map[string]any{"uploaded_images": res},
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.UploadImages: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) DeleteImage(ctx context.Context, req model.ProductImageDeleteRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteImage: failed to validate request : %w", err)
+		return
+	}
+
+	_, err = s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteImage: failed to get product : %w", err)
+		return
+	}
+
+	// objects are only removed once the row is gone
+	var deleted entity.ProductImage
+	defer func() {
+		if err == nil {
+			s.deleteBlobs(ctx, []string{deleted.ObjectKey, deleted.ThumbnailKey})
+		}
+	}()
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteImage: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.DeleteImage: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	deleted, err = s.repo.WithTx(tx).DeleteImage(ctx, req.ProductID, req.ImageID)
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteImage: failed to delete product image : %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   req.ProductID,
+		Before:     map[string]any{"deleted_image": s.toProductImageResponses([]entity.ProductImage{deleted})[0]},
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.DeleteImage: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) ReorderImages(ctx context.Context, req model.ProductImageReorderRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.ReorderImages: failed to validate request : %w", err)
+		return
+	}
+
+	_, err = s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.ReorderImages: failed to get product : %w", err)
+		return
+	}
+
+	images, err := s.repo.GetImages(ctx, req.ProductID)
+	if err != nil {
+		err = fmt.Errorf("product.service.ReorderImages: failed to get product images : %w", err)
+		return
+	}
+
+	// the new order must be a permutation of the current images
+	if len(req.ImageIDs) != len(images) {
+		err = constant.ErrProductImageOrderMismatch
+		return
+	}
+
+	remaining := make(map[uuid.UUID]bool, len(images))
+	for _, img := range images {
+		remaining[img.ID] = true
+	}
+
+	for _, id := range req.ImageIDs {
+		if !remaining[id] {
+			err = constant.ErrProductImageOrderMismatch
+			return
+		}
+		delete(remaining, id)
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.ReorderImages: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.ReorderImages: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).UpdateImagePositions(ctx, req.ProductID, req.ImageIDs)
+	if err != nil {
+		err = fmt.Errorf("product.service.ReorderImages: failed to update image positions : %w", err)
+		return
+	}
+
+	beforeIDs := make([]uuid.UUID, len(images))
+	for i, img := range images {
+		beforeIDs[i] = img.ID
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   req.ProductID,
+		Before:     map[string]any{"image_ids": beforeIDs},
+		After:      map[string]any{"image_ids": req.ImageIDs},
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.ReorderImages: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) BatchReduceStok(ctx context.Context, req []model.ReduceStokRequest) (err error) {
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.BatchUpdateStok: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.BatchUpdateStok: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	for _, v := range req {
+		// the product stok is the total of its variants, so both are reduced
+		if v.VariantID.Valid {
+			if v.Reserved {
+				err = s.repo.ConsumeReservedVariantStok(ctx, v.VariantID.UUID, v.ReduceBy)
+			} else {
+				err = s.repo.ReduceVariantStok(ctx, v.VariantID.UUID, v.ReduceBy)
+			}
+			if err != nil {
+				err = fmt.Errorf("product.service.BatchUpdateStok: failed to update variant stok : %w", err)
+				return err
+			}
+		}
+
+		if v.Reserved {
+			err = s.repo.ConsumeReservedStok(ctx, v.ID, v.ReduceBy)
+		} else {
+			err = s.repo.ReduceStok(ctx, v.ID, v.ReduceBy)
+		}
+		if err != nil {
+			err = fmt.Errorf("product.service.BatchUpdateStok: failed to update batch stok : %w", err)
+			return err
+		}
+
+		_, err = s.repo.CreateStockMovement(ctx, entity.StockMovement{
+			ProductID:     v.ID,
+			VariantID:     v.VariantID,
+			Delta:         -v.ReduceBy,
+			Reason:        entity.StockMovementReasonSale,
+			ActorID:       v.UserID,
+			TransactionID: v.TransactionID,
+		})
+		if err != nil {
+			err = fmt.Errorf("product.service.BatchUpdateStok: failed to record stock movement : %w", err)
+			return err
+		}
+
+		err = s.auditSvc.Record(ctx, model.AuditLogRecordRequest{
+			Action:     entity.AuditActionUpdate,
+			EntityType: entity.AuditEntityProduct,
+			EntityID:   v.ID,
+			After:      v,
+		})
+		if err != nil {
+			err = fmt.Errorf("product.service.BatchUpdateStok: failed to record audit log : %w", err)
+			return err
+		}
+	}
+
+	return
+}
+
+// BatchReserveStok holds stok for a reservation, the unreserved stok of every product must be enough.
+// It does not begin a transaction, call it WithTx so a failed item releases the others.
+func (s Service) BatchReserveStok(ctx context.Context, req []model.ReserveStokRequest) (err error) {
+	for _, v := range req {
+		if v.VariantID.Valid {
+			err = s.repo.ReserveVariantStok(ctx, v.VariantID.UUID, v.Qty)
+			if err != nil {
+				err = fmt.Errorf("product.service.BatchReserveStok: failed to reserve variant stok : %w", err)
+				return err
+			}
+		}
+
+		err = s.repo.ReserveStok(ctx, v.ID, v.Qty)
+		if err != nil {
+			err = fmt.Errorf("product.service.BatchReserveStok: failed to reserve stok : %w", err)
+			return err
+		}
+	}
+
+	return
+}
+
+// BatchReleaseStok gives back stok held by a reservation, call it WithTx like BatchReserveStok.
+func (s Service) BatchReleaseStok(ctx context.Context, req []model.ReserveStokRequest) (err error) {
+	for _, v := range req {
+		if v.VariantID.Valid {
+			err = s.repo.ReleaseVariantStok(ctx, v.VariantID.UUID, v.Qty)
+			if err != nil {
+				err = fmt.Errorf("product.service.BatchReleaseStok: failed to release variant stok : %w", err)
+				return err
+			}
+		}
+
+		err = s.repo.ReleaseStok(ctx, v.ID, v.Qty)
+		if err != nil {
+			err = fmt.Errorf("product.service.BatchReleaseStok: failed to release stok : %w", err)
+			return err
+		}
+	}
+
+	return
+}
+
+// AddRating adds the rating of a new review to the product, it must be called within the transaction
+// creating the review using WithTx.
+func (s Service) AddRating(ctx context.Context, id uuid.UUID, rating int) (err error) {
+	err = s.repo.AddRating(ctx, id, rating)
+	if err != nil {
+		err = fmt.Errorf("product.service.AddRating: failed to add rating : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) GetByIDs(ctx context.Context, ids []uuid.UUID) (res map[uuid.UUID]model.GetProductResponse, err error) {
+	results, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetByIDs: failed to get products by ids : %w", err)
+		return
+	}
+
+	res = make(map[uuid.UUID]model.GetProductResponse)
+
+	for k, v := range results {
+		res[k] = model.GetProductResponse{
+			ID:            v.ID,
+			Name:          v.Name,
+			Description:   v.Description,
+			Stok:          v.Stok,
+			AvailableStok: v.Stok,
+			Price:         v.Price,
+			OwnerID:       v.UserID,
+			HasVariants:   v.HasVariants,
+		}
+	}
+
+	return
+}
+
+func newStockMovement(productID uuid.UUID, variantID uuid.NullUUID, delta int, reason entity.StockMovementReason, actorID uuid.UUID) entity.StockMovement {
+	return entity.StockMovement{
+		ProductID: productID,
+		VariantID: variantID,
+		Delta:     delta,
+		Reason:    reason,
+		ActorID:   uuid.NullUUID{UUID: actorID, Valid: true},
+	}
+}
+
+func toStockMovementResponse(data entity.StockMovement) model.StockMovementResponse {
+	return model.StockMovementResponse{
+		ID:            data.ID,
+		ProductID:     data.ProductID,
+		VariantID:     data.VariantID,
+		Delta:         data.Delta,
+		Reason:        string(data.Reason),
+		StokAfter:     data.StokAfter,
+		ActorID:       data.ActorID,
+		TransactionID: data.TransactionID,
+		Note:          data.Note,
+		CreatedAt:     data.CreatedAt,
+	}
+}
+
+// AdjustStok increments or decrements the stok by a delta, it never lowers the stok under the reserved stok.
+func (s Service) AdjustStok(ctx context.Context, req model.StockAdjustmentRequest) (res model.StockMovementResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.AdjustStok: failed to validate request : %w", err)
+		return
+	}
+
+	reason := entity.StockMovementReason(req.Reason)
+	if reason != entity.StockMovementReasonAdjustment && req.Delta < 0 {
+		err = constant.ErrStockAdjustmentInvalidDelta
+		return
+	}
+
+	resultProduct, err := s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.AdjustStok: failed to get product : %w", err)
+		return
+	}
+
+	if req.VariantID.Valid {
+		found := false
+		for _, v := range resultProduct.Variants {
+			if v.ID == req.VariantID.UUID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			err = constant.ErrProductVariantNotFound
+			return
+		}
+	} else if resultProduct.HasVariants {
+		err = constant.ErrProductVariantRequired
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("product.service.AdjustStok: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("product.service.AdjustStok: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	if req.VariantID.Valid {
+		_, err = s.repo.WithTx(tx).AdjustVariantStok(ctx, req.VariantID.UUID, req.Delta)
+		if err != nil {
+			err = fmt.Errorf("product.service.AdjustStok: failed to adjust variant stok : %w", err)
+			return
+		}
+
+		err = s.repo.WithTx(tx).SyncVariantTotals(ctx, req.ProductID)
+		if err != nil {
+			err = fmt.Errorf("product.service.AdjustStok: failed to sync variant totals : %w", err)
+			return
+		}
+	} else {
+		_, err = s.repo.WithTx(tx).AdjustStok(ctx, req.ProductID, req.Delta)
+		if err != nil {
+			err = fmt.Errorf("product.service.AdjustStok: failed to adjust stok : %w", err)
+			return
+		}
+	}
+
+	movement := newStockMovement(req.ProductID, req.VariantID, req.Delta, reason, req.UserID)
+	if req.Note != "" {
+		movement.Note = null.StringFrom(req.Note)
+	}
+
+	movement, err = s.repo.WithTx(tx).CreateStockMovement(ctx, movement)
+	if err != nil {
+		err = fmt.Errorf("product.service.AdjustStok: failed to record stock movement : %w", err)
+		return
+	}
+
+	res = toStockMovementResponse(movement)
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityProduct,
+		EntityID:   req.ProductID,
+		After:      map[string]any{"stock_movement": res},
+	})
+	if err != nil {
+		err = fmt.Errorf("product.service.AdjustStok: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// GetStockMovements returns the stok history of a product to its owner, newest first.
+func (s Service) GetStockMovements(ctx context.Context, req model.GetListStockMovementRequest) (res pkgutil.PaginationResponse[[]model.StockMovementResponse], err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetStockMovements: failed to validate request : %w", err)
+		return
+	}
+
+	_, err = s.getOwnedProduct(ctx, req.ProductID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetStockMovements: failed to get product : %w", err)
+		return
+	}
+
+	filter := entity.ListStockMovementFilter{
+		ProductID: req.ProductID,
+		VariantID: req.VariantID,
+		Reason:    req.Reason,
+		Page:      req.Page,
+		Limit:     req.Limit,
+	}
+
+	results, err := s.repo.GetStockMovements(ctx, filter)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetStockMovements: failed to get stock movements from db : %w", err)
+		return
+	}
+
+	resData := make([]model.StockMovementResponse, len(results))
+	for i, result := range results {
+		resData[i] = toStockMovementResponse(result)
+	}
+
+	total, err := s.repo.GetTotalStockMovement(ctx, filter)
+	if err != nil {
+		err = fmt.Errorf("product.service.GetStockMovements: failed to get total stock movement from db : %w", err)
+		return
+	}
+
+	totalPage := 0
+	if total%filter.Limit != 0 {
+		totalPage = total/filter.Limit + 1
+	} else {
+		totalPage = total / filter.Limit
+	}
+
+	res = pkgutil.PaginationResponse[[]model.StockMovementResponse]{
+		TotalData: total,
+		TotalPage: totalPage,
+		Page:      filter.Page,
+		Limit:     filter.Limit,
+		Data:      resData,
+	}
+
+	return
+}
+
+// GetCacheStats returns the counters of the product catalog cache of this instance.
+func (s Service) GetCacheStats(ctx context.Context) (res model.ProductCacheStatsResponse) {
+	cache, ok := s.repo.(product.RepositoryCache)
+	if !ok {
+		return
+	}
+
+	stats := cache.Stats()
+
+	res = model.ProductCacheStatsResponse{
+		Enabled: true,
+		Hits:    stats.Hits,
+		Misses:  stats.Misses,
+		Errors:  stats.Errors,
+	}
+
+	if total := stats.Hits + stats.Misses; total > 0 {
+		res.HitRatio = float64(stats.Hits) / float64(total)
 	}
 
 	return
@@ -0,0 +1,1231 @@
+package productsvc
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arfan21/vocagame/config"
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	auditsvc "github.com/arfan21/vocagame/internal/audit/service"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	productrepo "github.com/arfan21/vocagame/internal/product/repository"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/validation"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+var productColumns = []string{
+	"id", "sku", "name", "stok", "reserved_stok", "price", "description", "sold_count", "rating_count", "rating_sum",
+	"version", "created_at", "deleted_at", "delisted_at", "published_at", "owner_id", "owner_name",
+	"categories", "tags", "images", "variants", "final_price", "sale",
+}
+
+func initPgMock(t *testing.T) pgxmock.PgxPoolIface {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mock
+}
+
+func initDepMock(db pgxmock.PgxPoolIface) (svc *Service) {
+	auditSvc := auditsvc.New(auditrepo.New(db))
+	svc = New(productrepo.New(db, db), auditSvc, nil)
+
+	return
+}
+
+func expectRecordAuditLog(dbMock pgxmock.PgxPoolIface) {
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func expectCreatePriceHistory(dbMock pgxmock.PgxPoolIface) {
+	dbMock.ExpectExec("INSERT INTO product_price_history (.+) VALUES (.+)").
+		WithArgs(
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+// expectGetProducts expects a product listing whose clauses after the join match query, which returns data.
+func expectGetProducts(dbMock pgxmock.PgxPoolIface, query string, args []any, data ...entity.Product) {
+	rows := pgxmock.NewRows(productColumns)
+	for _, v := range data {
+		rows.AddRow(
+			v.ID, v.SKU, v.Name, v.Stok, v.ReservedStok, v.Price, v.Description, v.SoldCount, v.RatingCount, v.RatingSum,
+			v.Version, v.CreatedAt, v.DeletedAt, v.DelistedAt, v.PublishedAt, v.User.ID, v.User.Fullname,
+			v.Categories, v.Tags, v.Images, v.Variants, v.FinalPrice, v.Sale,
+		)
+	}
+
+	dbMock.ExpectQuery("SELECT (.+) FROM products p JOIN users u (.+)" + query).
+		WithArgs(args...).
+		WillReturnRows(rows)
+}
+
+// expectGetProduct expects the product to be looked up by its id.
+func expectGetProduct(dbMock pgxmock.PgxPoolIface, v entity.Product) {
+	expectGetProducts(dbMock, "", []any{v.ID, 1, 0}, v)
+}
+
+func newProduct(ownerID uuid.UUID) entity.Product {
+	return entity.Product{
+		ID:           uuid.New(),
+		Name:         "product 1",
+		Stok:         10,
+		ReservedStok: 4,
+		Price:        decimal.NewFromInt(1000),
+		FinalPrice:   decimal.NewFromInt(1000),
+		Version:      1,
+		CreatedAt:    time.Now(),
+		PublishedAt:  null.TimeFrom(time.Now().Add(-time.Hour)),
+		User:         entity.User{ID: ownerID, Fullname: "owner"},
+		Categories:   []entity.Category{},
+		Tags:         []string{},
+		Images:       []entity.ProductImage{},
+		Variants:     []entity.ProductVariant{},
+	}
+}
+
+func TestProductCursorRoundTripSuccess(t *testing.T) {
+	product := entity.Product{
+		ID:         uuid.New(),
+		FinalPrice: decimal.RequireFromString("15000.50"),
+		SoldCount:  42,
+		CreatedAt:  time.Date(2024, 3, 1, 10, 20, 30, 123456000, time.UTC),
+		Search:     entity.ProductSearch{Rank: 0.25},
+	}
+
+	tests := []struct {
+		sort  string
+		value string
+	}{
+		{sort: entity.ProductSortNewest, value: "2024-03-01 10:20:30.123456"},
+		{sort: entity.ProductSortPriceAsc, value: "15000.5"},
+		{sort: entity.ProductSortPriceDesc, value: "15000.5"},
+		{sort: entity.ProductSortBestSelling, value: "42"},
+		{sort: entity.ProductSortRelevance, value: "0.25"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			cursor, err := encodeProductCursor(product, tt.sort)
+			assert.NoError(t, err)
+
+			data, err := decodeProductCursor(cursor)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.sort, data.Sort)
+			assert.Equal(t, tt.value, data.Value)
+			assert.Equal(t, product.ID, data.ID)
+
+			filter, err := listProductFilterFromRequest(model.GetListProductRequest{
+				Limit:  10,
+				Sort:   tt.sort,
+				Q:      "diamond",
+				Cursor: cursor,
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, data, filter.Cursor)
+			assert.Equal(t, 1, filter.Page)
+		})
+	}
+}
+
+// rawCursor encodes a cursor with any value, like a client tampering with it.
+func rawCursor(sort string, value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"s":"` + sort + `","v":"` + value + `","id":"` + uuid.NewString() + `"}`))
+}
+
+func TestDecodeProductCursorFailedMalformed(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		_, err := decodeProductCursor(cursor)
+		assert.ErrorIs(t, err, constant.ErrInvalidCursor, cursor)
+	}
+
+	// a value which is not of the sql type of its sort would fail the query
+	tests := []struct {
+		sort  string
+		value string
+	}{
+		{sort: entity.ProductSortNewest, value: "x"},
+		{sort: entity.ProductSortNewest, value: "2024-03-01T10:20:30Z"},
+		{sort: entity.ProductSortNewest, value: "0000-01-01 00:00:00"},
+		{sort: entity.ProductSortPriceAsc, value: "x"},
+		{sort: entity.ProductSortPriceDesc, value: "1e999999999"},
+		{sort: entity.ProductSortBestSelling, value: "4.2"},
+		{sort: entity.ProductSortBestSelling, value: "2147483648"},
+		{sort: entity.ProductSortRelevance, value: "x"},
+		{sort: entity.ProductSortRelevance, value: "NaN"},
+		{sort: entity.ProductSortRelevance, value: "Inf"},
+		{sort: "name", value: "x"},
+		{sort: "", value: ""},
+	}
+
+	for _, tt := range tests {
+		_, err := decodeProductCursor(rawCursor(tt.sort, tt.value))
+		assert.ErrorIs(t, err, constant.ErrInvalidCursor, tt.sort+" "+tt.value)
+	}
+}
+
+func TestDecodeProductCursorNormalizeValueSuccess(t *testing.T) {
+	tests := []struct {
+		sort  string
+		value string
+		want  string
+	}{
+		{sort: entity.ProductSortNewest, value: "2024-03-01 10:20:30.120", want: "2024-03-01 10:20:30.12"},
+		{sort: entity.ProductSortPriceAsc, value: "0015000.50", want: "15000.5"},
+		{sort: entity.ProductSortBestSelling, value: "+42", want: "42"},
+		{sort: entity.ProductSortRelevance, value: "0x1p-2", want: "0.25"},
+	}
+
+	for _, tt := range tests {
+		data, err := decodeProductCursor(rawCursor(tt.sort, tt.value))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, data.Value)
+	}
+}
+
+func TestListProductFilterFromRequestFailedCursorSortMismatch(t *testing.T) {
+	cursor, err := encodeProductCursor(entity.Product{ID: uuid.New(), FinalPrice: decimal.NewFromInt(100)}, entity.ProductSortPriceAsc)
+	assert.NoError(t, err)
+
+	_, err = listProductFilterFromRequest(model.GetListProductRequest{
+		Limit:  10,
+		Sort:   entity.ProductSortPriceDesc,
+		Cursor: cursor,
+	})
+	assert.ErrorIs(t, err, constant.ErrInvalidCursor)
+
+	// without a sort the listing is sorted by newest
+	_, err = listProductFilterFromRequest(model.GetListProductRequest{
+		Limit:  10,
+		Cursor: cursor,
+	})
+	assert.ErrorIs(t, err, constant.ErrInvalidCursor)
+}
+
+func TestGetProductsRelevanceWithoutQuerySuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	product := newProduct(uuid.New())
+	product.CreatedAt = time.Date(2024, 3, 1, 10, 20, 30, 123456000, time.UTC)
+
+	req := model.GetListProductRequest{
+		Sort:       entity.ProductSortRelevance,
+		Page:       1,
+		Limit:      1,
+		SkipTotal:  true,
+		SkipFacets: true,
+	}
+
+	// without a search there is no rank, the products are sorted by newest
+	expectGetProducts(dbMock, regexp.QuoteMeta("ORDER BY p.created_at DESC, p.id DESC LIMIT $1 OFFSET $2"), []any{1, 0}, product)
+
+	res, err := svc.GetProducts(context.Background(), req)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res.NextCursor)
+
+	cursor, err := decodeProductCursor(res.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.ProductSortNewest, cursor.Sort)
+	assert.Equal(t, "2024-03-01 10:20:30.123456", cursor.Value)
+
+	// the second page continues after the created_at of the last product
+	expectGetProducts(dbMock,
+		regexp.QuoteMeta("(p.created_at, p.id) < ($1::timestamp, $2) ORDER BY p.created_at DESC, p.id DESC LIMIT $3"),
+		[]any{"2024-03-01 10:20:30.123456", product.ID, 1},
+	)
+
+	req.Cursor = res.NextCursor
+
+	res, err = svc.GetProducts(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Data)
+	assert.Empty(t, res.NextCursor)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAdjustStokSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	product := newProduct(userID)
+	movementID := uuid.New()
+
+	req := model.StockAdjustmentRequest{
+		ProductID: product.ID,
+		UserID:    userID,
+		Delta:     5,
+		Reason:    string(entity.StockMovementReasonRestock),
+		Note:      "supplier delivery",
+	}
+
+	expectGetProduct(dbMock, product)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("UPDATE products SET stok = (.+) WHERE (.+) RETURNING stok").
+		WithArgs(req.Delta, product.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"stok"}).AddRow(15))
+	dbMock.ExpectQuery("INSERT INTO stock_movements (.+) VALUES (.+) RETURNING (.+)").
+		WithArgs(
+			product.ID,
+			uuid.NullUUID{},
+			req.Delta,
+			entity.StockMovementReasonRestock,
+			uuid.NullUUID{UUID: userID, Valid: true},
+			uuid.NullUUID{},
+			null.StringFrom(req.Note),
+		).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "stok_after", "created_at"}).AddRow(movementID, 15, time.Now()))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	res, err := svc.AdjustStok(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, movementID, res.ID)
+	assert.Equal(t, req.Delta, res.Delta)
+	assert.Equal(t, 15, res.StokAfter)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAdjustStokVariantSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	product := newProduct(userID)
+	variantID := uuid.New()
+	product.Variants = []entity.ProductVariant{{ID: variantID, ProductID: product.ID, Name: "S", Price: product.Price, Stok: 10}}
+
+	req := model.StockAdjustmentRequest{
+		ProductID: product.ID,
+		UserID:    userID,
+		VariantID: uuid.NullUUID{UUID: variantID, Valid: true},
+		Delta:     -2,
+		Reason:    string(entity.StockMovementReasonAdjustment),
+	}
+
+	expectGetProduct(dbMock, product)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("UPDATE product_variants SET stok = (.+) WHERE (.+) RETURNING stok").
+		WithArgs(req.Delta, variantID).
+		WillReturnRows(pgxmock.NewRows([]string{"stok"}).AddRow(8))
+	dbMock.ExpectExec("UPDATE products p SET stok = (.+) FROM (.+)").
+		WithArgs(product.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	dbMock.ExpectQuery("INSERT INTO stock_movements (.+) VALUES (.+) RETURNING (.+)").
+		WithArgs(
+			product.ID,
+			req.VariantID,
+			req.Delta,
+			entity.StockMovementReasonAdjustment,
+			uuid.NullUUID{UUID: userID, Valid: true},
+			uuid.NullUUID{},
+			null.String{},
+		).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "stok_after", "created_at"}).AddRow(uuid.New(), 8, time.Now()))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	res, err := svc.AdjustStok(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, 8, res.StokAfter)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAdjustStokFailedBelowReserved(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	product := newProduct(userID)
+
+	// 10 - 7 is under the 4 reserved
+	req := model.StockAdjustmentRequest{
+		ProductID: product.ID,
+		UserID:    userID,
+		Delta:     -7,
+		Reason:    string(entity.StockMovementReasonAdjustment),
+	}
+
+	expectGetProduct(dbMock, product)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("UPDATE products SET stok = (.+) WHERE (.+) RETURNING stok").
+		WithArgs(req.Delta, product.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"stok"}))
+	dbMock.ExpectRollback()
+
+	_, err := svc.AdjustStok(context.Background(), req)
+	assert.ErrorIs(t, err, constant.ErrStockBelowReserved)
+	// no stock movement is recorded
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAdjustStokFailedNegativeRestock(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	_, err := svc.AdjustStok(context.Background(), model.StockAdjustmentRequest{
+		ProductID: uuid.New(),
+		UserID:    uuid.New(),
+		Delta:     -1,
+		Reason:    string(entity.StockMovementReasonRestock),
+	})
+	assert.ErrorIs(t, err, constant.ErrStockAdjustmentInvalidDelta)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestAdjustStokFailedNotOwner(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	product := newProduct(uuid.New())
+	expectGetProduct(dbMock, product)
+
+	_, err := svc.AdjustStok(context.Background(), model.StockAdjustmentRequest{
+		ProductID: product.ID,
+		UserID:    uuid.New(),
+		Delta:     1,
+		Reason:    string(entity.StockMovementReasonRestock),
+	})
+	assert.ErrorIs(t, err, constant.ErrCannotUpdateNotOwner)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDeleteProductSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	product := newProduct(userID)
+	product.DelistedAt = null.TimeFrom(time.Now())
+
+	// an unlisted product can be deleted too
+	expectGetProducts(dbMock, regexp.QuoteMeta("WHERE p.deleted_at IS NULL AND p.id = $1 "), []any{product.ID, 1, 0}, product)
+	dbMock.ExpectBegin()
+	// the product is only marked deleted, its transactions keep referencing it
+	dbMock.ExpectExec(regexp.QuoteMeta("UPDATE products SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(product.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	err := svc.Delete(context.Background(), product.ID, userID)
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDeleteProductFailedNotOwner(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	product := newProduct(uuid.New())
+	expectGetProduct(dbMock, product)
+
+	err := svc.Delete(context.Background(), product.ID, uuid.New())
+	assert.ErrorIs(t, err, constant.ErrCannotDeleteNotOwner)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDeleteProductFailedAlreadyDeleted(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	product := newProduct(uuid.New())
+	expectGetProducts(dbMock, regexp.QuoteMeta("WHERE p.deleted_at IS NULL AND p.id = $1 "), []any{product.ID, 1, 0})
+
+	err := svc.Delete(context.Background(), product.ID, product.User.ID)
+	assert.ErrorIs(t, err, constant.ErrProductNotFound)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestDelistProductSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	product := newProduct(userID)
+
+	expectGetProducts(dbMock,
+		regexp.QuoteMeta("WHERE p.deleted_at IS NULL AND p.delisted_at IS NULL AND p.published_at <= now() AND p.id = $1 "),
+		[]any{product.ID, 1, 0}, product,
+	)
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(regexp.QuoteMeta("UPDATE products SET delisted_at = now() WHERE id = $1 AND delisted_at IS NULL")).
+		WithArgs(product.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	err := svc.Delist(context.Background(), product.ID, userID)
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRestoreProductSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	product := newProduct(userID)
+	product.DeletedAt = null.TimeFrom(time.Now())
+
+	expectGetProducts(dbMock,
+		regexp.QuoteMeta("WHERE (p.deleted_at IS NOT NULL OR p.delisted_at IS NOT NULL) AND p.id = $1 "),
+		[]any{product.ID, 1, 0}, product,
+	)
+	dbMock.ExpectBegin()
+	// restoring clears both the delete and the delist
+	dbMock.ExpectExec(regexp.QuoteMeta("UPDATE products SET deleted_at = NULL, delisted_at = NULL WHERE id = $1")).
+		WithArgs(product.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	err := svc.Restore(context.Background(), product.ID, userID)
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRestoreProductFailedNotArchived(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	product := newProduct(uuid.New())
+
+	// a listed product is not found among the archived products
+	expectGetProducts(dbMock,
+		regexp.QuoteMeta("WHERE (p.deleted_at IS NOT NULL OR p.delisted_at IS NOT NULL) AND p.id = $1 "),
+		[]any{product.ID, 1, 0},
+	)
+
+	err := svc.Restore(context.Background(), product.ID, product.User.ID)
+	assert.ErrorIs(t, err, constant.ErrProductNotFound)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRestoreProductFailedNotOwner(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	product := newProduct(uuid.New())
+	product.DeletedAt = null.TimeFrom(time.Now())
+	expectGetProduct(dbMock, product)
+
+	err := svc.Restore(context.Background(), product.ID, uuid.New())
+	assert.ErrorIs(t, err, constant.ErrCannotRestoreNotOwner)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetArchivedProductsSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	deleted := newProduct(userID)
+	deleted.DeletedAt = null.TimeFrom(time.Now())
+	delisted := newProduct(userID)
+	delisted.DelistedAt = null.TimeFrom(time.Now())
+
+	expectGetProducts(dbMock,
+		regexp.QuoteMeta("WHERE (p.deleted_at IS NOT NULL OR p.delisted_at IS NOT NULL) AND p.user_id = $1 ORDER BY"),
+		[]any{userID, 10, 0}, deleted, delisted,
+	)
+
+	res, err := svc.GetArchivedProducts(context.Background(), userID, model.GetListProductRequest{
+		Page:      1,
+		Limit:     10,
+		SkipTotal: true,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	assert.Len(t, res.Data, 2)
+	assert.True(t, res.Data[0].DeletedAt.Valid)
+	assert.Equal(t, entity.ProductStatusUnlisted, res.Data[1].Status)
+}
+
+// nearNow matches a time within a second of now, for the publish time set by the service.
+type nearNow struct{}
+
+func (nearNow) Match(v any) bool {
+	t, ok := v.(time.Time)
+	return ok && t.Location() == time.UTC && time.Since(t).Abs() < time.Second
+}
+
+// auditStatus matches the after value of an audit log of a product with the status.
+type auditStatus string
+
+func (a auditStatus) Match(v any) bool {
+	data, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+
+	var product model.GetProductResponse
+	return json.Unmarshal(data, &product) == nil && product.Status == string(a)
+}
+
+func expectRecordProductAuditLog(dbMock pgxmock.PgxPoolIface, action string, id uuid.UUID, status string) {
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			uuid.NullUUID{}, action, entity.AuditEntityProduct, id,
+			pgxmock.AnyArg(), auditStatus(status), "", "",
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func TestProductStatusSuccess(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		publishedAt null.Time
+		delistedAt  null.Time
+		want        string
+	}{
+		{want: entity.ProductStatusDraft},
+		{publishedAt: null.TimeFrom(now.Add(time.Hour)), want: entity.ProductStatusScheduled},
+		{publishedAt: null.TimeFrom(now), want: entity.ProductStatusPublished},
+		{publishedAt: null.TimeFrom(now.Add(-time.Hour)), want: entity.ProductStatusPublished},
+		{publishedAt: null.TimeFrom(now.Add(-time.Hour)), delistedAt: null.TimeFrom(now), want: entity.ProductStatusUnlisted},
+		{delistedAt: null.TimeFrom(now), want: entity.ProductStatusUnlisted},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, productStatus(tt.publishedAt, tt.delistedAt, now))
+	}
+}
+
+func TestPublishTimeSuccess(t *testing.T) {
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	assert.Equal(t, now.UTC(), publishTime(nil, now))
+	assert.Equal(t, now.UTC(), publishTime(&past, now))
+	assert.Equal(t, now.UTC(), publishTime(&now, now))
+	assert.Equal(t, future.UTC(), publishTime(&future, now))
+	assert.Equal(t, time.UTC, publishTime(&future, now).Location())
+}
+
+func TestGetProductFailedUnpublished(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	id := uuid.New()
+
+	// a draft or scheduled product is not found by buyers
+	expectGetProducts(dbMock,
+		regexp.QuoteMeta("WHERE p.deleted_at IS NULL AND p.delisted_at IS NULL AND p.published_at <= now() AND p.id = $1 "),
+		[]any{id, 1, 0},
+	)
+
+	_, err := svc.GetProduct(context.Background(), id)
+	assert.ErrorIs(t, err, constant.ErrProductNotFound)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetProductsHidesUnpublishedSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	product := newProduct(uuid.New())
+
+	expectGetProducts(dbMock,
+		regexp.QuoteMeta("WHERE p.deleted_at IS NULL AND p.delisted_at IS NULL AND p.published_at <= now() ORDER BY"),
+		[]any{10, 0}, product,
+	)
+
+	res, err := svc.GetProducts(context.Background(), model.GetListProductRequest{
+		Page:       1,
+		Limit:      10,
+		SkipTotal:  true,
+		SkipFacets: true,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+	assert.Len(t, res.Data, 1)
+	assert.Equal(t, entity.ProductStatusPublished, res.Data[0].Status)
+}
+
+func TestGetOwnProductsSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+
+	draft := newProduct(userID)
+	draft.PublishedAt = null.Time{}
+	scheduled := newProduct(userID)
+	scheduled.PublishedAt = null.TimeFrom(time.Now().Add(time.Hour))
+	published := newProduct(userID)
+	unlisted := newProduct(userID)
+	unlisted.DelistedAt = null.TimeFrom(time.Now())
+
+	// without a status the owner sees every product which is not deleted
+	expectGetProducts(dbMock,
+		regexp.QuoteMeta("WHERE p.deleted_at IS NULL AND p.user_id = $1 ORDER BY"),
+		[]any{userID, 10, 0}, draft, scheduled, published, unlisted,
+	)
+
+	res, err := svc.GetOwnProducts(context.Background(), userID, model.GetListOwnProductRequest{Page: 1, Limit: 10, SkipTotal: true})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	var statuses []string
+	for _, product := range res.Data {
+		statuses = append(statuses, product.Status)
+	}
+
+	assert.Equal(t, []string{
+		entity.ProductStatusDraft, entity.ProductStatusScheduled, entity.ProductStatusPublished, entity.ProductStatusUnlisted,
+	}, statuses)
+}
+
+func TestGetOwnProductsStatusSuccess(t *testing.T) {
+	userID := uuid.New()
+
+	draft := newProduct(userID)
+	draft.PublishedAt = null.Time{}
+	scheduled := newProduct(userID)
+	scheduled.PublishedAt = null.TimeFrom(time.Now().Add(time.Hour))
+	published := newProduct(userID)
+	unlisted := newProduct(userID)
+	unlisted.DelistedAt = null.TimeFrom(time.Now())
+
+	tests := []struct {
+		product entity.Product
+		status  string
+		where   string
+	}{
+		{product: draft, status: entity.ProductStatusDraft, where: "p.published_at IS NULL AND p.delisted_at IS NULL"},
+		{product: scheduled, status: entity.ProductStatusScheduled, where: "p.published_at > now() AND p.delisted_at IS NULL"},
+		{product: published, status: entity.ProductStatusPublished, where: "p.published_at <= now() AND p.delisted_at IS NULL"},
+		{product: unlisted, status: entity.ProductStatusUnlisted, where: "p.delisted_at IS NOT NULL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			dbMock := initPgMock(t)
+			svc := initDepMock(dbMock)
+
+			expectGetProducts(dbMock,
+				regexp.QuoteMeta("WHERE p.deleted_at IS NULL AND "+tt.where+" AND p.user_id = $1 ORDER BY"),
+				[]any{userID, 10, 0}, tt.product,
+			)
+
+			res, err := svc.GetOwnProducts(context.Background(), userID, model.GetListOwnProductRequest{
+				Status:    tt.status,
+				Page:      1,
+				Limit:     10,
+				SkipTotal: true,
+			})
+			assert.NoError(t, err)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+			assert.Len(t, res.Data, 1)
+			assert.Equal(t, tt.status, res.Data[0].Status)
+		})
+	}
+}
+
+func TestGetOwnProductsFailedInvalidStatus(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	_, err := svc.GetOwnProducts(context.Background(), uuid.New(), model.GetListOwnProductRequest{Status: "deleted", Page: 1, Limit: 10})
+	assert.Error(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPublishProductSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	product := newProduct(userID)
+	product.PublishedAt = null.Time{}
+
+	expectGetProduct(dbMock, product)
+	dbMock.ExpectBegin()
+	// a draft is published now
+	dbMock.ExpectExec(regexp.QuoteMeta("UPDATE products SET published_at = $1, delisted_at = NULL WHERE id = $2 AND deleted_at IS NULL")).
+		WithArgs(nearNow{}, product.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordProductAuditLog(dbMock, entity.AuditActionPublish, product.ID, entity.ProductStatusPublished)
+	dbMock.ExpectCommit()
+
+	err := svc.Publish(context.Background(), model.ProductPublishRequest{ID: product.ID, UserID: userID})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPublishProductScheduledSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	product := newProduct(userID)
+	product.DelistedAt = null.TimeFrom(time.Now())
+
+	publishAt := time.Now().Add(24 * time.Hour).In(time.FixedZone("WIB", 7*60*60))
+
+	expectGetProduct(dbMock, product)
+	dbMock.ExpectBegin()
+	// an unlisted product is listed again from publish_at
+	dbMock.ExpectExec(regexp.QuoteMeta("UPDATE products SET published_at = $1, delisted_at = NULL WHERE id = $2 AND deleted_at IS NULL")).
+		WithArgs(publishAt.UTC(), product.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordProductAuditLog(dbMock, entity.AuditActionPublish, product.ID, entity.ProductStatusScheduled)
+	dbMock.ExpectCommit()
+
+	err := svc.Publish(context.Background(), model.ProductPublishRequest{ID: product.ID, UserID: userID, PublishAt: &publishAt})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestPublishProductFailedNotOwner(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	product := newProduct(uuid.New())
+	product.PublishedAt = null.Time{}
+	expectGetProduct(dbMock, product)
+
+	err := svc.Publish(context.Background(), model.ProductPublishRequest{ID: product.ID, UserID: uuid.New()})
+	assert.ErrorIs(t, err, constant.ErrCannotUpdateNotOwner)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUnpublishProductSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	product := newProduct(userID)
+
+	expectGetProduct(dbMock, product)
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec(regexp.QuoteMeta("UPDATE products SET published_at = NULL, delisted_at = NULL WHERE id = $1 AND deleted_at IS NULL")).
+		WithArgs(product.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordProductAuditLog(dbMock, entity.AuditActionUnpublish, product.ID, entity.ProductStatusDraft)
+	dbMock.ExpectCommit()
+
+	err := svc.Unpublish(context.Background(), product.ID, userID)
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// newFileHeader returns data as a file of a multipart form, like an upload of the client.
+func newFileHeader(t *testing.T, filename string, contentType string, data []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="images"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = part.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return form.File["images"][0]
+}
+
+func encodeImage(t *testing.T, width int, height int, isJPEG bool) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x * y), A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+
+	var err error
+	if isJPEG {
+		err = jpeg.Encode(&buf, img, nil)
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// pngHeader returns the signature and header chunk of a png declaring width x height pixels, without pixel data.
+func pngHeader(width uint32, height uint32) []byte {
+	chunk := []byte("IHDR")
+	chunk = binary.BigEndian.AppendUint32(chunk, width)
+	chunk = binary.BigEndian.AppendUint32(chunk, height)
+	// 8 bit RGBA, deflate, no filter, no interlace
+	chunk = append(chunk, 8, 6, 0, 0, 0)
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, 13)
+	data = append(data, chunk...)
+	data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(chunk))
+
+	return data
+}
+
+func setProductImageConfig(t *testing.T, maxSize int, thumbnailSize int) {
+	cfg := config.GetConfig()
+	previous := cfg.ProductImage
+	t.Cleanup(func() { cfg.ProductImage = previous })
+
+	cfg.ProductImage.MaxSize = maxSize
+	cfg.ProductImage.ThumbnailSize = thumbnailSize
+}
+
+func TestProcessProductImageSuccess(t *testing.T) {
+	setProductImageConfig(t, 1<<20, 100)
+
+	productID := uuid.New()
+
+	tests := []struct {
+		name            string
+		data            []byte
+		contentType     string
+		ext             string
+		width           int
+		height          int
+		thumbnailWidth  int
+		thumbnailHeight int
+	}{
+		{name: "landscape png", data: encodeImage(t, 400, 200, false), contentType: "image/png", ext: ".png", width: 400, height: 200, thumbnailWidth: 100, thumbnailHeight: 50},
+		{name: "portrait jpeg", data: encodeImage(t, 300, 600, true), contentType: "image/jpeg", ext: ".jpg", width: 300, height: 600, thumbnailWidth: 50, thumbnailHeight: 100},
+		{name: "small png is not scaled up", data: encodeImage(t, 60, 40, false), contentType: "image/png", ext: ".png", width: 60, height: 40, thumbnailWidth: 60, thumbnailHeight: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the content type and name of the upload are ignored
+			file := newFileHeader(t, "image.bin", "application/octet-stream", tt.data)
+
+			res, err := processProductImage(productID, file)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.data, res.data)
+			assert.Equal(t, tt.contentType, res.image.ContentType)
+			assert.Equal(t, int64(len(tt.data)), res.image.Size)
+			assert.Equal(t, tt.width, res.image.Width)
+			assert.Equal(t, tt.height, res.image.Height)
+			assert.Equal(t, "products/"+productID.String()+"/"+res.image.ID.String()+tt.ext, res.image.ObjectKey)
+			assert.Equal(t, "products/"+productID.String()+"/"+res.image.ID.String()+"_thumb"+tt.ext, res.image.ThumbnailKey)
+
+			thumbnail, format, err := image.DecodeConfig(bytes.NewReader(res.thumbnail))
+			assert.NoError(t, err)
+			assert.Equal(t, strings.TrimPrefix(tt.contentType, "image/"), format)
+			assert.Equal(t, tt.thumbnailWidth, thumbnail.Width)
+			assert.Equal(t, tt.thumbnailHeight, thumbnail.Height)
+		})
+	}
+}
+
+func TestProcessProductImageFailedInvalidType(t *testing.T) {
+	setProductImageConfig(t, 1<<20, 100)
+
+	for name, data := range map[string][]byte{
+		"text renamed to png": []byte("name,price\nproduct 1,1000\n"),
+		"gif":                 []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"),
+		"truncated png":       encodeImage(t, 40, 40, false)[:60],
+		"png signature only":  []byte("\x89PNG\r\n\x1a\n"),
+	} {
+		file := newFileHeader(t, "photo.png", "image/png", data)
+
+		_, err := processProductImage(uuid.New(), file)
+		assert.ErrorIs(t, err, constant.ErrProductImageInvalidType, name)
+	}
+}
+
+func TestProcessProductImageFailedTooLarge(t *testing.T) {
+	data := encodeImage(t, 100, 100, false)
+	setProductImageConfig(t, len(data)-1, 100)
+
+	_, err := processProductImage(uuid.New(), newFileHeader(t, "photo.png", "image/png", data))
+	assert.ErrorIs(t, err, constant.ErrProductImageTooLarge)
+
+	// a file of exactly the max size is accepted
+	setProductImageConfig(t, len(data), 100)
+
+	_, err = processProductImage(uuid.New(), newFileHeader(t, "photo.png", "image/png", data))
+	assert.NoError(t, err)
+}
+
+func TestProcessProductImageFailedTooManyPixels(t *testing.T) {
+	setProductImageConfig(t, 1<<20, 100)
+
+	// the header alone declares more pixels than are decoded, the image data is never read
+	for _, size := range [][2]uint32{{10_000, 5_001}, {50_000, 50_000}} {
+		file := newFileHeader(t, "bomb.png", "image/png", pngHeader(size[0], size[1]))
+
+		_, err := processProductImage(uuid.New(), file)
+		assert.ErrorIs(t, err, constant.ErrProductImageTooLarge)
+	}
+}
+
+func TestImportCSVSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	createdID := uuid.New()
+	existing := newProduct(userID)
+	existing.SKU = null.StringFrom("OLD-1")
+	existing.Price = decimal.NewFromInt(1500)
+
+	file := strings.Join([]string{
+		"sku,name,description,price,stok,tags,unknown",
+		"NEW-1,New product,a new product,1000,5,,ignored",
+		"OLD-1,Old product,an existing product,2000,7,Game|voucher,ignored",
+		"BAD-1,Bad product,bad price and stok,abc,x,,",
+		"BAD-2,,missing name,1000,1,,",
+		`BAD-3,bad"quote,bare quote,1000,1,,`,
+	}, "\n")
+
+	// a new sku creates a product
+	dbMock.ExpectQuery("SELECT id FROM products WHERE (.+)").
+		WithArgs(userID, "NEW-1").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}))
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("INSERT INTO products (.+) VALUES (.+) RETURNING id").
+		WithArgs(userID, "New product", "a new product", 5, decimal.NewFromInt(1000), null.StringFrom("NEW-1"), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(createdID))
+	dbMock.ExpectQuery("INSERT INTO stock_movements (.+) VALUES (.+) RETURNING (.+)").
+		WithArgs(createdID, uuid.NullUUID{}, 5, entity.StockMovementReasonRestock, uuid.NullUUID{UUID: userID, Valid: true}, uuid.NullUUID{}, null.String{}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "stok_after", "created_at"}).AddRow(uuid.New(), 5, time.Now()))
+	expectCreatePriceHistory(dbMock)
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	// an existing sku updates the product
+	dbMock.ExpectQuery("SELECT id FROM products WHERE (.+)").
+		WithArgs(userID, "OLD-1").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(existing.ID))
+	expectGetProduct(dbMock, existing)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT stok FROM products WHERE (.+) FOR UPDATE").
+		WithArgs(existing.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"stok"}).AddRow(existing.Stok))
+	dbMock.ExpectQuery("UPDATE products SET (.+) RETURNING version").
+		WithArgs("Old product", "an existing product", 7, decimal.NewFromInt(2000), existing.ID, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"version"}).AddRow(2))
+	dbMock.ExpectQuery("INSERT INTO stock_movements (.+) VALUES (.+) RETURNING (.+)").
+		WithArgs(existing.ID, uuid.NullUUID{}, 7-existing.Stok, entity.StockMovementReasonAdjustment, uuid.NullUUID{UUID: userID, Valid: true}, uuid.NullUUID{}, null.String{}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "stok_after", "created_at"}).AddRow(uuid.New(), 7, time.Now()))
+	expectCreatePriceHistory(dbMock)
+	dbMock.ExpectExec("DELETE FROM product_tags WHERE (.+)").
+		WithArgs(existing.ID).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	dbMock.ExpectExec("INSERT INTO product_tags (.+)").
+		WithArgs(existing.ID, []string{"game", "voucher"}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	res, err := svc.Import(context.Background(), model.ProductImportRequest{
+		UserID: userID,
+		Format: model.ProductFileFormatCSV,
+		File:   strings.NewReader(file),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	assert.Equal(t, 5, res.Total)
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, 1, res.Updated)
+	assert.Equal(t, 3, res.Failed)
+	assert.Len(t, res.Rows, 5)
+
+	assert.Equal(t, model.ProductImportStatusCreated, res.Rows[0].Status)
+	assert.Equal(t, 2, res.Rows[0].Row)
+	assert.Equal(t, uuid.NullUUID{UUID: createdID, Valid: true}, res.Rows[0].ProductID)
+
+	assert.Equal(t, model.ProductImportStatusUpdated, res.Rows[1].Status)
+	assert.Equal(t, uuid.NullUUID{UUID: existing.ID, Valid: true}, res.Rows[1].ProductID)
+
+	assert.Equal(t, model.ProductImportStatusFailed, res.Rows[2].Status)
+	assert.Equal(t, "invalid row", res.Rows[2].Message)
+	assert.Len(t, res.Rows[2].Errors, 2)
+	assert.Equal(t, "price", res.Rows[2].Errors[0].Field)
+	assert.Equal(t, "stok", res.Rows[2].Errors[1].Field)
+
+	assert.Equal(t, model.ProductImportStatusFailed, res.Rows[3].Status)
+	assert.Equal(t, "invalid row", res.Rows[3].Message)
+	assert.NotEmpty(t, res.Rows[3].Errors)
+
+	assert.Equal(t, model.ProductImportStatusFailed, res.Rows[4].Status)
+	assert.Equal(t, 6, res.Rows[4].Row)
+	assert.NotEmpty(t, res.Rows[4].Message)
+	assert.Empty(t, res.Rows[4].Errors)
+}
+
+func TestImportNDJSONSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	createdID := uuid.New()
+
+	file := strings.Join([]string{
+		`{"sku":"NEW-1","name":"New product","description":"a new product","price":"1000","stok":0}`,
+		"",
+		`{"sku":"BAD-1",`,
+		`{"sku":"BAD-2","name":"Bad product","description":"negative stok","price":"1000","stok":-1}`,
+	}, "\n")
+
+	dbMock.ExpectQuery("SELECT id FROM products WHERE (.+)").
+		WithArgs(userID, "NEW-1").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}))
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("INSERT INTO products (.+) VALUES (.+) RETURNING id").
+		WithArgs(userID, "New product", "a new product", 0, decimal.NewFromInt(1000), null.StringFrom("NEW-1"), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(createdID))
+	// no stock movement is recorded for an empty stok
+	expectCreatePriceHistory(dbMock)
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	res, err := svc.Import(context.Background(), model.ProductImportRequest{
+		UserID: userID,
+		Format: model.ProductFileFormatNDJSON,
+		File:   strings.NewReader(file),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	assert.Equal(t, 3, res.Total)
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, 2, res.Failed)
+
+	// blank lines are skipped but still counted in the line numbers
+	assert.Equal(t, 1, res.Rows[0].Row)
+	assert.Equal(t, model.ProductImportStatusCreated, res.Rows[0].Status)
+
+	assert.Equal(t, 3, res.Rows[1].Row)
+	assert.Equal(t, model.ProductImportStatusFailed, res.Rows[1].Status)
+	assert.True(t, strings.HasPrefix(res.Rows[1].Message, "invalid json"))
+
+	assert.Equal(t, 4, res.Rows[2].Row)
+	assert.Equal(t, model.ProductImportStatusFailed, res.Rows[2].Status)
+	assert.Equal(t, "invalid row", res.Rows[2].Message)
+	assert.Equal(t, "stok", res.Rows[2].Errors[0].Field)
+}
+
+func TestExportRoundTripSuccess(t *testing.T) {
+	userID := uuid.New()
+
+	products := []entity.Product{
+		{ID: uuid.New(), SKU: null.StringFrom("ML-86"), Name: "86 Diamonds", Description: "mobile legends, 86 diamonds", Price: decimal.RequireFromString("20000.50"), Stok: 12, Tags: []string{"game", "voucher"}},
+		{ID: uuid.New(), SKU: null.StringFrom("FF-100"), Name: `Free "Fire"`, Description: "line 1\nline 2", Price: decimal.NewFromInt(15000), Stok: 0, Tags: []string{}},
+	}
+
+	// the rows an export is imported as, every exported value is imported again
+	want := []model.ProductImportRow{
+		{SKU: "ML-86", Name: "86 Diamonds", Description: "mobile legends, 86 diamonds", Price: decimal.RequireFromString("20000.5"), Stok: 12, Tags: []string{"game", "voucher"}},
+		{SKU: "FF-100", Name: `Free "Fire"`, Description: "line 1\nline 2", Price: decimal.NewFromInt(15000), Stok: 0, Tags: []string{}},
+	}
+
+	for _, format := range []string{model.ProductFileFormatCSV, model.ProductFileFormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			dbMock := initPgMock(t)
+			svc := initDepMock(dbMock)
+
+			rows := pgxmock.NewRows([]string{"id", "sku", "name", "description", "price", "stok", "tags"})
+			for _, v := range products {
+				rows.AddRow(v.ID, v.SKU, v.Name, v.Description, v.Price, v.Stok, v.Tags)
+			}
+
+			dbMock.ExpectQuery("SELECT (.+) FROM products p WHERE (.+)").
+				WithArgs(userID, uuid.NullUUID{}, productExportBatchSize).
+				WillReturnRows(rows)
+
+			stream, err := svc.Export(context.Background(), model.ProductExportRequest{UserID: userID, Format: format})
+			assert.NoError(t, err)
+
+			var buf bytes.Buffer
+			assert.NoError(t, stream(&buf))
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+
+			if format == model.ProductFileFormatCSV {
+				header, _, _ := strings.Cut(buf.String(), "\n")
+				assert.Equal(t, "id,sku,name,description,price,stok,tags", header)
+			}
+
+			lines, err := readProductImportLines(format, &buf)
+			assert.NoError(t, err)
+			assert.Len(t, lines, len(want))
+
+			for i, line := range lines {
+				assert.NoError(t, line.err)
+				assert.NoError(t, validation.Validate(line.row))
+				assert.Equal(t, want[i].SKU, line.row.SKU)
+				assert.Equal(t, want[i].Name, line.row.Name)
+				assert.Equal(t, want[i].Description, line.row.Description)
+				assert.True(t, want[i].Price.Equal(line.row.Price))
+				assert.Equal(t, want[i].Stok, line.row.Stok)
+				assert.Equal(t, want[i].Tags, line.row.Tags)
+			}
+		})
+	}
+}
+
+func TestImportFailedTooManyRows(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	file := strings.Repeat(`{"sku":"SKU"}`+"\n", config.GetConfig().ProductImport.MaxRows+1)
+
+	_, err := svc.Import(context.Background(), model.ProductImportRequest{
+		UserID: uuid.New(),
+		Format: model.ProductFileFormatNDJSON,
+		File:   strings.NewReader(file),
+	})
+	assert.ErrorIs(t, err, constant.ErrProductImportTooManyRows)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
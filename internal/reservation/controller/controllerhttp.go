@@ -0,0 +1,127 @@
+package reservationctrl
+
+import (
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/internal/reservation"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/exception"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ControllerHTTP struct {
+	svc reservation.Service
+}
+
+func New(svc reservation.Service) *ControllerHTTP {
+	return &ControllerHTTP{svc: svc}
+}
+
+// @Summary Create Reservation
+// @Description Reserve stok of products for a while, checkout with the reservation_id to buy them
+// @Tags Reservation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param body body model.ReservationCreateRequest true "Payload Create Reservation Request"
+// @Success 201 {object} pkgutil.HTTPResponse{data=model.ReservationResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/reservations [post]
+func (ctrl ControllerHTTP) Create(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ReservationCreateRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+	req.UserID = uuidUserID
+
+	res, err := ctrl.svc.Create(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusCreated).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusCreated,
+		Data: res,
+	})
+}
+
+// @Summary Get Reservation
+// @Description Get reservation of the user by id
+// @Tags Reservation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param reservationId path string true "Reservation ID"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.ReservationResponse}
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/reservations/:reservationId [get]
+func (ctrl ControllerHTTP) GetByID(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	uuidID, err := uuid.Parse(c.Params("reservationId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetByID(c.UserContext(), model.GetReservationRequest{ID: uuidID, UserID: uuidUserID})
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Cancel Reservation
+// @Description Release the reserved stok before the reservation expires
+// @Tags Reservation
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param reservationId path string true "Reservation ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/reservations/:reservationId [delete]
+func (ctrl ControllerHTTP) Cancel(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	uuidID, err := uuid.Parse(c.Params("reservationId"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.Cancel(c.UserContext(), model.GetReservationRequest{ID: uuidID, UserID: uuidUserID})
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
@@ -0,0 +1,21 @@
+package reservation
+
+import (
+	"context"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	reservationrepo "github.com/arfan21/vocagame/internal/reservation/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository interface {
+	Begin(ctx context.Context) (tx pgx.Tx, err error)
+	WithTx(tx pgx.Tx) *reservationrepo.Repository
+
+	Create(ctx context.Context, data entity.StockReservation, expiresIn int) (id uuid.UUID, err error)
+	CreateItems(ctx context.Context, data []entity.StockReservationItem) (err error)
+	GetByID(ctx context.Context, id, userID uuid.UUID, isForUpdate bool) (data entity.StockReservation, err error)
+	GetExpired(ctx context.Context, limit int) (result []entity.StockReservation, err error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status entity.StockReservationStatus, transactionID uuid.NullUUID) (err error)
+}
@@ -0,0 +1,233 @@
+package reservationrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/pkg/constant"
+	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository struct {
+	db    dbpostgres.Queryer
+	rawDb dbpostgres.Raw
+}
+
+func New(raw dbpostgres.Raw, queryer dbpostgres.Queryer) *Repository {
+	return &Repository{
+		db:    queryer,
+		rawDb: raw,
+	}
+}
+
+func (r Repository) Begin(ctx context.Context) (tx pgx.Tx, err error) {
+	return r.rawDb.Begin(ctx)
+}
+
+func (r Repository) WithTx(tx pgx.Tx) *Repository {
+	r.db = tx
+	return &r
+}
+
+// Create stores the reservation, expires_at is set by the database clock like the sweeper compares it.
+func (r Repository) Create(ctx context.Context, data entity.StockReservation, expiresIn int) (id uuid.UUID, err error) {
+	query := `
+		INSERT INTO stock_reservations (user_id, status, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3))
+		RETURNING id
+	`
+
+	err = r.db.QueryRow(ctx, query, data.UserID, data.Status, expiresIn).Scan(&id)
+	if err != nil {
+		err = fmt.Errorf("reservation.repository.Create: failed to create reservation: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) CreateItems(ctx context.Context, data []entity.StockReservationItem) (err error) {
+	columns := []string{"reservation_id", "product_id", "variant_id", "qty"}
+
+	rows := make([][]interface{}, len(data))
+	for i, item := range data {
+		rows[i] = []interface{}{item.ReservationID, item.ProductID, item.VariantID, item.Qty}
+	}
+
+	_, err = r.db.CopyFrom(ctx,
+		pgx.Identifier{entity.StockReservationItem{}.TableName()},
+		columns,
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		err = fmt.Errorf("reservation.repository.CreateItems: failed to create reservation items: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) GetByID(ctx context.Context, id, userID uuid.UUID, isForUpdate bool) (data entity.StockReservation, err error) {
+	query := `
+		SELECT id, user_id, status, expires_at, expires_at <= now() AS is_expired, transaction_id, created_at, updated_at
+		FROM stock_reservations
+		WHERE id = $1 AND user_id = $2
+	`
+
+	if isForUpdate {
+		query += " FOR UPDATE"
+	}
+
+	err = r.db.QueryRow(ctx, query, id, userID).Scan(
+		&data.ID,
+		&data.UserID,
+		&data.Status,
+		&data.ExpiresAt,
+		&data.IsExpired,
+		&data.TransactionID,
+		&data.CreatedAt,
+		&data.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrReservationNotFound
+		} else {
+			err = fmt.Errorf("reservation.repository.GetByID: failed to get reservation: %w", err)
+		}
+		return
+	}
+
+	items, err := r.getItems(ctx, []uuid.UUID{data.ID})
+	if err != nil {
+		err = fmt.Errorf("reservation.repository.GetByID: failed to get reservation items: %w", err)
+		return
+	}
+
+	data.Items = items[data.ID]
+
+	return
+}
+
+// GetExpired locks active reservations past their expiry, reservations locked by another sweeper are skipped.
+func (r Repository) GetExpired(ctx context.Context, limit int) (result []entity.StockReservation, err error) {
+	query := `
+		SELECT id, user_id, status, expires_at, transaction_id, created_at, updated_at
+		FROM stock_reservations
+		WHERE status = $1 AND expires_at <= now()
+		ORDER BY expires_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := r.db.Query(ctx, query, entity.StockReservationStatusActive, limit)
+	if err != nil {
+		err = fmt.Errorf("reservation.repository.GetExpired: failed to get expired reservations: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		data := entity.StockReservation{IsExpired: true}
+
+		err = rows.Scan(
+			&data.ID,
+			&data.UserID,
+			&data.Status,
+			&data.ExpiresAt,
+			&data.TransactionID,
+			&data.CreatedAt,
+			&data.UpdatedAt,
+		)
+		if err != nil {
+			err = fmt.Errorf("reservation.repository.GetExpired: failed to scan reservation: %w", err)
+			return
+		}
+
+		ids = append(ids, data.ID)
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("reservation.repository.GetExpired: failed after scan reservations: %w", rows.Err())
+		return
+	}
+
+	if len(result) == 0 {
+		return
+	}
+
+	items, err := r.getItems(ctx, ids)
+	if err != nil {
+		err = fmt.Errorf("reservation.repository.GetExpired: failed to get reservation items: %w", err)
+		return
+	}
+
+	for i := range result {
+		result[i].Items = items[result[i].ID]
+	}
+
+	return
+}
+
+func (r Repository) getItems(ctx context.Context, reservationIDs []uuid.UUID) (result map[uuid.UUID][]entity.StockReservationItem, err error) {
+	query := `
+		SELECT id, reservation_id, product_id, variant_id, qty
+		FROM stock_reservation_items
+		WHERE reservation_id = ANY($1)
+	`
+
+	rows, err := r.db.Query(ctx, query, reservationIDs)
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	result = make(map[uuid.UUID][]entity.StockReservationItem)
+
+	for rows.Next() {
+		var item entity.StockReservationItem
+
+		err = rows.Scan(
+			&item.ID,
+			&item.ReservationID,
+			&item.ProductID,
+			&item.VariantID,
+			&item.Qty,
+		)
+		if err != nil {
+			return
+		}
+
+		result[item.ReservationID] = append(result[item.ReservationID], item)
+	}
+
+	return result, rows.Err()
+}
+
+func (r Repository) UpdateStatus(ctx context.Context, id uuid.UUID, status entity.StockReservationStatus, transactionID uuid.NullUUID) (err error) {
+	query := `
+		UPDATE stock_reservations
+		SET status = $1, transaction_id = $2
+		WHERE id = $3
+	`
+
+	cmd, err := r.db.Exec(ctx, query, status, transactionID, id)
+	if err != nil {
+		err = fmt.Errorf("reservation.repository.UpdateStatus: failed to update reservation status: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("reservation.repository.UpdateStatus: nothing updated: %w", constant.ErrReservationNotFound)
+		return
+	}
+
+	return
+}
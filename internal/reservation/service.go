@@ -0,0 +1,21 @@
+package reservation
+
+import (
+	"context"
+
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Service interface {
+	WithTx(tx pgx.Tx) Service
+
+	Create(ctx context.Context, req model.ReservationCreateRequest) (res model.ReservationResponse, err error)
+	GetByID(ctx context.Context, req model.GetReservationRequest) (res model.ReservationResponse, err error)
+	Cancel(ctx context.Context, req model.GetReservationRequest) (err error)
+	GetActiveForCheckout(ctx context.Context, req model.GetReservationRequest) (res model.ReservationResponse, err error)
+	Consume(ctx context.Context, id uuid.UUID, transactionID uuid.UUID) (err error)
+	ReleaseExpired(ctx context.Context) (released int, err error)
+	RunSweeper(ctx context.Context)
+}
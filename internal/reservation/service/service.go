@@ -0,0 +1,451 @@
+package reservationsvc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arfan21/vocagame/config"
+	"github.com/arfan21/vocagame/internal/audit"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/internal/product"
+	"github.com/arfan21/vocagame/internal/reservation"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/logger"
+	"github.com/arfan21/vocagame/pkg/validation"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// sweepBatchSize is how many expired reservations are released per database transaction.
+const sweepBatchSize = 100
+
+type Service struct {
+	repo       reservation.Repository
+	productSvc product.Service
+	auditSvc   audit.Service
+}
+
+func New(repo reservation.Repository, productSvc product.Service, auditSvc audit.Service) *Service {
+	return &Service{repo: repo, productSvc: productSvc, auditSvc: auditSvc}
+}
+
+func (s Service) WithTx(tx pgx.Tx) reservation.Service {
+	s.repo = s.repo.WithTx(tx)
+	s.productSvc = s.productSvc.WithTx(tx)
+	s.auditSvc = s.auditSvc.WithTx(tx)
+	return &s
+}
+
+func toReservationResponse(data entity.StockReservation) model.ReservationResponse {
+	res := model.ReservationResponse{
+		ID:            data.ID,
+		Status:        string(data.Status),
+		ExpiresAt:     data.ExpiresAt,
+		TransactionID: data.TransactionID,
+		CreatedAt:     data.CreatedAt,
+		Items:         make([]model.ReservationItemResponse, len(data.Items)),
+	}
+
+	for i, item := range data.Items {
+		res.Items[i] = model.ReservationItemResponse{
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			Qty:       item.Qty,
+		}
+	}
+
+	return res
+}
+
+func toReserveStokRequests(items []entity.StockReservationItem) []model.ReserveStokRequest {
+	req := make([]model.ReserveStokRequest, len(items))
+	for i, item := range items {
+		req[i] = model.ReserveStokRequest{
+			ID:        item.ProductID,
+			VariantID: item.VariantID,
+			Qty:       item.Qty,
+		}
+	}
+
+	return req
+}
+
+// mergeItems sums the qty of items for the same product and variant.
+func mergeItems(items []model.ReservationItemRequest) []model.ReservationItemRequest {
+	type key struct {
+		productID uuid.UUID
+		variantID uuid.NullUUID
+	}
+
+	merged := make([]model.ReservationItemRequest, 0, len(items))
+	index := make(map[key]int)
+
+	for _, item := range items {
+		k := key{productID: item.ProductID, variantID: item.VariantID}
+		if i, ok := index[k]; ok {
+			merged[i].Qty += item.Qty
+			continue
+		}
+
+		index[k] = len(merged)
+		merged = append(merged, item)
+	}
+
+	return merged
+}
+
+// validateItems checks the items can be bought by the user, the stok itself is checked while reserving.
+func (s Service) validateItems(ctx context.Context, userID uuid.UUID, items []model.ReservationItemRequest) (err error) {
+	productIDs := make([]uuid.UUID, len(items))
+	var variantIDs []uuid.UUID
+
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+		if item.VariantID.Valid {
+			variantIDs = append(variantIDs, item.VariantID.UUID)
+		}
+	}
+
+	products, err := s.productSvc.GetByIDs(ctx, productIDs)
+	if err != nil {
+		err = fmt.Errorf("failed to get products : %w", err)
+		return
+	}
+
+	var variants map[uuid.UUID]model.ProductVariantResponse
+	if len(variantIDs) != 0 {
+		variants, err = s.productSvc.GetVariantsByIDs(ctx, variantIDs)
+		if err != nil {
+			err = fmt.Errorf("failed to get product variants : %w", err)
+			return
+		}
+	}
+
+	for _, item := range items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			errProductNotFound := *constant.ErrProductNotFound
+			errProductNotFound.Message = fmt.Sprintf("product with id '%s' not found", item.ProductID)
+			err = &errProductNotFound
+			return
+		}
+
+		if product.OwnerID == userID {
+			err = constant.ErrCannotPurchaseOwnProduct
+			return
+		}
+
+		if item.VariantID.Valid {
+			variant, ok := variants[item.VariantID.UUID]
+			if !ok || variant.ProductID != product.ID {
+				errVariantNotFound := *constant.ErrProductVariantNotFound
+				errVariantNotFound.Message = fmt.Sprintf("variant with id '%s' of product with name %s not found", item.VariantID.UUID, product.Name)
+				err = &errVariantNotFound
+				return
+			}
+		} else if product.HasVariants {
+			err = constant.ErrProductVariantRequired
+			return
+		}
+	}
+
+	return
+}
+
+func (s Service) Create(ctx context.Context, req model.ReservationCreateRequest) (res model.ReservationResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Create: failed to validate request : %w", err)
+		return
+	}
+
+	items := mergeItems(req.Items)
+
+	err = s.validateItems(ctx, req.UserID, items)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Create: %w", err)
+		return
+	}
+
+	expiresIn := config.GetConfig().Reservation.ExpireIn
+	if req.ExpiresInMinutes != 0 {
+		expiresIn = req.ExpiresInMinutes * 60
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Create: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("reservation.service.Create: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	data := entity.StockReservation{
+		UserID: req.UserID,
+		Status: entity.StockReservationStatusActive,
+		Items:  make([]entity.StockReservationItem, len(items)),
+	}
+
+	data.ID, err = s.repo.WithTx(tx).Create(ctx, data, expiresIn)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Create: failed to create reservation : %w", err)
+		return
+	}
+
+	for i, item := range items {
+		data.Items[i] = entity.StockReservationItem{
+			ReservationID: data.ID,
+			ProductID:     item.ProductID,
+			VariantID:     item.VariantID,
+			Qty:           item.Qty,
+		}
+	}
+
+	err = s.productSvc.WithTx(tx).BatchReserveStok(ctx, toReserveStokRequests(data.Items))
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Create: failed to reserve stok : %w", err)
+		return
+	}
+
+	err = s.repo.WithTx(tx).CreateItems(ctx, data.Items)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Create: failed to create reservation items : %w", err)
+		return
+	}
+
+	data, err = s.repo.WithTx(tx).GetByID(ctx, data.ID, req.UserID, false)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Create: failed to get reservation : %w", err)
+		return
+	}
+
+	res = toReservationResponse(data)
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntityReservation,
+		EntityID:   data.ID,
+		After:      res,
+	})
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Create: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) GetByID(ctx context.Context, req model.GetReservationRequest) (res model.ReservationResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.GetByID: failed to validate request : %w", err)
+		return
+	}
+
+	data, err := s.repo.GetByID(ctx, req.ID, req.UserID, false)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.GetByID: failed to get reservation : %w", err)
+		return
+	}
+
+	return toReservationResponse(data), nil
+}
+
+// Cancel releases the stok of an active reservation before it expires.
+func (s Service) Cancel(ctx context.Context, req model.GetReservationRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Cancel: failed to validate request : %w", err)
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Cancel: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("reservation.service.Cancel: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	data, err := s.repo.WithTx(tx).GetByID(ctx, req.ID, req.UserID, true)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Cancel: failed to get reservation : %w", err)
+		return
+	}
+
+	if data.Status != entity.StockReservationStatusActive {
+		err = constant.ErrReservationNotActive
+		return
+	}
+
+	err = s.release(ctx, tx, data, entity.StockReservationStatusReleased)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Cancel: %w", err)
+		return
+	}
+
+	return
+}
+
+// release gives back the stok of the reservation and closes it with status.
+func (s Service) release(ctx context.Context, tx pgx.Tx, data entity.StockReservation, status entity.StockReservationStatus) (err error) {
+	err = s.productSvc.WithTx(tx).BatchReleaseStok(ctx, toReserveStokRequests(data.Items))
+	if err != nil {
+		err = fmt.Errorf("failed to release stok : %w", err)
+		return
+	}
+
+	err = s.repo.WithTx(tx).UpdateStatus(ctx, data.ID, status, uuid.NullUUID{})
+	if err != nil {
+		err = fmt.Errorf("failed to update reservation status : %w", err)
+		return
+	}
+
+	before := toReservationResponse(data)
+	after := before
+	after.Status = string(status)
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityReservation,
+		EntityID:   data.ID,
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// GetActiveForCheckout locks the reservation until the checkout transaction ends, call it WithTx.
+func (s Service) GetActiveForCheckout(ctx context.Context, req model.GetReservationRequest) (res model.ReservationResponse, err error) {
+	data, err := s.repo.GetByID(ctx, req.ID, req.UserID, true)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.GetActiveForCheckout: failed to get reservation : %w", err)
+		return
+	}
+
+	if data.Status != entity.StockReservationStatusActive {
+		err = constant.ErrReservationNotActive
+		return
+	}
+
+	// the sweeper may not have released it yet
+	if data.IsExpired {
+		err = constant.ErrReservationExpired
+		return
+	}
+
+	return toReservationResponse(data), nil
+}
+
+// Consume closes the reservation once its stok is bought by the transaction, call it WithTx.
+func (s Service) Consume(ctx context.Context, id uuid.UUID, transactionID uuid.UUID) (err error) {
+	err = s.repo.UpdateStatus(ctx, id, entity.StockReservationStatusConsumed, uuid.NullUUID{UUID: transactionID, Valid: true})
+	if err != nil {
+		err = fmt.Errorf("reservation.service.Consume: failed to update reservation status : %w", err)
+		return
+	}
+
+	return
+}
+
+// ReleaseExpired releases the stok of every expired reservation in batches, it returns how many were released.
+func (s Service) ReleaseExpired(ctx context.Context) (released int, err error) {
+	for {
+		var count int
+		count, err = s.releaseExpiredBatch(ctx)
+		released += count
+		if err != nil || count < sweepBatchSize {
+			return
+		}
+	}
+}
+
+func (s Service) releaseExpiredBatch(ctx context.Context) (count int, err error) {
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.ReleaseExpired: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("reservation.service.ReleaseExpired: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	results, err := s.repo.WithTx(tx).GetExpired(ctx, sweepBatchSize)
+	if err != nil {
+		err = fmt.Errorf("reservation.service.ReleaseExpired: failed to get expired reservations : %w", err)
+		return
+	}
+
+	for _, data := range results {
+		err = s.release(ctx, tx, data, entity.StockReservationStatusExpired)
+		if err != nil {
+			err = fmt.Errorf("reservation.service.ReleaseExpired: %w", err)
+			return
+		}
+	}
+
+	return len(results), nil
+}
+
+// RunSweeper releases expired reservations every RESERVATION_SWEEP_INTERVAL until ctx is done.
+func (s Service) RunSweeper(ctx context.Context) {
+	interval := time.Duration(config.GetConfig().Reservation.SweepInterval) * time.Second
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.ReleaseExpired(ctx)
+			if err != nil {
+				logger.Log(ctx).Error().Err(err).Msg("reservation.service: failed to release expired reservations")
+				continue
+			}
+
+			if released != 0 {
+				logger.Log(ctx).Info().Int("released", released).Msg("reservation.service: released expired reservations")
+			}
+		}
+	}
+}
@@ -0,0 +1,410 @@
+package reservationsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	auditsvc "github.com/arfan21/vocagame/internal/audit/service"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	productrepo "github.com/arfan21/vocagame/internal/product/repository"
+	productsvc "github.com/arfan21/vocagame/internal/product/service"
+	reservationrepo "github.com/arfan21/vocagame/internal/reservation/repository"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	reservationColumns        = []string{"id", "user_id", "status", "expires_at", "is_expired", "transaction_id", "created_at", "updated_at"}
+	expiredReservationColumns = []string{"id", "user_id", "status", "expires_at", "transaction_id", "created_at", "updated_at"}
+	reservationItemColumns    = []string{"id", "reservation_id", "product_id", "variant_id", "qty"}
+)
+
+func initPgMock(t *testing.T) pgxmock.PgxPoolIface {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mock
+}
+
+func initDepMock(db pgxmock.PgxPoolIface) (svc *Service) {
+	auditSvc := auditsvc.New(auditrepo.New(db))
+	productSvc := productsvc.New(productrepo.New(db, db), auditSvc, nil)
+
+	svc = New(reservationrepo.New(db, db), productSvc, auditSvc)
+
+	return
+}
+
+func expectRecordAuditLog(dbMock pgxmock.PgxPoolIface) {
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func expectGetReservation(dbMock pgxmock.PgxPoolIface, data entity.StockReservation) {
+	dbMock.ExpectQuery("SELECT (.+) FROM stock_reservations WHERE id = (.+) FOR UPDATE").
+		WithArgs(data.ID, data.UserID).
+		WillReturnRows(
+			pgxmock.NewRows(reservationColumns).
+				AddRow(data.ID, data.UserID, data.Status, data.ExpiresAt, data.IsExpired, data.TransactionID, time.Now(), time.Now()),
+		)
+
+	rows := pgxmock.NewRows(reservationItemColumns)
+	for _, item := range data.Items {
+		rows.AddRow(uuid.New(), data.ID, item.ProductID, item.VariantID, item.Qty)
+	}
+
+	dbMock.ExpectQuery("SELECT (.+) FROM stock_reservation_items (.+)").
+		WithArgs([]uuid.UUID{data.ID}).
+		WillReturnRows(rows)
+}
+
+// expectReleaseStok expects the reserved stok of every item to be given back.
+func expectReleaseStok(dbMock pgxmock.PgxPoolIface, items []entity.StockReservationItem) {
+	for _, item := range items {
+		if item.VariantID.Valid {
+			dbMock.ExpectExec("UPDATE product_variants SET reserved_stok = (.+) WHERE (.+)").
+				WithArgs(item.Qty, item.VariantID.UUID).
+				WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		}
+
+		dbMock.ExpectExec("UPDATE products SET reserved_stok = (.+) WHERE (.+)").
+			WithArgs(item.Qty, item.ProductID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	}
+}
+
+func newReservation(userID uuid.UUID, status entity.StockReservationStatus) entity.StockReservation {
+	productID := uuid.New()
+
+	return entity.StockReservation{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    status,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+		Items: []entity.StockReservationItem{
+			{ProductID: productID, VariantID: uuid.NullUUID{UUID: uuid.New(), Valid: true}, Qty: 1},
+			{ProductID: productID, VariantID: uuid.NullUUID{UUID: uuid.New(), Valid: true}, Qty: 2},
+		},
+	}
+}
+
+func TestCreateReservationSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	reservationID := uuid.New()
+	productID := uuid.New()
+	variantSID := uuid.New()
+	variantMID := uuid.New()
+
+	// the items of variant S are merged, variant M stays a separate item of the same product
+	req := model.ReservationCreateRequest{
+		UserID: userID,
+		Items: []model.ReservationItemRequest{
+			{ProductID: productID, VariantID: uuid.NullUUID{UUID: variantSID, Valid: true}, Qty: 1},
+			{ProductID: productID, VariantID: uuid.NullUUID{UUID: variantMID, Valid: true}, Qty: 2},
+			{ProductID: productID, VariantID: uuid.NullUUID{UUID: variantSID, Valid: true}, Qty: 3},
+		},
+		ExpiresInMinutes: 5,
+	}
+
+	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
+		WithArgs([]uuid.UUID{productID, productID}).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(productID, "product 1", 10, decimal.NewFromInt(1000), uuid.New(), true),
+		)
+	dbMock.ExpectQuery("SELECT (.+) FROM product_variants (.+)").
+		WithArgs([]uuid.UUID{variantSID, variantMID}).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "product_id", "sku", "name", "price", "stok", "sold_count"}).
+				AddRow(variantSID, productID, "SKU-S", "S", decimal.NewFromInt(1000), 5, 0).
+				AddRow(variantMID, productID, "SKU-M", "M", decimal.NewFromInt(1500), 5, 0),
+		)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("INSERT INTO stock_reservations (.+) VALUES (.+) RETURNING id").
+		WithArgs(userID, entity.StockReservationStatusActive, 5*60).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(reservationID))
+
+	merged := []entity.StockReservationItem{
+		{ProductID: productID, VariantID: uuid.NullUUID{UUID: variantSID, Valid: true}, Qty: 4},
+		{ProductID: productID, VariantID: uuid.NullUUID{UUID: variantMID, Valid: true}, Qty: 2},
+	}
+	for _, item := range merged {
+		dbMock.ExpectExec("UPDATE product_variants SET reserved_stok = (.+) WHERE (.+)").
+			WithArgs(item.Qty, item.VariantID.UUID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		dbMock.ExpectExec("UPDATE products SET reserved_stok = (.+) WHERE (.+)").
+			WithArgs(item.Qty, productID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	}
+
+	dbMock.ExpectCopyFrom(pgx.Identifier{entity.StockReservationItem{}.TableName()}, []string{"reservation_id", "product_id", "variant_id", "qty"}).
+		WillReturnResult(2)
+
+	dbMock.ExpectQuery("SELECT (.+) FROM stock_reservations WHERE (.+)").
+		WithArgs(reservationID, userID).
+		WillReturnRows(
+			pgxmock.NewRows(reservationColumns).
+				AddRow(reservationID, userID, entity.StockReservationStatusActive, time.Now().Add(5*time.Minute), false, uuid.NullUUID{}, time.Now(), time.Now()),
+		)
+	itemRows := pgxmock.NewRows(reservationItemColumns)
+	for _, item := range merged {
+		itemRows.AddRow(uuid.New(), reservationID, item.ProductID, item.VariantID, item.Qty)
+	}
+	dbMock.ExpectQuery("SELECT (.+) FROM stock_reservation_items (.+)").
+		WithArgs([]uuid.UUID{reservationID}).
+		WillReturnRows(itemRows)
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	res, err := svc.Create(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, reservationID, res.ID)
+	assert.Equal(t, string(entity.StockReservationStatusActive), res.Status)
+	assert.Len(t, res.Items, 2)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateReservationFailedStokNotEnough(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	userID := uuid.New()
+	reservationID := uuid.New()
+	productID := uuid.New()
+
+	req := model.ReservationCreateRequest{
+		UserID:           userID,
+		Items:            []model.ReservationItemRequest{{ProductID: productID, Qty: 3}},
+		ExpiresInMinutes: 5,
+	}
+
+	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
+		WithArgs([]uuid.UUID{productID}).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(productID, "product 1", 2, decimal.NewFromInt(1000), uuid.New(), false),
+		)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("INSERT INTO stock_reservations (.+) VALUES (.+) RETURNING id").
+		WithArgs(userID, entity.StockReservationStatusActive, 5*60).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(reservationID))
+	dbMock.ExpectExec("UPDATE products SET reserved_stok = (.+) WHERE (.+)").
+		WithArgs(3, productID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	dbMock.ExpectRollback()
+
+	_, err := svc.Create(context.Background(), req)
+	assert.ErrorIs(t, err, constant.ErrProductNotFoundOrStok)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCancelReservationSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	data := newReservation(uuid.New(), entity.StockReservationStatusActive)
+
+	dbMock.ExpectBegin()
+	expectGetReservation(dbMock, data)
+	expectReleaseStok(dbMock, data.Items)
+	dbMock.ExpectExec("UPDATE stock_reservations SET status = (.+) WHERE (.+)").
+		WithArgs(entity.StockReservationStatusReleased, uuid.NullUUID{}, data.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	err := svc.Cancel(context.Background(), model.GetReservationRequest{ID: data.ID, UserID: data.UserID})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCancelReservationFailedNotActive(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	data := newReservation(uuid.New(), entity.StockReservationStatusConsumed)
+
+	dbMock.ExpectBegin()
+	expectGetReservation(dbMock, data)
+	dbMock.ExpectRollback()
+
+	err := svc.Cancel(context.Background(), model.GetReservationRequest{ID: data.ID, UserID: data.UserID})
+	assert.ErrorIs(t, err, constant.ErrReservationNotActive)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestGetActiveForCheckoutSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	data := newReservation(uuid.New(), entity.StockReservationStatusActive)
+	expectGetReservation(dbMock, data)
+
+	res, err := svc.GetActiveForCheckout(context.Background(), model.GetReservationRequest{ID: data.ID, UserID: data.UserID})
+	assert.NoError(t, err)
+	assert.Len(t, res.Items, 2)
+	assert.Equal(t, res.Items[0].ProductID, res.Items[1].ProductID)
+}
+
+func TestGetActiveForCheckoutFailedNotActive(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	for _, status := range []entity.StockReservationStatus{
+		entity.StockReservationStatusConsumed,
+		entity.StockReservationStatusReleased,
+		entity.StockReservationStatusExpired,
+	} {
+		data := newReservation(uuid.New(), status)
+		expectGetReservation(dbMock, data)
+
+		_, err := svc.GetActiveForCheckout(context.Background(), model.GetReservationRequest{ID: data.ID, UserID: data.UserID})
+		assert.ErrorIs(t, err, constant.ErrReservationNotActive, status)
+	}
+}
+
+func TestGetActiveForCheckoutFailedExpired(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	// the sweeper has not released the reservation yet
+	data := newReservation(uuid.New(), entity.StockReservationStatusActive)
+	data.ExpiresAt = time.Now().Add(-time.Minute)
+	data.IsExpired = true
+	expectGetReservation(dbMock, data)
+
+	_, err := svc.GetActiveForCheckout(context.Background(), model.GetReservationRequest{ID: data.ID, UserID: data.UserID})
+	assert.ErrorIs(t, err, constant.ErrReservationExpired)
+}
+
+func TestConsumeReservationSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	id := uuid.New()
+	transactionID := uuid.New()
+
+	dbMock.ExpectExec("UPDATE stock_reservations SET status = (.+) WHERE (.+)").
+		WithArgs(entity.StockReservationStatusConsumed, uuid.NullUUID{UUID: transactionID, Valid: true}, id).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err := svc.Consume(context.Background(), id, transactionID)
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestConsumeReservationFailedNotFound(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	id := uuid.New()
+	transactionID := uuid.New()
+
+	dbMock.ExpectExec("UPDATE stock_reservations SET status = (.+) WHERE (.+)").
+		WithArgs(entity.StockReservationStatusConsumed, uuid.NullUUID{UUID: transactionID, Valid: true}, id).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err := svc.Consume(context.Background(), id, transactionID)
+	assert.ErrorIs(t, err, constant.ErrReservationNotFound)
+}
+
+// expectReleaseExpiredBatch expects a batch of count expired reservations to be released in one transaction.
+func expectReleaseExpiredBatch(dbMock pgxmock.PgxPoolIface, count int) {
+	dbMock.ExpectBegin()
+
+	rows := pgxmock.NewRows(expiredReservationColumns)
+	itemRows := pgxmock.NewRows(reservationItemColumns)
+	batch := make([]entity.StockReservation, count)
+	ids := make([]uuid.UUID, count)
+	for i := range batch {
+		batch[i] = newReservation(uuid.New(), entity.StockReservationStatusActive)
+		batch[i].Items = batch[i].Items[:1]
+		ids[i] = batch[i].ID
+
+		rows.AddRow(batch[i].ID, batch[i].UserID, batch[i].Status, time.Now().Add(-time.Minute), uuid.NullUUID{}, time.Now(), time.Now())
+		itemRows.AddRow(uuid.New(), batch[i].ID, batch[i].Items[0].ProductID, batch[i].Items[0].VariantID, batch[i].Items[0].Qty)
+	}
+
+	dbMock.ExpectQuery("SELECT (.+) FROM stock_reservations (.+) FOR UPDATE SKIP LOCKED").
+		WithArgs(entity.StockReservationStatusActive, sweepBatchSize).
+		WillReturnRows(rows)
+
+	if count != 0 {
+		dbMock.ExpectQuery("SELECT (.+) FROM stock_reservation_items (.+)").
+			WithArgs(ids).
+			WillReturnRows(itemRows)
+	}
+
+	for _, data := range batch {
+		expectReleaseStok(dbMock, data.Items)
+		dbMock.ExpectExec("UPDATE stock_reservations SET status = (.+) WHERE (.+)").
+			WithArgs(entity.StockReservationStatusExpired, uuid.NullUUID{}, data.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		expectRecordAuditLog(dbMock)
+	}
+
+	dbMock.ExpectCommit()
+}
+
+func TestReleaseExpiredBatchesSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	// a full batch is followed by another batch, a partial batch ends the sweep
+	expectReleaseExpiredBatch(dbMock, sweepBatchSize)
+	expectReleaseExpiredBatch(dbMock, 3)
+
+	released, err := svc.ReleaseExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, sweepBatchSize+3, released)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReleaseExpiredEmptySuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	expectReleaseExpiredBatch(dbMock, 0)
+
+	released, err := svc.ReleaseExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReleaseExpiredFailedBatchRolledBack(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	expectReleaseExpiredBatch(dbMock, sweepBatchSize)
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT (.+) FROM stock_reservations (.+) FOR UPDATE SKIP LOCKED").
+		WithArgs(entity.StockReservationStatusActive, sweepBatchSize).
+		WillReturnError(pgx.ErrTxClosed)
+	dbMock.ExpectRollback()
+
+	// the committed batch stays released
+	released, err := svc.ReleaseExpired(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, sweepBatchSize, released)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -0,0 +1,139 @@
+package reviewctrl
+
+import (
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/internal/review"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/exception"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ControllerHTTP struct {
+	svc review.Service
+}
+
+func New(svc review.Service) *ControllerHTTP {
+	return &ControllerHTTP{svc: svc}
+}
+
+// @Summary Create Product Review
+// @Description Rate and review a purchased line of a completed transaction, every line can be reviewed once
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param body body model.ProductReviewCreateRequest true "Payload Create Product Review Request"
+// @Success 201 {object} pkgutil.HTTPResponse{data=model.ProductReviewResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/reviews [post]
+func (ctrl ControllerHTTP) Create(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductReviewCreateRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.Create(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusCreated).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusCreated,
+		Data: res,
+	})
+}
+
+// @Summary Get Product Reviews
+// @Description Get reviews of the product
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Param page query string true "Page"
+// @Param limit query string true "Limit"
+// @Param rating query int false "Only reviews with this rating"
+// @Param sort query string false "Sort, default newest" Enums(newest, oldest, rating_desc, rating_asc)
+// @Success 200 {object} pkgutil.HTTPResponse{data=pkgutil.PaginationResponse[[]model.ProductReviewResponse]{data=[]model.ProductReviewResponse}}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/reviews [get]
+func (ctrl ControllerHTTP) GetList(c *fiber.Ctx) error {
+	reqQuery := model.GetListProductReviewRequest{}
+	err := c.QueryParser(&reqQuery)
+	exception.PanicIfNeeded(err)
+
+	reqQuery.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetList(c.UserContext(), reqQuery)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Reply Product Review
+// @Description Reply to a review of the product, seller only, a review can be replied once
+// @Tags Review
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param productId path string true "Product ID"
+// @Param reviewId path string true "Review ID"
+// @Param body body model.ProductReviewReplyRequest true "Payload Reply Product Review Request"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.ProductReviewResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/products/:productId/reviews/:reviewId/reply [post]
+func (ctrl ControllerHTTP) Reply(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.ProductReviewReplyRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	req.ProductID, err = uuid.Parse(c.Params("productId"))
+	exception.PanicIfNeeded(err)
+
+	req.ReviewID, err = uuid.Parse(c.Params("reviewId"))
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.Reply(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
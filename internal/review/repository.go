@@ -0,0 +1,22 @@
+package review
+
+import (
+	"context"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	reviewrepo "github.com/arfan21/vocagame/internal/review/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type Repository interface {
+	Begin(ctx context.Context) (tx pgx.Tx, err error)
+	WithTx(tx pgx.Tx) *reviewrepo.Repository
+
+	GetPurchase(ctx context.Context, transactionDetailID uuid.UUID, userID uuid.UUID) (data entity.ReviewPurchase, err error)
+	Create(ctx context.Context, data entity.ProductReview) (result entity.ProductReview, err error)
+	GetByID(ctx context.Context, productID uuid.UUID, id uuid.UUID) (data entity.ProductReview, err error)
+	SetReply(ctx context.Context, id uuid.UUID, reply string) (err error)
+	GetList(ctx context.Context, filter entity.ListProductReviewFilter) (result []entity.ProductReview, err error)
+	GetTotal(ctx context.Context, filter entity.ListProductReviewFilter) (result int, err error)
+}
@@ -0,0 +1,288 @@
+package reviewrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/pkg/constant"
+	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type Repository struct {
+	db    dbpostgres.Queryer
+	rawDb dbpostgres.Raw
+}
+
+func New(raw dbpostgres.Raw, queryer dbpostgres.Queryer) *Repository {
+	return &Repository{
+		db:    queryer,
+		rawDb: raw,
+	}
+}
+
+func (r Repository) Begin(ctx context.Context) (tx pgx.Tx, err error) {
+	return r.rawDb.Begin(ctx)
+}
+
+func (r Repository) WithTx(tx pgx.Tx) *Repository {
+	r.db = tx
+	return &r
+}
+
+// GetPurchase returns the purchased line of the buyer, lines of other users are not found.
+func (r Repository) GetPurchase(ctx context.Context, transactionDetailID uuid.UUID, userID uuid.UUID) (data entity.ReviewPurchase, err error) {
+	query := `
+		SELECT td.id, td.product_id, t.status
+		FROM transaction_details td
+		JOIN transactions t ON t.id = td.transaction_id
+		WHERE td.id = $1 AND t.user_id = $2
+	`
+
+	err = r.db.QueryRow(ctx, query, transactionDetailID, userID).Scan(
+		&data.TransactionDetailID,
+		&data.ProductID,
+		&data.Status,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrReviewPurchaseNotFound
+		}
+
+		err = fmt.Errorf("review.repository.GetPurchase: failed to get purchase: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) Create(ctx context.Context, data entity.ProductReview) (result entity.ProductReview, err error) {
+	query := `
+		INSERT INTO product_reviews (product_id, transaction_detail_id, user_id, rating, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	result = data
+	err = r.db.QueryRow(ctx, query,
+		data.ProductID,
+		data.TransactionDetailID,
+		data.UserID,
+		data.Rating,
+		data.Comment,
+	).Scan(&result.ID, &result.CreatedAt, &result.UpdatedAt)
+	if err != nil {
+		var pgxError *pgconn.PgError
+		if errors.As(err, &pgxError) {
+			if pgxError.Code == constant.ErrSQLUniqueViolation {
+				err = constant.ErrReviewAlreadyExists
+			}
+		}
+
+		err = fmt.Errorf("review.repository.Create: failed to create review: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r Repository) GetByID(ctx context.Context, productID uuid.UUID, id uuid.UUID) (data entity.ProductReview, err error) {
+	query := `
+		SELECT
+			pr.id,
+			pr.product_id,
+			pr.transaction_detail_id,
+			pr.user_id,
+			pr.rating,
+			pr.comment,
+			pr.reply,
+			pr.replied_at,
+			pr.created_at,
+			pr.updated_at,
+			u.fullname,
+			p.user_id AS seller_id
+		FROM
+			product_reviews pr
+			JOIN users u ON u.id = pr.user_id
+			JOIN products p ON p.id = pr.product_id
+		WHERE pr.id = $1 AND pr.product_id = $2
+	`
+
+	err = r.db.QueryRow(ctx, query, id, productID).Scan(
+		&data.ID,
+		&data.ProductID,
+		&data.TransactionDetailID,
+		&data.UserID,
+		&data.Rating,
+		&data.Comment,
+		&data.Reply,
+		&data.RepliedAt,
+		&data.CreatedAt,
+		&data.UpdatedAt,
+		&data.User.Fullname,
+		&data.SellerID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrReviewNotFound
+		}
+
+		err = fmt.Errorf("review.repository.GetByID: failed to get review: %w", err)
+		return
+	}
+
+	data.User.ID = data.UserID
+
+	return
+}
+
+// SetReply sets the reply of the seller, a review can only be replied once.
+func (r Repository) SetReply(ctx context.Context, id uuid.UUID, reply string) (err error) {
+	query := `
+		UPDATE product_reviews
+		SET reply = $1, replied_at = now()
+		WHERE id = $2 AND reply IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, reply, id)
+	if err != nil {
+		err = fmt.Errorf("review.repository.SetReply: failed to set reply: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("review.repository.SetReply: nothing updated: %w", constant.ErrReviewAlreadyReplied)
+		return
+	}
+
+	return
+}
+
+func (r Repository) queryRowsWithFilter(ctx context.Context, query string, filter entity.ListProductReviewFilter, disableOffset bool) (rows pgx.Rows, err error) {
+	filterArgs := []any{filter.ProductID}
+	query += "WHERE pr.product_id = $1 "
+
+	if filter.Rating != 0 {
+		filterArgs = append(filterArgs, filter.Rating)
+		query += "AND pr.rating = $" + strconv.Itoa(len(filterArgs)) + " "
+	}
+
+	if !disableOffset {
+		switch filter.Sort {
+		case entity.ProductReviewSortOldest:
+			query += "ORDER BY pr.created_at, pr.id "
+		case entity.ProductReviewSortRatingDesc:
+			query += "ORDER BY pr.rating DESC, pr.created_at DESC, pr.id "
+		case entity.ProductReviewSortRatingAsc:
+			query += "ORDER BY pr.rating, pr.created_at DESC, pr.id "
+		default:
+			query += "ORDER BY pr.created_at DESC, pr.id "
+		}
+
+		filterArgs = append(filterArgs, filter.Limit)
+		query += "LIMIT $" + strconv.Itoa(len(filterArgs)) + " "
+
+		offset := (filter.Page - 1) * filter.Limit
+		filterArgs = append(filterArgs, offset)
+		query += "OFFSET $" + strconv.Itoa(len(filterArgs)) + " "
+	}
+
+	return r.db.Query(ctx, query, filterArgs...)
+}
+
+func (r Repository) GetList(ctx context.Context, filter entity.ListProductReviewFilter) (result []entity.ProductReview, err error) {
+	query := `
+		SELECT
+			pr.id,
+			pr.product_id,
+			pr.transaction_detail_id,
+			pr.user_id,
+			pr.rating,
+			pr.comment,
+			pr.reply,
+			pr.replied_at,
+			pr.created_at,
+			pr.updated_at,
+			u.fullname
+		FROM
+			product_reviews pr
+			JOIN users u ON u.id = pr.user_id
+	`
+
+	rows, err := r.queryRowsWithFilter(ctx, query, filter, false)
+	if err != nil {
+		err = fmt.Errorf("review.repository.GetList: failed to get reviews: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var data entity.ProductReview
+
+		err = rows.Scan(
+			&data.ID,
+			&data.ProductID,
+			&data.TransactionDetailID,
+			&data.UserID,
+			&data.Rating,
+			&data.Comment,
+			&data.Reply,
+			&data.RepliedAt,
+			&data.CreatedAt,
+			&data.UpdatedAt,
+			&data.User.Fullname,
+		)
+		if err != nil {
+			err = fmt.Errorf("review.repository.GetList: failed to scan review: %w", err)
+			return
+		}
+
+		data.User.ID = data.UserID
+		result = append(result, data)
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("review.repository.GetList: failed after scan reviews: %w", rows.Err())
+		return
+	}
+
+	return
+}
+
+func (r Repository) GetTotal(ctx context.Context, filter entity.ListProductReviewFilter) (result int, err error) {
+	query := `
+		SELECT
+			COUNT(pr.id)
+		FROM
+			product_reviews pr
+	`
+
+	rows, err := r.queryRowsWithFilter(ctx, query, filter, true)
+	if err != nil {
+		err = fmt.Errorf("review.repository.GetTotal: failed to get total review: %w", err)
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&result)
+		if err != nil {
+			err = fmt.Errorf("review.repository.GetTotal: failed to scan total review: %w", err)
+			return
+		}
+	}
+
+	if rows.Err() != nil {
+		err = fmt.Errorf("review.repository.GetTotal: failed after scan total review: %w", rows.Err())
+		return
+	}
+
+	return
+}
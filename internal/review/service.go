@@ -0,0 +1,14 @@
+package review
+
+import (
+	"context"
+
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+)
+
+type Service interface {
+	Create(ctx context.Context, req model.ProductReviewCreateRequest) (res model.ProductReviewResponse, err error)
+	Reply(ctx context.Context, req model.ProductReviewReplyRequest) (res model.ProductReviewResponse, err error)
+	GetList(ctx context.Context, req model.GetListProductReviewRequest) (res pkgutil.PaginationResponse[[]model.ProductReviewResponse], err error)
+}
@@ -0,0 +1,241 @@
+package reviewsvc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arfan21/vocagame/internal/audit"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/internal/product"
+	"github.com/arfan21/vocagame/internal/review"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/pkgutil"
+	"github.com/arfan21/vocagame/pkg/validation"
+)
+
+type Service struct {
+	repo       review.Repository
+	productSvc product.Service
+	auditSvc   audit.Service
+}
+
+func New(repo review.Repository, productSvc product.Service, auditSvc audit.Service) *Service {
+	return &Service{repo: repo, productSvc: productSvc, auditSvc: auditSvc}
+}
+
+func toProductReviewResponse(data entity.ProductReview) model.ProductReviewResponse {
+	return model.ProductReviewResponse{
+		ID:        data.ID,
+		ProductID: data.ProductID,
+		UserID:    data.UserID,
+		UserName:  data.User.Fullname,
+		Rating:    data.Rating,
+		Comment:   data.Comment,
+		Reply:     data.Reply,
+		RepliedAt: data.RepliedAt,
+		CreatedAt: data.CreatedAt,
+	}
+}
+
+// Create reviews a purchased line of the buyer, the transaction of the line must be completed.
+func (s Service) Create(ctx context.Context, req model.ProductReviewCreateRequest) (res model.ProductReviewResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("review.service.Create: failed to validate request : %w", err)
+		return
+	}
+
+	purchase, err := s.repo.GetPurchase(ctx, req.TransactionDetailID, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("review.service.Create: failed to get purchase : %w", err)
+		return
+	}
+
+	if purchase.ProductID != req.ProductID {
+		err = fmt.Errorf("review.service.Create: purchase of another product : %w", constant.ErrReviewPurchaseNotFound)
+		return
+	}
+
+	if purchase.Status != entity.TransactionStatusCompleted {
+		err = constant.ErrReviewPurchaseNotCompleted
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("review.service.Create: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("review.service.Create: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	data, err := s.repo.WithTx(tx).Create(ctx, entity.ProductReview{
+		ProductID:           req.ProductID,
+		TransactionDetailID: req.TransactionDetailID,
+		UserID:              req.UserID,
+		Rating:              req.Rating,
+		Comment:             req.Comment,
+	})
+	if err != nil {
+		err = fmt.Errorf("review.service.Create: failed to create review : %w", err)
+		return
+	}
+
+	err = s.productSvc.WithTx(tx).AddRating(ctx, req.ProductID, req.Rating)
+	if err != nil {
+		err = fmt.Errorf("review.service.Create: failed to add rating : %w", err)
+		return
+	}
+
+	data, err = s.repo.WithTx(tx).GetByID(ctx, data.ProductID, data.ID)
+	if err != nil {
+		err = fmt.Errorf("review.service.Create: failed to get review : %w", err)
+		return
+	}
+
+	res = toProductReviewResponse(data)
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntityReview,
+		EntityID:   data.ID,
+		After:      res,
+	})
+	if err != nil {
+		err = fmt.Errorf("review.service.Create: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+// Reply sets the reply of the seller of the product, a review can only be replied once.
+func (s Service) Reply(ctx context.Context, req model.ProductReviewReplyRequest) (res model.ProductReviewResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("review.service.Reply: failed to validate request : %w", err)
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("review.service.Reply: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("review.service.Reply: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	data, err := s.repo.WithTx(tx).GetByID(ctx, req.ProductID, req.ReviewID)
+	if err != nil {
+		err = fmt.Errorf("review.service.Reply: failed to get review : %w", err)
+		return
+	}
+
+	if data.SellerID != req.UserID {
+		err = constant.ErrCannotReplyNotOwner
+		return
+	}
+
+	before := toProductReviewResponse(data)
+
+	err = s.repo.WithTx(tx).SetReply(ctx, data.ID, req.Reply)
+	if err != nil {
+		err = fmt.Errorf("review.service.Reply: failed to set reply : %w", err)
+		return
+	}
+
+	data, err = s.repo.WithTx(tx).GetByID(ctx, req.ProductID, req.ReviewID)
+	if err != nil {
+		err = fmt.Errorf("review.service.Reply: failed to get review : %w", err)
+		return
+	}
+
+	res = toProductReviewResponse(data)
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityReview,
+		EntityID:   data.ID,
+		Before:     before,
+		After:      res,
+	})
+	if err != nil {
+		err = fmt.Errorf("review.service.Reply: failed to record audit log : %w", err)
+		return
+	}
+
+	return
+}
+
+func (s Service) GetList(ctx context.Context, req model.GetListProductReviewRequest) (res pkgutil.PaginationResponse[[]model.ProductReviewResponse], err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("review.service.GetList: failed to validate request : %w", err)
+		return
+	}
+
+	filter := entity.ListProductReviewFilter{
+		ProductID: req.ProductID,
+		Rating:    req.Rating,
+		Sort:      req.Sort,
+		Page:      req.Page,
+		Limit:     req.Limit,
+	}
+
+	results, err := s.repo.GetList(ctx, filter)
+	if err != nil {
+		err = fmt.Errorf("review.service.GetList: failed to get reviews from db : %w", err)
+		return
+	}
+
+	resData := make([]model.ProductReviewResponse, len(results))
+	for i, result := range results {
+		resData[i] = toProductReviewResponse(result)
+	}
+
+	total, err := s.repo.GetTotal(ctx, filter)
+	if err != nil {
+		err = fmt.Errorf("review.service.GetList: failed to get total review from db : %w", err)
+		return
+	}
+
+	totalPage := 0
+	if total%filter.Limit != 0 {
+		totalPage = total/filter.Limit + 1
+	} else {
+		totalPage = total / filter.Limit
+	}
+
+	res = pkgutil.PaginationResponse[[]model.ProductReviewResponse]{
+		TotalData: total,
+		TotalPage: totalPage,
+		Page:      filter.Page,
+		Limit:     filter.Limit,
+		Data:      resData,
+	}
+
+	return
+}
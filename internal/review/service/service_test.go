@@ -0,0 +1,272 @@
+package reviewsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	auditsvc "github.com/arfan21/vocagame/internal/audit/service"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	productrepo "github.com/arfan21/vocagame/internal/product/repository"
+	productsvc "github.com/arfan21/vocagame/internal/product/service"
+	reviewrepo "github.com/arfan21/vocagame/internal/review/repository"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+var reviewColumns = []string{
+	"id", "product_id", "transaction_detail_id", "user_id", "rating", "comment", "reply", "replied_at",
+	"created_at", "updated_at", "fullname", "seller_id",
+}
+
+func initPgMock(t *testing.T) pgxmock.PgxPoolIface {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mock
+}
+
+func initDepMock(db pgxmock.PgxPoolIface) (svc *Service) {
+	auditSvc := auditsvc.New(auditrepo.New(db))
+	productSvc := productsvc.New(productrepo.New(db, db), auditSvc, nil)
+
+	svc = New(reviewrepo.New(db, db), productSvc, auditSvc)
+
+	return
+}
+
+func expectRecordAuditLog(dbMock pgxmock.PgxPoolIface) {
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func expectGetPurchase(dbMock pgxmock.PgxPoolIface, req model.ProductReviewCreateRequest, productID uuid.UUID, status entity.TransactionStatus) {
+	dbMock.ExpectQuery("SELECT (.+) FROM transaction_details td JOIN transactions t (.+)").
+		WithArgs(req.TransactionDetailID, req.UserID).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "product_id", "status"}).
+				AddRow(req.TransactionDetailID, productID, status),
+		)
+}
+
+func expectGetReview(dbMock pgxmock.PgxPoolIface, data entity.ProductReview) {
+	dbMock.ExpectQuery("SELECT (.+) FROM product_reviews pr (.+)").
+		WithArgs(data.ID, data.ProductID).
+		WillReturnRows(
+			pgxmock.NewRows(reviewColumns).
+				AddRow(
+					data.ID, data.ProductID, data.TransactionDetailID, data.UserID, data.Rating, data.Comment, data.Reply, data.RepliedAt,
+					data.CreatedAt, data.UpdatedAt, "buyer", data.SellerID,
+				),
+		)
+}
+
+func newCreateRequest() model.ProductReviewCreateRequest {
+	return model.ProductReviewCreateRequest{
+		ProductID:           uuid.New(),
+		UserID:              uuid.New(),
+		TransactionDetailID: uuid.New(),
+		Rating:              4,
+		Comment:             "fast delivery",
+	}
+}
+
+func TestCreateReviewSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	req := newCreateRequest()
+	review := entity.ProductReview{
+		ID:                  uuid.New(),
+		ProductID:           req.ProductID,
+		TransactionDetailID: req.TransactionDetailID,
+		UserID:              req.UserID,
+		Rating:              req.Rating,
+		Comment:             req.Comment,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+		SellerID:            uuid.New(),
+	}
+
+	expectGetPurchase(dbMock, req, req.ProductID, entity.TransactionStatusCompleted)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("INSERT INTO product_reviews (.+) VALUES (.+) RETURNING (.+)").
+		WithArgs(req.ProductID, req.TransactionDetailID, req.UserID, req.Rating, req.Comment).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(review.ID, review.CreatedAt, review.UpdatedAt))
+	dbMock.ExpectExec("UPDATE products SET rating_count = (.+) WHERE (.+)").
+		WithArgs(req.Rating, req.ProductID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectGetReview(dbMock, review)
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	res, err := svc.Create(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, review.ID, res.ID)
+	assert.Equal(t, req.Rating, res.Rating)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateReviewFailedPurchaseNotCompleted(t *testing.T) {
+	for _, status := range []entity.TransactionStatus{entity.TransactionStatusProcessing, entity.TransactionStatusFailed} {
+		t.Run(string(status), func(t *testing.T) {
+			dbMock := initPgMock(t)
+			svc := initDepMock(dbMock)
+
+			req := newCreateRequest()
+			expectGetPurchase(dbMock, req, req.ProductID, status)
+
+			_, err := svc.Create(context.Background(), req)
+			assert.ErrorIs(t, err, constant.ErrReviewPurchaseNotCompleted)
+			assert.NoError(t, dbMock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCreateReviewFailedPurchaseOfAnotherProduct(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	req := newCreateRequest()
+	expectGetPurchase(dbMock, req, uuid.New(), entity.TransactionStatusCompleted)
+
+	_, err := svc.Create(context.Background(), req)
+	assert.ErrorIs(t, err, constant.ErrReviewPurchaseNotFound)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateReviewFailedPurchaseOfAnotherUser(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	// the line is looked up with the user of the request, so lines of other buyers are not found
+	req := newCreateRequest()
+	dbMock.ExpectQuery("SELECT (.+) FROM transaction_details td JOIN transactions t (.+)").
+		WithArgs(req.TransactionDetailID, req.UserID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "product_id", "status"}))
+
+	_, err := svc.Create(context.Background(), req)
+	assert.ErrorIs(t, err, constant.ErrReviewPurchaseNotFound)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCreateReviewFailedAlreadyReviewed(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	req := newCreateRequest()
+
+	expectGetPurchase(dbMock, req, req.ProductID, entity.TransactionStatusCompleted)
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("INSERT INTO product_reviews (.+) VALUES (.+) RETURNING (.+)").
+		WithArgs(req.ProductID, req.TransactionDetailID, req.UserID, req.Rating, req.Comment).
+		WillReturnError(&pgconn.PgError{Code: constant.ErrSQLUniqueViolation})
+	dbMock.ExpectRollback()
+
+	_, err := svc.Create(context.Background(), req)
+	assert.ErrorIs(t, err, constant.ErrReviewAlreadyExists)
+	// the rating is not added again
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func newReview(sellerID uuid.UUID) entity.ProductReview {
+	return entity.ProductReview{
+		ID:                  uuid.New(),
+		ProductID:           uuid.New(),
+		TransactionDetailID: uuid.New(),
+		UserID:              uuid.New(),
+		Rating:              5,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
+		SellerID:            sellerID,
+	}
+}
+
+func TestReplyReviewSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	sellerID := uuid.New()
+	review := newReview(sellerID)
+	replied := review
+	replied.Reply = null.StringFrom("thank you")
+	replied.RepliedAt = null.TimeFrom(time.Now())
+
+	dbMock.ExpectBegin()
+	expectGetReview(dbMock, review)
+	dbMock.ExpectExec("UPDATE product_reviews SET reply = (.+) WHERE (.+) AND reply IS NULL").
+		WithArgs(replied.Reply.String, review.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectGetReview(dbMock, replied)
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	res, err := svc.Reply(context.Background(), model.ProductReviewReplyRequest{
+		ProductID: review.ProductID,
+		ReviewID:  review.ID,
+		UserID:    sellerID,
+		Reply:     replied.Reply.String,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, replied.Reply, res.Reply)
+	assert.True(t, res.RepliedAt.Valid)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReplyReviewFailedAlreadyReplied(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	sellerID := uuid.New()
+	review := newReview(sellerID)
+	review.Reply = null.StringFrom("thank you")
+	review.RepliedAt = null.TimeFrom(time.Now())
+
+	dbMock.ExpectBegin()
+	expectGetReview(dbMock, review)
+	dbMock.ExpectExec("UPDATE product_reviews SET reply = (.+) WHERE (.+) AND reply IS NULL").
+		WithArgs("thanks again", review.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	dbMock.ExpectRollback()
+
+	_, err := svc.Reply(context.Background(), model.ProductReviewReplyRequest{
+		ProductID: review.ProductID,
+		ReviewID:  review.ID,
+		UserID:    sellerID,
+		Reply:     "thanks again",
+	})
+	assert.ErrorIs(t, err, constant.ErrReviewAlreadyReplied)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestReplyReviewFailedNotOwner(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	review := newReview(uuid.New())
+
+	dbMock.ExpectBegin()
+	expectGetReview(dbMock, review)
+	dbMock.ExpectRollback()
+
+	_, err := svc.Reply(context.Background(), model.ProductReviewReplyRequest{
+		ProductID: review.ProductID,
+		ReviewID:  review.ID,
+		UserID:    review.UserID,
+		Reply:     "replying to myself",
+	})
+	assert.ErrorIs(t, err, constant.ErrCannotReplyNotOwner)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
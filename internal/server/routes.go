@@ -1,10 +1,30 @@
 package server
 
 import (
+	"time"
+
+	"github.com/arfan21/vocagame/config"
+	apikeyctrl "github.com/arfan21/vocagame/internal/apikey/controller"
+	apikeyrepo "github.com/arfan21/vocagame/internal/apikey/repository"
+	apikeysvc "github.com/arfan21/vocagame/internal/apikey/service"
+	auditctrl "github.com/arfan21/vocagame/internal/audit/controller"
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	auditsvc "github.com/arfan21/vocagame/internal/audit/service"
+	categoryctrl "github.com/arfan21/vocagame/internal/category/controller"
+	categoryrepo "github.com/arfan21/vocagame/internal/category/repository"
+	categorysvc "github.com/arfan21/vocagame/internal/category/service"
+	"github.com/arfan21/vocagame/internal/entity"
 	"github.com/arfan21/vocagame/internal/middleware"
+	"github.com/arfan21/vocagame/internal/product"
 	productctrl "github.com/arfan21/vocagame/internal/product/controller"
 	productrepo "github.com/arfan21/vocagame/internal/product/repository"
 	productsvc "github.com/arfan21/vocagame/internal/product/service"
+	reservationctrl "github.com/arfan21/vocagame/internal/reservation/controller"
+	reservationrepo "github.com/arfan21/vocagame/internal/reservation/repository"
+	reservationsvc "github.com/arfan21/vocagame/internal/reservation/service"
+	reviewctrl "github.com/arfan21/vocagame/internal/review/controller"
+	reviewrepo "github.com/arfan21/vocagame/internal/review/repository"
+	reviewsvc "github.com/arfan21/vocagame/internal/review/service"
 	transactionctrl "github.com/arfan21/vocagame/internal/transaction/controller"
 	transactionrepo "github.com/arfan21/vocagame/internal/transaction/repository"
 	transactionsvc "github.com/arfan21/vocagame/internal/transaction/service"
@@ -14,6 +34,7 @@ import (
 	walletctrl "github.com/arfan21/vocagame/internal/wallet/controller"
 	walletrepo "github.com/arfan21/vocagame/internal/wallet/repository"
 	walletsvc "github.com/arfan21/vocagame/internal/wallet/service"
+	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -22,27 +43,67 @@ func (s *Server) Routes() {
 	api := s.app.Group("/api")
 	api.Get("/health-check", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
 
-	userRepo := userrepo.New(s.db)
-	userRepoRedis := userrepo.NewRedis(s.dbRedis)
-	userSvc := usersvc.New(userRepo, userRepoRedis)
-	userCtrl := userctrl.New(userSvc)
+	// writes to the product catalog invalidate its cache once the transaction is committed,
+	// also in transactions begun by other repositories
+	db := dbpostgres.WithAfterCommit(s.db)
 
-	productRepo := productrepo.New(s.db, s.db)
-	productSvc := productsvc.New(productRepo)
+	auditRepo := auditrepo.New(s.db)
+	auditSvc := auditsvc.New(auditRepo)
+	auditCtrl := auditctrl.New(auditSvc)
+
+	categoryRepo := categoryrepo.New(db, s.db)
+	categorySvc := categorysvc.New(categoryRepo, auditSvc)
+	categoryCtrl := categoryctrl.New(categorySvc)
+
+	var productRepo product.Repository = productrepo.New(db, s.db)
+	if productCache := config.GetConfig().ProductCache; productCache.Enabled {
+		productRepo = productrepo.NewCache(productrepo.New(db, s.db), s.dbRedis, time.Duration(productCache.TTL)*time.Second)
+	}
+
+	productSvc := productsvc.New(productRepo, auditSvc, s.blobStore)
 	productCtrl := productctrl.New(productSvc)
 
-	walletRepo := walletrepo.New(s.db, s.db)
-	walletSvc := walletsvc.New(walletRepo)
+	reviewRepo := reviewrepo.New(db, s.db)
+	reviewSvc := reviewsvc.New(reviewRepo, productSvc, auditSvc)
+	reviewCtrl := reviewctrl.New(reviewSvc)
+
+	reservationRepo := reservationrepo.New(db, s.db)
+	reservationSvc := reservationsvc.New(reservationRepo, productSvc, auditSvc)
+	reservationCtrl := reservationctrl.New(reservationSvc)
+	s.workers = append(s.workers, reservationSvc.RunSweeper)
+
+	walletRepo := walletrepo.New(db, s.db)
+	walletSvc := walletsvc.New(walletRepo, auditSvc)
 	walletCtrl := walletctrl.New(walletSvc)
 
-	transactionRepo := transactionrepo.New(s.db, s.db)
-	transactionSvc := transactionsvc.New(transactionRepo, walletSvc, productSvc)
+	userRepo := userrepo.New(s.db)
+	userRepoRedis := userrepo.NewRedis(s.dbRedis)
+	userSvc := usersvc.New(userRepo, userRepoRedis, auditSvc, walletSvc, productSvc, s.mailer, s.jwtKeys)
+	userCtrl := userctrl.New(userSvc)
+	middleware.UseJWTKeys(s.jwtKeys)
+	middleware.UseTokenRevocation(userSvc)
+	middleware.UseStepUpVerifier(userSvc)
+
+	apiKeyRepo := apikeyrepo.New(db, s.db)
+	apiKeySvc := apikeysvc.New(apiKeyRepo, auditSvc)
+	apiKeyCtrl := apikeyctrl.New(apiKeySvc)
+	middleware.UseAPIKeyAuthenticator(apiKeySvc)
+
+	transactionRepo := transactionrepo.New(db, s.db)
+	transactionSvc := transactionsvc.New(transactionRepo, walletSvc, productSvc, auditSvc, reservationSvc)
 	transactionCtrl := transactionctrl.New(transactionSvc)
 
+	s.app.Get("/.well-known/jwks.json", userCtrl.GetJWKS)
+
 	s.RoutesCustomer(api, userCtrl)
+	s.RoutesAPIKey(api, apiKeyCtrl)
 	s.RoutesProduct(api, productCtrl)
+	s.RoutesReview(api, reviewCtrl)
+	s.RoutesCategory(api, categoryCtrl)
+	s.RoutesReservation(api, reservationCtrl)
 	s.RoutesWallet(api, walletCtrl)
 	s.RoutesTransaction(api, transactionCtrl)
+	s.RoutesAudit(api, auditCtrl)
 }
 
 func (s Server) RoutesCustomer(route fiber.Router, ctrl *userctrl.ControllerHTTP) {
@@ -50,32 +111,109 @@ func (s Server) RoutesCustomer(route fiber.Router, ctrl *userctrl.ControllerHTTP
 	usersV1 := v1.Group("/users")
 	usersV1.Post("/register", ctrl.Register)
 	usersV1.Post("/login", ctrl.Login)
+	usersV1.Post("/login/2fa", ctrl.LoginTwoFactor)
 	usersV1.Post("/refresh-token", ctrl.RefreshToken)
+	usersV1.Post("/verify-email", ctrl.VerifyEmail)
+	usersV1.Post("/verify-email/resend", middleware.JWTAuth, ctrl.ResendVerificationEmail)
+	usersV1.Post("/forgot-password", ctrl.ForgotPassword)
+	usersV1.Post("/reset-password", ctrl.ResetPassword)
+	usersV1.Put("/password", middleware.JWTAuth, ctrl.ChangePassword)
+	usersV1.Get("/me", middleware.JWTAuth, ctrl.GetProfile)
+	usersV1.Put("/me", middleware.JWTAuth, ctrl.UpdateProfile)
 	usersV1.Post("/logout", middleware.JWTAuth, ctrl.Logout)
+	usersV1.Post("/logout-all", middleware.JWTAuth, ctrl.LogoutAll)
+	usersV1.Get("/sessions", middleware.JWTAuth, ctrl.GetSessions)
+	usersV1.Delete("/sessions/:id", middleware.JWTAuth, ctrl.RevokeSession)
+	usersV1.Post("/2fa/enroll", middleware.JWTAuth, ctrl.EnrollTwoFactor)
+	usersV1.Post("/2fa/confirm", middleware.JWTAuth, ctrl.ConfirmTwoFactor)
+	usersV1.Post("/2fa/disable", middleware.JWTAuth, ctrl.DisableTwoFactor)
+	usersV1.Post("/:id/unlock", middleware.JWTAuth, middleware.RequireRole(entity.UserRoleAdmin), ctrl.UnlockLogin)
+}
+
+func (s Server) RoutesAPIKey(route fiber.Router, ctrl *apikeyctrl.ControllerHTTP) {
+	v1 := route.Group("/v1")
+	apiKeyV1 := v1.Group("/api-keys")
+	apiKeyV1.Post("", middleware.JWTAuth, ctrl.Create)
+	apiKeyV1.Get("", middleware.JWTAuth, ctrl.GetList)
+	apiKeyV1.Delete("/:id", middleware.JWTAuth, ctrl.Revoke)
 }
 
 func (s Server) RoutesProduct(route fiber.Router, ctrl *productctrl.ControllerHTTP) {
 	v1 := route.Group("/v1")
 	productV1 := v1.Group("/products")
-	productV1.Post("", middleware.JWTAuth, ctrl.Create)
+	productV1.Post("", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeProductsWrite), ctrl.Create)
 	productV1.Get("", ctrl.GetProducts)
-	productV1.Put("/:productId", middleware.JWTAuth, ctrl.Update)
+	productV1.Get("/archived", middleware.JWTAuth, ctrl.GetArchivedProducts)
+	productV1.Get("/mine", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeProductsRead), ctrl.GetOwnProducts)
+	productV1.Post("/import", middleware.JWTAuth, ctrl.Import)
+	productV1.Get("/export", middleware.JWTAuth, ctrl.Export)
+	productV1.Get("/cache-stats", middleware.JWTAuth, middleware.RequireRole(entity.UserRoleAdmin), ctrl.GetCacheStats)
+	productV1.Get("/:productId", ctrl.GetProduct)
+	productV1.Put("/:productId", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeProductsWrite), ctrl.Update)
+	productV1.Patch("/:productId", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeProductsWrite), ctrl.Patch)
 	productV1.Delete("/:productId", middleware.JWTAuth, ctrl.Delete)
+	productV1.Post("/:productId/delist", middleware.JWTAuth, ctrl.Delist)
+	productV1.Post("/:productId/restore", middleware.JWTAuth, ctrl.Restore)
+	productV1.Post("/:productId/publish", middleware.JWTAuth, ctrl.Publish)
+	productV1.Post("/:productId/unpublish", middleware.JWTAuth, ctrl.Unpublish)
+	productV1.Post("/:productId/variants", middleware.JWTAuth, ctrl.CreateVariant)
+	productV1.Put("/:productId/variants/:variantId", middleware.JWTAuth, ctrl.UpdateVariant)
+	productV1.Delete("/:productId/variants/:variantId", middleware.JWTAuth, ctrl.DeleteVariant)
+	productV1.Post("/:productId/images", middleware.JWTAuth, ctrl.UploadImages)
+	productV1.Put("/:productId/images/order", middleware.JWTAuth, ctrl.ReorderImages)
+	productV1.Delete("/:productId/images/:imageId", middleware.JWTAuth, ctrl.DeleteImage)
+	productV1.Post("/:productId/stock-adjustments", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeProductsWrite), ctrl.AdjustStok)
+	productV1.Get("/:productId/stock-movements", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeProductsRead), ctrl.GetStockMovements)
+	productV1.Post("/:productId/sales", middleware.JWTAuth, ctrl.CreateSale)
+	productV1.Get("/:productId/sales", middleware.JWTAuth, ctrl.GetSales)
+	productV1.Delete("/:productId/sales/:saleId", middleware.JWTAuth, ctrl.CancelSale)
+	productV1.Get("/:productId/price-history", middleware.JWTAuth, ctrl.GetPriceHistory)
+}
+
+func (s Server) RoutesReview(route fiber.Router, ctrl *reviewctrl.ControllerHTTP) {
+	v1 := route.Group("/v1")
+	reviewV1 := v1.Group("/products/:productId/reviews")
+	reviewV1.Post("", middleware.JWTAuth, ctrl.Create)
+	reviewV1.Get("", ctrl.GetList)
+	reviewV1.Post("/:reviewId/reply", middleware.JWTAuth, ctrl.Reply)
+}
+
+func (s Server) RoutesCategory(route fiber.Router, ctrl *categoryctrl.ControllerHTTP) {
+	v1 := route.Group("/v1")
+	categoryV1 := v1.Group("/categories")
+	categoryV1.Get("", ctrl.GetTree)
+	categoryV1.Post("", middleware.JWTAuth, middleware.RequireRole(entity.UserRoleAdmin), ctrl.Create)
+	categoryV1.Put("/:categoryId", middleware.JWTAuth, middleware.RequireRole(entity.UserRoleAdmin), ctrl.Update)
+	categoryV1.Delete("/:categoryId", middleware.JWTAuth, middleware.RequireRole(entity.UserRoleAdmin), ctrl.Delete)
+}
+
+func (s Server) RoutesReservation(route fiber.Router, ctrl *reservationctrl.ControllerHTTP) {
+	v1 := route.Group("/v1")
+	reservationV1 := v1.Group("/reservations")
+	reservationV1.Post("", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeTransactionsWrite), ctrl.Create)
+	reservationV1.Get("/:reservationId", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeTransactionsRead), ctrl.GetByID)
+	reservationV1.Delete("/:reservationId", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeTransactionsWrite), ctrl.Cancel)
 }
 
 func (s Server) RoutesWallet(route fiber.Router, ctrl *walletctrl.ControllerHTTP) {
 	v1 := route.Group("/v1")
 	walletV1 := v1.Group("/wallets")
-	walletV1.Post("", middleware.JWTAuth, ctrl.Create)
-	walletV1.Get("", middleware.JWTAuth, ctrl.GetByUserID)
+	walletV1.Post("", middleware.JWTAuth, middleware.RequireVerifiedEmail, ctrl.Create)
+	walletV1.Get("", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeWalletsRead), ctrl.GetByUserID)
 }
 
 func (s Server) RoutesTransaction(route fiber.Router, ctrl *transactionctrl.ControllerHTTP) {
 	v1 := route.Group("/v1")
 	transactionV1 := v1.Group("/transactions")
-	transactionV1.Post("/deposit", middleware.JWTAuth, ctrl.CreateDepositTransaction)
-	transactionV1.Post("/withdraw", middleware.JWTAuth, ctrl.CreateWithdrawTransaction)
-	transactionV1.Get("/wallet", middleware.JWTAuth, ctrl.GetHistoryWalletByUserID)
-	transactionV1.Post("/checkout", middleware.JWTAuth, ctrl.Checkout)
-	transactionV1.Get("/:transactionId", middleware.JWTAuth, ctrl.GetByID)
+	transactionV1.Post("/deposit", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeTransactionsWrite), middleware.RequireVerifiedEmail, ctrl.CreateDepositTransaction)
+	transactionV1.Post("/withdraw", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeTransactionsWrite), middleware.RequireVerifiedEmail, middleware.RequireWithdrawStepUp, ctrl.CreateWithdrawTransaction)
+	transactionV1.Get("/wallet", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeTransactionsRead), ctrl.GetHistoryWalletByUserID)
+	transactionV1.Post("/checkout", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeTransactionsWrite), middleware.RequireVerifiedEmail, ctrl.Checkout)
+	transactionV1.Get("/:transactionId", middleware.Auth, middleware.RequireScope(entity.APIKeyScopeTransactionsRead), ctrl.GetByID)
+}
+
+func (s Server) RoutesAudit(route fiber.Router, ctrl *auditctrl.ControllerHTTP) {
+	v1 := route.Group("/v1")
+	auditV1 := v1.Group("/audit-logs")
+	auditV1.Get("", middleware.JWTAuth, middleware.RequireRole(entity.UserRoleAdmin), ctrl.GetList)
 }
@@ -4,14 +4,18 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/arfan21/vocagame/config"
 	_ "github.com/arfan21/vocagame/docs"
 	"github.com/arfan21/vocagame/internal/middleware"
+	"github.com/arfan21/vocagame/pkg/blobstore"
 	"github.com/arfan21/vocagame/pkg/exception"
+	"github.com/arfan21/vocagame/pkg/jwtkey"
 	"github.com/arfan21/vocagame/pkg/logger"
+	"github.com/arfan21/vocagame/pkg/mailer"
 	"github.com/arfan21/vocagame/pkg/pkgutil"
 	"github.com/gofiber/contrib/fiberzerolog"
 	"github.com/gofiber/fiber/v2"
@@ -25,20 +29,52 @@ import (
 
 const (
 	ctxTimeout = 5
+	// localStoragePrefix is where files of the local blob store are served, see STORAGE_PUBLIC_URL
+	localStoragePrefix = "/storage"
 )
 
 type Server struct {
-	app     *fiber.App
-	db      *pgxpool.Pool
-	dbRedis *redis.Client
+	app       *fiber.App
+	db        *pgxpool.Pool
+	dbRedis   *redis.Client
+	blobStore blobstore.BlobStore
+	mailer    mailer.Mailer
+	jwtKeys   *jwtkey.KeySet
+	// workers run in the background until the server shuts down
+	workers []func(ctx context.Context)
 }
 
 func New(
 	db *pgxpool.Pool,
 	dbRedis *redis.Client,
+	blobStore blobstore.BlobStore,
+	mailer mailer.Mailer,
+	jwtKeys *jwtkey.KeySet,
 ) *Server {
+	// room for uploading every image of a product in one request
+	productImage := config.GetConfig().ProductImage
+	bodyLimit := max(fiber.DefaultBodyLimit, productImage.MaxSize*productImage.MaxCount+1<<20)
+
+	proxy := config.GetConfig().Proxy
+
+	var trustedProxies []string
+	for _, trusted := range strings.Split(proxy.TrustedProxies, ",") {
+		trusted = strings.TrimSpace(trusted)
+		if trusted != "" {
+			trustedProxies = append(trustedProxies, trusted)
+		}
+	}
+
 	app := fiber.New(fiber.Config{
-		ErrorHandler: exception.FiberErrorHandler,
+		ErrorHandler:             exception.FiberErrorHandler,
+		EnableSplittingOnParsers: true,
+		BodyLimit:                bodyLimit,
+		// a client could set the header itself to dodge the login throttle of its IP,
+		// so it is only read from the proxies
+		ProxyHeader:             proxy.Header,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          trustedProxies,
+		EnableIPValidation:      true,
 	})
 
 	timeout := time.Duration(config.GetConfig().Service.Timeout) * time.Second
@@ -53,20 +89,36 @@ func New(
 	app.Use(requestid.New())
 
 	app.Use(middleware.RequestIdUser())
+	app.Use(middleware.ClientIPUser())
 	app.Use(recover.New())
 
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
+	if localStore, ok := blobStore.(*blobstore.Local); ok {
+		app.Static(localStoragePrefix, localStore.Dir())
+	}
+
 	return &Server{
-		app:     app,
-		db:      db,
-		dbRedis: dbRedis,
+		app:       app,
+		db:        db,
+		dbRedis:   dbRedis,
+		blobStore: blobStore,
+		mailer:    mailer,
+		jwtKeys:   jwtKeys,
 	}
 }
 
 func (s *Server) Run() error {
 	s.Routes()
 	ctx := context.Background()
+
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+
+	for _, worker := range s.workers {
+		go worker(workerCtx)
+	}
+
 	go func() {
 		if err := s.app.Listen(pkgutil.GetPort()); err != nil {
 			logger.Log(ctx).Fatal().Err(err).Msg("failed to start server")
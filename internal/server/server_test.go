@@ -0,0 +1,52 @@
+package server
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arfan21/vocagame/config"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientIP returns the client IP the handlers see for a request with the proxy header set to header.
+func clientIP(t *testing.T, trustedProxies string, header string) string {
+	cfg := config.GetConfig()
+	cfg.Proxy.Header = fiber.HeaderXForwardedFor
+	cfg.Proxy.TrustedProxies = trustedProxies
+
+	s := New(nil, nil, nil, nil, nil)
+	s.app.Get("/ip", func(c *fiber.Ctx) error {
+		ip, _ := c.UserContext().Value(constant.ClientIPContextKey).(string)
+		return c.SendString(ip)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/ip", nil)
+	req.Header.Set(fiber.HeaderXForwardedFor, header)
+
+	res, err := s.app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body)
+}
+
+func TestClientIPTrustedProxySuccess(t *testing.T) {
+	// requests of app.Test come from 0.0.0.0
+	assert.Equal(t, "203.0.113.7", clientIP(t, "10.0.0.0/8, 0.0.0.0", "203.0.113.7"))
+	assert.Equal(t, "203.0.113.7", clientIP(t, "0.0.0.0/32", "not-an-ip, 203.0.113.7"))
+}
+
+func TestClientIPFailedUntrustedProxy(t *testing.T) {
+	// a client sending the header itself keeps its own IP
+	assert.Equal(t, "0.0.0.0", clientIP(t, "10.0.0.0/8", "203.0.113.7"))
+	assert.Equal(t, "0.0.0.0", clientIP(t, "", "203.0.113.7"))
+}
@@ -61,9 +61,12 @@ func (ctrl ControllerHTTP) CreateDepositTransaction(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "With the bearer started"
+// @Param X-2FA-Code header string false "Two factor code, required from TWO_FACTOR_WITHDRAW_STEP_UP_AMOUNT for users with two factor authentication"
 // @Param body body model.CreateWithdrawTransactionRequest true "Create Withdraw Transaction"
 // @Success 201 {object} pkgutil.HTTPResponse
 // @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse "Two factor code required"
 // @Failure 500 {object} pkgutil.HTTPResponse
 // @Router /api/v1/transactions/withdraw [post]
 func (ctrl ControllerHTTP) CreateWithdrawTransaction(c *fiber.Ctx) error {
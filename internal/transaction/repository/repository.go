@@ -55,11 +55,11 @@ func (r Repository) Create(ctx context.Context, data entity.Transaction) (id uui
 }
 
 func (r Repository) CreateDetail(ctx context.Context, data []entity.TransactionDetail) (err error) {
-	columns := []string{"transaction_id", "product_id", "qty"}
+	columns := []string{"transaction_id", "product_id", "variant_id", "qty", "price", "sale_id"}
 
 	rows := make([][]interface{}, len(data))
 	for i, item := range data {
-		rows[i] = []interface{}{item.TransactionID, item.ProductID, item.Qty}
+		rows[i] = []interface{}{item.TransactionID, item.ProductID, item.VariantID, item.Qty, item.Price, item.SaleID}
 	}
 
 	rowsAffected, err := r.db.CopyFrom(ctx,
@@ -140,13 +140,18 @@ func (r Repository) GetByID(ctx context.Context, id, userID uuid.UUID) (res enti
 			t.updated_at,
 			td.id AS transaction_detail_id,
 			td.product_id,
+			td.variant_id,
 			td.qty,
 			p.name AS product_name,
-			p.price AS product_price
+			COALESCE(td.price, pv.price, p.price) AS product_price,
+			td.sale_id,
+			pv.name AS variant_name,
+			pv.sku AS variant_sku
 		FROM transactions t
 		LEFT JOIN transaction_types tt ON t.transaction_type_id = tt.id
 		LEFT JOIN transaction_details td ON t.id = td.transaction_id
 		LEFT JOIN products p ON td.product_id = p.id
+		LEFT JOIN product_variants pv ON td.variant_id = pv.id
 		WHERE t.id = $1 AND t.user_id = $2
 	`
 
@@ -173,9 +178,13 @@ func (r Repository) GetByID(ctx context.Context, id, userID uuid.UUID) (res enti
 			&res.UpdatedAt,
 			&detail.ID,
 			&detail.ProductID,
+			&detail.VariantID,
 			&detail.Qty,
 			&detail.Product.Name,
 			&detail.Product.Price,
+			&detail.SaleID,
+			&detail.Product.VariantName,
+			&detail.Product.VariantSKU,
 		)
 
 		if err != nil {
@@ -4,26 +4,43 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/arfan21/vocagame/internal/audit"
 	"github.com/arfan21/vocagame/internal/entity"
 	"github.com/arfan21/vocagame/internal/model"
 	"github.com/arfan21/vocagame/internal/product"
+	"github.com/arfan21/vocagame/internal/reservation"
 	"github.com/arfan21/vocagame/internal/transaction"
 	"github.com/arfan21/vocagame/internal/wallet"
 	"github.com/arfan21/vocagame/pkg/constant"
 	"github.com/arfan21/vocagame/pkg/validation"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/shopspring/decimal"
 	"gopkg.in/guregu/null.v4"
 )
 
 type Service struct {
-	repo       transaction.Repository
-	walletSvc  wallet.Service
-	productSvc product.Service
+	repo           transaction.Repository
+	walletSvc      wallet.Service
+	productSvc     product.Service
+	auditSvc       audit.Service
+	reservationSvc reservation.Service
 }
 
-func New(repo transaction.Repository, walletSvc wallet.Service, productSvc product.Service) *Service {
-	return &Service{repo: repo, walletSvc: walletSvc, productSvc: productSvc}
+func New(
+	repo transaction.Repository,
+	walletSvc wallet.Service,
+	productSvc product.Service,
+	auditSvc audit.Service,
+	reservationSvc reservation.Service,
+) *Service {
+	return &Service{
+		repo:           repo,
+		walletSvc:      walletSvc,
+		productSvc:     productSvc,
+		auditSvc:       auditSvc,
+		reservationSvc: reservationSvc,
+	}
 }
 
 func (s Service) CreateDepositTransaction(ctx context.Context, req model.CreateDepositTransactionRequest) (res model.CreateTransactionResponse, err error) {
@@ -58,12 +75,14 @@ func (s Service) CreateDepositTransaction(ctx context.Context, req model.CreateD
 		return
 	}
 
+	previousBalance := walletData.Balance
 	walletData.Balance = walletData.Balance.Add(req.Amount)
 
 	walletDataReq := model.UpdateBalanceRequest{
-		ID:      walletData.ID,
-		Balance: walletData.Balance,
-		UserID:  walletData.UserID,
+		ID:              walletData.ID,
+		Balance:         walletData.Balance,
+		PreviousBalance: previousBalance,
+		UserID:          walletData.UserID,
 	}
 
 	err = s.walletSvc.WithTx(tx).UpdateBalance(ctx, walletDataReq)
@@ -85,6 +104,12 @@ func (s Service) CreateDepositTransaction(ctx context.Context, req model.CreateD
 		return
 	}
 
+	err = s.recordTransactionCreated(ctx, tx, idTx, transactionData)
+	if err != nil {
+		err = fmt.Errorf("transaction.service.CreateDepositTransaction: failed to record audit log: %w", err)
+		return
+	}
+
 	res.TransactionID = idTx.String()
 
 	return
@@ -127,12 +152,14 @@ func (s Service) CreateWithdrawTransaction(ctx context.Context, req model.Create
 		return
 	}
 
+	previousBalance := walletData.Balance
 	walletData.Balance = walletData.Balance.Sub(req.Amount)
 
 	walletDataReq := model.UpdateBalanceRequest{
-		ID:      walletData.ID,
-		Balance: walletData.Balance,
-		UserID:  walletData.UserID,
+		ID:              walletData.ID,
+		Balance:         walletData.Balance,
+		PreviousBalance: previousBalance,
+		UserID:          walletData.UserID,
 	}
 
 	err = s.walletSvc.WithTx(tx).UpdateBalance(ctx, walletDataReq)
@@ -154,11 +181,31 @@ func (s Service) CreateWithdrawTransaction(ctx context.Context, req model.Create
 		return
 	}
 
+	err = s.recordTransactionCreated(ctx, tx, idTx, transactionData)
+	if err != nil {
+		err = fmt.Errorf("transaction.service.CreateWithdrawTransaction: failed to record audit log: %w", err)
+		return
+	}
+
 	res.TransactionID = idTx.String()
 
 	return
 }
 
+func (s Service) recordTransactionCreated(ctx context.Context, tx pgx.Tx, id uuid.UUID, data entity.Transaction) (err error) {
+	return s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntityTransaction,
+		EntityID:   id,
+		After: model.GetTransactionResponse{
+			ID:          id,
+			UserID:      data.UserID,
+			Status:      string(data.Status),
+			TotalAmount: data.TotalAmount,
+		},
+	})
+}
+
 func (s Service) GetHistoryWalletByUserID(ctx context.Context, userID uuid.UUID) (res []model.GetTransactionResponse, err error) {
 	transactions, err := s.repo.GetHistoryWalletByUserID(ctx, userID)
 	if err != nil {
@@ -212,6 +259,37 @@ func (s Service) Checkout(ctx context.Context, req model.CheckoutTransactionRequ
 		}
 	}()
 
+	// the stok of a reservation is already held for the user, so it is not checked again
+	isReserved := req.ReservationID.Valid
+	if isReserved {
+		if len(req.Products) != 0 {
+			err = constant.ErrCheckoutReservationWithProduct
+			return
+		}
+
+		var reservationData model.ReservationResponse
+		reservationData, err = s.reservationSvc.WithTx(tx).GetActiveForCheckout(ctx, model.GetReservationRequest{
+			ID:     req.ReservationID.UUID,
+			UserID: req.UserID,
+		})
+		if err != nil {
+			err = fmt.Errorf("transaction.service.Checkout: failed to get reservation: %w", err)
+			return
+		}
+
+		req.Products = make([]model.CheckoutProductRequest, len(reservationData.Items))
+		for i, v := range reservationData.Items {
+			req.Products[i] = model.CheckoutProductRequest{
+				ProductID: v.ProductID,
+				VariantID: v.VariantID,
+				Qty:       v.Qty,
+			}
+		}
+	} else if len(req.Products) == 0 {
+		err = constant.ErrCheckoutProductsRequired
+		return
+	}
+
 	productIds := make([]uuid.UUID, len(req.Products))
 
 	for i, v := range req.Products {
@@ -224,30 +302,58 @@ func (s Service) Checkout(ctx context.Context, req model.CheckoutTransactionRequ
 		return
 	}
 
-	if len(products) != len(req.Products) {
-		productId := ""
-		for _, v := range req.Products {
-			if _, ok := products[v.ProductID]; !ok {
-				productId = v.ProductID.String()
-				break
-			}
+	// several variants of a product are separate items of one product, so every item is looked up
+	for _, v := range req.Products {
+		if _, ok := products[v.ProductID]; !ok {
+			errProductNotFound := *constant.ErrProductNotFound
+			errProductNotFound.Message = fmt.Sprintf("product with id '%s' not found", v.ProductID)
+			err = &errProductNotFound
+			return
 		}
+	}
 
-		errProductNotFound := *constant.ErrProductNotFound
-		errProductNotFound.Message = fmt.Sprintf("product with id '%s' not found", productId)
-		err = &errProductNotFound
-		return
+	var variantIds []uuid.UUID
+	for _, v := range req.Products {
+		if v.VariantID.Valid {
+			variantIds = append(variantIds, v.VariantID.UUID)
+		}
+	}
+
+	var variants map[uuid.UUID]model.ProductVariantResponse
+	if len(variantIds) != 0 {
+		variants, err = s.productSvc.WithTx(tx).GetVariantsByIDs(ctx, variantIds)
+		if err != nil {
+			err = fmt.Errorf("transaction.service.Checkout: failed to get product variants: %w", err)
+			return
+		}
 	}
 
 	productUpdateRequests := make([]model.ReduceStokRequest, len(req.Products))
-	totalAmount := decimal.NewFromInt(0)
+	prices := make([]decimal.Decimal, len(req.Products))
 
 	// check stok
 	for i, v := range req.Products {
 		product := products[v.ProductID]
-		if product.Stok < v.Qty {
+		name, stok, price := product.Name, product.Stok, product.Price
+
+		if v.VariantID.Valid {
+			variant, ok := variants[v.VariantID.UUID]
+			if !ok || variant.ProductID != product.ID {
+				errVariantNotFound := *constant.ErrProductVariantNotFound
+				errVariantNotFound.Message = fmt.Sprintf("variant with id '%s' of product with name %s not found", v.VariantID.UUID, product.Name)
+				err = &errVariantNotFound
+				return
+			}
+
+			name, stok, price = product.Name+" - "+variant.Name, variant.Stok, variant.Price
+		} else if product.HasVariants {
+			err = constant.ErrProductVariantRequired
+			return
+		}
+
+		if !isReserved && stok < v.Qty {
 			errProductStockNotEnough := *constant.ErrProductStokNotEnough
-			errProductStockNotEnough.Message = fmt.Sprintf("product with name %s stok not enough", product.Name)
+			errProductStockNotEnough.Message = fmt.Sprintf("product with name %s stok not enough", name)
 			err = &errProductStockNotEnough
 			return
 		}
@@ -258,11 +364,29 @@ func (s Service) Checkout(ctx context.Context, req model.CheckoutTransactionRequ
 		}
 
 		productUpdateRequests[i] = model.ReduceStokRequest{
-			ID:       product.ID,
-			ReduceBy: v.Qty,
+			ID:        product.ID,
+			VariantID: v.VariantID,
+			ReduceBy:  v.Qty,
+			Reserved:  isReserved,
+		}
+
+		prices[i] = price
+	}
+
+	// sales are applied once every item is checked, so a rejected checkout does not use any sale quota
+	appliedSales, err := s.productSvc.WithTx(tx).ApplySales(ctx, req.UserID, req.Products)
+	if err != nil {
+		err = fmt.Errorf("transaction.service.Checkout: failed to apply sales: %w", err)
+		return
+	}
+
+	totalAmount := decimal.NewFromInt(0)
+	for i, v := range req.Products {
+		if appliedSales[i].SaleID.Valid {
+			prices[i] = decimal.Min(prices[i], appliedSales[i].Price)
 		}
 
-		totalAmount = totalAmount.Add(product.Price.Mul(decimal.NewFromInt(int64(v.Qty))))
+		totalAmount = totalAmount.Add(prices[i].Mul(decimal.NewFromInt(int64(v.Qty))))
 	}
 
 	walletData, err := s.walletSvc.WithTx(tx).GetByUserID(ctx, req.UserID, true)
@@ -276,12 +400,14 @@ func (s Service) Checkout(ctx context.Context, req model.CheckoutTransactionRequ
 		return
 	}
 
+	previousBalance := walletData.Balance
 	walletData.Balance = walletData.Balance.Sub(totalAmount)
 
 	walletDataReq := model.UpdateBalanceRequest{
-		ID:      walletData.ID,
-		Balance: walletData.Balance,
-		UserID:  walletData.UserID,
+		ID:              walletData.ID,
+		Balance:         walletData.Balance,
+		PreviousBalance: previousBalance,
+		UserID:          walletData.UserID,
 	}
 
 	err = s.walletSvc.WithTx(tx).UpdateBalance(ctx, walletDataReq)
@@ -303,13 +429,22 @@ func (s Service) Checkout(ctx context.Context, req model.CheckoutTransactionRequ
 		return
 	}
 
+	err = s.recordTransactionCreated(ctx, tx, idTx, transactionData)
+	if err != nil {
+		err = fmt.Errorf("transaction.service.Checkout: failed to record audit log: %w", err)
+		return
+	}
+
 	transactionDetailData := make([]entity.TransactionDetail, len(req.Products))
 
 	for i, v := range req.Products {
 		transactionDetailData[i] = entity.TransactionDetail{
 			TransactionID: uuid.NullUUID{UUID: idTx, Valid: true},
 			ProductID:     uuid.NullUUID{UUID: v.ProductID, Valid: true},
+			VariantID:     v.VariantID,
 			Qty:           null.IntFrom(int64(v.Qty)),
+			Price:         decimal.NewNullDecimal(prices[i]),
+			SaleID:        appliedSales[i].SaleID,
 		}
 	}
 
@@ -319,12 +454,25 @@ func (s Service) Checkout(ctx context.Context, req model.CheckoutTransactionRequ
 		return
 	}
 
+	for i := range productUpdateRequests {
+		productUpdateRequests[i].TransactionID = uuid.NullUUID{UUID: idTx, Valid: true}
+		productUpdateRequests[i].UserID = uuid.NullUUID{UUID: req.UserID, Valid: true}
+	}
+
 	err = s.productSvc.WithTx(tx).BatchReduceStok(ctx, productUpdateRequests)
 	if err != nil {
 		err = fmt.Errorf("transaction.service.Checkout: failed to update product stok: %w", err)
 		return
 	}
 
+	if isReserved {
+		err = s.reservationSvc.WithTx(tx).Consume(ctx, req.ReservationID.UUID, idTx)
+		if err != nil {
+			err = fmt.Errorf("transaction.service.Checkout: failed to consume reservation: %w", err)
+			return
+		}
+	}
+
 	res.TransactionID = idTx.String()
 
 	return
@@ -357,9 +505,13 @@ func (s Service) GetByID(ctx context.Context, req model.GetTransactionByIDReques
 		res.Details[i] = model.TransactionDetailResponse{
 			ID:           v.ID.UUID,
 			ProductID:    v.ProductID.UUID,
+			VariantID:    v.VariantID,
 			Qty:          int(v.Qty.ValueOrZero()),
 			ProductName:  v.Product.Name.ValueOrZero(),
+			VariantName:  v.Product.VariantName.ValueOrZero(),
+			VariantSKU:   v.Product.VariantSKU.ValueOrZero(),
 			ProductPrice: v.Product.Price.Decimal,
+			SaleID:       v.SaleID,
 		}
 	}
 
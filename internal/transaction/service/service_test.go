@@ -3,13 +3,19 @@ package transactionsvc
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"sync"
 	"testing"
+	"time"
 
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	auditsvc "github.com/arfan21/vocagame/internal/audit/service"
 	"github.com/arfan21/vocagame/internal/entity"
 	"github.com/arfan21/vocagame/internal/model"
 	productrepo "github.com/arfan21/vocagame/internal/product/repository"
 	productsvc "github.com/arfan21/vocagame/internal/product/service"
+	reservationrepo "github.com/arfan21/vocagame/internal/reservation/repository"
+	reservationsvc "github.com/arfan21/vocagame/internal/reservation/service"
 	transactionrepo "github.com/arfan21/vocagame/internal/transaction/repository"
 	walletrepo "github.com/arfan21/vocagame/internal/wallet/repository"
 	walletsvc "github.com/arfan21/vocagame/internal/wallet/service"
@@ -24,6 +30,7 @@ import (
 	"github.com/pashagolub/pgxmock/v3"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
 )
 
 var db *pgxpool.Pool
@@ -95,14 +102,20 @@ func initDocker(t *testing.T) (*dockertest.Pool, *dockertest.Resource) {
 func initDep(t *testing.T) (svc *Service) {
 	dockerPool, dockerResource = initDocker(t)
 
+	auditRepo := auditrepo.New(db)
+	auditSvc := auditsvc.New(auditRepo)
+
 	walletRepo := walletrepo.New(db, db)
-	walletSvc := walletsvc.New(walletRepo)
+	walletSvc := walletsvc.New(walletRepo, auditSvc)
 
 	productRepo := productrepo.New(db, db)
-	productSvc := productsvc.New(productRepo)
+	productSvc := productsvc.New(productRepo, auditSvc, nil)
+
+	reservationRepo := reservationrepo.New(db, db)
+	reservationSvc := reservationsvc.New(reservationRepo, productSvc, auditSvc)
 
 	transactionRepo := transactionrepo.New(db, db)
-	svc = New(transactionRepo, walletSvc, productSvc)
+	svc = New(transactionRepo, walletSvc, productSvc, auditSvc, reservationSvc)
 
 	return
 }
@@ -253,18 +266,44 @@ func initPgMock(t *testing.T) pgxmock.PgxPoolIface {
 
 func initDepMock(db pgxmock.PgxPoolIface) (svc *Service) {
 
+	auditRepo := auditrepo.New(db)
+	auditSvc := auditsvc.New(auditRepo)
+
 	walletRepo := walletrepo.New(db, db)
-	walletSvc := walletsvc.New(walletRepo)
+	walletSvc := walletsvc.New(walletRepo, auditSvc)
 
 	productRepo := productrepo.New(db, db)
-	productSvc := productsvc.New(productRepo)
+	productSvc := productsvc.New(productRepo, auditSvc, nil)
+
+	reservationRepo := reservationrepo.New(db, db)
+	reservationSvc := reservationsvc.New(reservationRepo, productSvc, auditSvc)
 
 	transactionRepo := transactionrepo.New(db, db)
-	svc = New(transactionRepo, walletSvc, productSvc)
+	svc = New(transactionRepo, walletSvc, productSvc, auditSvc, reservationSvc)
 
 	return
 }
 
+var productSaleColumns = []string{
+	"id", "product_id", "variant_id", "sale_price", "starts_at", "ends_at", "quota",
+	"sold", "per_user_limit", "created_by", "cancelled_at", "created_at", "updated_at",
+}
+
+func expectRecordAuditLog(dbMock pgxmock.PgxPoolIface) {
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			pgxmock.AnyArg(),
+			pgxmock.AnyArg(),
+			pgxmock.AnyArg(),
+			pgxmock.AnyArg(),
+			pgxmock.AnyArg(),
+			pgxmock.AnyArg(),
+			pgxmock.AnyArg(),
+			pgxmock.AnyArg(),
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
 func TestCreateDepositTransactionSuccess(t *testing.T) {
 	dbMock := initPgMock(t)
 	svc := initDepMock(dbMock)
@@ -293,6 +332,7 @@ func TestCreateDepositTransactionSuccess(t *testing.T) {
 	dbMock.ExpectExec("UPDATE wallets SET balance = (.+) WHERE id (.+)  ").
 		WithArgs(initialBalance.Add(req.Amount), walletID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
 
 	// insert transaction
 	dbMock.ExpectQuery("INSERT INTO transactions (.+) VALUES (.+) RETURNING id").
@@ -300,6 +340,7 @@ func TestCreateDepositTransactionSuccess(t *testing.T) {
 		WillReturnRows(
 			pgxmock.NewRows([]string{"id"}).AddRow(transactionID),
 		)
+	expectRecordAuditLog(dbMock)
 
 	dbMock.ExpectCommit()
 
@@ -402,6 +443,7 @@ func TestCreateDepositTransactionFailedInsertTransaction(t *testing.T) {
 	dbMock.ExpectExec("UPDATE wallets SET balance = (.+) WHERE id (.+)  ").
 		WithArgs(initialBalance.Add(req.Amount), walletID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
 
 	// insert transaction
 	dbMock.ExpectQuery("INSERT INTO transactions (.+) VALUES (.+) RETURNING id").
@@ -492,6 +534,7 @@ func TestCreateWithdrawTransactionSuccess(t *testing.T) {
 	dbMock.ExpectExec("UPDATE wallets SET balance = (.+) WHERE id (.+)  ").
 		WithArgs(initialBalance.Sub(req.Amount), walletID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
 
 	// insert transaction
 	dbMock.ExpectQuery("INSERT INTO transactions (.+) VALUES (.+) RETURNING id").
@@ -499,6 +542,7 @@ func TestCreateWithdrawTransactionSuccess(t *testing.T) {
 		WillReturnRows(
 			pgxmock.NewRows([]string{"id"}).AddRow(transactionID),
 		)
+	expectRecordAuditLog(dbMock)
 
 	dbMock.ExpectCommit()
 
@@ -631,6 +675,7 @@ func TestCreateWithdrawTransactionFailedInsertTransaction(t *testing.T) {
 	dbMock.ExpectExec("UPDATE wallets SET balance = (.+) WHERE id (.+)  ").
 		WithArgs(initialBalance.Sub(req.Amount), walletID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
 
 	// insert transaction
 	dbMock.ExpectQuery("INSERT INTO transactions (.+) VALUES (.+) RETURNING id").
@@ -677,10 +722,15 @@ func TestCheckoutSuccess(t *testing.T) {
 	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
 		WithArgs(productIds).
 		WillReturnRows(
-			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id"}).
-				AddRow(req.Products[0].ProductID, "product 1", 2, decimal.NewFromInt(1000), uuid.New()),
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(req.Products[0].ProductID, "product 1", 2, decimal.NewFromInt(1000), uuid.New(), false),
 		)
 
+	// get active sales
+	dbMock.ExpectQuery("SELECT (.+) FROM product_sales (.+)").
+		WithArgs(productIds).
+		WillReturnRows(pgxmock.NewRows(productSaleColumns))
+
 	// get wallet
 	dbMock.ExpectQuery("SELECT (.+) FROM wallets (.+) FOR UPDATE").
 		WithArgs(userID).
@@ -693,6 +743,7 @@ func TestCheckoutSuccess(t *testing.T) {
 	dbMock.ExpectExec("UPDATE wallets SET balance = (.+) WHERE id (.+)  ").
 		WithArgs(initialBalance.Sub(decimal.NewFromInt(2000)), walletID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
 
 	// insert transaction
 	dbMock.ExpectQuery("INSERT INTO transactions (.+) VALUES (.+) RETURNING id").
@@ -700,9 +751,10 @@ func TestCheckoutSuccess(t *testing.T) {
 		WillReturnRows(
 			pgxmock.NewRows([]string{"id"}).AddRow(transactionID),
 		)
+	expectRecordAuditLog(dbMock)
 
 	// insert transaction detail
-	dbMock.ExpectCopyFrom(pgx.Identifier{entity.TransactionDetail{}.TableName()}, []string{"transaction_id", "product_id", "qty"}).
+	dbMock.ExpectCopyFrom(pgx.Identifier{entity.TransactionDetail{}.TableName()}, []string{"transaction_id", "product_id", "variant_id", "qty", "price", "sale_id"}).
 		WillReturnResult(1)
 
 	// update stok
@@ -710,6 +762,20 @@ func TestCheckoutSuccess(t *testing.T) {
 	dbMock.ExpectExec("UPDATE products SET (.+) WHERE (.+)").
 		WithArgs(req.Products[0].Qty, req.Products[0].ProductID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	dbMock.ExpectQuery("INSERT INTO stock_movements (.+) VALUES (.+) RETURNING (.+)").
+		WithArgs(
+			req.Products[0].ProductID,
+			uuid.NullUUID{},
+			-req.Products[0].Qty,
+			entity.StockMovementReasonSale,
+			uuid.NullUUID{UUID: userID, Valid: true},
+			uuid.NullUUID{UUID: transactionID, Valid: true},
+			null.String{},
+		).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "stok_after", "created_at"}).AddRow(uuid.New(), 0, time.Now()),
+		)
+	expectRecordAuditLog(dbMock)
 	dbMock.ExpectCommit()
 
 	dbMock.ExpectCommit()
@@ -719,6 +785,164 @@ func TestCheckoutSuccess(t *testing.T) {
 	assert.Equal(t, transactionID.String(), id.TransactionID)
 }
 
+func TestCheckoutSuccessWithSale(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	assert.NotNil(t, dbMock)
+
+	userID := uuid.New()
+	walletID := uuid.New()
+	transactionID := uuid.New()
+	saleID := uuid.New()
+
+	req := model.CheckoutTransactionRequest{
+		UserID: userID,
+		Products: []model.CheckoutProductRequest{
+			{
+				ProductID: uuid.New(),
+				Qty:       2,
+			},
+		},
+	}
+
+	productIds := []uuid.UUID{req.Products[0].ProductID}
+
+	dbMock.ExpectBegin()
+	// get product by ids
+	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
+		WithArgs(productIds).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(req.Products[0].ProductID, "product 1", 2, decimal.NewFromInt(1000), uuid.New(), false),
+		)
+
+	// get active sales
+	dbMock.ExpectQuery("SELECT (.+) FROM product_sales (.+)").
+		WithArgs(productIds).
+		WillReturnRows(
+			pgxmock.NewRows(productSaleColumns).
+				AddRow(
+					saleID, req.Products[0].ProductID, uuid.NullUUID{}, decimal.NewFromInt(800),
+					time.Now().Add(-time.Hour), time.Now().Add(time.Hour), null.IntFrom(10),
+					0, null.IntFrom(5), uuid.New(), null.Time{}, time.Now(), time.Now(),
+				),
+		)
+
+	// use sale quota
+	dbMock.ExpectExec("UPDATE product_sales (.+) SET sold = (.+) WHERE (.+)").
+		WithArgs(req.Products[0].Qty, saleID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	dbMock.ExpectExec("INSERT INTO product_sale_usages (.+)").
+		WithArgs(saleID, userID, req.Products[0].Qty, 5).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	// get wallet
+	dbMock.ExpectQuery("SELECT (.+) FROM wallets (.+) FOR UPDATE").
+		WithArgs(userID).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "user_id", "balance", "created_at", "updated_at"}).
+				AddRow(walletID, userID, initialBalance, nil, nil),
+		)
+
+	// update balance with the sale price
+	dbMock.ExpectExec("UPDATE wallets SET balance = (.+) WHERE id (.+)  ").
+		WithArgs(initialBalance.Sub(decimal.NewFromInt(1600)), walletID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+
+	// insert transaction
+	dbMock.ExpectQuery("INSERT INTO transactions (.+) VALUES (.+) RETURNING id").
+		WithArgs(userID, constant.TransactionTypePurchaseID, entity.TransactionStatusCompleted, decimal.NewFromInt(1600)).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id"}).AddRow(transactionID),
+		)
+	expectRecordAuditLog(dbMock)
+
+	// insert transaction detail
+	dbMock.ExpectCopyFrom(pgx.Identifier{entity.TransactionDetail{}.TableName()}, []string{"transaction_id", "product_id", "variant_id", "qty", "price", "sale_id"}).
+		WillReturnResult(1)
+
+	// update stok
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec("UPDATE products SET (.+) WHERE (.+)").
+		WithArgs(req.Products[0].Qty, req.Products[0].ProductID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	dbMock.ExpectQuery("INSERT INTO stock_movements (.+) VALUES (.+) RETURNING (.+)").
+		WithArgs(
+			req.Products[0].ProductID,
+			uuid.NullUUID{},
+			-req.Products[0].Qty,
+			entity.StockMovementReasonSale,
+			uuid.NullUUID{UUID: userID, Valid: true},
+			uuid.NullUUID{UUID: transactionID, Valid: true},
+			null.String{},
+		).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "stok_after", "created_at"}).AddRow(uuid.New(), 0, time.Now()),
+		)
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+
+	dbMock.ExpectCommit()
+
+	id, err := svc.Checkout(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, transactionID.String(), id.TransactionID)
+}
+
+func TestCheckoutFailedSaleQuotaExceeded(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	assert.NotNil(t, dbMock)
+
+	userID := uuid.New()
+	saleID := uuid.New()
+
+	req := model.CheckoutTransactionRequest{
+		UserID: userID,
+		Products: []model.CheckoutProductRequest{
+			{
+				ProductID: uuid.New(),
+				Qty:       2,
+			},
+		},
+	}
+
+	productIds := []uuid.UUID{req.Products[0].ProductID}
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
+		WithArgs(productIds).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(req.Products[0].ProductID, "product 1", 2, decimal.NewFromInt(1000), uuid.New(), false),
+		)
+
+	dbMock.ExpectQuery("SELECT (.+) FROM product_sales (.+)").
+		WithArgs(productIds).
+		WillReturnRows(
+			pgxmock.NewRows(productSaleColumns).
+				AddRow(
+					saleID, req.Products[0].ProductID, uuid.NullUUID{}, decimal.NewFromInt(800),
+					time.Now().Add(-time.Hour), time.Now().Add(time.Hour), null.IntFrom(10),
+					9, null.Int{}, uuid.New(), null.Time{}, time.Now(), time.Now(),
+				),
+		)
+
+	// only 1 of the quota is left
+	dbMock.ExpectExec("UPDATE product_sales (.+) SET sold = (.+) WHERE (.+)").
+		WithArgs(req.Products[0].Qty, saleID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	dbMock.ExpectRollback()
+
+	_, err := svc.Checkout(context.Background(), req)
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, constant.ErrSaleQuotaExceeded)
+}
+
 func TestCheckoutConcurrenct(t *testing.T) {
 	svc := initDep(t)
 
@@ -784,6 +1008,347 @@ func TestCheckoutConcurrenct(t *testing.T) {
 	assert.True(t, balance.Equal(initialBalance.Sub(decimal.NewFromInt(3000))))
 }
 
+func TestCheckoutVariantsOfSameProductSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	assert.NotNil(t, dbMock)
+
+	userID := uuid.New()
+	walletID := uuid.New()
+	transactionID := uuid.New()
+	productID := uuid.New()
+	variantSID := uuid.New()
+	variantMID := uuid.New()
+
+	req := model.CheckoutTransactionRequest{
+		UserID: userID,
+		Products: []model.CheckoutProductRequest{
+			{
+				ProductID: productID,
+				VariantID: uuid.NullUUID{UUID: variantSID, Valid: true},
+				Qty:       1,
+			},
+			{
+				ProductID: productID,
+				VariantID: uuid.NullUUID{UUID: variantMID, Valid: true},
+				Qty:       2,
+			},
+		},
+	}
+
+	productIds := []uuid.UUID{productID, productID}
+
+	dbMock.ExpectBegin()
+	// get product by ids, the product is returned once for both items
+	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
+		WithArgs(productIds).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(productID, "product 1", 10, decimal.NewFromInt(1000), uuid.New(), true),
+		)
+
+	// get variants by ids
+	dbMock.ExpectQuery("SELECT (.+) FROM product_variants (.+)").
+		WithArgs([]uuid.UUID{variantSID, variantMID}).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "product_id", "sku", "name", "price", "stok", "sold_count"}).
+				AddRow(variantSID, productID, "SKU-S", "S", decimal.NewFromInt(1000), 5, 0).
+				AddRow(variantMID, productID, "SKU-M", "M", decimal.NewFromInt(1500), 5, 0),
+		)
+
+	// get active sales
+	dbMock.ExpectQuery("SELECT (.+) FROM product_sales (.+)").
+		WithArgs(productIds).
+		WillReturnRows(pgxmock.NewRows(productSaleColumns))
+
+	// get wallet
+	dbMock.ExpectQuery("SELECT (.+) FROM wallets (.+) FOR UPDATE").
+		WithArgs(userID).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "user_id", "balance", "created_at", "updated_at"}).
+				AddRow(walletID, userID, initialBalance, nil, nil),
+		)
+
+	// update balance, 1 x 1000 + 2 x 1500
+	dbMock.ExpectExec("UPDATE wallets SET balance = (.+) WHERE id (.+)  ").
+		WithArgs(initialBalance.Sub(decimal.NewFromInt(4000)), walletID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+
+	// insert transaction
+	dbMock.ExpectQuery("INSERT INTO transactions (.+) VALUES (.+) RETURNING id").
+		WithArgs(userID, constant.TransactionTypePurchaseID, entity.TransactionStatusCompleted, decimal.NewFromInt(4000)).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id"}).AddRow(transactionID),
+		)
+	expectRecordAuditLog(dbMock)
+
+	// insert transaction detail
+	dbMock.ExpectCopyFrom(pgx.Identifier{entity.TransactionDetail{}.TableName()}, []string{"transaction_id", "product_id", "variant_id", "qty", "price", "sale_id"}).
+		WillReturnResult(2)
+
+	// update stok of both variants and their product
+	dbMock.ExpectBegin()
+	for _, item := range req.Products {
+		dbMock.ExpectExec("UPDATE product_variants SET (.+) WHERE (.+)").
+			WithArgs(item.Qty, item.VariantID.UUID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		dbMock.ExpectExec("UPDATE products SET (.+) WHERE (.+)").
+			WithArgs(item.Qty, productID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		dbMock.ExpectQuery("INSERT INTO stock_movements (.+) VALUES (.+) RETURNING (.+)").
+			WithArgs(
+				productID,
+				item.VariantID,
+				-item.Qty,
+				entity.StockMovementReasonSale,
+				uuid.NullUUID{UUID: userID, Valid: true},
+				uuid.NullUUID{UUID: transactionID, Valid: true},
+				null.String{},
+			).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"id", "stok_after", "created_at"}).AddRow(uuid.New(), 0, time.Now()),
+			)
+		expectRecordAuditLog(dbMock)
+	}
+	dbMock.ExpectCommit()
+
+	dbMock.ExpectCommit()
+
+	id, err := svc.Checkout(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, transactionID.String(), id.TransactionID)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCheckoutReservationWithVariantsOfSameProductSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	assert.NotNil(t, dbMock)
+
+	userID := uuid.New()
+	walletID := uuid.New()
+	transactionID := uuid.New()
+	reservationID := uuid.New()
+	productID := uuid.New()
+	variantSID := uuid.New()
+	variantMID := uuid.New()
+
+	items := []model.CheckoutProductRequest{
+		{
+			ProductID: productID,
+			VariantID: uuid.NullUUID{UUID: variantSID, Valid: true},
+			Qty:       1,
+		},
+		{
+			ProductID: productID,
+			VariantID: uuid.NullUUID{UUID: variantMID, Valid: true},
+			Qty:       2,
+		},
+	}
+
+	req := model.CheckoutTransactionRequest{
+		UserID:        userID,
+		ReservationID: uuid.NullUUID{UUID: reservationID, Valid: true},
+	}
+
+	productIds := []uuid.UUID{productID, productID}
+
+	dbMock.ExpectBegin()
+	// get reservation, it holds both variants of the product
+	dbMock.ExpectQuery("SELECT (.+) FROM stock_reservations WHERE (.+) FOR UPDATE").
+		WithArgs(reservationID, userID).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "user_id", "status", "expires_at", "is_expired", "transaction_id", "created_at", "updated_at"}).
+				AddRow(reservationID, userID, entity.StockReservationStatusActive, time.Now().Add(time.Minute), false, uuid.NullUUID{}, time.Now(), time.Now()),
+		)
+	itemRows := pgxmock.NewRows([]string{"id", "reservation_id", "product_id", "variant_id", "qty"})
+	for _, item := range items {
+		itemRows.AddRow(uuid.New(), reservationID, item.ProductID, item.VariantID, item.Qty)
+	}
+	dbMock.ExpectQuery("SELECT (.+) FROM stock_reservation_items (.+)").
+		WithArgs([]uuid.UUID{reservationID}).
+		WillReturnRows(itemRows)
+
+	// get product by ids, the product is returned once for both items
+	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
+		WithArgs(productIds).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(productID, "product 1", 10, decimal.NewFromInt(1000), uuid.New(), true),
+		)
+
+	// get variants by ids
+	dbMock.ExpectQuery("SELECT (.+) FROM product_variants (.+)").
+		WithArgs([]uuid.UUID{variantSID, variantMID}).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "product_id", "sku", "name", "price", "stok", "sold_count"}).
+				AddRow(variantSID, productID, "SKU-S", "S", decimal.NewFromInt(1000), 5, 0).
+				AddRow(variantMID, productID, "SKU-M", "M", decimal.NewFromInt(1500), 5, 0),
+		)
+
+	// get active sales
+	dbMock.ExpectQuery("SELECT (.+) FROM product_sales (.+)").
+		WithArgs(productIds).
+		WillReturnRows(pgxmock.NewRows(productSaleColumns))
+
+	// get wallet
+	dbMock.ExpectQuery("SELECT (.+) FROM wallets (.+) FOR UPDATE").
+		WithArgs(userID).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "user_id", "balance", "created_at", "updated_at"}).
+				AddRow(walletID, userID, initialBalance, nil, nil),
+		)
+
+	// update balance, 1 x 1000 + 2 x 1500
+	dbMock.ExpectExec("UPDATE wallets SET balance = (.+) WHERE id (.+)  ").
+		WithArgs(initialBalance.Sub(decimal.NewFromInt(4000)), walletID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+
+	// insert transaction
+	dbMock.ExpectQuery("INSERT INTO transactions (.+) VALUES (.+) RETURNING id").
+		WithArgs(userID, constant.TransactionTypePurchaseID, entity.TransactionStatusCompleted, decimal.NewFromInt(4000)).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id"}).AddRow(transactionID),
+		)
+	expectRecordAuditLog(dbMock)
+
+	// insert transaction detail
+	dbMock.ExpectCopyFrom(pgx.Identifier{entity.TransactionDetail{}.TableName()}, []string{"transaction_id", "product_id", "variant_id", "qty", "price", "sale_id"}).
+		WillReturnResult(2)
+
+	// consume the reserved stok of both variants and their product
+	dbMock.ExpectBegin()
+	for _, item := range items {
+		dbMock.ExpectExec("UPDATE product_variants SET (.+) WHERE (.+)").
+			WithArgs(item.Qty, item.VariantID.UUID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		dbMock.ExpectExec("UPDATE products SET (.+) WHERE (.+)").
+			WithArgs(item.Qty, productID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		dbMock.ExpectQuery("INSERT INTO stock_movements (.+) VALUES (.+) RETURNING (.+)").
+			WithArgs(
+				productID,
+				item.VariantID,
+				-item.Qty,
+				entity.StockMovementReasonSale,
+				uuid.NullUUID{UUID: userID, Valid: true},
+				uuid.NullUUID{UUID: transactionID, Valid: true},
+				null.String{},
+			).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"id", "stok_after", "created_at"}).AddRow(uuid.New(), 0, time.Now()),
+			)
+		expectRecordAuditLog(dbMock)
+	}
+	dbMock.ExpectCommit()
+
+	// consume reservation
+	dbMock.ExpectExec("UPDATE stock_reservations SET status = (.+) WHERE (.+)").
+		WithArgs(entity.StockReservationStatusConsumed, uuid.NullUUID{UUID: transactionID, Valid: true}, reservationID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	dbMock.ExpectCommit()
+
+	id, err := svc.Checkout(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, transactionID.String(), id.TransactionID)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCheckoutFailedProductNotFound(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	assert.NotNil(t, dbMock)
+
+	userID := uuid.New()
+	productID := uuid.New()
+	missingID := uuid.New()
+
+	req := model.CheckoutTransactionRequest{
+		UserID: userID,
+		Products: []model.CheckoutProductRequest{
+			{ProductID: productID, Qty: 1},
+			{ProductID: productID, Qty: 1},
+			{ProductID: missingID, Qty: 1},
+		},
+	}
+
+	dbMock.ExpectBegin()
+	// get product by ids, the product count matches the item count but one item is missing
+	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
+		WithArgs([]uuid.UUID{productID, productID, missingID}).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(productID, "product 1", 10, decimal.NewFromInt(1000), uuid.New(), false),
+		)
+
+	dbMock.ExpectRollback()
+
+	id, err := svc.Checkout(context.Background(), req)
+
+	var errNotFound *constant.ErrNotFound
+	assert.ErrorAs(t, err, &errNotFound)
+	assert.Contains(t, err.Error(), missingID.String())
+	assert.Equal(t, "", id.TransactionID)
+}
+
+func TestCheckoutFailedProductDeleted(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	productID := uuid.New()
+
+	dbMock.ExpectBegin()
+	// a deleted or unlisted product is not returned, so it can not be bought
+	dbMock.ExpectQuery(regexp.QuoteMeta("WHERE p.id = ANY($1) AND p.deleted_at IS NULL AND p.delisted_at IS NULL")).
+		WithArgs([]uuid.UUID{productID}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}))
+	dbMock.ExpectRollback()
+
+	id, err := svc.Checkout(context.Background(), model.CheckoutTransactionRequest{
+		UserID:   uuid.New(),
+		Products: []model.CheckoutProductRequest{{ProductID: productID, Qty: 1}},
+	})
+
+	var errNotFound *constant.ErrNotFound
+	assert.ErrorAs(t, err, &errNotFound)
+	assert.Contains(t, err.Error(), productID.String())
+	assert.Equal(t, "", id.TransactionID)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestCheckoutFailedProductNotPublished(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	draftID := uuid.New()
+	scheduledID := uuid.New()
+
+	dbMock.ExpectBegin()
+	// drafts and products scheduled for later are not returned, so they can not be bought yet
+	dbMock.ExpectQuery(regexp.QuoteMeta("WHERE p.id = ANY($1) AND p.deleted_at IS NULL AND p.delisted_at IS NULL AND p.published_at <= now()")).
+		WithArgs([]uuid.UUID{draftID, scheduledID}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}))
+	dbMock.ExpectRollback()
+
+	_, err := svc.Checkout(context.Background(), model.CheckoutTransactionRequest{
+		UserID: uuid.New(),
+		Products: []model.CheckoutProductRequest{
+			{ProductID: draftID, Qty: 1},
+			{ProductID: scheduledID, Qty: 1},
+		},
+	})
+
+	var errNotFound *constant.ErrNotFound
+	assert.ErrorAs(t, err, &errNotFound)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
 func TestCheckoutFailedStokNotEnough(t *testing.T) {
 	dbMock := initPgMock(t)
 	svc := initDepMock(dbMock)
@@ -812,8 +1377,8 @@ func TestCheckoutFailedStokNotEnough(t *testing.T) {
 	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
 		WithArgs(productIds).
 		WillReturnRows(
-			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id"}).
-				AddRow(req.Products[0].ProductID, "product 1", 2, decimal.NewFromInt(1000), uuid.New()),
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(req.Products[0].ProductID, "product 1", 2, decimal.NewFromInt(1000), uuid.New(), false),
 		)
 
 	dbMock.ExpectRollback()
@@ -825,6 +1390,46 @@ func TestCheckoutFailedStokNotEnough(t *testing.T) {
 	assert.Equal(t, "", id.TransactionID)
 }
 
+func TestCheckoutFailedVariantRequired(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc := initDepMock(dbMock)
+
+	assert.NotNil(t, dbMock)
+
+	userID := uuid.New()
+	req := model.CheckoutTransactionRequest{
+		UserID: userID,
+		Products: []model.CheckoutProductRequest{
+			{
+				ProductID: uuid.New(),
+				Qty:       1,
+			},
+		},
+	}
+
+	productIds := make([]uuid.UUID, len(req.Products))
+
+	for i, product := range req.Products {
+		productIds[i] = product.ProductID
+	}
+
+	dbMock.ExpectBegin()
+	// get product by ids
+	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
+		WithArgs(productIds).
+		WillReturnRows(
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(req.Products[0].ProductID, "product 1", 10, decimal.NewFromInt(1000), uuid.New(), true),
+		)
+
+	dbMock.ExpectRollback()
+
+	id, err := svc.Checkout(context.Background(), req)
+
+	assert.ErrorIs(t, err, constant.ErrProductVariantRequired)
+	assert.Equal(t, "", id.TransactionID)
+}
+
 func TestCheckoutFailedInsufficientBalance(t *testing.T) {
 	dbMock := initPgMock(t)
 	svc := initDepMock(dbMock)
@@ -853,10 +1458,15 @@ func TestCheckoutFailedInsufficientBalance(t *testing.T) {
 	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
 		WithArgs(productIds).
 		WillReturnRows(
-			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id"}).
-				AddRow(req.Products[0].ProductID, "product 1", 10, decimal.NewFromInt(1000), uuid.New()),
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(req.Products[0].ProductID, "product 1", 10, decimal.NewFromInt(1000), uuid.New(), false),
 		)
 
+	// get active sales
+	dbMock.ExpectQuery("SELECT (.+) FROM product_sales (.+)").
+		WithArgs(productIds).
+		WillReturnRows(pgxmock.NewRows(productSaleColumns))
+
 	// get wallet
 	dbMock.ExpectQuery("SELECT (.+) FROM wallets (.+) FOR UPDATE").
 		WithArgs(userID).
@@ -905,10 +1515,15 @@ func TestCheckoutFailedBatchReduceStok(t *testing.T) {
 	dbMock.ExpectQuery("SELECT (.+) FROM products (.+)").
 		WithArgs(productIds).
 		WillReturnRows(
-			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id"}).
-				AddRow(req.Products[0].ProductID, "product 1", 10, decimal.NewFromInt(1000), uuid.New()),
+			pgxmock.NewRows([]string{"id", "name", "stok", "price", "user_id", "has_variants"}).
+				AddRow(req.Products[0].ProductID, "product 1", 10, decimal.NewFromInt(1000), uuid.New(), false),
 		)
 
+	// get active sales
+	dbMock.ExpectQuery("SELECT (.+) FROM product_sales (.+)").
+		WithArgs(productIds).
+		WillReturnRows(pgxmock.NewRows(productSaleColumns))
+
 	// get wallet
 	dbMock.ExpectQuery("SELECT (.+) FROM wallets (.+) FOR UPDATE").
 		WithArgs(userID).
@@ -921,6 +1536,7 @@ func TestCheckoutFailedBatchReduceStok(t *testing.T) {
 	dbMock.ExpectExec("UPDATE wallets SET balance = (.+) WHERE id (.+)  ").
 		WithArgs(initialBalance.Sub(decimal.NewFromInt(2000)), walletID).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
 
 	// insert transaction
 	dbMock.ExpectQuery("INSERT INTO transactions (.+) VALUES (.+) RETURNING id").
@@ -928,9 +1544,10 @@ func TestCheckoutFailedBatchReduceStok(t *testing.T) {
 		WillReturnRows(
 			pgxmock.NewRows([]string{"id"}).AddRow(transactionID),
 		)
+	expectRecordAuditLog(dbMock)
 
 	// insert transaction detail
-	dbMock.ExpectCopyFrom(pgx.Identifier{entity.TransactionDetail{}.TableName()}, []string{"transaction_id", "product_id", "qty"}).
+	dbMock.ExpectCopyFrom(pgx.Identifier{entity.TransactionDetail{}.TableName()}, []string{"transaction_id", "product_id", "variant_id", "qty", "price", "sale_id"}).
 		WillReturnResult(1)
 
 	// update stok
@@ -3,9 +3,11 @@ package userctrl
 import (
 	"github.com/arfan21/vocagame/internal/model"
 	"github.com/arfan21/vocagame/internal/user"
+	"github.com/arfan21/vocagame/pkg/constant"
 	"github.com/arfan21/vocagame/pkg/exception"
 	"github.com/arfan21/vocagame/pkg/pkgutil"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type ControllerHTTP struct {
@@ -40,13 +42,15 @@ func (ctrl ControllerHTTP) Register(c *fiber.Ctx) error {
 }
 
 // @Summary Login user
-// @Description Login user
+// @Description Login user. With two factor authentication the response has a challenge token instead of the tokens, the login is finished at /api/v1/users/login/2fa
 // @Tags user
 // @Accept json
 // @Produce json
 // @Param body body model.UserLoginRequest true "Payload user Login Request"
 // @Success 200 {object} pkgutil.HTTPResponse{data=model.UserLoginResponse}
 // @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 429 {object} pkgutil.HTTPResponse "Too many failed attempts, retry after the Retry-After header"
 // @Failure 500 {object} pkgutil.HTTPResponse
 // @Router /api/v1/users/login [post]
 func (ctrl ControllerHTTP) Login(c *fiber.Ctx) error {
@@ -54,6 +58,9 @@ func (ctrl ControllerHTTP) Login(c *fiber.Ctx) error {
 	err := c.BodyParser(&req)
 	exception.PanicIfNeeded(err)
 
+	req.UserAgent = c.Get(fiber.HeaderUserAgent)
+	req.IP = c.IP()
+
 	res, err := ctrl.svc.Login(c.UserContext(), req)
 	exception.PanicIfNeeded(err)
 
@@ -63,14 +70,43 @@ func (ctrl ControllerHTTP) Login(c *fiber.Ctx) error {
 	})
 }
 
+// @Summary Login Two Factor
+// @Description Finish a login with the challenge token and a totp code or an unused recovery code
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param body body model.UserLoginTwoFactorRequest true "Payload Login Two Factor Request"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.UserLoginResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 429 {object} pkgutil.HTTPResponse "Too many failed attempts, retry after the Retry-After header"
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/login/2fa [post]
+func (ctrl ControllerHTTP) LoginTwoFactor(c *fiber.Ctx) error {
+	var req model.UserLoginTwoFactorRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.IP = c.IP()
+
+	res, err := ctrl.svc.LoginTwoFactor(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
 // @Summary Refresh Token user
-// @Description Refresh Token user
+// @Description Rotate the refresh token, the returned refresh token replaces the sent one. Sending a rotated refresh token again logs out every session of the user
 // @Tags user
 // @Accept json
 // @Produce json
 // @Param body body model.UserRefreshTokenRequest true "Payload user Refresh Token Request"
 // @Success 200 {object} pkgutil.HTTPResponse{data=model.UserLoginResponse}
 // @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 401 {object} pkgutil.HTTPResponse
 // @Failure 500 {object} pkgutil.HTTPResponse
 // @Router /api/v1/users/refresh-token [post]
 func (ctrl ControllerHTTP) RefreshToken(c *fiber.Ctx) error {
@@ -88,7 +124,7 @@ func (ctrl ControllerHTTP) RefreshToken(c *fiber.Ctx) error {
 }
 
 // @Summary Logout user
-// @Description Logout user
+// @Description Logout user, the refresh token and the access token of the request are revoked
 // @Tags user
 // @Accept json
 // @Produce json
@@ -103,6 +139,8 @@ func (ctrl ControllerHTTP) Logout(c *fiber.Ctx) error {
 	err := c.BodyParser(&req)
 	exception.PanicIfNeeded(err)
 
+	req.AccessToken, _ = c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+
 	err = ctrl.svc.Logout(c.UserContext(), req)
 	exception.PanicIfNeeded(err)
 
@@ -110,3 +148,452 @@ func (ctrl ControllerHTTP) Logout(c *fiber.Ctx) error {
 		Code: fiber.StatusOK,
 	})
 }
+
+// @Summary Logout user everywhere
+// @Description Revoke every access token and refresh token of the logged in user
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/logout-all [post]
+func (ctrl ControllerHTTP) LogoutAll(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.LogoutAll(c.UserContext(), uuidUserID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Get Sessions
+// @Description Get the devices the logged in user is logged in on
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Success 200 {object} pkgutil.HTTPResponse{data=[]model.UserSessionResponse}
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/sessions [get]
+func (ctrl ControllerHTTP) GetSessions(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetSessions(c.UserContext(), uuidUserID, claims.SessionID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Revoke Session
+// @Description Log out the device of the session
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param id path string true "Session ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/sessions/:id [delete]
+func (ctrl ControllerHTTP) RevokeSession(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	uuidID, err := uuid.Parse(c.Params("id"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.RevokeSession(c.UserContext(), model.UserSessionRevokeRequest{
+		ID:     uuidID,
+		UserID: uuidUserID,
+	})
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Verify Email
+// @Description Verify the email of the user with the token of the verification link
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param body body model.UserVerifyEmailRequest true "Payload Verify Email Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/verify-email [post]
+func (ctrl ControllerHTTP) VerifyEmail(c *fiber.Ctx) error {
+	var req model.UserVerifyEmailRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.VerifyEmail(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Resend Verification Email
+// @Description Send the verification link to the email of the logged in user again
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 429 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/verify-email/resend [post]
+func (ctrl ControllerHTTP) ResendVerificationEmail(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	uuidUserID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.ResendVerificationEmail(c.UserContext(), uuidUserID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Forgot Password
+// @Description Send a single use reset password link to the email, the response is the same for an unregistered email
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param body body model.UserForgotPasswordRequest true "Payload Forgot Password Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/forgot-password [post]
+func (ctrl ControllerHTTP) ForgotPassword(c *fiber.Ctx) error {
+	var req model.UserForgotPasswordRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.ForgotPassword(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Reset Password
+// @Description Set a new password with the token of the reset password link, every session of the user is logged out
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param body body model.UserResetPasswordRequest true "Payload Reset Password Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/reset-password [post]
+func (ctrl ControllerHTTP) ResetPassword(c *fiber.Ctx) error {
+	var req model.UserResetPasswordRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.ResetPassword(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Change Password
+// @Description Change the password of the logged in user, the other sessions of the user are logged out
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param body body model.UserChangePasswordRequest true "Payload Change Password Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/password [put]
+func (ctrl ControllerHTTP) ChangePassword(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.UserChangePasswordRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+	req.SessionID = claims.SessionID
+
+	err = ctrl.svc.ChangePassword(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Get Profile
+// @Description Get the profile of the logged in user with a summary of its wallet and the count of its products
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.UserProfileResponse}
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/me [get]
+func (ctrl ControllerHTTP) GetProfile(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.GetProfile(c.UserContext(), userID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Update Profile
+// @Description Update the profile of the logged in user, a new email is applied once the link sent to it is opened
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param body body model.UserUpdateProfileRequest true "Payload Update Profile Request"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.UserProfileResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/me [put]
+func (ctrl ControllerHTTP) UpdateProfile(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.UserUpdateProfileRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.UpdateProfile(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Unlock Login
+// @Description Clear the failed logins and the lockout of the email of a user, only for admin
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param id path string true "User ID"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 403 {object} pkgutil.HTTPResponse
+// @Failure 404 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/:id/unlock [post]
+func (ctrl ControllerHTTP) UnlockLogin(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.UnlockLogin(c.UserContext(), userID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary Enroll Two Factor
+// @Description Create a totp secret for the logged in user, two factor authentication is on once a code of it is confirmed
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.UserTwoFactorEnrollResponse}
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/2fa/enroll [post]
+func (ctrl ControllerHTTP) EnrollTwoFactor(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.EnrollTwoFactor(c.UserContext(), userID)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Confirm Two Factor
+// @Description Turn on two factor authentication with a code of the enrolled secret, the recovery codes are only shown in this response
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param body body model.UserTwoFactorConfirmRequest true "Payload Confirm Two Factor Request"
+// @Success 200 {object} pkgutil.HTTPResponse{data=model.UserTwoFactorRecoveryCodesResponse}
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 409 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/2fa/confirm [post]
+func (ctrl ControllerHTTP) ConfirmTwoFactor(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.UserTwoFactorConfirmRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	res, err := ctrl.svc.ConfirmTwoFactor(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+		Data: res,
+	})
+}
+
+// @Summary Disable Two Factor
+// @Description Turn off two factor authentication with the password and a totp or recovery code
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "With the bearer started"
+// @Param body body model.UserTwoFactorDisableRequest true "Payload Disable Two Factor Request"
+// @Success 200 {object} pkgutil.HTTPResponse
+// @Failure 400 {object} pkgutil.HTTPResponse{errors=[]pkgutil.ErrValidationResponse} "Error validation field"
+// @Failure 401 {object} pkgutil.HTTPResponse
+// @Failure 500 {object} pkgutil.HTTPResponse
+// @Router /api/v1/users/2fa/disable [post]
+func (ctrl ControllerHTTP) DisableTwoFactor(c *fiber.Ctx) error {
+	claims, ok := c.Locals(constant.JWTClaimsContextKey).(model.JWTClaims)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(pkgutil.HTTPResponse{
+			Code:    fiber.StatusUnauthorized,
+			Message: "invalid or expired token",
+		})
+	}
+
+	var req model.UserTwoFactorDisableRequest
+	err := c.BodyParser(&req)
+	exception.PanicIfNeeded(err)
+
+	req.UserID, err = uuid.Parse(claims.Subject)
+	exception.PanicIfNeeded(err)
+
+	err = ctrl.svc.DisableTwoFactor(c.UserContext(), req)
+	exception.PanicIfNeeded(err)
+
+	return c.Status(fiber.StatusOK).JSON(pkgutil.HTTPResponse{
+		Code: fiber.StatusOK,
+	})
+}
+
+// @Summary JWKS
+// @Description The public keys access tokens are signed with, a token is verified with the key of its kid header
+// @Tags user
+// @Produce json
+// @Success 200 {object} object "RFC 7517 JSON Web Key Set"
+// @Router /.well-known/jwks.json [get]
+func (ctrl ControllerHTTP) GetJWKS(c *fiber.Ctx) error {
+	// keys only change on a restart, a rotated out key stays in the set while its tokens are valid
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+
+	return c.Status(fiber.StatusOK).JSON(ctrl.svc.GetJWKS())
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/arfan21/vocagame/internal/entity"
 	userrepo "github.com/arfan21/vocagame/internal/user/repository"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -13,12 +14,43 @@ type Repository interface {
 	Begin(ctx context.Context) (tx pgx.Tx, err error)
 	WithTx(tx pgx.Tx) *userrepo.Repository
 
-	Create(ctx context.Context, data entity.User) (err error)
+	Create(ctx context.Context, data entity.User) (id uuid.UUID, err error)
 	GetByEmail(ctx context.Context, email string) (data entity.User, err error)
+	GetByID(ctx context.Context, id uuid.UUID) (data entity.User, err error)
+	VerifyEmail(ctx context.Context, id uuid.UUID, email string) (err error)
+	UpdatePassword(ctx context.Context, id uuid.UUID, password string) (err error)
+	UpdateProfile(ctx context.Context, data entity.User) (err error)
+	SetTOTPSecret(ctx context.Context, id uuid.UUID, secret string) (err error)
+	EnableTOTP(ctx context.Context, id uuid.UUID) (err error)
+	DisableTOTP(ctx context.Context, id uuid.UUID) (err error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) (err error)
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (err error)
 }
 
 type RepositoryRedis interface {
 	SetRefreshToken(ctx context.Context, token string, expireIn time.Duration, payload entity.UserRefreshToken) (err error)
 	IsRefreshTokenExist(ctx context.Context, token string) (payload entity.UserRefreshToken, err error)
+	MarkRefreshTokenUsed(ctx context.Context, token string, expireIn time.Duration) (firstUse bool, err error)
 	DeleteRefreshToken(ctx context.Context, token string) (err error)
+	RevokeRefreshTokenFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (err error)
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) (err error)
+	RevokeAccessToken(ctx context.Context, tokenID string, expireIn time.Duration) (err error)
+	GetTokenVersion(ctx context.Context, userID uuid.UUID) (version int, err error)
+	IncrTokenVersion(ctx context.Context, userID uuid.UUID) (version int, err error)
+	IsAccessTokenRevoked(ctx context.Context, tokenID string, userID uuid.UUID, sessionID uuid.UUID, version int) (revoked bool, err error)
+	SetSession(ctx context.Context, session entity.UserSession, expireIn time.Duration) (err error)
+	GetSession(ctx context.Context, id uuid.UUID) (session entity.UserSession, err error)
+	GetSessions(ctx context.Context, userID uuid.UUID) (sessions []entity.UserSession, err error)
+	AcquireVerificationEmail(ctx context.Context, userID uuid.UUID, interval time.Duration) (acquired bool, err error)
+	AcquirePasswordResetEmail(ctx context.Context, userID uuid.UUID, interval time.Duration) (acquired bool, err error)
+	SetPasswordResetToken(ctx context.Context, userID uuid.UUID, tokenHash string, expireIn time.Duration) (err error)
+	ConsumePasswordResetToken(ctx context.Context, tokenHash string) (userID uuid.UUID, err error)
+	GetLoginLockout(ctx context.Context, email string, ip string) (retryAfter time.Duration, err error)
+	RecordLoginFailure(ctx context.Context, email string, ip string, window time.Duration) (emailFailures int, ipFailures int, err error)
+	LockLogin(ctx context.Context, email string, ip string, emailLockout time.Duration, ipLockout time.Duration) (err error)
+	ResetLoginFailures(ctx context.Context, email string) (err error)
+	SetLoginChallenge(ctx context.Context, tokenHash string, challenge entity.UserLoginChallenge, expireIn time.Duration) (err error)
+	GetLoginChallenge(ctx context.Context, tokenHash string) (challenge entity.UserLoginChallenge, err error)
+	DeleteLoginChallenge(ctx context.Context, tokenHash string) (deleted bool, err error)
+	MarkTOTPStepUsed(ctx context.Context, userID uuid.UUID, step int64, expireIn time.Duration) (firstUse bool, err error)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/arfan21/vocagame/internal/entity"
 	"github.com/arfan21/vocagame/pkg/constant"
 	dbpostgres "github.com/arfan21/vocagame/pkg/db/postgres"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -34,13 +35,14 @@ func (r Repository) WithTx(tx pgx.Tx) *Repository {
 	return &r
 }
 
-func (r Repository) Create(ctx context.Context, data entity.User) (err error) {
+func (r Repository) Create(ctx context.Context, data entity.User) (id uuid.UUID, err error) {
 	query := `
 		INSERT INTO users (fullname, email, password)
 		VALUES ($1, $2, $3)
+		RETURNING id
 	`
 
-	_, err = r.db.Exec(ctx, query, data.Fullname, data.Email, data.Password)
+	err = r.db.QueryRow(ctx, query, data.Fullname, data.Email, data.Password).Scan(&id)
 	if err != nil {
 		var pgxError *pgconn.PgError
 		if errors.As(err, &pgxError) {
@@ -58,7 +60,7 @@ func (r Repository) Create(ctx context.Context, data entity.User) (err error) {
 
 func (r Repository) GetByEmail(ctx context.Context, email string) (data entity.User, err error) {
 	query := `
-		SELECT id, fullname, email, password
+		SELECT id, fullname, email, password, role, email_verified_at, avatar_url, phone, language, pending_email, totp_secret, totp_enabled_at, created_at, updated_at
 		FROM users
 		WHERE email = $1
 	`
@@ -68,6 +70,16 @@ func (r Repository) GetByEmail(ctx context.Context, email string) (data entity.U
 		&data.Fullname,
 		&data.Email,
 		&data.Password,
+		&data.Role,
+		&data.EmailVerifiedAt,
+		&data.AvatarURL,
+		&data.Phone,
+		&data.Language,
+		&data.PendingEmail,
+		&data.TOTPSecret,
+		&data.TOTPEnabledAt,
+		&data.CreatedAt,
+		&data.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -81,3 +93,230 @@ func (r Repository) GetByEmail(ctx context.Context, email string) (data entity.U
 
 	return
 }
+
+func (r Repository) GetByID(ctx context.Context, id uuid.UUID) (data entity.User, err error) {
+	query := `
+		SELECT id, fullname, email, password, role, email_verified_at, avatar_url, phone, language, pending_email, totp_secret, totp_enabled_at, created_at, updated_at
+		FROM users
+		WHERE id = $1
+	`
+
+	err = r.db.QueryRow(ctx, query, id).Scan(
+		&data.ID,
+		&data.Fullname,
+		&data.Email,
+		&data.Password,
+		&data.Role,
+		&data.EmailVerifiedAt,
+		&data.AvatarURL,
+		&data.Phone,
+		&data.Language,
+		&data.PendingEmail,
+		&data.TOTPSecret,
+		&data.TOTPEnabledAt,
+		&data.CreatedAt,
+		&data.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = constant.ErrUserNotFound
+		}
+
+		err = fmt.Errorf("user.repository.GetByID: failed to get user by id: %w", err)
+		return
+	}
+
+	return
+}
+
+// VerifyEmail marks email as verified when it is still the email of the user, verifying it again is a no-op.
+// When email is the pending email of the user it replaces the current email.
+func (r Repository) VerifyEmail(ctx context.Context, id uuid.UUID, email string) (err error) {
+	query := `
+		UPDATE users
+		SET
+			email = $2,
+			pending_email = CASE WHEN pending_email = $2 THEN NULL ELSE pending_email END,
+			email_verified_at = CASE WHEN email = $2 THEN COALESCE(email_verified_at, now()) ELSE now() END,
+			updated_at = CASE WHEN email = $2 THEN updated_at ELSE now() END
+		WHERE id = $1 AND (email = $2 OR pending_email = $2)
+	`
+
+	cmd, err := r.db.Exec(ctx, query, id, email)
+	if err != nil {
+		var pgxError *pgconn.PgError
+		if errors.As(err, &pgxError) {
+			if pgxError.Code == constant.ErrSQLUniqueViolation {
+				err = constant.ErrEmailAlreadyRegistered
+			}
+		}
+
+		err = fmt.Errorf("user.repository.VerifyEmail: failed to verify email: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("user.repository.VerifyEmail: failed to verify email: %w", constant.ErrEmailVerificationInvalid)
+		return
+	}
+
+	return
+}
+
+func (r Repository) UpdatePassword(ctx context.Context, id uuid.UUID, password string) (err error) {
+	query := `
+		UPDATE users
+		SET password = $1
+		WHERE id = $2
+	`
+
+	cmd, err := r.db.Exec(ctx, query, password, id)
+	if err != nil {
+		err = fmt.Errorf("user.repository.UpdatePassword: failed to update password: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("user.repository.UpdatePassword: failed to update password: %w", constant.ErrUserNotFound)
+		return
+	}
+
+	return
+}
+
+// UpdateProfile updates the profile fields of the user, the email is changed by VerifyEmail of data.PendingEmail.
+func (r Repository) UpdateProfile(ctx context.Context, data entity.User) (err error) {
+	query := `
+		UPDATE users
+		SET fullname = $1, avatar_url = $2, phone = $3, language = $4, pending_email = $5, updated_at = now()
+		WHERE id = $6
+	`
+
+	cmd, err := r.db.Exec(ctx, query, data.Fullname, data.AvatarURL, data.Phone, data.Language, data.PendingEmail, data.ID)
+	if err != nil {
+		err = fmt.Errorf("user.repository.UpdateProfile: failed to update profile: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("user.repository.UpdateProfile: failed to update profile: %w", constant.ErrUserNotFound)
+		return
+	}
+
+	return
+}
+
+// SetTOTPSecret stores the secret of an enrollment, it replaces an enrollment which was not confirmed.
+func (r Repository) SetTOTPSecret(ctx context.Context, id uuid.UUID, secret string) (err error) {
+	query := `
+		UPDATE users
+		SET totp_secret = $1
+		WHERE id = $2 AND totp_enabled_at IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, secret, id)
+	if err != nil {
+		err = fmt.Errorf("user.repository.SetTOTPSecret: failed to set totp secret: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("user.repository.SetTOTPSecret: failed to set totp secret: %w", constant.ErrTwoFactorAlreadyEnabled)
+		return
+	}
+
+	return
+}
+
+func (r Repository) EnableTOTP(ctx context.Context, id uuid.UUID) (err error) {
+	query := `
+		UPDATE users
+		SET totp_enabled_at = now()
+		WHERE id = $1 AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		err = fmt.Errorf("user.repository.EnableTOTP: failed to enable totp: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("user.repository.EnableTOTP: failed to enable totp: %w", constant.ErrTwoFactorAlreadyEnabled)
+		return
+	}
+
+	return
+}
+
+// DisableTOTP removes the secret and the recovery codes of the user.
+func (r Repository) DisableTOTP(ctx context.Context, id uuid.UUID) (err error) {
+	query := `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL
+		WHERE id = $1 AND totp_enabled_at IS NOT NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		err = fmt.Errorf("user.repository.DisableTOTP: failed to disable totp: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("user.repository.DisableTOTP: failed to disable totp: %w", constant.ErrTwoFactorNotEnabled)
+		return
+	}
+
+	_, err = r.db.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, id)
+	if err != nil {
+		err = fmt.Errorf("user.repository.DisableTOTP: failed to delete recovery codes: %w", err)
+		return
+	}
+
+	return
+}
+
+// ReplaceRecoveryCodes replaces the recovery codes of the user with codeHashes.
+func (r Repository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string) (err error) {
+	_, err = r.db.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		err = fmt.Errorf("user.repository.ReplaceRecoveryCodes: failed to delete recovery codes: %w", err)
+		return
+	}
+
+	query := `
+		INSERT INTO user_recovery_codes (user_id, code_hash)
+		SELECT $1, code_hash FROM unnest($2::text[]) AS code_hash
+	`
+
+	_, err = r.db.Exec(ctx, query, userID, codeHashes)
+	if err != nil {
+		err = fmt.Errorf("user.repository.ReplaceRecoveryCodes: failed to insert recovery codes: %w", err)
+		return
+	}
+
+	return
+}
+
+// UseRecoveryCode marks the recovery code as used, a code can only be used once.
+func (r Repository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (err error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	cmd, err := r.db.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		err = fmt.Errorf("user.repository.UseRecoveryCode: failed to use recovery code: %w", err)
+		return
+	}
+
+	if cmd.RowsAffected() == 0 {
+		err = fmt.Errorf("user.repository.UseRecoveryCode: failed to use recovery code: %w", constant.ErrTwoFactorCodeInvalid)
+		return
+	}
+
+	return
+}
@@ -5,13 +5,34 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/arfan21/vocagame/internal/entity"
 	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 )
 
+const (
+	refreshTokenUsedKeyPrefix         = "refresh_token:used:"
+	refreshTokenFamilyKeyPrefix       = "refresh_token:family:"
+	refreshTokenSessionKeyPrefix      = "refresh_token:session:"
+	userRefreshTokenFamiliesKeyPrefix = "user:refresh_token_families:"
+	revokedAccessTokenKeyPrefix       = "access_token:revoked:"
+	userTokenVersionKeyPrefix         = "user:token_version:"
+	userVerificationEmailKeyPrefix    = "user:verification_email:"
+	userPasswordResetEmailKeyPrefix   = "user:password_reset_email:"
+	userPasswordResetTokenKeyPrefix   = "user:password_reset_token:"
+	passwordResetTokenKeyPrefix       = "password_reset_token:"
+	loginFailuresEmailKeyPrefix       = "login:failures:email:"
+	loginFailuresIPKeyPrefix          = "login:failures:ip:"
+	loginLockoutEmailKeyPrefix        = "login:lockout:email:"
+	loginLockoutIPKeyPrefix           = "login:lockout:ip:"
+	loginChallengeKeyPrefix           = "login:challenge:"
+	userTOTPStepKeyPrefix             = "user:totp_step:"
+)
+
 type RepositoryRedis struct {
 	client *redis.Client
 }
@@ -20,6 +41,7 @@ func NewRedis(client *redis.Client) *RepositoryRedis {
 	return &RepositoryRedis{client: client}
 }
 
+// SetRefreshToken stores the refresh token and adds it to the token family of the payload.
 func (r RepositoryRedis) SetRefreshToken(ctx context.Context, token string, expireIn time.Duration, payload entity.UserRefreshToken) (err error) {
 	payloadJson, err := json.Marshal(payload)
 	if err != nil {
@@ -27,7 +49,21 @@ func (r RepositoryRedis) SetRefreshToken(ctx context.Context, token string, expi
 		return
 	}
 
-	err = r.client.Set(ctx, token, string(payloadJson), expireIn).Err()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, token, string(payloadJson), expireIn)
+
+		if payload.FamilyID != uuid.Nil {
+			familyKey := refreshTokenFamilyKeyPrefix + payload.FamilyID.String()
+			pipe.SAdd(ctx, familyKey, token)
+			pipe.Expire(ctx, familyKey, expireIn)
+
+			userKey := userRefreshTokenFamiliesKeyPrefix + payload.ID.String()
+			pipe.SAdd(ctx, userKey, payload.FamilyID.String())
+			pipe.Expire(ctx, userKey, expireIn)
+		}
+
+		return nil
+	})
 	if err != nil {
 		err = fmt.Errorf("user.repository_redis.SetRefreshToken: failed to set refresh token: %w", err)
 		return
@@ -55,6 +91,18 @@ func (r RepositoryRedis) IsRefreshTokenExist(ctx context.Context, token string)
 	return
 }
 
+// MarkRefreshTokenUsed marks the refresh token as rotated. firstUse is false when it was already rotated,
+// the check and the mark are atomic so only one of concurrent refreshes with the same token succeeds.
+func (r RepositoryRedis) MarkRefreshTokenUsed(ctx context.Context, token string, expireIn time.Duration) (firstUse bool, err error) {
+	firstUse, err = r.client.SetNX(ctx, refreshTokenUsedKeyPrefix+token, 1, expireIn).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.MarkRefreshTokenUsed: failed to mark refresh token: %w", err)
+		return
+	}
+
+	return
+}
+
 func (r RepositoryRedis) DeleteRefreshToken(ctx context.Context, token string) (err error) {
 	err = r.client.Del(ctx, token).Err()
 	if err != nil && !errors.Is(err, redis.Nil) {
@@ -64,3 +112,450 @@ func (r RepositoryRedis) DeleteRefreshToken(ctx context.Context, token string) (
 
 	return
 }
+
+// RevokeRefreshTokenFamily deletes every refresh token rotated from the same login.
+func (r RepositoryRedis) RevokeRefreshTokenFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (err error) {
+	keys, err := r.refreshTokenFamilyKeys(ctx, familyID)
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.RevokeRefreshTokenFamily: %w", err)
+		return
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		pipe.SRem(ctx, userRefreshTokenFamiliesKeyPrefix+userID.String(), familyID.String())
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.RevokeRefreshTokenFamily: failed to delete refresh tokens: %w", err)
+		return
+	}
+
+	return
+}
+
+// RevokeUserRefreshTokens deletes every refresh token of the user, which logs out all of its sessions.
+func (r RepositoryRedis) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) (err error) {
+	userKey := userRefreshTokenFamiliesKeyPrefix + userID.String()
+
+	familyIDs, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.RevokeUserRefreshTokens: failed to get token families: %w", err)
+		return
+	}
+
+	keys := []string{userKey}
+	for _, familyID := range familyIDs {
+		id, errParse := uuid.Parse(familyID)
+		if errParse != nil {
+			continue
+		}
+
+		familyKeys, errFamily := r.refreshTokenFamilyKeys(ctx, id)
+		if errFamily != nil {
+			err = fmt.Errorf("user.repository_redis.RevokeUserRefreshTokens: %w", errFamily)
+			return
+		}
+
+		keys = append(keys, familyKeys...)
+	}
+
+	err = r.client.Del(ctx, keys...).Err()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.RevokeUserRefreshTokens: failed to delete refresh tokens: %w", err)
+		return
+	}
+
+	return
+}
+
+// RevokeAccessToken denies the access token with jti tokenID until it expires.
+func (r RepositoryRedis) RevokeAccessToken(ctx context.Context, tokenID string, expireIn time.Duration) (err error) {
+	err = r.client.Set(ctx, revokedAccessTokenKeyPrefix+tokenID, 1, expireIn).Err()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.RevokeAccessToken: failed to revoke access token: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetTokenVersion returns the token version of the user, zero when it was never bumped.
+func (r RepositoryRedis) GetTokenVersion(ctx context.Context, userID uuid.UUID) (version int, err error) {
+	version, err = r.client.Get(ctx, userTokenVersionKeyPrefix+userID.String()).Int()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+
+		err = fmt.Errorf("user.repository_redis.GetTokenVersion: failed to get token version: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r RepositoryRedis) IncrTokenVersion(ctx context.Context, userID uuid.UUID) (version int, err error) {
+	result, err := r.client.Incr(ctx, userTokenVersionKeyPrefix+userID.String()).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.IncrTokenVersion: failed to increment token version: %w", err)
+		return
+	}
+
+	return int(result), nil
+}
+
+// IsAccessTokenRevoked reports whether the access token was revoked on logout, its session was revoked,
+// or it is older than the token version of the user. sessionID is uuid.Nil for tokens issued before sessions.
+func (r RepositoryRedis) IsAccessTokenRevoked(ctx context.Context, tokenID string, userID uuid.UUID, sessionID uuid.UUID, version int) (revoked bool, err error) {
+	results, err := r.client.MGet(ctx,
+		revokedAccessTokenKeyPrefix+tokenID,
+		userTokenVersionKeyPrefix+userID.String(),
+		refreshTokenSessionKeyPrefix+sessionID.String(),
+	).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.IsAccessTokenRevoked: failed to get revocation: %w", err)
+		return
+	}
+
+	if tokenID != "" && results[0] != nil {
+		return true, nil
+	}
+
+	if sessionID != uuid.Nil && results[2] == nil {
+		return true, nil
+	}
+
+	currentVersion, ok := results[1].(string)
+	if !ok {
+		return false, nil
+	}
+
+	current, err := strconv.Atoi(currentVersion)
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.IsAccessTokenRevoked: failed to parse token version: %w", err)
+		return
+	}
+
+	return version < current, nil
+}
+
+// SetSession stores the session, it expires with the refresh token last issued for it.
+func (r RepositoryRedis) SetSession(ctx context.Context, session entity.UserSession, expireIn time.Duration) (err error) {
+	sessionJson, err := json.Marshal(session)
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.SetSession: failed to marshal session: %w", err)
+		return
+	}
+
+	err = r.client.Set(ctx, refreshTokenSessionKeyPrefix+session.ID.String(), string(sessionJson), expireIn).Err()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.SetSession: failed to set session: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r RepositoryRedis) GetSession(ctx context.Context, id uuid.UUID) (session entity.UserSession, err error) {
+	resultStr, err := r.client.Get(ctx, refreshTokenSessionKeyPrefix+id.String()).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = constant.ErrSessionNotFound
+		}
+		err = fmt.Errorf("user.repository_redis.GetSession: failed to get session: %w", err)
+		return
+	}
+
+	err = json.Unmarshal([]byte(resultStr), &session)
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.GetSession: failed to unmarshal session: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetSessions returns the sessions of the user which are not revoked or expired.
+func (r RepositoryRedis) GetSessions(ctx context.Context, userID uuid.UUID) (sessions []entity.UserSession, err error) {
+	familyIDs, err := r.client.SMembers(ctx, userRefreshTokenFamiliesKeyPrefix+userID.String()).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.GetSessions: failed to get token families: %w", err)
+		return
+	}
+
+	sessions = make([]entity.UserSession, 0, len(familyIDs))
+	if len(familyIDs) == 0 {
+		return
+	}
+
+	keys := make([]string, len(familyIDs))
+	for i, familyID := range familyIDs {
+		keys[i] = refreshTokenSessionKeyPrefix + familyID
+	}
+
+	results, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.GetSessions: failed to get sessions: %w", err)
+		return
+	}
+
+	for _, result := range results {
+		resultStr, ok := result.(string)
+		if !ok {
+			continue
+		}
+
+		var session entity.UserSession
+		err = json.Unmarshal([]byte(resultStr), &session)
+		if err != nil {
+			err = fmt.Errorf("user.repository_redis.GetSessions: failed to unmarshal session: %w", err)
+			return
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return
+}
+
+// AcquireVerificationEmail rate limits verification emails, acquired is false when one was sent to the user within interval.
+func (r RepositoryRedis) AcquireVerificationEmail(ctx context.Context, userID uuid.UUID, interval time.Duration) (acquired bool, err error) {
+	acquired, err = r.client.SetNX(ctx, userVerificationEmailKeyPrefix+userID.String(), 1, interval).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.AcquireVerificationEmail: failed to acquire: %w", err)
+		return
+	}
+
+	return
+}
+
+// AcquirePasswordResetEmail rate limits password reset emails, acquired is false when one was sent to the user within interval.
+func (r RepositoryRedis) AcquirePasswordResetEmail(ctx context.Context, userID uuid.UUID, interval time.Duration) (acquired bool, err error) {
+	acquired, err = r.client.SetNX(ctx, userPasswordResetEmailKeyPrefix+userID.String(), 1, interval).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.AcquirePasswordResetEmail: failed to acquire: %w", err)
+		return
+	}
+
+	return
+}
+
+// SetPasswordResetToken stores the hash of the reset token of the user, it replaces the previous token of the user.
+func (r RepositoryRedis) SetPasswordResetToken(ctx context.Context, userID uuid.UUID, tokenHash string, expireIn time.Duration) (err error) {
+	userKey := userPasswordResetTokenKeyPrefix + userID.String()
+
+	previousHash, err := r.client.Get(ctx, userKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		err = fmt.Errorf("user.repository_redis.SetPasswordResetToken: failed to get previous token: %w", err)
+		return
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previousHash != "" {
+			pipe.Del(ctx, passwordResetTokenKeyPrefix+previousHash)
+		}
+
+		pipe.Set(ctx, passwordResetTokenKeyPrefix+tokenHash, userID.String(), expireIn)
+		pipe.Set(ctx, userKey, tokenHash, expireIn)
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.SetPasswordResetToken: failed to set token: %w", err)
+		return
+	}
+
+	return
+}
+
+// ConsumePasswordResetToken deletes the reset token and returns its user, a token can only be consumed once.
+func (r RepositoryRedis) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (userID uuid.UUID, err error) {
+	result, err := r.client.GetDel(ctx, passwordResetTokenKeyPrefix+tokenHash).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = constant.ErrPasswordResetTokenInvalid
+		}
+		err = fmt.Errorf("user.repository_redis.ConsumePasswordResetToken: failed to get token: %w", err)
+		return
+	}
+
+	userID, err = uuid.Parse(result)
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.ConsumePasswordResetToken: failed to parse user id: %w", err)
+		return
+	}
+
+	err = r.client.Del(ctx, userPasswordResetTokenKeyPrefix+userID.String()).Err()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.ConsumePasswordResetToken: failed to delete token of user: %w", err)
+		return
+	}
+
+	return
+}
+
+// refreshTokenFamilyKeys returns the keys of the tokens in the family, their used marks, the session and the family itself.
+func (r RepositoryRedis) refreshTokenFamilyKeys(ctx context.Context, familyID uuid.UUID) (keys []string, err error) {
+	familyKey := refreshTokenFamilyKeyPrefix + familyID.String()
+
+	tokens, err := r.client.SMembers(ctx, familyKey).Result()
+	if err != nil {
+		err = fmt.Errorf("failed to get tokens of family: %w", err)
+		return
+	}
+
+	keys = make([]string, 0, len(tokens)*2+2)
+	keys = append(keys, familyKey, refreshTokenSessionKeyPrefix+familyID.String())
+	for _, token := range tokens {
+		keys = append(keys, token, refreshTokenUsedKeyPrefix+token)
+	}
+
+	return
+}
+
+// GetLoginLockout returns how long login with email or from ip is locked out, it is zero when neither is.
+func (r RepositoryRedis) GetLoginLockout(ctx context.Context, email string, ip string) (retryAfter time.Duration, err error) {
+	cmds, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.PTTL(ctx, loginLockoutEmailKeyPrefix+email)
+		if ip != "" {
+			pipe.PTTL(ctx, loginLockoutIPKeyPrefix+ip)
+		}
+
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.GetLoginLockout: failed to get lockout: %w", err)
+		return
+	}
+
+	// a missing key has a negative ttl
+	for _, cmd := range cmds {
+		retryAfter = max(retryAfter, cmd.(*redis.DurationCmd).Val())
+	}
+
+	return
+}
+
+// RecordLoginFailure counts a failed login with email and from ip, the counts expire window after the first failure.
+func (r RepositoryRedis) RecordLoginFailure(ctx context.Context, email string, ip string, window time.Duration) (emailFailures int, ipFailures int, err error) {
+	var emailCmd, ipCmd *redis.IntCmd
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		emailKey := loginFailuresEmailKeyPrefix + email
+		emailCmd = pipe.Incr(ctx, emailKey)
+		pipe.ExpireNX(ctx, emailKey, window)
+
+		if ip != "" {
+			ipKey := loginFailuresIPKeyPrefix + ip
+			ipCmd = pipe.Incr(ctx, ipKey)
+			pipe.ExpireNX(ctx, ipKey, window)
+		}
+
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.RecordLoginFailure: failed to count failure: %w", err)
+		return
+	}
+
+	emailFailures = int(emailCmd.Val())
+	if ipCmd != nil {
+		ipFailures = int(ipCmd.Val())
+	}
+
+	return
+}
+
+// LockLogin locks out login with email and from ip, a zero lockout leaves it unchanged.
+func (r RepositoryRedis) LockLogin(ctx context.Context, email string, ip string, emailLockout time.Duration, ipLockout time.Duration) (err error) {
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if emailLockout > 0 {
+			pipe.Set(ctx, loginLockoutEmailKeyPrefix+email, 1, emailLockout)
+		}
+
+		if ip != "" && ipLockout > 0 {
+			pipe.Set(ctx, loginLockoutIPKeyPrefix+ip, 1, ipLockout)
+		}
+
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.LockLogin: failed to lock: %w", err)
+		return
+	}
+
+	return
+}
+
+// ResetLoginFailures clears the failed logins and the lockout of email.
+func (r RepositoryRedis) ResetLoginFailures(ctx context.Context, email string) (err error) {
+	err = r.client.Del(ctx, loginFailuresEmailKeyPrefix+email, loginLockoutEmailKeyPrefix+email).Err()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.ResetLoginFailures: failed to reset: %w", err)
+		return
+	}
+
+	return
+}
+
+// SetLoginChallenge stores the challenge by the hash of its token.
+func (r RepositoryRedis) SetLoginChallenge(ctx context.Context, tokenHash string, challenge entity.UserLoginChallenge, expireIn time.Duration) (err error) {
+	challengeJson, err := json.Marshal(challenge)
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.SetLoginChallenge: failed to marshal challenge: %w", err)
+		return
+	}
+
+	err = r.client.Set(ctx, loginChallengeKeyPrefix+tokenHash, string(challengeJson), expireIn).Err()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.SetLoginChallenge: failed to set challenge: %w", err)
+		return
+	}
+
+	return
+}
+
+func (r RepositoryRedis) GetLoginChallenge(ctx context.Context, tokenHash string) (challenge entity.UserLoginChallenge, err error) {
+	challengeJson, err := r.client.Get(ctx, loginChallengeKeyPrefix+tokenHash).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			err = constant.ErrTwoFactorChallengeInvalid
+		}
+
+		err = fmt.Errorf("user.repository_redis.GetLoginChallenge: failed to get challenge: %w", err)
+		return
+	}
+
+	err = json.Unmarshal([]byte(challengeJson), &challenge)
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.GetLoginChallenge: failed to unmarshal challenge: %w", err)
+		return
+	}
+
+	return
+}
+
+// DeleteLoginChallenge deletes the challenge, deleted is false when it was already deleted, e.g. by a concurrent login.
+func (r RepositoryRedis) DeleteLoginChallenge(ctx context.Context, tokenHash string) (deleted bool, err error) {
+	n, err := r.client.Del(ctx, loginChallengeKeyPrefix+tokenHash).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.DeleteLoginChallenge: failed to delete challenge: %w", err)
+		return
+	}
+
+	deleted = n > 0
+
+	return
+}
+
+// MarkTOTPStepUsed marks the time step of a totp code of the user as used, firstUse is false when it was used before.
+func (r RepositoryRedis) MarkTOTPStepUsed(ctx context.Context, userID uuid.UUID, step int64, expireIn time.Duration) (firstUse bool, err error) {
+	key := userTOTPStepKeyPrefix + userID.String() + ":" + strconv.FormatInt(step, 10)
+
+	firstUse, err = r.client.SetNX(ctx, key, 1, expireIn).Result()
+	if err != nil {
+		err = fmt.Errorf("user.repository_redis.MarkTOTPStepUsed: failed to mark step used: %w", err)
+		return
+	}
+
+	return
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/pkg/jwtkey"
+	"github.com/google/uuid"
 )
 
 type Service interface {
@@ -11,4 +13,22 @@ type Service interface {
 	Login(ctx context.Context, req model.UserLoginRequest) (res model.UserLoginResponse, err error)
 	RefreshToken(ctx context.Context, req model.UserRefreshTokenRequest) (res model.UserLoginResponse, err error)
 	Logout(ctx context.Context, req model.UserLogoutRequest) (err error)
+	LogoutAll(ctx context.Context, userID uuid.UUID) (err error)
+	IsAccessTokenRevoked(ctx context.Context, claims model.JWTClaims) (revoked bool, err error)
+	GetSessions(ctx context.Context, userID uuid.UUID, currentSessionID string) (res []model.UserSessionResponse, err error)
+	RevokeSession(ctx context.Context, req model.UserSessionRevokeRequest) (err error)
+	VerifyEmail(ctx context.Context, req model.UserVerifyEmailRequest) (err error)
+	ResendVerificationEmail(ctx context.Context, userID uuid.UUID) (err error)
+	ForgotPassword(ctx context.Context, req model.UserForgotPasswordRequest) (err error)
+	ResetPassword(ctx context.Context, req model.UserResetPasswordRequest) (err error)
+	ChangePassword(ctx context.Context, req model.UserChangePasswordRequest) (err error)
+	GetProfile(ctx context.Context, userID uuid.UUID) (res model.UserProfileResponse, err error)
+	UpdateProfile(ctx context.Context, req model.UserUpdateProfileRequest) (res model.UserProfileResponse, err error)
+	UnlockLogin(ctx context.Context, userID uuid.UUID) (err error)
+	LoginTwoFactor(ctx context.Context, req model.UserLoginTwoFactorRequest) (res model.UserLoginResponse, err error)
+	EnrollTwoFactor(ctx context.Context, userID uuid.UUID) (res model.UserTwoFactorEnrollResponse, err error)
+	ConfirmTwoFactor(ctx context.Context, req model.UserTwoFactorConfirmRequest) (res model.UserTwoFactorRecoveryCodesResponse, err error)
+	DisableTwoFactor(ctx context.Context, req model.UserTwoFactorDisableRequest) (err error)
+	VerifyStepUp(ctx context.Context, userID uuid.UUID, code string) (err error)
+	GetJWKS() jwtkey.JWKS
 }
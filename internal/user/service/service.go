@@ -2,27 +2,67 @@ package usersvc
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/arfan21/vocagame/config"
+	"github.com/arfan21/vocagame/internal/audit"
 	"github.com/arfan21/vocagame/internal/entity"
 	"github.com/arfan21/vocagame/internal/model"
+	"github.com/arfan21/vocagame/internal/product"
 	"github.com/arfan21/vocagame/internal/user"
+	"github.com/arfan21/vocagame/internal/wallet"
 	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/jwtkey"
+	"github.com/arfan21/vocagame/pkg/logger"
+	"github.com/arfan21/vocagame/pkg/mailer"
+	"github.com/arfan21/vocagame/pkg/totp"
 	"github.com/arfan21/vocagame/pkg/validation"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/guregu/null.v4"
 )
 
+const emailVerificationAudience = "email_verification"
+
 type Service struct {
-	repo      user.Repository
-	repoRedis user.RepositoryRedis
+	repo       user.Repository
+	repoRedis  user.RepositoryRedis
+	auditSvc   audit.Service
+	walletSvc  wallet.Service
+	productSvc product.Service
+	mail       mailer.Mailer
+	keys       *jwtkey.KeySet
 }
 
-func New(repo user.Repository, repoRedis user.RepositoryRedis) *Service {
-	return &Service{repo: repo, repoRedis: repoRedis}
+func New(
+	repo user.Repository,
+	repoRedis user.RepositoryRedis,
+	auditSvc audit.Service,
+	walletSvc wallet.Service,
+	productSvc product.Service,
+	mail mailer.Mailer,
+	keys *jwtkey.KeySet,
+) *Service {
+	return &Service{
+		repo:       repo,
+		repoRedis:  repoRedis,
+		auditSvc:   auditSvc,
+		walletSvc:  walletSvc,
+		productSvc: productSvc,
+		mail:       mail,
+		keys:       keys,
+	}
 }
 
 func (s Service) Register(ctx context.Context, req model.UserRegisterRequest) (err error) {
@@ -44,12 +84,63 @@ func (s Service) Register(ctx context.Context, req model.UserRegisterRequest) (e
 		Password: string(hashedPassword),
 	}
 
-	err = s.repo.Create(ctx, data)
+	data.ID, err = s.register(ctx, data)
+	if err != nil {
+		return
+	}
+
+	// registration succeeds without the email, the user can request it again
+	errSend := s.sendVerificationEmail(ctx, data)
+	if errSend != nil {
+		logger.Log(ctx).Error().Err(errSend).Msg("user.service.Register: failed to send verification email")
+	}
+
+	return
+}
+
+// register stores the user and its audit log in one transaction.
+func (s Service) register(ctx context.Context, data entity.User) (id uuid.UUID, err error) {
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("user.service.Register: failed to begin transaction: %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("user.service.Register: failed to commit transaction: %w", err)
+			return
+		}
+	}()
+
+	id, err = s.repo.WithTx(tx).Create(ctx, data)
 	if err != nil {
 		err = fmt.Errorf("user.service.Register: failed to register user: %w", err)
 		return
 	}
 
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntityUser,
+		EntityID:   id,
+		After: model.UserResponse{
+			ID:       id,
+			Fullname: data.Fullname,
+			Email:    data.Email,
+			Role:     entity.UserRoleUser,
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("user.service.Register: failed to record audit log: %w", err)
+		return
+	}
+
 	return
 }
 
@@ -60,55 +151,219 @@ func (s Service) Login(ctx context.Context, req model.UserLoginRequest) (res mod
 		return
 	}
 
-	data, err := s.repo.GetByEmail(ctx, req.Email)
+	throttleEmail := strings.ToLower(req.Email)
+
+	retryAfter, err := s.repoRedis.GetLoginLockout(ctx, throttleEmail, req.IP)
 	if err != nil {
-		err = fmt.Errorf("user.service.Login: failed to get user by email: %w", err)
+		err = fmt.Errorf("user.service.Login: %w", err)
 		return
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(data.Password), []byte(req.Password))
-	if err != nil {
+	if retryAfter > 0 {
+		err = fmt.Errorf("user.service.Login: %w", loginLockedOut(retryAfter))
+		return
+	}
+
+	data, err := s.repo.GetByEmail(ctx, req.Email)
+	if err == nil {
+		err = bcrypt.CompareHashAndPassword([]byte(data.Password), []byte(req.Password))
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
 			err = constant.ErrEmailOrPasswordInvalid
 		}
-		err = fmt.Errorf("user.service.Login: failed to compare password: %w", err)
+	}
+
+	// an unknown email counts as a failure too, so the lockout does not tell which emails are registered
+	if errors.Is(err, constant.ErrEmailOrPasswordInvalid) {
+		var errRecord error
+		retryAfter, errRecord = s.recordLoginFailure(ctx, throttleEmail, req.IP)
+		if errRecord != nil {
+			logger.Log(ctx).Error().Err(errRecord).Msg("user.service.Login: failed to record login failure")
+		}
+
+		if retryAfter > 0 {
+			err = loginLockedOut(retryAfter)
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("user.service.Login: failed to check credentials: %w", err)
+		return
+	}
+
+	// with two factor authentication the failures are reset once the code is verified, a wrong code counts as a failure
+	if data.TOTPEnabledAt.Valid {
+		res, err = s.createLoginChallenge(ctx, entity.UserLoginChallenge{
+			UserID:     data.ID,
+			Email:      throttleEmail,
+			DeviceName: req.DeviceName,
+			UserAgent:  req.UserAgent,
+			IP:         req.IP,
+		})
+		if err != nil {
+			err = fmt.Errorf("user.service.Login: %w", err)
+			return
+		}
+
+		return
+	}
+
+	err = s.repoRedis.ResetLoginFailures(ctx, throttleEmail)
+	if err != nil {
+		logger.Log(ctx).Error().Err(err).Msg("user.service.Login: failed to reset login failures")
+		err = nil
+	}
+
+	res, err = s.issueLoginTokens(ctx, data, req.DeviceName, req.UserAgent, req.IP)
+	if err != nil {
+		err = fmt.Errorf("user.service.Login: %w", err)
+		return
+	}
+
+	return
+}
+
+// issueLoginTokens starts a new session of the user on the device.
+func (s Service) issueLoginTokens(ctx context.Context, data entity.User, deviceName string, userAgent string, ip string) (res model.UserLoginResponse, err error) {
+	now := time.Now()
+	familyID := uuid.New()
+
+	return s.issueTokens(ctx, entity.UserRefreshToken{
+		ID:            data.ID,
+		Email:         data.Email,
+		Role:          data.Role,
+		EmailVerified: data.EmailVerifiedAt.Valid,
+		FamilyID:      familyID,
+	}, entity.UserSession{
+		ID:         familyID,
+		UserID:     data.ID,
+		DeviceName: deviceName,
+		UserAgent:  userAgent,
+		IP:         ip,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	})
+}
+
+func loginLockedOut(retryAfter time.Duration) *constant.ErrLockedOut {
+	return &constant.ErrLockedOut{
+		Message:    "too many failed login attempts, try again later",
+		RetryAfter: retryAfter,
+	}
+}
+
+// recordLoginFailure counts a failed login and locks out the email or ip once it reaches its limit.
+// Before the limit every failure of the email after LOGIN_THROTTLE_DELAY_AFTER delays the next attempt,
+// doubling up to the lockout. retryAfter is the longest lockout set, it is zero when none is.
+func (s Service) recordLoginFailure(ctx context.Context, email string, ip string) (retryAfter time.Duration, err error) {
+	cfg := config.GetConfig().LoginThrottle
+	lockout := time.Duration(cfg.Lockout) * time.Second
+
+	emailFailures, ipFailures, err := s.repoRedis.RecordLoginFailure(ctx, email, ip, time.Duration(cfg.Window)*time.Second)
+	if err != nil {
+		return
+	}
+
+	var emailLockout, ipLockout time.Duration
+	switch {
+	case cfg.MaxAttemptsPerEmail > 0 && emailFailures >= cfg.MaxAttemptsPerEmail:
+		emailLockout = lockout
+	case cfg.DelayBase > 0 && emailFailures > cfg.DelayAfter:
+		emailLockout = time.Duration(cfg.DelayBase) * time.Second << min(emailFailures-cfg.DelayAfter-1, 16)
+		emailLockout = min(emailLockout, lockout)
+	}
+
+	if cfg.MaxAttemptsPerIP > 0 && ipFailures >= cfg.MaxAttemptsPerIP {
+		ipLockout = lockout
+	}
+
+	if emailLockout <= 0 && ipLockout <= 0 {
+		return
+	}
+
+	err = s.repoRedis.LockLogin(ctx, email, ip, emailLockout, ipLockout)
+	if err != nil {
+		return
+	}
+
+	retryAfter = max(emailLockout, ipLockout)
+
+	return
+}
+
+// UnlockLogin clears the failed logins and the lockout of the email of the user.
+// A lockout of the IP the attempts came from is left to expire.
+func (s Service) UnlockLogin(ctx context.Context, userID uuid.UUID) (err error) {
+	data, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("user.service.UnlockLogin: failed to get user: %w", err)
+		return
+	}
+
+	err = s.repoRedis.ResetLoginFailures(ctx, strings.ToLower(data.Email))
+	if err != nil {
+		err = fmt.Errorf("user.service.UnlockLogin: %w", err)
+		return
+	}
+
+	err = s.auditSvc.Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUnlock,
+		EntityType: entity.AuditEntityUser,
+		EntityID:   userID,
+	})
+	if err != nil {
+		err = fmt.Errorf("user.service.UnlockLogin: failed to record audit log: %w", err)
 		return
 	}
 
+	return
+}
+
+// issueTokens creates an access token and a refresh token in the token family of payload, and stores the session of the family.
+func (s Service) issueTokens(ctx context.Context, payload entity.UserRefreshToken, session entity.UserSession) (res model.UserLoginResponse, err error) {
+	tokenVersion, err := s.repoRedis.GetTokenVersion(ctx, payload.ID)
+	if err != nil {
+		err = fmt.Errorf("failed to get token version: %w", err)
+		return
+	}
+
+	claims := model.JWTClaims{
+		Email:         payload.Email,
+		Role:          payload.Role,
+		TokenVersion:  tokenVersion,
+		SessionID:     payload.FamilyID.String(),
+		EmailVerified: payload.EmailVerified,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: payload.ID.String(),
+		},
+	}
+
 	accessTokenExpire := time.Duration(config.GetConfig().JWT.AccessTokenExpireIn) * time.Second
 
-	accessToken, err := s.CreateJWTWithExpiry(
-		data.ID.String(),
-		data.Email,
-		config.GetConfig().JWT.AccessTokenSecret,
-		accessTokenExpire,
-	)
+	accessToken, err := s.CreateJWTWithExpiry(claims, accessTokenExpire)
 
 	if err != nil {
-		err = fmt.Errorf("user.service.Login: failed to create access token: %w", err)
+		err = fmt.Errorf("failed to create access token: %w", err)
 		return
 	}
 
 	refreshTokenExpire := time.Duration(config.GetConfig().JWT.RefreshTokenExpireIn) * time.Second
 
-	refreshToken, err := s.CreateJWTWithExpiry(
-		data.ID.String(),
-		data.Email,
-		config.GetConfig().JWT.RefreshTokenSecret,
-		refreshTokenExpire,
-	)
+	refreshToken, err := s.createRefreshToken(claims, refreshTokenExpire)
 
 	if err != nil {
-		err = fmt.Errorf("user.service.Login: failed to create refresh token: %w", err)
+		err = fmt.Errorf("failed to create refresh token: %w", err)
 		return
 	}
 
-	err = s.repoRedis.SetRefreshToken(ctx, refreshToken, refreshTokenExpire, entity.UserRefreshToken{
-		Email: data.Email,
-		ID:    data.ID,
-	})
+	err = s.repoRedis.SetRefreshToken(ctx, refreshToken, refreshTokenExpire, payload)
+	if err != nil {
+		err = fmt.Errorf("failed to set refresh token: %w", err)
+		return
+	}
+
+	err = s.repoRedis.SetSession(ctx, session, refreshTokenExpire)
 	if err != nil {
-		err = fmt.Errorf("user.service.Login: failed to set refresh token: %w", err)
+		err = fmt.Errorf("failed to set session: %w", err)
 		return
 	}
 
@@ -123,19 +378,27 @@ func (s Service) Login(ctx context.Context, req model.UserLoginRequest) (res mod
 	return
 }
 
-func (s Service) CreateJWTWithExpiry(id, email, secret string, expiry time.Duration) (token string, err error) {
-	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, model.JWTClaims{
-		Email: email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "Synapsis ID",
-			Subject:   id,
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
-	})
+// CreateJWTWithExpiry signs an access token with the signing key, services verify it with the keys of the JWKS.
+func (s Service) CreateJWTWithExpiry(claims model.JWTClaims, expiry time.Duration) (token string, err error) {
+	claims.RegisteredClaims = registeredClaims(claims.Subject, expiry)
+
+	token, err = s.keys.Sign(claims)
+	if err != nil {
+		err = fmt.Errorf("usecase: failed to create jwt token: %w", err)
+		return
+	}
+
+	return
+}
+
+// createRefreshToken signs with JWT_REFRESH_TOKEN_SECRET, a refresh token is only accepted by RefreshToken
+// which looks it up in redis and never as an access token.
+func (s Service) createRefreshToken(claims model.JWTClaims, expiry time.Duration) (token string, err error) {
+	claims.RegisteredClaims = registeredClaims(claims.Subject, expiry)
 
-	token, err = jwtToken.SignedString([]byte(secret))
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	token, err = jwtToken.SignedString([]byte(config.GetConfig().JWT.RefreshTokenSecret))
 	if err != nil {
 		err = fmt.Errorf("usecase: failed to create jwt token: %w", err)
 		return
@@ -144,6 +407,24 @@ func (s Service) CreateJWTWithExpiry(id, email, secret string, expiry time.Durat
 	return
 }
 
+func registeredClaims(subject string, expiry time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+
+	return jwt.RegisteredClaims{
+		Issuer:    config.GetConfig().JWT.Issuer,
+		Subject:   subject,
+		ID:        uuid.NewString(),
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiry)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+	}
+}
+
+// GetJWKS returns the public keys access tokens are verified with.
+func (s Service) GetJWKS() jwtkey.JWKS {
+	return s.keys.JWKS()
+}
+
 func (s Service) RefreshToken(ctx context.Context, req model.UserRefreshTokenRequest) (res model.UserLoginResponse, err error) {
 	err = validation.Validate(req)
 	if err != nil {
@@ -157,23 +438,66 @@ func (s Service) RefreshToken(ctx context.Context, req model.UserRefreshTokenReq
 		return
 	}
 
-	accessTokenExpire := time.Duration(config.GetConfig().JWT.AccessTokenExpireIn) * time.Second
-	accessToken, err := s.CreateJWTWithExpiry(
-		payload.ID.String(),
-		payload.Email,
-		config.GetConfig().JWT.AccessTokenSecret,
-		accessTokenExpire,
-	)
+	refreshTokenExpire := time.Duration(config.GetConfig().JWT.RefreshTokenExpireIn) * time.Second
 
+	firstUse, err := s.repoRedis.MarkRefreshTokenUsed(ctx, req.RefreshToken, refreshTokenExpire)
 	if err != nil {
-		err = fmt.Errorf("user.service.RefreshToken: failed to create access token: %w", err)
+		err = fmt.Errorf("user.service.RefreshToken: failed to mark refresh token used: %w", err)
 		return
 	}
 
-	res = model.UserLoginResponse{
-		AccessToken: accessToken,
-		ExpiresIn:   int(accessTokenExpire.Seconds()),
-		TokenType:   "Bearer",
+	// a rotated token is only presented again when it was stolen, either by the thief or by the user
+	// after the thief refreshed first. Every session of the user is revoked since we can not tell which.
+	if !firstUse {
+		err = s.repoRedis.RevokeUserRefreshTokens(ctx, payload.ID)
+		if err != nil {
+			err = fmt.Errorf("user.service.RefreshToken: failed to revoke refresh tokens: %w", err)
+			return
+		}
+
+		err = fmt.Errorf("user.service.RefreshToken: refresh token reused: %w", constant.ErrRefreshTokenReused)
+		return
+	}
+
+	// the user may have verified the email or changed role since the token was issued
+	data, err := s.repo.GetByID(ctx, payload.ID)
+	if err != nil {
+		err = fmt.Errorf("user.service.RefreshToken: failed to get user: %w", err)
+		return
+	}
+
+	payload.Email = data.Email
+	payload.Role = data.Role
+	payload.EmailVerified = data.EmailVerifiedAt.Valid
+
+	// tokens issued before token families start a new family
+	if payload.FamilyID == uuid.Nil {
+		payload.FamilyID = uuid.New()
+	}
+
+	now := time.Now()
+
+	session, err := s.repoRedis.GetSession(ctx, payload.FamilyID)
+	if err != nil {
+		if !errors.Is(err, constant.ErrSessionNotFound) {
+			err = fmt.Errorf("user.service.RefreshToken: failed to get session: %w", err)
+			return
+		}
+
+		// tokens issued before sessions get a session on their first refresh
+		session = entity.UserSession{
+			ID:        payload.FamilyID,
+			UserID:    payload.ID,
+			CreatedAt: now,
+		}
+	}
+
+	session.LastUsedAt = now
+
+	res, err = s.issueTokens(ctx, payload, session)
+	if err != nil {
+		err = fmt.Errorf("user.service.RefreshToken: %w", err)
+		return
 	}
 
 	return
@@ -186,9 +510,1066 @@ func (s Service) Logout(ctx context.Context, req model.UserLogoutRequest) (err e
 		return
 	}
 
-	err = s.repoRedis.DeleteRefreshToken(ctx, req.RefreshToken)
+	// tokens issued before access tokens got a jti can only be revoked by logging out everywhere
+	if req.AccessToken.ID != "" && req.AccessToken.ExpiresAt != nil {
+		expireIn := time.Until(req.AccessToken.ExpiresAt.Time)
+		if expireIn > 0 {
+			err = s.repoRedis.RevokeAccessToken(ctx, req.AccessToken.ID, expireIn)
+			if err != nil {
+				err = fmt.Errorf("user.service.Logout: failed to revoke access token: %w", err)
+				return
+			}
+		}
+	}
+
+	payload, err := s.repoRedis.IsRefreshTokenExist(ctx, req.RefreshToken)
+	if err != nil {
+		// the token is already expired or revoked
+		if errors.Is(err, constant.ErrUnauthorizedAccess) {
+			return nil
+		}
+
+		err = fmt.Errorf("user.service.Logout: failed to check refresh token: %w", err)
+		return
+	}
+
+	if payload.FamilyID == uuid.Nil {
+		err = s.repoRedis.DeleteRefreshToken(ctx, req.RefreshToken)
+		if err != nil {
+			err = fmt.Errorf("user.service.Logout: failed to delete refresh token: %w", err)
+			return
+		}
+
+		return
+	}
+
+	err = s.repoRedis.RevokeRefreshTokenFamily(ctx, payload.ID, payload.FamilyID)
+	if err != nil {
+		err = fmt.Errorf("user.service.Logout: failed to revoke refresh token family: %w", err)
+		return
+	}
+
+	return
+}
+
+// LogoutAll revokes every access token and refresh token of the user.
+func (s Service) LogoutAll(ctx context.Context, userID uuid.UUID) (err error) {
+	_, err = s.repoRedis.IncrTokenVersion(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("user.service.LogoutAll: failed to bump token version: %w", err)
+		return
+	}
+
+	err = s.repoRedis.RevokeUserRefreshTokens(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("user.service.LogoutAll: failed to revoke refresh tokens: %w", err)
+		return
+	}
+
+	return
+}
+
+// IsAccessTokenRevoked is checked by middleware.JWTAuth for every valid access token.
+func (s Service) IsAccessTokenRevoked(ctx context.Context, claims model.JWTClaims) (revoked bool, err error) {
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		err = fmt.Errorf("user.service.IsAccessTokenRevoked: failed to parse subject: %w", err)
+		return
+	}
+
+	var sessionID uuid.UUID
+	if claims.SessionID != "" {
+		sessionID, err = uuid.Parse(claims.SessionID)
+		if err != nil {
+			err = fmt.Errorf("user.service.IsAccessTokenRevoked: failed to parse session id: %w", err)
+			return
+		}
+	}
+
+	revoked, err = s.repoRedis.IsAccessTokenRevoked(ctx, claims.ID, userID, sessionID, claims.TokenVersion)
+	if err != nil {
+		err = fmt.Errorf("user.service.IsAccessTokenRevoked: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetSessions lists the sessions of the user, the most recently used first.
+func (s Service) GetSessions(ctx context.Context, userID uuid.UUID, currentSessionID string) (res []model.UserSessionResponse, err error) {
+	sessions, err := s.repoRedis.GetSessions(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("user.service.GetSessions: failed to get sessions: %w", err)
+		return
+	}
+
+	slices.SortFunc(sessions, func(a, b entity.UserSession) int {
+		return b.LastUsedAt.Compare(a.LastUsedAt)
+	})
+
+	res = make([]model.UserSessionResponse, len(sessions))
+	for i, session := range sessions {
+		res[i] = model.UserSessionResponse{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.ID.String() == currentSessionID,
+		}
+	}
+
+	return
+}
+
+// RevokeSession logs out the device of the session, its refresh tokens and access tokens stop working.
+func (s Service) RevokeSession(ctx context.Context, req model.UserSessionRevokeRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("user.service.RevokeSession: failed to validate request: %w", err)
+		return
+	}
+
+	session, err := s.repoRedis.GetSession(ctx, req.ID)
+	if err != nil {
+		err = fmt.Errorf("user.service.RevokeSession: failed to get session: %w", err)
+		return
+	}
+
+	// a session of another user is reported as not found, so session ids of other users can not be probed
+	if session.UserID != req.UserID {
+		err = fmt.Errorf("user.service.RevokeSession: session of another user: %w", constant.ErrSessionNotFound)
+		return
+	}
+
+	err = s.repoRedis.RevokeRefreshTokenFamily(ctx, req.UserID, req.ID)
+	if err != nil {
+		err = fmt.Errorf("user.service.RevokeSession: failed to revoke refresh token family: %w", err)
+		return
+	}
+
+	return
+}
+
+// sendVerificationEmail sends a signed link which verifies the email of data.
+func (s Service) sendVerificationEmail(ctx context.Context, data entity.User) (err error) {
+	cfg := config.GetConfig().EmailVerification
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, model.EmailVerificationClaims{
+		Email: data.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    config.GetConfig().JWT.Issuer,
+			Subject:   data.ID.String(),
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(cfg.ExpireIn) * time.Second)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+
+	token, err := jwtToken.SignedString([]byte(cfg.Secret))
+	if err != nil {
+		err = fmt.Errorf("failed to create verification token: %w", err)
+		return
+	}
+
+	link, err := linkWithToken(cfg.URL, token)
+	if err != nil {
+		err = fmt.Errorf("failed to create verification link: %w", err)
+		return
+	}
+
+	err = s.mail.Send(ctx, mailer.Message{
+		To:      data.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below.\n\n%s\n\nIf you did not create an account, you can ignore this email.\n",
+			data.Fullname,
+			link,
+		),
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to send verification email: %w", err)
+		return
+	}
+
+	return
+}
+
+// VerifyEmail confirms the email in the token of a verification link.
+func (s Service) VerifyEmail(ctx context.Context, req model.UserVerifyEmailRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("user.service.VerifyEmail: failed to validate request: %w", err)
+		return
+	}
+
+	var claims model.EmailVerificationClaims
+	_, err = jwt.ParseWithClaims(req.Token, &claims, func(t *jwt.Token) (interface{}, error) {
+		secret := config.GetConfig().EmailVerification.Secret
+		// jwt accepts an empty key, which would let anyone sign a token
+		if secret == "" {
+			return nil, errors.New("EMAIL_VERIFICATION_SECRET is empty")
+		}
+
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}), jwt.WithAudience(emailVerificationAudience))
+	if err != nil {
+		err = fmt.Errorf("user.service.VerifyEmail: failed to parse token: %w: %w", constant.ErrEmailVerificationInvalid, err)
+		return
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		err = fmt.Errorf("user.service.VerifyEmail: failed to parse subject: %w", constant.ErrEmailVerificationInvalid)
+		return
+	}
+
+	err = s.repo.VerifyEmail(ctx, userID, claims.Email)
+	if err != nil {
+		err = fmt.Errorf("user.service.VerifyEmail: %w", err)
+		return
+	}
+
+	return
+}
+
+// ResendVerificationEmail sends the verification link again, at most once per EMAIL_VERIFICATION_RESEND_INTERVAL.
+func (s Service) ResendVerificationEmail(ctx context.Context, userID uuid.UUID) (err error) {
+	data, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("user.service.ResendVerificationEmail: failed to get user: %w", err)
+		return
+	}
+
+	// a pending email is verified before the current one, which stays verified until it is replaced
+	if data.PendingEmail.Valid {
+		data.Email = data.PendingEmail.String
+	} else if data.EmailVerifiedAt.Valid {
+		err = constant.ErrEmailAlreadyVerified
+		return
+	}
+
+	interval := time.Duration(config.GetConfig().EmailVerification.ResendInterval) * time.Second
+
+	acquired, err := s.repoRedis.AcquireVerificationEmail(ctx, userID, interval)
+	if err != nil {
+		err = fmt.Errorf("user.service.ResendVerificationEmail: %w", err)
+		return
+	}
+
+	if !acquired {
+		err = constant.ErrEmailVerificationTooSoon
+		return
+	}
+
+	err = s.sendVerificationEmail(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("user.service.ResendVerificationEmail: %w", err)
+		return
+	}
+
+	return
+}
+
+// linkWithToken adds token as the token query parameter of base.
+func linkWithToken(base string, token string) (link string, err error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		err = fmt.Errorf("failed to parse url: %w", err)
+		return
+	}
+
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// hashToken is the key of a secret token such as a reset token, the token itself is only sent to the user.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken returns 32 random bytes encoded for urls.
+func randomToken() (token string, err error) {
+	b := make([]byte, 32)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = fmt.Errorf("failed to generate token: %w", err)
+		return
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+
+	return
+}
+
+// ForgotPassword sends a single use reset link to the email. It succeeds for an unknown email too,
+// so the response does not tell whether an email is registered.
+func (s Service) ForgotPassword(ctx context.Context, req model.UserForgotPasswordRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("user.service.ForgotPassword: failed to validate request: %w", err)
+		return
+	}
+
+	data, err := s.repo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, constant.ErrEmailOrPasswordInvalid) {
+			return nil
+		}
+
+		err = fmt.Errorf("user.service.ForgotPassword: failed to get user by email: %w", err)
+		return
+	}
+
+	cfg := config.GetConfig().PasswordReset
+
+	acquired, err := s.repoRedis.AcquirePasswordResetEmail(ctx, data.ID, time.Duration(cfg.ResendInterval)*time.Second)
+	if err != nil {
+		err = fmt.Errorf("user.service.ForgotPassword: %w", err)
+		return
+	}
+
+	// the previous link was sent moments ago, it is still valid
+	if !acquired {
+		return nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		err = fmt.Errorf("user.service.ForgotPassword: %w", err)
+		return
+	}
+
+	expireIn := time.Duration(cfg.ExpireIn) * time.Second
+
+	err = s.repoRedis.SetPasswordResetToken(ctx, data.ID, hashToken(token), expireIn)
+	if err != nil {
+		err = fmt.Errorf("user.service.ForgotPassword: %w", err)
+		return
+	}
+
+	link, err := linkWithToken(cfg.URL, token)
+	if err != nil {
+		err = fmt.Errorf("user.service.ForgotPassword: failed to create reset link: %w", err)
+		return
+	}
+
+	err = s.mail.Send(ctx, mailer.Message{
+		To:      data.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to reset your password, it can be used once within %d minutes.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+			data.Fullname,
+			int(expireIn.Minutes()),
+			link,
+		),
+	})
+	if err != nil {
+		err = fmt.Errorf("user.service.ForgotPassword: failed to send reset email: %w", err)
+		return
+	}
+
+	return
+}
+
+// ResetPassword sets the password of the user of the reset token and logs out every session of the user.
+func (s Service) ResetPassword(ctx context.Context, req model.UserResetPasswordRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("user.service.ResetPassword: failed to validate request: %w", err)
+		return
+	}
+
+	userID, err := s.repoRedis.ConsumePasswordResetToken(ctx, hashToken(req.Token))
+	if err != nil {
+		err = fmt.Errorf("user.service.ResetPassword: %w", err)
+		return
+	}
+
+	err = s.updatePassword(ctx, userID, req.Password)
+	if err != nil {
+		err = fmt.Errorf("user.service.ResetPassword: %w", err)
+		return
+	}
+
+	err = s.LogoutAll(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("user.service.ResetPassword: %w", err)
+		return
+	}
+
+	return
+}
+
+// ChangePassword sets a new password after checking the current one, the other sessions of the user are logged out.
+func (s Service) ChangePassword(ctx context.Context, req model.UserChangePasswordRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("user.service.ChangePassword: failed to validate request: %w", err)
+		return
+	}
+
+	data, err := s.repo.GetByID(ctx, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("user.service.ChangePassword: failed to get user: %w", err)
+		return
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(data.Password), []byte(req.CurrentPassword))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			err = constant.ErrCurrentPasswordInvalid
+		}
+		err = fmt.Errorf("user.service.ChangePassword: failed to compare password: %w", err)
+		return
+	}
+
+	err = s.updatePassword(ctx, req.UserID, req.NewPassword)
+	if err != nil {
+		err = fmt.Errorf("user.service.ChangePassword: %w", err)
+		return
+	}
+
+	sessions, err := s.repoRedis.GetSessions(ctx, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("user.service.ChangePassword: failed to get sessions: %w", err)
+		return
+	}
+
+	for _, session := range sessions {
+		if session.ID.String() == req.SessionID {
+			continue
+		}
+
+		err = s.repoRedis.RevokeRefreshTokenFamily(ctx, req.UserID, session.ID)
+		if err != nil {
+			err = fmt.Errorf("user.service.ChangePassword: failed to revoke session: %w", err)
+			return
+		}
+	}
+
+	return
+}
+
+// updatePassword hashes and stores the password with an audit log, the password itself is not logged.
+func (s Service) updatePassword(ctx context.Context, userID uuid.UUID, password string) (err error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		err = fmt.Errorf("failed to hash password: %w", err)
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).UpdatePassword(ctx, userID, string(hashedPassword))
+	if err != nil {
+		err = fmt.Errorf("failed to update password: %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionPasswordChange,
+		EntityType: entity.AuditEntityUser,
+		EntityID:   userID,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to record audit log: %w", err)
+		return
+	}
+
+	return
+}
+
+// GetProfile returns the profile of the user with a summary of its wallet and the count of its products.
+func (s Service) GetProfile(ctx context.Context, userID uuid.UUID) (res model.UserProfileResponse, err error) {
+	data, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("user.service.GetProfile: failed to get user: %w", err)
+		return
+	}
+
+	res = profileResponse(data)
+
+	walletData, err := s.walletSvc.GetByUserID(ctx, userID, false)
+	if err != nil && !errors.Is(err, constant.ErrWalletNotFound) {
+		err = fmt.Errorf("user.service.GetProfile: failed to get wallet: %w", err)
+		return
+	}
+
+	if err == nil {
+		res.Wallet = &model.UserWalletSummaryResponse{
+			ID:      walletData.ID,
+			Balance: walletData.Balance,
+		}
+	}
+
+	products, err := s.productSvc.GetOwnProducts(ctx, userID, model.GetListOwnProductRequest{Page: 1, Limit: 1})
+	if err != nil {
+		err = fmt.Errorf("user.service.GetProfile: failed to count products: %w", err)
+		return
+	}
+
+	res.ProductCount = products.TotalData
+
+	return
+}
+
+// UpdateProfile replaces the profile of the user. A different email becomes the pending email
+// and a verification link is sent to it, the current email is kept until the link is opened.
+func (s Service) UpdateProfile(ctx context.Context, req model.UserUpdateProfileRequest) (res model.UserProfileResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("user.service.UpdateProfile: failed to validate request: %w", err)
+		return
+	}
+
+	before, err := s.repo.GetByID(ctx, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("user.service.UpdateProfile: failed to get user: %w", err)
+		return
+	}
+
+	data := before
+	data.Fullname = req.Fullname
+	data.AvatarURL = null.NewString(req.AvatarURL, req.AvatarURL != "")
+	data.Phone = null.NewString(req.Phone, req.Phone != "")
+	data.Language = req.Language
+	if data.Language == "" {
+		data.Language = "en"
+	}
+
+	// sending the current email again cancels a pending change
+	data.PendingEmail = null.NewString(req.Email, req.Email != before.Email)
+	if data.PendingEmail.Valid {
+		var owner entity.User
+		owner, err = s.repo.GetByEmail(ctx, req.Email)
+		if err == nil && owner.ID != req.UserID {
+			err = fmt.Errorf("user.service.UpdateProfile: %w", constant.ErrEmailAlreadyRegistered)
+			return
+		}
+
+		if err != nil && !errors.Is(err, constant.ErrEmailOrPasswordInvalid) {
+			err = fmt.Errorf("user.service.UpdateProfile: failed to check email: %w", err)
+			return
+		}
+	}
+
+	err = s.updateProfile(ctx, before, data)
+	if err != nil {
+		err = fmt.Errorf("user.service.UpdateProfile: %w", err)
+		return
+	}
+
+	// the profile is updated without the email, the user can request it again
+	if data.PendingEmail.Valid && data.PendingEmail != before.PendingEmail {
+		pending := data
+		pending.Email = data.PendingEmail.String
+
+		errSend := s.sendVerificationEmail(ctx, pending)
+		if errSend != nil {
+			logger.Log(ctx).Error().Err(errSend).Msg("user.service.UpdateProfile: failed to send verification email")
+		}
+	}
+
+	res, err = s.GetProfile(ctx, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("user.service.UpdateProfile: %w", err)
+		return
+	}
+
+	return
+}
+
+// updateProfile stores the profile and its audit log in one transaction.
+func (s Service) updateProfile(ctx context.Context, before entity.User, data entity.User) (err error) {
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).UpdateProfile(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("failed to update profile: %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityUser,
+		EntityID:   data.ID,
+		Before:     profileResponse(before),
+		After:      profileResponse(data),
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to record audit log: %w", err)
+		return
+	}
+
+	return
+}
+
+func profileResponse(data entity.User) model.UserProfileResponse {
+	return model.UserProfileResponse{
+		ID:               data.ID,
+		Fullname:         data.Fullname,
+		Email:            data.Email,
+		EmailVerified:    data.EmailVerifiedAt.Valid,
+		PendingEmail:     data.PendingEmail,
+		Role:             data.Role,
+		AvatarURL:        data.AvatarURL,
+		Phone:            data.Phone,
+		Language:         data.Language,
+		TwoFactorEnabled: data.TOTPEnabledAt.Valid,
+		CreatedAt:        data.CreatedAt,
+		UpdatedAt:        data.UpdatedAt,
+	}
+}
+
+// createLoginChallenge stores the login until its two factor code is sent, only the hash of the token is stored.
+func (s Service) createLoginChallenge(ctx context.Context, challenge entity.UserLoginChallenge) (res model.UserLoginResponse, err error) {
+	token, err := randomToken()
+	if err != nil {
+		err = fmt.Errorf("failed to create challenge: %w", err)
+		return
+	}
+
+	expireIn := config.GetConfig().TwoFactor.ChallengeExpireIn
+
+	err = s.repoRedis.SetLoginChallenge(ctx, hashToken(token), challenge, time.Duration(expireIn)*time.Second)
+	if err != nil {
+		err = fmt.Errorf("failed to create challenge: %w", err)
+		return
+	}
+
+	res = model.UserLoginResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     token,
+		ExpiresInChallenge: expireIn,
+	}
+
+	return
+}
+
+// LoginTwoFactor finishes a login of a user with two factor authentication. A wrong code counts as a failed login,
+// the challenge stays valid until it expires or the login is locked out.
+func (s Service) LoginTwoFactor(ctx context.Context, req model.UserLoginTwoFactorRequest) (res model.UserLoginResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("user.service.LoginTwoFactor: failed to validate request: %w", err)
+		return
+	}
+
+	tokenHash := hashToken(req.ChallengeToken)
+
+	challenge, err := s.repoRedis.GetLoginChallenge(ctx, tokenHash)
+	if err != nil {
+		err = fmt.Errorf("user.service.LoginTwoFactor: %w", err)
+		return
+	}
+
+	retryAfter, err := s.repoRedis.GetLoginLockout(ctx, challenge.Email, req.IP)
+	if err != nil {
+		err = fmt.Errorf("user.service.LoginTwoFactor: %w", err)
+		return
+	}
+
+	if retryAfter > 0 {
+		err = fmt.Errorf("user.service.LoginTwoFactor: %w", loginLockedOut(retryAfter))
+		return
+	}
+
+	data, err := s.repo.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		err = fmt.Errorf("user.service.LoginTwoFactor: failed to get user: %w", err)
+		return
+	}
+
+	// two factor authentication was disabled after the challenge was created
+	if !data.TOTPEnabledAt.Valid {
+		err = fmt.Errorf("user.service.LoginTwoFactor: %w", constant.ErrTwoFactorChallengeInvalid)
+		return
+	}
+
+	err = s.verifyTwoFactorCode(ctx, data, req.Code)
+	if errors.Is(err, constant.ErrTwoFactorCodeInvalid) {
+		var errRecord error
+		retryAfter, errRecord = s.recordLoginFailure(ctx, challenge.Email, req.IP)
+		if errRecord != nil {
+			logger.Log(ctx).Error().Err(errRecord).Msg("user.service.LoginTwoFactor: failed to record login failure")
+		}
+
+		if retryAfter > 0 {
+			err = loginLockedOut(retryAfter)
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("user.service.LoginTwoFactor: failed to verify code: %w", err)
+		return
+	}
+
+	deleted, err := s.repoRedis.DeleteLoginChallenge(ctx, tokenHash)
+	if err != nil {
+		err = fmt.Errorf("user.service.LoginTwoFactor: %w", err)
+		return
+	}
+
+	// another request finished the login with the same challenge
+	if !deleted {
+		err = fmt.Errorf("user.service.LoginTwoFactor: %w", constant.ErrTwoFactorChallengeInvalid)
+		return
+	}
+
+	err = s.repoRedis.ResetLoginFailures(ctx, challenge.Email)
+	if err != nil {
+		logger.Log(ctx).Error().Err(err).Msg("user.service.LoginTwoFactor: failed to reset login failures")
+		err = nil
+	}
+
+	res, err = s.issueLoginTokens(ctx, data, challenge.DeviceName, challenge.UserAgent, challenge.IP)
+	if err != nil {
+		err = fmt.Errorf("user.service.LoginTwoFactor: %w", err)
+		return
+	}
+
+	return
+}
+
+// verifyTwoFactorCode accepts a totp code of the user, which works once, or an unused recovery code.
+func (s Service) verifyTwoFactorCode(ctx context.Context, data entity.User, code string) (err error) {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	if len(code) != totp.Digits {
+		err = s.repo.UseRecoveryCode(ctx, data.ID, hashToken(code))
+		return
+	}
+
+	return s.verifyTOTP(ctx, data, code)
+}
+
+// verifyTOTP accepts a code of the step before or after now, a code is rejected once it was used.
+func (s Service) verifyTOTP(ctx context.Context, data entity.User, code string) (err error) {
+	step, ok := totp.Validate(data.TOTPSecret.String, code, time.Now(), 1)
+	if !ok {
+		err = constant.ErrTwoFactorCodeInvalid
+		return
+	}
+
+	firstUse, err := s.repoRedis.MarkTOTPStepUsed(ctx, data.ID, step, 3*totp.Period)
+	if err != nil {
+		return
+	}
+
+	if !firstUse {
+		err = constant.ErrTwoFactorCodeInvalid
+		return
+	}
+
+	return
+}
+
+// EnrollTwoFactor creates a totp secret for the user, two factor authentication is on once a code of it is confirmed.
+// Enrolling again replaces a secret which was not confirmed.
+func (s Service) EnrollTwoFactor(ctx context.Context, userID uuid.UUID) (res model.UserTwoFactorEnrollResponse, err error) {
+	data, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("user.service.EnrollTwoFactor: failed to get user: %w", err)
+		return
+	}
+
+	if data.TOTPEnabledAt.Valid {
+		err = fmt.Errorf("user.service.EnrollTwoFactor: %w", constant.ErrTwoFactorAlreadyEnabled)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		err = fmt.Errorf("user.service.EnrollTwoFactor: %w", err)
+		return
+	}
+
+	err = s.repo.SetTOTPSecret(ctx, userID, secret)
+	if err != nil {
+		err = fmt.Errorf("user.service.EnrollTwoFactor: %w", err)
+		return
+	}
+
+	res = model.UserTwoFactorEnrollResponse{
+		Secret: secret,
+		URI:    totp.URI(config.GetConfig().TwoFactor.Issuer, data.Email, secret),
+	}
+
+	return
+}
+
+// ConfirmTwoFactor turns on two factor authentication with a code of the enrolled secret and returns the recovery codes.
+func (s Service) ConfirmTwoFactor(ctx context.Context, req model.UserTwoFactorConfirmRequest) (res model.UserTwoFactorRecoveryCodesResponse, err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("user.service.ConfirmTwoFactor: failed to validate request: %w", err)
+		return
+	}
+
+	data, err := s.repo.GetByID(ctx, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("user.service.ConfirmTwoFactor: failed to get user: %w", err)
+		return
+	}
+
+	if data.TOTPEnabledAt.Valid {
+		err = fmt.Errorf("user.service.ConfirmTwoFactor: %w", constant.ErrTwoFactorAlreadyEnabled)
+		return
+	}
+
+	if !data.TOTPSecret.Valid {
+		err = fmt.Errorf("user.service.ConfirmTwoFactor: %w", constant.ErrTwoFactorNotEnrolled)
+		return
+	}
+
+	err = s.verifyTOTP(ctx, data, req.Code)
+	if err != nil {
+		err = fmt.Errorf("user.service.ConfirmTwoFactor: failed to verify code: %w", err)
+		return
+	}
+
+	codes := make([]string, config.GetConfig().TwoFactor.RecoveryCodes)
+	codeHashes := make([]string, len(codes))
+	for i := range codes {
+		codes[i], err = recoveryCode()
+		if err != nil {
+			err = fmt.Errorf("user.service.ConfirmTwoFactor: %w", err)
+			return
+		}
+
+		codeHashes[i] = hashToken(strings.ReplaceAll(codes[i], "-", ""))
+	}
+
+	err = s.enableTwoFactor(ctx, req.UserID, codeHashes)
+	if err != nil {
+		err = fmt.Errorf("user.service.ConfirmTwoFactor: %w", err)
+		return
+	}
+
+	res.RecoveryCodes = codes
+
+	return
+}
+
+// recoveryCode returns 10 random lowercase base32 characters, grouped by five for reading.
+func recoveryCode() (code string, err error) {
+	b := make([]byte, 5)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = fmt.Errorf("failed to generate recovery code: %w", err)
+		return
+	}
+
+	code = strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	code = code[:5] + "-" + code[5:]
+
+	return
+}
+
+func (s Service) enableTwoFactor(ctx context.Context, userID uuid.UUID, codeHashes []string) (err error) {
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("failed to commit transaction: %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).EnableTOTP(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("failed to enable two factor authentication: %w", err)
+		return
+	}
+
+	err = s.repo.WithTx(tx).ReplaceRecoveryCodes(ctx, userID, codeHashes)
+	if err != nil {
+		err = fmt.Errorf("failed to store recovery codes: %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionEnable2FA,
+		EntityType: entity.AuditEntityUser,
+		EntityID:   userID,
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to record audit log: %w", err)
+		return
+	}
+
+	return
+}
+
+// DisableTwoFactor turns off two factor authentication, it needs the password and a totp or recovery code.
+func (s Service) DisableTwoFactor(ctx context.Context, req model.UserTwoFactorDisableRequest) (err error) {
+	err = validation.Validate(req)
+	if err != nil {
+		err = fmt.Errorf("user.service.DisableTwoFactor: failed to validate request: %w", err)
+		return
+	}
+
+	data, err := s.repo.GetByID(ctx, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("user.service.DisableTwoFactor: failed to get user: %w", err)
+		return
+	}
+
+	if !data.TOTPEnabledAt.Valid {
+		err = fmt.Errorf("user.service.DisableTwoFactor: %w", constant.ErrTwoFactorNotEnabled)
+		return
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(data.Password), []byte(req.Password))
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			err = constant.ErrCurrentPasswordInvalid
+		}
+		err = fmt.Errorf("user.service.DisableTwoFactor: failed to compare password: %w", err)
+		return
+	}
+
+	err = s.verifyTwoFactorCode(ctx, data, req.Code)
+	if err != nil {
+		err = fmt.Errorf("user.service.DisableTwoFactor: failed to verify code: %w", err)
+		return
+	}
+
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("user.service.DisableTwoFactor: failed to begin transaction: %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("user.service.DisableTwoFactor: failed to commit transaction: %w", err)
+			return
+		}
+	}()
+
+	err = s.repo.WithTx(tx).DisableTOTP(ctx, req.UserID)
+	if err != nil {
+		err = fmt.Errorf("user.service.DisableTwoFactor: %w", err)
+		return
+	}
+
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionDisable2FA,
+		EntityType: entity.AuditEntityUser,
+		EntityID:   req.UserID,
+	})
+	if err != nil {
+		err = fmt.Errorf("user.service.DisableTwoFactor: failed to record audit log: %w", err)
+		return
+	}
+
+	return
+}
+
+// VerifyStepUp asks a user with two factor authentication for a code again before a sensitive action.
+// It passes users without two factor authentication, wrong codes count as failed logins.
+func (s Service) VerifyStepUp(ctx context.Context, userID uuid.UUID, code string) (err error) {
+	data, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		err = fmt.Errorf("user.service.VerifyStepUp: failed to get user: %w", err)
+		return
+	}
+
+	if !data.TOTPEnabledAt.Valid {
+		return
+	}
+
+	if code == "" {
+		err = fmt.Errorf("user.service.VerifyStepUp: %w", constant.ErrTwoFactorRequired)
+		return
+	}
+
+	throttleEmail := strings.ToLower(data.Email)
+	ip, _ := ctx.Value(constant.ClientIPContextKey).(string)
+
+	retryAfter, err := s.repoRedis.GetLoginLockout(ctx, throttleEmail, ip)
+	if err != nil {
+		err = fmt.Errorf("user.service.VerifyStepUp: %w", err)
+		return
+	}
+
+	if retryAfter > 0 {
+		err = fmt.Errorf("user.service.VerifyStepUp: %w", loginLockedOut(retryAfter))
+		return
+	}
+
+	err = s.verifyTwoFactorCode(ctx, data, code)
+	if errors.Is(err, constant.ErrTwoFactorCodeInvalid) {
+		var errRecord error
+		retryAfter, errRecord = s.recordLoginFailure(ctx, throttleEmail, ip)
+		if errRecord != nil {
+			logger.Log(ctx).Error().Err(errRecord).Msg("user.service.VerifyStepUp: failed to record login failure")
+		}
+
+		if retryAfter > 0 {
+			err = loginLockedOut(retryAfter)
+		}
+	}
+
 	if err != nil {
-		err = fmt.Errorf("user.service.Logout: failed to delete refresh token: %w", err)
+		err = fmt.Errorf("user.service.VerifyStepUp: failed to verify code: %w", err)
 		return
 	}
 
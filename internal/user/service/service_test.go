@@ -0,0 +1,1366 @@
+package usersvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arfan21/vocagame/config"
+	auditrepo "github.com/arfan21/vocagame/internal/audit/repository"
+	auditsvc "github.com/arfan21/vocagame/internal/audit/service"
+	"github.com/arfan21/vocagame/internal/entity"
+	"github.com/arfan21/vocagame/internal/model"
+	productrepo "github.com/arfan21/vocagame/internal/product/repository"
+	productsvc "github.com/arfan21/vocagame/internal/product/service"
+	userrepo "github.com/arfan21/vocagame/internal/user/repository"
+	walletrepo "github.com/arfan21/vocagame/internal/wallet/repository"
+	walletsvc "github.com/arfan21/vocagame/internal/wallet/service"
+	"github.com/arfan21/vocagame/pkg/constant"
+	"github.com/arfan21/vocagame/pkg/jwtkey"
+	"github.com/arfan21/vocagame/pkg/mailer"
+	"github.com/arfan21/vocagame/pkg/totp"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/guregu/null.v4"
+)
+
+var userColumns = []string{
+	"id", "fullname", "email", "password", "role", "email_verified_at", "avatar_url", "phone", "language",
+	"pending_email", "totp_secret", "totp_enabled_at", "created_at", "updated_at",
+}
+
+type fakeRedisEntry struct {
+	value    string
+	members  map[string]bool
+	expireAt time.Time
+}
+
+// fakeRedis answers the commands used by userrepo.RepositoryRedis from memory, the client never dials.
+// Keys expire by its own clock, which only moves with advance.
+type fakeRedis struct {
+	mu   sync.Mutex
+	now  time.Time
+	data map[string]*fakeRedisEntry
+}
+
+func (f *fakeRedis) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (f *fakeRedis) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		return f.process(cmd)
+	}
+}
+
+func (f *fakeRedis) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		for _, cmd := range cmds {
+			err := f.process(cmd)
+			if err != nil && err != redis.Nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func (f *fakeRedis) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+}
+
+func (f *fakeRedis) get(key string) *fakeRedisEntry {
+	entry, ok := f.data[key]
+	if !ok {
+		return nil
+	}
+
+	if !entry.expireAt.IsZero() && !f.now.Before(entry.expireAt) {
+		delete(f.data, key)
+		return nil
+	}
+
+	return entry
+}
+
+func fakeRedisArg(arg any) string {
+	if b, ok := arg.([]byte); ok {
+		return string(b)
+	}
+
+	return fmt.Sprint(arg)
+}
+
+func (f *fakeRedis) process(cmd redis.Cmder) error {
+	args := make([]string, len(cmd.Args()))
+	for i, arg := range cmd.Args() {
+		args[i] = fakeRedisArg(arg)
+	}
+
+	switch args[0] {
+	case "multi", "exec":
+	case "get", "getdel":
+		entry := f.get(args[1])
+		if entry == nil {
+			cmd.SetErr(redis.Nil)
+			return redis.Nil
+		}
+
+		if args[0] == "getdel" {
+			delete(f.data, args[1])
+		}
+
+		cmd.(*redis.StringCmd).SetVal(entry.value)
+	case "set", "setnx":
+		entry := &fakeRedisEntry{value: args[2]}
+		nx := args[0] == "setnx"
+		for i := 3; i < len(args); i++ {
+			switch args[i] {
+			case "ex", "px":
+				n, _ := strconv.ParseInt(args[i+1], 10, 64)
+				unit := time.Second
+				if args[i] == "px" {
+					unit = time.Millisecond
+				}
+
+				entry.expireAt = f.now.Add(time.Duration(n) * unit)
+				i++
+			case "nx":
+				nx = true
+			}
+		}
+
+		if nx {
+			ok := f.get(args[1]) == nil
+			if ok {
+				f.data[args[1]] = entry
+			}
+
+			cmd.(*redis.BoolCmd).SetVal(ok)
+			return nil
+		}
+
+		f.data[args[1]] = entry
+		cmd.(*redis.StatusCmd).SetVal("OK")
+	case "del":
+		var n int64
+		for _, key := range args[1:] {
+			if f.get(key) != nil {
+				delete(f.data, key)
+				n++
+			}
+		}
+
+		cmd.(*redis.IntCmd).SetVal(n)
+	case "incr":
+		entry := f.get(args[1])
+		if entry == nil {
+			entry = &fakeRedisEntry{}
+			f.data[args[1]] = entry
+		}
+
+		n, _ := strconv.ParseInt(entry.value, 10, 64)
+		n++
+
+		entry.value = strconv.FormatInt(n, 10)
+		cmd.(*redis.IntCmd).SetVal(n)
+	case "expire":
+		entry := f.get(args[1])
+		ok := entry != nil && (len(args) < 4 || entry.expireAt.IsZero())
+		if ok {
+			n, _ := strconv.ParseInt(args[2], 10, 64)
+			entry.expireAt = f.now.Add(time.Duration(n) * time.Second)
+		}
+
+		cmd.(*redis.BoolCmd).SetVal(ok)
+	case "pttl":
+		ttl := time.Duration(-2)
+		if entry := f.get(args[1]); entry != nil {
+			ttl = entry.expireAt.Sub(f.now)
+		}
+
+		cmd.(*redis.DurationCmd).SetVal(ttl)
+	case "sadd", "srem":
+		entry := f.get(args[1])
+		if entry == nil {
+			entry = &fakeRedisEntry{members: map[string]bool{}}
+			f.data[args[1]] = entry
+		}
+
+		for _, member := range args[2:] {
+			if args[0] == "sadd" {
+				entry.members[member] = true
+			} else {
+				delete(entry.members, member)
+			}
+		}
+
+		cmd.(*redis.IntCmd).SetVal(int64(len(args) - 2))
+	case "smembers":
+		members := []string{}
+		if entry := f.get(args[1]); entry != nil {
+			for member := range entry.members {
+				members = append(members, member)
+			}
+		}
+
+		cmd.(*redis.StringSliceCmd).SetVal(members)
+	case "mget":
+		values := make([]any, len(args)-1)
+		for i, key := range args[1:] {
+			if entry := f.get(key); entry != nil {
+				values[i] = entry.value
+			}
+		}
+
+		cmd.(*redis.SliceCmd).SetVal(values)
+	default:
+		err := fmt.Errorf("fakeRedis: unsupported command %s", args[0])
+		cmd.SetErr(err)
+		return err
+	}
+
+	return nil
+}
+
+// mockRepository serves user.Repository from pgxmock, userrepo.New only accepts a pool.
+type mockRepository struct {
+	*userrepo.Repository
+	db pgxmock.PgxPoolIface
+}
+
+func (r mockRepository) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.db.Begin(ctx)
+}
+
+func initPgMock(t *testing.T) pgxmock.PgxPoolIface {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return mock
+}
+
+func initConfig() {
+	cfg := config.GetConfig()
+	cfg.JWT.AccessTokenExpireIn = 300
+	cfg.JWT.RefreshTokenSecret = "refresh-token-secret-of-the-tests"
+	cfg.JWT.RefreshTokenExpireIn = 3600
+	cfg.EmailVerification.Secret = "email-verification-secret-of-the-tests"
+	cfg.EmailVerification.ExpireIn = 3600
+	cfg.PasswordReset.ExpireIn = 1800
+	cfg.PasswordReset.ResendInterval = 60
+	cfg.LoginThrottle.MaxAttemptsPerEmail = 3
+	cfg.LoginThrottle.MaxAttemptsPerIP = 5
+	cfg.LoginThrottle.Window = 900
+	cfg.LoginThrottle.Lockout = 600
+	cfg.LoginThrottle.DelayAfter = 0
+	cfg.LoginThrottle.DelayBase = 0
+}
+
+func initDepMock(t *testing.T, db pgxmock.PgxPoolIface) (svc *Service, fake *fakeRedis, mail *mailer.Memory) {
+	initConfig()
+
+	signing, err := jwtkey.Generate(jwtkey.AlgEdDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := jwtkey.NewKeySet(signing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake = &fakeRedis{now: time.Now(), data: map[string]*fakeRedisEntry{}}
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	client.AddHook(fake)
+
+	mail = mailer.NewMemory()
+	repo := mockRepository{Repository: userrepo.New(nil).WithTx(db), db: db}
+
+	auditSvc := auditsvc.New(auditrepo.New(db))
+	walletSvc := walletsvc.New(walletrepo.New(db, db), auditSvc)
+	productSvc := productsvc.New(productrepo.New(db, db), auditSvc, nil)
+
+	svc = New(repo, userrepo.NewRedis(client), auditSvc, walletSvc, productSvc, mail, keys)
+
+	return
+}
+
+func userRows(data entity.User) *pgxmock.Rows {
+	return pgxmock.NewRows(userColumns).
+		AddRow(
+			data.ID, data.Fullname, data.Email, data.Password, data.Role, data.EmailVerifiedAt, data.AvatarURL, data.Phone, data.Language,
+			data.PendingEmail, data.TOTPSecret, data.TOTPEnabledAt, data.CreatedAt, data.UpdatedAt,
+		)
+}
+
+func expectGetUser(dbMock pgxmock.PgxPoolIface, data entity.User) {
+	dbMock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+)").
+		WithArgs(data.ID).
+		WillReturnRows(userRows(data))
+}
+
+func expectGetUserByEmail(dbMock pgxmock.PgxPoolIface, data entity.User) {
+	dbMock.ExpectQuery("SELECT (.+) FROM users WHERE email = (.+)").
+		WithArgs(data.Email).
+		WillReturnRows(userRows(data))
+}
+
+func expectRecordAuditLog(dbMock pgxmock.PgxPoolIface) {
+	dbMock.ExpectExec("INSERT INTO audit_logs (.+) VALUES (.+)").
+		WithArgs(
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(),
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func newUser() entity.User {
+	return entity.User{
+		ID:              uuid.New(),
+		Fullname:        "user 1",
+		Email:           "user1@mail.com",
+		Role:            entity.UserRoleUser,
+		EmailVerifiedAt: null.TimeFrom(time.Now()),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+}
+
+func parseAccessToken(t *testing.T, svc *Service, token string) model.JWTClaims {
+	var claims model.JWTClaims
+	_, err := jwt.ParseWithClaims(token, &claims, svc.keys.Keyfunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return claims
+}
+
+func TestRefreshTokenRotationSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+	login, err := svc.issueLoginTokens(ctx, data, "phone", "test", "10.0.0.1")
+	assert.NoError(t, err)
+
+	// the role changed since the login
+	data.Role = entity.UserRoleAdmin
+	expectGetUser(dbMock, data)
+
+	res, err := svc.RefreshToken(ctx, model.UserRefreshTokenRequest{RefreshToken: login.RefreshToken})
+	assert.NoError(t, err)
+	assert.NotEqual(t, login.RefreshToken, res.RefreshToken)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	// the rotated token stays in the family and the session of the login
+	loginClaims := parseAccessToken(t, svc, login.AccessToken)
+	claims := parseAccessToken(t, svc, res.AccessToken)
+	assert.Equal(t, loginClaims.SessionID, claims.SessionID)
+	assert.Equal(t, entity.UserRoleAdmin, claims.Role)
+
+	payload, err := svc.repoRedis.IsRefreshTokenExist(ctx, res.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, loginClaims.SessionID, payload.FamilyID.String())
+
+	sessions, err := svc.GetSessions(ctx, data.ID, claims.SessionID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "phone", sessions[0].DeviceName)
+
+	// the rotated token refreshes in turn
+	expectGetUser(dbMock, data)
+
+	_, err = svc.RefreshToken(ctx, model.UserRefreshTokenRequest{RefreshToken: res.RefreshToken})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenFailedReused(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+	login, err := svc.issueLoginTokens(ctx, data, "phone", "test", "10.0.0.1")
+	assert.NoError(t, err)
+
+	other, err := svc.issueLoginTokens(ctx, data, "laptop", "test", "10.0.0.2")
+	assert.NoError(t, err)
+
+	// the thief refreshes first
+	expectGetUser(dbMock, data)
+
+	rotated, err := svc.RefreshToken(ctx, model.UserRefreshTokenRequest{RefreshToken: login.RefreshToken})
+	assert.NoError(t, err)
+
+	// then the user presents the rotated token again
+	_, err = svc.RefreshToken(ctx, model.UserRefreshTokenRequest{RefreshToken: login.RefreshToken})
+	assert.ErrorIs(t, err, constant.ErrRefreshTokenReused)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	// the token of the thief is revoked with the rest of its family, and so are the other sessions
+	for _, token := range []string{rotated.RefreshToken, other.RefreshToken} {
+		_, err = svc.RefreshToken(ctx, model.UserRefreshTokenRequest{RefreshToken: token})
+		assert.ErrorIs(t, err, constant.ErrUnauthorizedAccess)
+	}
+
+	for _, token := range []string{rotated.AccessToken, other.AccessToken} {
+		revoked, err := svc.IsAccessTokenRevoked(ctx, parseAccessToken(t, svc, token))
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	}
+
+	sessions, err := svc.GetSessions(ctx, data.ID, "")
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestLogoutSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, fake, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+	login, err := svc.issueLoginTokens(ctx, data, "phone", "test", "10.0.0.1")
+	assert.NoError(t, err)
+
+	other, err := svc.issueLoginTokens(ctx, data, "laptop", "test", "10.0.0.2")
+	assert.NoError(t, err)
+
+	claims := parseAccessToken(t, svc, login.AccessToken)
+
+	err = svc.Logout(ctx, model.UserLogoutRequest{RefreshToken: login.RefreshToken, AccessToken: claims})
+	assert.NoError(t, err)
+
+	revoked, err := svc.IsAccessTokenRevoked(ctx, claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = svc.RefreshToken(ctx, model.UserRefreshTokenRequest{RefreshToken: login.RefreshToken})
+	assert.ErrorIs(t, err, constant.ErrUnauthorizedAccess)
+
+	// the other session stays logged in
+	revoked, err = svc.IsAccessTokenRevoked(ctx, parseAccessToken(t, svc, other.AccessToken))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// the jti is denied until the access token expires, then it is dropped from the denylist
+	fake.advance(time.Duration(config.GetConfig().JWT.AccessTokenExpireIn) * time.Second)
+	assert.Nil(t, fake.get("access_token:revoked:"+claims.ID))
+
+	// logging out again is a no-op
+	err = svc.Logout(ctx, model.UserLogoutRequest{RefreshToken: login.RefreshToken, AccessToken: claims})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestIsAccessTokenRevokedSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+	login, err := svc.issueLoginTokens(ctx, data, "phone", "test", "10.0.0.1")
+	assert.NoError(t, err)
+
+	claims := parseAccessToken(t, svc, login.AccessToken)
+
+	// tokens issued before jti and sessions are only revoked by the token version
+	legacy := claims
+	legacy.ID = ""
+	legacy.SessionID = ""
+
+	for _, c := range []model.JWTClaims{claims, legacy} {
+		revoked, err := svc.IsAccessTokenRevoked(ctx, c)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	}
+
+	err = svc.LogoutAll(ctx, data.ID)
+	assert.NoError(t, err)
+
+	for _, c := range []model.JWTClaims{claims, legacy} {
+		revoked, err := svc.IsAccessTokenRevoked(ctx, c)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	}
+
+	// a login after logging out everywhere gets tokens of the new version
+	login, err = svc.issueLoginTokens(ctx, data, "phone", "test", "10.0.0.1")
+	assert.NoError(t, err)
+
+	revoked, err := svc.IsAccessTokenRevoked(ctx, parseAccessToken(t, svc, login.AccessToken))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestIsAccessTokenRevokedFailedInvalidSubject(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+
+	_, err := svc.IsAccessTokenRevoked(context.Background(), model.JWTClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "not-a-uuid"},
+	})
+	assert.Error(t, err)
+}
+
+func TestGetSessionsSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+	phone, err := svc.issueLoginTokens(ctx, data, "phone", "test", "10.0.0.1")
+	assert.NoError(t, err)
+
+	laptop, err := svc.issueLoginTokens(ctx, data, "laptop", "test", "10.0.0.2")
+	assert.NoError(t, err)
+
+	// sessions of other users are not listed
+	_, err = svc.issueLoginTokens(ctx, newUser(), "tablet", "test", "10.0.0.3")
+	assert.NoError(t, err)
+
+	// the phone is used again after the laptop logged in
+	expectGetUser(dbMock, data)
+
+	_, err = svc.RefreshToken(ctx, model.UserRefreshTokenRequest{RefreshToken: phone.RefreshToken})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	current := parseAccessToken(t, svc, laptop.AccessToken).SessionID
+
+	sessions, err := svc.GetSessions(ctx, data.ID, current)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	assert.Equal(t, "phone", sessions[0].DeviceName)
+	assert.Equal(t, "10.0.0.1", sessions[0].IP)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[0].LastUsedAt.After(sessions[0].CreatedAt))
+
+	assert.Equal(t, "laptop", sessions[1].DeviceName)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestRevokeSessionSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+	phone, err := svc.issueLoginTokens(ctx, data, "phone", "test", "10.0.0.1")
+	assert.NoError(t, err)
+
+	laptop, err := svc.issueLoginTokens(ctx, data, "laptop", "test", "10.0.0.2")
+	assert.NoError(t, err)
+
+	claims := parseAccessToken(t, svc, phone.AccessToken)
+
+	err = svc.RevokeSession(ctx, model.UserSessionRevokeRequest{ID: uuid.MustParse(claims.SessionID), UserID: data.ID})
+	assert.NoError(t, err)
+
+	// the access token of the session stops working before it expires
+	revoked, err := svc.IsAccessTokenRevoked(ctx, claims)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = svc.RefreshToken(ctx, model.UserRefreshTokenRequest{RefreshToken: phone.RefreshToken})
+	assert.ErrorIs(t, err, constant.ErrUnauthorizedAccess)
+
+	revoked, err = svc.IsAccessTokenRevoked(ctx, parseAccessToken(t, svc, laptop.AccessToken))
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	sessions, err := svc.GetSessions(ctx, data.ID, "")
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "laptop", sessions[0].DeviceName)
+
+	// revoking it again reports it as not found
+	err = svc.RevokeSession(ctx, model.UserSessionRevokeRequest{ID: uuid.MustParse(claims.SessionID), UserID: data.ID})
+	assert.ErrorIs(t, err, constant.ErrSessionNotFound)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestRevokeSessionFailedSessionOfAnotherUser(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+	login, err := svc.issueLoginTokens(ctx, data, "phone", "test", "10.0.0.1")
+	assert.NoError(t, err)
+
+	claims := parseAccessToken(t, svc, login.AccessToken)
+
+	err = svc.RevokeSession(ctx, model.UserSessionRevokeRequest{ID: uuid.MustParse(claims.SessionID), UserID: uuid.New()})
+	assert.ErrorIs(t, err, constant.ErrSessionNotFound)
+
+	// the session is left logged in
+	revoked, err := svc.IsAccessTokenRevoked(ctx, claims)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+}
+
+// sentToken returns the token of the link in the last email sent.
+func sentToken(t *testing.T, mail *mailer.Memory) string {
+	messages := mail.Messages()
+	if len(messages) == 0 {
+		t.Fatal("no email sent")
+	}
+
+	match := regexp.MustCompile(`token=(\S+)`).FindStringSubmatch(messages[len(messages)-1].Body)
+	if match == nil {
+		t.Fatal("no token in email")
+	}
+
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func verificationToken(t *testing.T, data entity.User, key string, expiresAt time.Time) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, model.EmailVerificationClaims{
+		Email: data.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   data.ID.String(),
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestVerifyEmailPendingEmailSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, mail := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+	data.PendingEmail = null.StringFrom("new@mail.com")
+
+	// the link is sent to the pending email, which replaces the current email once verified
+	pending := data
+	pending.Email = data.PendingEmail.String
+
+	err := svc.sendVerificationEmail(ctx, pending)
+	assert.NoError(t, err)
+	assert.Equal(t, pending.Email, mail.Messages()[0].To)
+
+	dbMock.ExpectExec("UPDATE users SET (.+) WHERE (.+)").
+		WithArgs(data.ID, pending.Email).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = svc.VerifyEmail(ctx, model.UserVerifyEmailRequest{Token: sentToken(t, mail)})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	// a link to the replaced email no longer matches the user
+	err = svc.sendVerificationEmail(ctx, data)
+	assert.NoError(t, err)
+
+	dbMock.ExpectExec("UPDATE users SET (.+) WHERE (.+)").
+		WithArgs(data.ID, data.Email).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = svc.VerifyEmail(ctx, model.UserVerifyEmailRequest{Token: sentToken(t, mail)})
+	assert.ErrorIs(t, err, constant.ErrEmailVerificationInvalid)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestVerifyEmailFailedForgedToken(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+
+	data := newUser()
+	expiresAt := time.Now().Add(time.Hour)
+
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, model.EmailVerificationClaims{
+		Email:            data.Email,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: data.ID.String(), Audience: jwt.ClaimStrings{emailVerificationAudience}},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+
+	tokens := map[string]string{
+		"empty key":   verificationToken(t, data, "", expiresAt),
+		"another key": verificationToken(t, data, "another-secret-of-at-least-32-characters", expiresAt),
+		"none":        none,
+	}
+
+	for name, token := range tokens {
+		err := svc.VerifyEmail(context.Background(), model.UserVerifyEmailRequest{Token: token})
+		assert.ErrorIs(t, err, constant.ErrEmailVerificationInvalid, name)
+	}
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestVerifyEmailFailedEmptySecret(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+
+	cfg := config.GetConfig()
+	cfg.EmailVerification.Secret = ""
+	defer initConfig()
+
+	token := verificationToken(t, newUser(), "", time.Now().Add(time.Hour))
+
+	err := svc.VerifyEmail(context.Background(), model.UserVerifyEmailRequest{Token: token})
+	assert.ErrorIs(t, err, constant.ErrEmailVerificationInvalid)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestVerifyEmailFailedExpiredToken(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+
+	token := verificationToken(t, newUser(), config.GetConfig().EmailVerification.Secret, time.Now().Add(-time.Minute))
+
+	err := svc.VerifyEmail(context.Background(), model.UserVerifyEmailRequest{Token: token})
+	assert.ErrorIs(t, err, constant.ErrEmailVerificationInvalid)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func expectUpdatePassword(dbMock pgxmock.PgxPoolIface, userID uuid.UUID) {
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec("UPDATE users SET password = (.+) WHERE (.+)").
+		WithArgs(pgxmock.AnyArg(), userID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+}
+
+func TestResetPasswordSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, mail := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+	phone, err := svc.issueLoginTokens(ctx, data, "phone", "test", "10.0.0.1")
+	assert.NoError(t, err)
+
+	expectGetUserByEmail(dbMock, data)
+
+	err = svc.ForgotPassword(ctx, model.UserForgotPasswordRequest{Email: data.Email})
+	assert.NoError(t, err)
+
+	token := sentToken(t, mail)
+
+	expectUpdatePassword(dbMock, data.ID)
+
+	err = svc.ResetPassword(ctx, model.UserResetPasswordRequest{Token: token, Password: "newpassword"})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	// every session is logged out, the one who reset the password may not be the one who is logged in
+	revoked, err := svc.IsAccessTokenRevoked(ctx, parseAccessToken(t, svc, phone.AccessToken))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = svc.RefreshToken(ctx, model.UserRefreshTokenRequest{RefreshToken: phone.RefreshToken})
+	assert.ErrorIs(t, err, constant.ErrUnauthorizedAccess)
+
+	// the token is single use
+	err = svc.ResetPassword(ctx, model.UserResetPasswordRequest{Token: token, Password: "anotherpassword"})
+	assert.ErrorIs(t, err, constant.ErrPasswordResetTokenInvalid)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestResetPasswordFailedReplacedToken(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, fake, mail := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+
+	expectGetUserByEmail(dbMock, data)
+
+	err := svc.ForgotPassword(ctx, model.UserForgotPasswordRequest{Email: data.Email})
+	assert.NoError(t, err)
+
+	first := sentToken(t, mail)
+
+	// a request within the resend interval keeps the link already sent
+	expectGetUserByEmail(dbMock, data)
+
+	err = svc.ForgotPassword(ctx, model.UserForgotPasswordRequest{Email: data.Email})
+	assert.NoError(t, err)
+	assert.Len(t, mail.Messages(), 1)
+
+	fake.advance(time.Duration(config.GetConfig().PasswordReset.ResendInterval) * time.Second)
+	expectGetUserByEmail(dbMock, data)
+
+	err = svc.ForgotPassword(ctx, model.UserForgotPasswordRequest{Email: data.Email})
+	assert.NoError(t, err)
+	assert.Len(t, mail.Messages(), 2)
+
+	// only the last link works
+	err = svc.ResetPassword(ctx, model.UserResetPasswordRequest{Token: first, Password: "newpassword"})
+	assert.ErrorIs(t, err, constant.ErrPasswordResetTokenInvalid)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestResetPasswordFailedExpiredToken(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, fake, mail := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newUser()
+
+	expectGetUserByEmail(dbMock, data)
+
+	err := svc.ForgotPassword(ctx, model.UserForgotPasswordRequest{Email: data.Email})
+	assert.NoError(t, err)
+
+	fake.advance(time.Duration(config.GetConfig().PasswordReset.ExpireIn) * time.Second)
+
+	err = svc.ResetPassword(ctx, model.UserResetPasswordRequest{Token: sentToken(t, mail), Password: "newpassword"})
+	assert.ErrorIs(t, err, constant.ErrPasswordResetTokenInvalid)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestForgotPasswordUnknownEmailSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, mail := initDepMock(t, dbMock)
+
+	dbMock.ExpectQuery("SELECT (.+) FROM users WHERE email = (.+)").
+		WithArgs("unknown@mail.com").
+		WillReturnRows(pgxmock.NewRows(userColumns))
+
+	// the response does not tell the email is not registered
+	err := svc.ForgotPassword(context.Background(), model.UserForgotPasswordRequest{Email: "unknown@mail.com"})
+	assert.NoError(t, err)
+	assert.Empty(t, mail.Messages())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// expectGetProfile expects the profile of a user without a wallet nor products.
+func expectGetProfile(dbMock pgxmock.PgxPoolIface, data entity.User) {
+	expectGetUser(dbMock, data)
+	dbMock.ExpectQuery("SELECT (.+) FROM wallets WHERE (.+)").
+		WithArgs(data.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "balance", "created_at", "updated_at"}))
+	dbMock.ExpectQuery("SELECT (.+) FROM products p JOIN users u (.+)").
+		WithArgs(data.ID, 1, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}))
+	dbMock.ExpectQuery("SELECT COUNT(.+) FROM products p (.+)").
+		WithArgs(data.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+}
+
+func expectUpdateProfile(dbMock pgxmock.PgxPoolIface, data entity.User) {
+	dbMock.ExpectBegin()
+	dbMock.ExpectExec("UPDATE users SET fullname = (.+) WHERE (.+)").
+		WithArgs(data.Fullname, data.AvatarURL, data.Phone, data.Language, data.PendingEmail, data.ID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	expectRecordAuditLog(dbMock)
+	dbMock.ExpectCommit()
+}
+
+func newUpdateProfileRequest(data entity.User, email string) model.UserUpdateProfileRequest {
+	return model.UserUpdateProfileRequest{
+		UserID:   data.ID,
+		Fullname: "user 1 renamed",
+		Email:    email,
+		Language: "id",
+	}
+}
+
+func TestUpdateProfilePendingEmailSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, mail := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	before := newUser()
+	req := newUpdateProfileRequest(before, "new@mail.com")
+
+	data := before
+	data.Fullname = req.Fullname
+	data.Language = req.Language
+	data.PendingEmail = null.StringFrom(req.Email)
+
+	expectGetUser(dbMock, before)
+	dbMock.ExpectQuery("SELECT (.+) FROM users WHERE email = (.+)").
+		WithArgs(req.Email).
+		WillReturnRows(pgxmock.NewRows(userColumns))
+	expectUpdateProfile(dbMock, data)
+	expectGetProfile(dbMock, data)
+
+	res, err := svc.UpdateProfile(ctx, req)
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	// the current email stays verified until the new one is
+	assert.Equal(t, before.Email, res.Email)
+	assert.True(t, res.EmailVerified)
+	assert.Equal(t, data.PendingEmail, res.PendingEmail)
+
+	messages := mail.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, req.Email, messages[0].To)
+
+	// opening the link swaps the pending email in
+	dbMock.ExpectExec("UPDATE users SET (.+) WHERE (.+)").
+		WithArgs(data.ID, req.Email).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = svc.VerifyEmail(ctx, model.UserVerifyEmailRequest{Token: sentToken(t, mail)})
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateProfileSamePendingEmailSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, mail := initDepMock(t, dbMock)
+
+	before := newUser()
+	before.PendingEmail = null.StringFrom("new@mail.com")
+	req := newUpdateProfileRequest(before, before.PendingEmail.String)
+
+	data := before
+	data.Fullname = req.Fullname
+	data.Language = req.Language
+
+	expectGetUser(dbMock, before)
+	dbMock.ExpectQuery("SELECT (.+) FROM users WHERE email = (.+)").
+		WithArgs(req.Email).
+		WillReturnRows(pgxmock.NewRows(userColumns))
+	expectUpdateProfile(dbMock, data)
+	expectGetProfile(dbMock, data)
+
+	// the link already sent to the pending email is still valid
+	_, err := svc.UpdateProfile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Empty(t, mail.Messages())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateProfileCancelPendingEmailSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, mail := initDepMock(t, dbMock)
+
+	before := newUser()
+	before.PendingEmail = null.StringFrom("new@mail.com")
+	req := newUpdateProfileRequest(before, before.Email)
+
+	data := before
+	data.Fullname = req.Fullname
+	data.Language = req.Language
+	data.PendingEmail = null.NewString(before.Email, false)
+
+	expectGetUser(dbMock, before)
+	expectUpdateProfile(dbMock, data)
+	expectGetProfile(dbMock, data)
+
+	res, err := svc.UpdateProfile(context.Background(), req)
+	assert.NoError(t, err)
+	assert.False(t, res.PendingEmail.Valid)
+	assert.Empty(t, mail.Messages())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestUpdateProfileFailedEmailAlreadyRegistered(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, mail := initDepMock(t, dbMock)
+
+	before := newUser()
+	owner := newUser()
+	owner.Email = "taken@mail.com"
+
+	expectGetUser(dbMock, before)
+	expectGetUserByEmail(dbMock, owner)
+
+	_, err := svc.UpdateProfile(context.Background(), newUpdateProfileRequest(before, owner.Email))
+	assert.ErrorIs(t, err, constant.ErrEmailAlreadyRegistered)
+	assert.Empty(t, mail.Messages())
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+const loginPassword = "password1"
+
+// newLoginUser returns a user with loginPassword as the password.
+func newLoginUser(t *testing.T) entity.User {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(loginPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := newUser()
+	data.Password = string(hashedPassword)
+
+	return data
+}
+
+func loginRequest(data entity.User, password string, ip string) model.UserLoginRequest {
+	return model.UserLoginRequest{Email: data.Email, Password: password, IP: ip}
+}
+
+// lockedOut returns the time left of the lockout of err, zero when err is not a lockout.
+func lockedOut(err error) time.Duration {
+	var errLockedOut *constant.ErrLockedOut
+	if errors.As(err, &errLockedOut) {
+		return errLockedOut.RetryAfter
+	}
+
+	return 0
+}
+
+func TestLoginFailedEmailLockout(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, fake, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newLoginUser(t)
+	lockout := time.Duration(config.GetConfig().LoginThrottle.Lockout) * time.Second
+
+	// the failures come from different IPs, so only the email is locked out
+	for i := 1; i <= 3; i++ {
+		expectGetUserByEmail(dbMock, data)
+
+		_, err := svc.Login(ctx, loginRequest(data, "wrongpassword", fmt.Sprintf("10.0.0.%d", i)))
+		if i < 3 {
+			assert.ErrorIs(t, err, constant.ErrEmailOrPasswordInvalid)
+			continue
+		}
+
+		assert.Equal(t, lockout, lockedOut(err))
+	}
+
+	// the right password is not even checked while locked out, whatever the IP
+	_, err := svc.Login(ctx, loginRequest(data, loginPassword, "10.0.0.9"))
+	assert.Positive(t, lockedOut(err))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	// the email is case insensitive
+	upper := data
+	upper.Email = "USER1@mail.com"
+
+	_, err = svc.Login(ctx, loginRequest(upper, loginPassword, "10.0.0.9"))
+	assert.Positive(t, lockedOut(err))
+
+	// the lockout expires
+	fake.advance(lockout)
+	expectGetUserByEmail(dbMock, data)
+
+	res, err := svc.Login(ctx, loginRequest(data, loginPassword, "10.0.0.9"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res.AccessToken)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestLoginFailedIPLockout(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, fake, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newLoginUser(t)
+	ip := "10.0.0.1"
+	lockout := time.Duration(config.GetConfig().LoginThrottle.Lockout) * time.Second
+
+	// unknown emails count as failures of the IP too
+	for i := 1; i <= 5; i++ {
+		email := fmt.Sprintf("unknown%d@mail.com", i)
+		dbMock.ExpectQuery("SELECT (.+) FROM users WHERE email = (.+)").
+			WithArgs(email).
+			WillReturnRows(pgxmock.NewRows(userColumns))
+
+		_, err := svc.Login(ctx, model.UserLoginRequest{Email: email, Password: loginPassword, IP: ip})
+		if i < 5 {
+			assert.ErrorIs(t, err, constant.ErrEmailOrPasswordInvalid)
+			continue
+		}
+
+		assert.Equal(t, lockout, lockedOut(err))
+	}
+
+	// every email is locked out from the IP, not from another one
+	_, err := svc.Login(ctx, loginRequest(data, loginPassword, ip))
+	assert.Positive(t, lockedOut(err))
+
+	expectGetUserByEmail(dbMock, data)
+
+	_, err = svc.Login(ctx, loginRequest(data, loginPassword, "10.0.0.2"))
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+
+	// a successful login does not clear the lockout of the IP, it expires
+	_, err = svc.Login(ctx, loginRequest(data, loginPassword, ip))
+	assert.Positive(t, lockedOut(err))
+
+	fake.advance(lockout)
+	expectGetUserByEmail(dbMock, data)
+
+	_, err = svc.Login(ctx, loginRequest(data, loginPassword, ip))
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestLoginResetFailuresSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, fake, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newLoginUser(t)
+
+	fail := func() error {
+		expectGetUserByEmail(dbMock, data)
+
+		_, err := svc.Login(ctx, loginRequest(data, "wrongpassword", ""))
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, fail(), constant.ErrEmailOrPasswordInvalid)
+	}
+
+	expectGetUserByEmail(dbMock, data)
+
+	_, err := svc.Login(ctx, loginRequest(data, loginPassword, ""))
+	assert.NoError(t, err)
+
+	// the count starts over after the successful login
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, fail(), constant.ErrEmailOrPasswordInvalid)
+	}
+
+	// and after the window of the first failure
+	fake.advance(time.Duration(config.GetConfig().LoginThrottle.Window) * time.Second)
+
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, fail(), constant.ErrEmailOrPasswordInvalid)
+	}
+
+	assert.Positive(t, lockedOut(fail()))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestLoginFailedDelay(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, fake, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	cfg := config.GetConfig()
+	cfg.LoginThrottle.MaxAttemptsPerEmail = 10
+	cfg.LoginThrottle.DelayAfter = 1
+	cfg.LoginThrottle.DelayBase = 2
+	defer initConfig()
+
+	data := newLoginUser(t)
+
+	// every failure after the first doubles the delay
+	for i, delay := range []time.Duration{0, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		expectGetUserByEmail(dbMock, data)
+
+		_, err := svc.Login(ctx, loginRequest(data, "wrongpassword", ""))
+		assert.Equal(t, delay, lockedOut(err), i)
+
+		fake.advance(delay)
+	}
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+// newTwoFactorUser returns a user with two factor authentication, loginPassword is the password.
+func newTwoFactorUser(t *testing.T) entity.User {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := newLoginUser(t)
+	data.TOTPSecret = null.StringFrom(secret)
+	data.TOTPEnabledAt = null.TimeFrom(time.Now())
+
+	return data
+}
+
+func currentCode(t *testing.T, data entity.User) string {
+	code, err := totp.Code(data.TOTPSecret.String, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
+
+func expectUseRecoveryCode(dbMock pgxmock.PgxPoolIface, userID uuid.UUID, code string, rowsAffected int64) {
+	dbMock.ExpectExec("UPDATE user_recovery_codes SET used_at = (.+) WHERE (.+)").
+		WithArgs(userID, hashToken(code)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", rowsAffected))
+}
+
+func TestLoginTwoFactorSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newTwoFactorUser(t)
+
+	expectGetUserByEmail(dbMock, data)
+
+	challenge, err := svc.Login(ctx, loginRequest(data, loginPassword, ""))
+	assert.NoError(t, err)
+	assert.True(t, challenge.TwoFactorRequired)
+	assert.Empty(t, challenge.AccessToken)
+
+	expectGetUser(dbMock, data)
+
+	res, err := svc.LoginTwoFactor(ctx, model.UserLoginTwoFactorRequest{ChallengeToken: challenge.ChallengeToken, Code: currentCode(t, data)})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res.AccessToken)
+
+	// the challenge is single use
+	_, err = svc.LoginTwoFactor(ctx, model.UserLoginTwoFactorRequest{ChallengeToken: challenge.ChallengeToken, Code: currentCode(t, data)})
+	assert.ErrorIs(t, err, constant.ErrTwoFactorChallengeInvalid)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestLoginTwoFactorFailedReplayedCode(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newTwoFactorUser(t)
+	code := currentCode(t, data)
+
+	for i := 0; i < 2; i++ {
+		expectGetUserByEmail(dbMock, data)
+
+		challenge, err := svc.Login(ctx, loginRequest(data, loginPassword, ""))
+		assert.NoError(t, err)
+
+		expectGetUser(dbMock, data)
+
+		// an observed code can not be sent again within its step
+		_, err = svc.LoginTwoFactor(ctx, model.UserLoginTwoFactorRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+		if i == 0 {
+			assert.NoError(t, err)
+			continue
+		}
+
+		assert.ErrorIs(t, err, constant.ErrTwoFactorCodeInvalid)
+	}
+
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestVerifyStepUpRecoveryCodeSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newTwoFactorUser(t)
+
+	// dashes, spaces and case of a recovery code are ignored
+	expectGetUser(dbMock, data)
+	expectUseRecoveryCode(dbMock, data.ID, "abcd1234efgh", 1)
+
+	err := svc.VerifyStepUp(ctx, data.ID, "ABCD-1234 efgh")
+	assert.NoError(t, err)
+
+	// a used code is rejected
+	expectGetUser(dbMock, data)
+	expectUseRecoveryCode(dbMock, data.ID, "abcd1234efgh", 0)
+
+	err = svc.VerifyStepUp(ctx, data.ID, "abcd-1234-efgh")
+	assert.ErrorIs(t, err, constant.ErrTwoFactorCodeInvalid)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestVerifyStepUpSuccess(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.Background()
+
+	data := newTwoFactorUser(t)
+	code := currentCode(t, data)
+
+	expectGetUser(dbMock, data)
+
+	err := svc.VerifyStepUp(ctx, data.ID, code)
+	assert.NoError(t, err)
+
+	// the code of a withdrawal can not be used for another one
+	expectGetUser(dbMock, data)
+
+	err = svc.VerifyStepUp(ctx, data.ID, code)
+	assert.ErrorIs(t, err, constant.ErrTwoFactorCodeInvalid)
+
+	// users without two factor authentication are not asked for a code
+	withoutTwoFactor := newUser()
+	expectGetUser(dbMock, withoutTwoFactor)
+
+	err = svc.VerifyStepUp(ctx, withoutTwoFactor.ID, "")
+	assert.NoError(t, err)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestVerifyStepUpFailedCodeRequired(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+
+	data := newTwoFactorUser(t)
+	expectGetUser(dbMock, data)
+
+	err := svc.VerifyStepUp(context.Background(), data.ID, "")
+	assert.ErrorIs(t, err, constant.ErrTwoFactorRequired)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestVerifyStepUpFailedLockout(t *testing.T) {
+	dbMock := initPgMock(t)
+	svc, _, _ := initDepMock(t, dbMock)
+	ctx := context.WithValue(context.Background(), constant.ClientIPContextKey, "10.0.0.1")
+
+	data := newTwoFactorUser(t)
+
+	// wrong codes count as failed logins of the user
+	var err error
+	for i := 0; i < 3; i++ {
+		expectGetUser(dbMock, data)
+
+		err = svc.VerifyStepUp(ctx, data.ID, "000000")
+	}
+
+	assert.Positive(t, lockedOut(err))
+
+	expectGetUser(dbMock, data)
+
+	err = svc.VerifyStepUp(ctx, data.ID, currentCode(t, data))
+	assert.Positive(t, lockedOut(err))
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
@@ -13,7 +13,7 @@ type Repository interface {
 	Begin(ctx context.Context) (tx pgx.Tx, err error)
 	WithTx(tx pgx.Tx) *walletrepo.Repository
 
-	Create(ctx context.Context, data entity.Wallet) (err error)
+	Create(ctx context.Context, data entity.Wallet) (id uuid.UUID, err error)
 	GetByUserID(ctx context.Context, userID uuid.UUID, isForUpdate bool) (data entity.Wallet, err error)
 	UpdateBalance(ctx context.Context, data entity.Wallet) (err error)
 }
@@ -34,16 +34,17 @@ func (r Repository) WithTx(tx pgx.Tx) *Repository {
 	return &r
 }
 
-func (r Repository) Create(ctx context.Context, data entity.Wallet) (err error) {
+func (r Repository) Create(ctx context.Context, data entity.Wallet) (id uuid.UUID, err error) {
 	query := `
 		INSERT INTO wallets (balance, user_id)
 		VALUES ($1, $2)
+		RETURNING id
 	`
 
-	_, err = r.db.Exec(ctx, query,
+	err = r.db.QueryRow(ctx, query,
 		data.Balance,
 		data.UserID,
-	)
+	).Scan(&id)
 
 	if err != nil {
 		var pgxError *pgconn.PgError
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/arfan21/vocagame/internal/audit"
 	"github.com/arfan21/vocagame/internal/entity"
 	"github.com/arfan21/vocagame/internal/model"
 	"github.com/arfan21/vocagame/internal/wallet"
@@ -13,15 +14,17 @@ import (
 )
 
 type Service struct {
-	repo wallet.Repository
+	repo     wallet.Repository
+	auditSvc audit.Service
 }
 
-func New(repo wallet.Repository) *Service {
-	return &Service{repo: repo}
+func New(repo wallet.Repository, auditSvc audit.Service) *Service {
+	return &Service{repo: repo, auditSvc: auditSvc}
 }
 
 func (s Service) WithTx(tx pgx.Tx) wallet.Service {
 	s.repo = s.repo.WithTx(tx)
+	s.auditSvc = s.auditSvc.WithTx(tx)
 	return &s
 }
 
@@ -36,13 +39,47 @@ func (s Service) Create(ctx context.Context, req model.CreateWalletRequest) (err
 		UserID: req.UserID,
 	}
 
-	err = s.repo.Create(ctx, data)
+	tx, err := s.repo.Begin(ctx)
+	if err != nil {
+		err = fmt.Errorf("wallet.service.Create: failed to begin transaction : %w", err)
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+
+		err = tx.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("wallet.service.Create: failed to commit transaction : %w", err)
+			return
+		}
+	}()
+
+	id, err := s.repo.WithTx(tx).Create(ctx, data)
 	if err != nil {
 		err = fmt.Errorf("wallet.service.Create: failed to create new wallet : %w", err)
 		return
 	}
 
-	return nil
+	err = s.auditSvc.WithTx(tx).Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionCreate,
+		EntityType: entity.AuditEntityWallet,
+		EntityID:   id,
+		After: model.WalletResponse{
+			ID:      id,
+			UserID:  data.UserID,
+			Balance: data.Balance,
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("wallet.service.Create: failed to record audit log : %w", err)
+		return
+	}
+
+	return
 }
 
 func (s Service) GetByUserID(ctx context.Context, userID uuid.UUID, isForUpdate bool) (res model.WalletResponse, err error) {
@@ -69,5 +106,32 @@ func (s Service) UpdateBalance(ctx context.Context, req model.UpdateBalanceReque
 		Balance: req.Balance,
 		UserID:  req.UserID,
 	}
-	return s.repo.UpdateBalance(ctx, data)
+
+	err = s.repo.UpdateBalance(ctx, data)
+	if err != nil {
+		err = fmt.Errorf("wallet.service.UpdateBalance: failed to update balance : %w", err)
+		return
+	}
+
+	err = s.auditSvc.Record(ctx, model.AuditLogRecordRequest{
+		Action:     entity.AuditActionUpdate,
+		EntityType: entity.AuditEntityWallet,
+		EntityID:   data.ID,
+		Before: model.WalletResponse{
+			ID:      data.ID,
+			UserID:  data.UserID,
+			Balance: req.PreviousBalance,
+		},
+		After: model.WalletResponse{
+			ID:      data.ID,
+			UserID:  data.UserID,
+			Balance: data.Balance,
+		},
+	})
+	if err != nil {
+		err = fmt.Errorf("wallet.service.UpdateBalance: failed to record audit log : %w", err)
+		return
+	}
+
+	return
 }
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS audit_logs (
+        id BIGSERIAL PRIMARY KEY,
+        actor_id UUID,
+        action VARCHAR(50) NOT NULL,
+        entity_type VARCHAR(50) NOT NULL,
+        entity_id UUID NOT NULL,
+        before_value JSONB,
+        after_value JSONB,
+        request_id VARCHAR(255),
+        ip VARCHAR(64),
+        created_at TIMESTAMP NOT NULL DEFAULT now ()
+    );
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+
+CREATE OR REPLACE FUNCTION trigger_audit_logs_append_only()
+  RETURNS trigger
+  LANGUAGE plpgsql
+AS $function$
+BEGIN
+	RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$function$;
+
+CREATE TRIGGER audit_logs_append_only
+BEFORE UPDATE OR DELETE ON audit_logs
+FOR EACH ROW EXECUTE FUNCTION trigger_audit_logs_append_only();
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS audit_logs_append_only ON audit_logs;
+
+DROP FUNCTION IF EXISTS trigger_audit_logs_append_only();
+
+DROP TABLE IF EXISTS audit_logs;
+
+-- +goose StatementEnd
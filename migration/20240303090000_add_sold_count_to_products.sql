@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS sold_count INT NOT NULL DEFAULT 0;
+
+UPDATE products p
+SET sold_count = sold.qty
+FROM (
+    SELECT td.product_id, SUM(td.qty) AS qty
+    FROM transaction_details td
+    JOIN transactions t ON t.id = td.transaction_id
+    WHERE t.status = 'COMPLETED'
+    GROUP BY td.product_id
+) sold
+WHERE sold.product_id = p.id;
+
+CREATE INDEX IF NOT EXISTS idx_products_created_at_id ON products (created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_products_price_id ON products (price, id);
+
+CREATE INDEX IF NOT EXISTS idx_products_sold_count_id ON products (sold_count, id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_products_sold_count_id;
+
+DROP INDEX IF EXISTS idx_products_price_id;
+
+DROP INDEX IF EXISTS idx_products_created_at_id;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS sold_count;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS delisted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_products_user_id_archived ON products (user_id)
+WHERE
+    deleted_at IS NOT NULL
+    OR delisted_at IS NOT NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_products_user_id_archived;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS delisted_at,
+DROP COLUMN IF EXISTS deleted_at;
+
+-- +goose StatementEnd
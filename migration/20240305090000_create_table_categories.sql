@@ -0,0 +1,50 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS categories (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        parent_id UUID,
+        name VARCHAR(100) NOT NULL,
+        slug VARCHAR(100) NOT NULL UNIQUE,
+        created_at TIMESTAMP DEFAULT now (),
+        updated_at TIMESTAMP DEFAULT now (),
+        CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories (id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+CREATE TRIGGER set_updated_at_categories BEFORE
+UPDATE ON categories FOR EACH ROW
+EXECUTE FUNCTION trigger_set_updated ();
+
+CREATE TABLE
+    IF NOT EXISTS product_categories (
+        product_id UUID NOT NULL,
+        category_id UUID NOT NULL,
+        PRIMARY KEY (product_id, category_id),
+        CONSTRAINT fk_product_categories_products FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+        CONSTRAINT fk_product_categories_categories FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_categories_category_id ON product_categories (category_id);
+
+CREATE TABLE
+    IF NOT EXISTS product_tags (
+        product_id UUID NOT NULL,
+        tag VARCHAR(50) NOT NULL,
+        PRIMARY KEY (product_id, tag),
+        CONSTRAINT fk_product_tags_products FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_tags_tag ON product_tags (tag);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS product_tags;
+
+DROP TABLE IF EXISTS product_categories;
+
+DROP TABLE IF EXISTS categories;
+
+-- +goose StatementEnd
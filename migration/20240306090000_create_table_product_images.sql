@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS product_images (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        product_id UUID NOT NULL,
+        object_key VARCHAR(255) NOT NULL,
+        thumbnail_key VARCHAR(255) NOT NULL,
+        content_type VARCHAR(50) NOT NULL,
+        size BIGINT NOT NULL,
+        width INT NOT NULL,
+        height INT NOT NULL,
+        position INT NOT NULL DEFAULT 0,
+        created_at TIMESTAMP DEFAULT now (),
+        CONSTRAINT fk_product_images_products FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id_position ON product_images (product_id, position);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS product_images;
+
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS product_variants (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        product_id UUID NOT NULL,
+        sku VARCHAR(100) NOT NULL,
+        name VARCHAR(255) NOT NULL,
+        price decimal NOT NULL,
+        stok INT NOT NULL DEFAULT 0,
+        sold_count INT NOT NULL DEFAULT 0,
+        created_at TIMESTAMP DEFAULT now (),
+        updated_at TIMESTAMP DEFAULT now (),
+        deleted_at TIMESTAMP,
+        CONSTRAINT fk_product_variants_products FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_product_id_sku ON product_variants (product_id, sku)
+WHERE
+    deleted_at IS NULL;
+
+CREATE TRIGGER set_updated_at_product_variants BEFORE
+UPDATE ON product_variants FOR EACH ROW
+EXECUTE FUNCTION trigger_set_updated ();
+
+ALTER TABLE transaction_details
+ADD COLUMN IF NOT EXISTS variant_id UUID,
+ADD CONSTRAINT fk_transaction_details_product_variants FOREIGN KEY (variant_id) REFERENCES product_variants (id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transaction_details
+DROP CONSTRAINT IF EXISTS fk_transaction_details_product_variants,
+DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS product_variants;
+
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS reserved_stok INT NOT NULL DEFAULT 0;
+
+ALTER TABLE product_variants
+ADD COLUMN IF NOT EXISTS reserved_stok INT NOT NULL DEFAULT 0;
+
+CREATE TABLE
+    IF NOT EXISTS stock_reservations (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        user_id UUID NOT NULL,
+        status VARCHAR(20) NOT NULL,
+        expires_at TIMESTAMP NOT NULL,
+        transaction_id UUID,
+        created_at TIMESTAMP DEFAULT now (),
+        updated_at TIMESTAMP DEFAULT now (),
+        CONSTRAINT fk_stock_reservations_users FOREIGN KEY (user_id) REFERENCES users (id),
+        CONSTRAINT fk_stock_reservations_transactions FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_active_expires_at ON stock_reservations (expires_at)
+WHERE
+    status = 'ACTIVE';
+
+CREATE TRIGGER set_updated_at_stock_reservations BEFORE
+UPDATE ON stock_reservations FOR EACH ROW
+EXECUTE FUNCTION trigger_set_updated ();
+
+CREATE TABLE
+    IF NOT EXISTS stock_reservation_items (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        reservation_id UUID NOT NULL,
+        product_id UUID NOT NULL,
+        variant_id UUID,
+        qty INT NOT NULL,
+        CONSTRAINT fk_stock_reservation_items_stock_reservations FOREIGN KEY (reservation_id) REFERENCES stock_reservations (id) ON DELETE CASCADE,
+        CONSTRAINT fk_stock_reservation_items_products FOREIGN KEY (product_id) REFERENCES products (id),
+        CONSTRAINT fk_stock_reservation_items_product_variants FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservation_items_reservation_id ON stock_reservation_items (reservation_id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS stock_reservation_items;
+
+DROP TABLE IF EXISTS stock_reservations;
+
+ALTER TABLE product_variants
+DROP COLUMN IF EXISTS reserved_stok;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS reserved_stok;
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS stock_movements (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        product_id UUID NOT NULL,
+        variant_id UUID,
+        delta INT NOT NULL,
+        reason VARCHAR(20) NOT NULL,
+        stok_after INT NOT NULL,
+        actor_id UUID,
+        transaction_id UUID,
+        note VARCHAR(255),
+        created_at TIMESTAMP DEFAULT now (),
+        CONSTRAINT fk_stock_movements_products FOREIGN KEY (product_id) REFERENCES products (id),
+        CONSTRAINT fk_stock_movements_product_variants FOREIGN KEY (variant_id) REFERENCES product_variants (id),
+        CONSTRAINT fk_stock_movements_users FOREIGN KEY (actor_id) REFERENCES users (id),
+        CONSTRAINT fk_stock_movements_transactions FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id_created_at ON stock_movements (product_id, created_at DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS stock_movements;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS sku VARCHAR(100);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_user_id_sku ON products (user_id, sku)
+WHERE
+    deleted_at IS NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_products_user_id_sku;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS sku;
+
+-- +goose StatementEnd
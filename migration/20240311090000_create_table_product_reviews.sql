@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS rating_count INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS rating_sum INT NOT NULL DEFAULT 0;
+
+CREATE TABLE
+    IF NOT EXISTS product_reviews (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        product_id UUID NOT NULL,
+        transaction_detail_id UUID NOT NULL,
+        user_id UUID NOT NULL,
+        rating SMALLINT NOT NULL,
+        comment TEXT NOT NULL DEFAULT '',
+        reply TEXT,
+        replied_at TIMESTAMP,
+        created_at TIMESTAMP DEFAULT now (),
+        updated_at TIMESTAMP DEFAULT now (),
+        CONSTRAINT fk_product_reviews_products FOREIGN KEY (product_id) REFERENCES products (id),
+        CONSTRAINT fk_product_reviews_transaction_details FOREIGN KEY (transaction_detail_id) REFERENCES transaction_details (id),
+        CONSTRAINT fk_product_reviews_users FOREIGN KEY (user_id) REFERENCES users (id),
+        CONSTRAINT uq_product_reviews_transaction_detail_id UNIQUE (transaction_detail_id),
+        CONSTRAINT chk_product_reviews_rating CHECK (rating BETWEEN 1 AND 5)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_reviews_product_id_created_at ON product_reviews (product_id, created_at DESC);
+
+CREATE TRIGGER set_updated_at_product_reviews BEFORE
+UPDATE ON product_reviews FOR EACH ROW
+EXECUTE FUNCTION trigger_set_updated ();
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS product_reviews;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS rating_sum,
+DROP COLUMN IF EXISTS rating_count;
+
+-- +goose StatementEnd
@@ -0,0 +1,81 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE
+    IF NOT EXISTS product_sales (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        product_id UUID NOT NULL,
+        variant_id UUID,
+        sale_price DECIMAL NOT NULL,
+        starts_at TIMESTAMP NOT NULL,
+        ends_at TIMESTAMP NOT NULL,
+        quota INT,
+        sold INT NOT NULL DEFAULT 0,
+        per_user_limit INT,
+        created_by UUID NOT NULL,
+        cancelled_at TIMESTAMP,
+        created_at TIMESTAMP DEFAULT now (),
+        updated_at TIMESTAMP DEFAULT now (),
+        CONSTRAINT fk_product_sales_products FOREIGN KEY (product_id) REFERENCES products (id),
+        CONSTRAINT fk_product_sales_product_variants FOREIGN KEY (variant_id) REFERENCES product_variants (id),
+        CONSTRAINT fk_product_sales_users FOREIGN KEY (created_by) REFERENCES users (id),
+        CONSTRAINT chk_product_sales_period CHECK (ends_at > starts_at),
+        CONSTRAINT chk_product_sales_sale_price CHECK (sale_price > 0),
+        CONSTRAINT chk_product_sales_quota CHECK (quota IS NULL OR sold <= quota)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_sales_product_id_ends_at ON product_sales (product_id, ends_at)
+WHERE
+    cancelled_at IS NULL;
+
+CREATE TRIGGER set_updated_at_product_sales BEFORE
+UPDATE ON product_sales FOR EACH ROW
+EXECUTE FUNCTION trigger_set_updated ();
+
+CREATE TABLE
+    IF NOT EXISTS product_sale_usages (
+        sale_id UUID NOT NULL,
+        user_id UUID NOT NULL,
+        qty INT NOT NULL,
+        PRIMARY KEY (sale_id, user_id),
+        CONSTRAINT fk_product_sale_usages_product_sales FOREIGN KEY (sale_id) REFERENCES product_sales (id),
+        CONSTRAINT fk_product_sale_usages_users FOREIGN KEY (user_id) REFERENCES users (id)
+    );
+
+CREATE TABLE
+    IF NOT EXISTS product_price_history (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        product_id UUID NOT NULL,
+        variant_id UUID,
+        reason VARCHAR(20) NOT NULL,
+        price DECIMAL NOT NULL,
+        sale_id UUID,
+        starts_at TIMESTAMP,
+        ends_at TIMESTAMP,
+        actor_id UUID,
+        created_at TIMESTAMP DEFAULT now (),
+        CONSTRAINT fk_product_price_history_products FOREIGN KEY (product_id) REFERENCES products (id),
+        CONSTRAINT fk_product_price_history_product_variants FOREIGN KEY (variant_id) REFERENCES product_variants (id),
+        CONSTRAINT fk_product_price_history_product_sales FOREIGN KEY (sale_id) REFERENCES product_sales (id),
+        CONSTRAINT fk_product_price_history_users FOREIGN KEY (actor_id) REFERENCES users (id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_product_price_history_product_id_created_at ON product_price_history (product_id, created_at DESC);
+
+ALTER TABLE transaction_details
+ADD COLUMN IF NOT EXISTS price DECIMAL,
+ADD COLUMN IF NOT EXISTS sale_id UUID REFERENCES product_sales (id);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transaction_details
+DROP COLUMN IF EXISTS sale_id,
+DROP COLUMN IF EXISTS price;
+
+DROP TABLE IF EXISTS product_price_history;
+
+DROP TABLE IF EXISTS product_sale_usages;
+
+DROP TABLE IF EXISTS product_sales;
+
+-- +goose StatementEnd
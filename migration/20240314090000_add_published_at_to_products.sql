@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+
+UPDATE products
+SET
+    published_at = created_at;
+
+CREATE INDEX IF NOT EXISTS idx_products_published_at ON products (published_at)
+WHERE
+    deleted_at IS NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_products_published_at;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS published_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS avatar_url VARCHAR(2048),
+ADD COLUMN IF NOT EXISTS phone VARCHAR(20),
+ADD COLUMN IF NOT EXISTS language VARCHAR(35) NOT NULL DEFAULT 'en',
+ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+DROP COLUMN IF EXISTS avatar_url,
+DROP COLUMN IF EXISTS phone,
+DROP COLUMN IF EXISTS language,
+DROP COLUMN IF EXISTS pending_email;
+
+-- +goose StatementEnd
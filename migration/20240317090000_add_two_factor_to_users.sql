@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS totp_secret VARCHAR(64),
+ADD COLUMN IF NOT EXISTS totp_enabled_at TIMESTAMP;
+
+CREATE TABLE
+    IF NOT EXISTS user_recovery_codes (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+        user_id UUID NOT NULL,
+        code_hash VARCHAR(64) NOT NULL,
+        used_at TIMESTAMP,
+        created_at TIMESTAMP DEFAULT now (),
+        CONSTRAINT fk_user_recovery_codes_users FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+        CONSTRAINT uq_user_recovery_codes_user_id_code_hash UNIQUE (user_id, code_hash)
+    );
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_recovery_codes;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS totp_secret,
+DROP COLUMN IF EXISTS totp_enabled_at;
+
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/arfan21/vocagame/config"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// BlobStore stores binary objects such as product images under a key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (err error)
+	Delete(ctx context.Context, key string) (err error)
+	// URL returns the public url of the object.
+	URL(key string) string
+}
+
+// New creates the blob store of the configured storage driver.
+func New() (BlobStore, error) {
+	cfg := config.GetConfig().Storage
+
+	switch cfg.Driver {
+	case DriverLocal:
+		return NewLocal(cfg.LocalDir, cfg.PublicURL)
+	case DriverS3:
+		return NewS3(S3Config{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+			PublicURL:    cfg.PublicURL,
+		})
+	}
+
+	return nil, fmt.Errorf("blobstore: unknown storage driver %q", cfg.Driver)
+}
@@ -0,0 +1,108 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local stores objects as files below a directory.
+type Local struct {
+	dir       string
+	publicURL string
+}
+
+func NewLocal(dir string, publicURL string) (*Local, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("blobstore.NewLocal: failed to create directory: %w", err)
+	}
+
+	return &Local{dir: dir, publicURL: strings.TrimSuffix(publicURL, "/")}, nil
+}
+
+// Dir is the directory the objects are stored in, to be served as static files.
+func (l Local) Dir() string {
+	return l.dir
+}
+
+func (l Local) path(key string) (string, error) {
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+
+	// reject keys escaping the storage directory, e.g. "../secret"
+	rel, err := filepath.Rel(l.dir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("blobstore.Local: invalid key %q", key)
+	}
+
+	return path, nil
+}
+
+func (l Local) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (err error) {
+	path, err := l.path(key)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return fmt.Errorf("blobstore.Local.Put: failed to create directory: %w", err)
+	}
+
+	// write to a temporary file first so readers never see a partial object
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("blobstore.Local.Put: failed to create file: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(file.Name())
+		}
+	}()
+
+	_, err = io.Copy(file, body)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("blobstore.Local.Put: failed to write file: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("blobstore.Local.Put: failed to close file: %w", err)
+	}
+
+	err = os.Chmod(file.Name(), 0o644)
+	if err != nil {
+		return fmt.Errorf("blobstore.Local.Put: failed to chmod file: %w", err)
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return fmt.Errorf("blobstore.Local.Put: failed to rename file: %w", err)
+	}
+
+	return
+}
+
+func (l Local) Delete(ctx context.Context, key string) (err error) {
+	path, err := l.path(key)
+	if err != nil {
+		return
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("blobstore.Local.Delete: failed to remove file: %w", err)
+	}
+
+	return nil
+}
+
+func (l Local) URL(key string) string {
+	return l.publicURL + "/" + key
+}
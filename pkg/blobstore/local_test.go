@@ -0,0 +1,62 @@
+package blobstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalPutSuccess(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(filepath.Join(dir, "uploads"), "http://localhost:8080/uploads/")
+	assert.NoError(t, err)
+
+	key := "products/abc/image.jpg"
+	err = store.Put(context.Background(), key, strings.NewReader("jpeg"), 4, "image/jpeg")
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "uploads", "products", "abc", "image.jpg"))
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", string(data))
+	assert.Equal(t, "http://localhost:8080/uploads/"+key, store.URL(key))
+
+	err = store.Delete(context.Background(), key)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "uploads", "products", "abc", "image.jpg"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLocalPutFailedPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(filepath.Join(dir, "uploads"), "")
+	assert.NoError(t, err)
+
+	for _, key := range []string{"../secret", "products/../../secret", "..", "", ".", "products/.."} {
+		err = store.Put(context.Background(), key, strings.NewReader("data"), 4, "image/jpeg")
+		assert.Error(t, err, key)
+	}
+
+	// nothing is written next to the storage directory
+	_, err = os.Stat(filepath.Join(dir, "secret"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLocalDeleteFailedPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocal(filepath.Join(dir, "uploads"), "")
+	assert.NoError(t, err)
+
+	outside := filepath.Join(dir, "secret")
+	err = os.WriteFile(outside, []byte("data"), 0o644)
+	assert.NoError(t, err)
+
+	err = store.Delete(context.Background(), "../secret")
+	assert.Error(t, err)
+
+	_, err = os.Stat(outside)
+	assert.NoError(t, err)
+}
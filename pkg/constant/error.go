@@ -1,15 +1,37 @@
 package constant
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 const (
-	ErrSQLUniqueViolation = "23505"
+	ErrSQLUniqueViolation     = "23505"
+	ErrSQLForeignKeyViolation = "23503"
 )
 
 var (
 	ErrEmailAlreadyRegistered         = &ErrConflict{Message: "email already registered"}
 	ErrEmailOrPasswordInvalid         = &ErrUnauthorized{Message: "email or password invalid"}
 	ErrUnauthorizedAccess             = &ErrUnauthorized{Message: "unauthorized access"}
+	ErrRefreshTokenReused             = &ErrUnauthorized{Message: "refresh token already used, every session is logged out"}
+	ErrSessionNotFound                = &ErrNotFound{Message: "session not found"}
+	ErrUserNotFound                   = &ErrNotFound{Message: "user not found"}
+	ErrEmailVerificationInvalid       = &ErrBadRequest{Message: "verification link invalid or expired"}
+	ErrEmailAlreadyVerified           = &ErrConflict{Message: "email already verified"}
+	ErrEmailVerificationTooSoon       = &ErrTooManyRequests{Message: "verification email already sent, try again later"}
+	ErrEmailNotVerified               = &ErrForbidden{Message: "email not verified"}
+	ErrPasswordResetTokenInvalid      = &ErrBadRequest{Message: "reset password token invalid or expired"}
+	ErrCurrentPasswordInvalid         = &ErrBadRequest{Message: "current password invalid"}
+	ErrTwoFactorAlreadyEnabled        = &ErrConflict{Message: "two factor authentication already enabled"}
+	ErrTwoFactorNotEnrolled           = &ErrBadRequest{Message: "two factor authentication not enrolled"}
+	ErrTwoFactorNotEnabled            = &ErrBadRequest{Message: "two factor authentication not enabled"}
+	ErrTwoFactorCodeInvalid           = &ErrUnauthorized{Message: "two factor code invalid"}
+	ErrTwoFactorChallengeInvalid      = &ErrUnauthorized{Message: "two factor challenge invalid or expired"}
+	ErrTwoFactorRequired              = &ErrForbidden{Message: "two factor code required"}
+	ErrAPIKeyInvalid                  = &ErrUnauthorized{Message: "api key invalid, expired or revoked"}
+	ErrAPIKeyNotFound                 = &ErrNotFound{Message: "api key not found"}
+	ErrAPIKeyExpiresInPast            = &ErrBadRequest{Message: "api key must expire in the future"}
 	ErrStringNotDecimal               = &ErrBadRequest{Message: "string not decimal"}
 	ErrInvalidUUID                    = &ErrBadRequest{Message: "invalid UUID"}
 	ErrProductNotFound                = &ErrNotFound{Message: "product not found"}
@@ -20,11 +42,55 @@ var (
 	ErrTxDetailInsertedNotEqual       = errors.New("transaction detail inserted not equal with transaction detail request")
 	ErrCannotUpdateNotOwner           = &ErrForbidden{Message: "cannot update product, not owner"}
 	ErrCannotDeleteNotOwner           = &ErrForbidden{Message: "cannot delete product, not owner"}
+	ErrCannotDelistNotOwner           = &ErrForbidden{Message: "cannot delist product, not owner"}
+	ErrCannotRestoreNotOwner          = &ErrForbidden{Message: "cannot restore product, not owner"}
 	ErrWalletAlreadyCreated           = &ErrConflict{Message: "wallet already created"}
 	ErrWalletNotFound                 = &ErrNotFound{Message: "wallet not found"}
 	ErrInsufficientBalance            = &ErrBadRequest{Message: "insufficient balance"}
 	ErrCannotPurchaseOwnProduct       = &ErrBadRequest{Message: "cannot purchase own product"}
 	ErrTransactionNotFound            = &ErrNotFound{Message: "transaction not found"}
+	ErrInvalidCursor                  = &ErrBadRequest{Message: "invalid cursor"}
+	ErrProductInvalidPriceRange       = &ErrBadRequest{Message: "min_price must be less than or equal to max_price"}
+	ErrCategoryNotFound               = &ErrNotFound{Message: "category not found"}
+	ErrCategoryParentNotFound         = &ErrBadRequest{Message: "parent category not found"}
+	ErrCategoryInvalidParent          = &ErrBadRequest{Message: "category cannot be moved under itself or its descendant"}
+	ErrCategorySlugAlreadyExists      = &ErrConflict{Message: "category slug already exists"}
+	ErrCategoryInvalidSlug            = &ErrBadRequest{Message: "category slug must contain a letter or digit"}
+	ErrCategoryHasChildren            = &ErrConflict{Message: "category still has sub categories"}
+	ErrProductCategoryNotFound        = &ErrBadRequest{Message: "one or more categories not found"}
+	ErrProductVariantNotFound         = &ErrNotFound{Message: "product variant not found"}
+	ErrProductVariantSKUAlreadyExists = &ErrConflict{Message: "product variant sku already exists"}
+	ErrProductVariantRequired         = &ErrBadRequest{Message: "variant_id is required for product with variants"}
+	ErrProductSKUAlreadyExists        = &ErrConflict{Message: "product sku already exists"}
+	ErrProductImportInvalidFormat     = &ErrBadRequest{Message: "import format must be csv or ndjson"}
+	ErrProductImportTooManyRows       = &ErrBadRequest{Message: "too many rows to import"}
+	ErrStockBelowReserved             = &ErrBadRequest{Message: "stok can not be lower than the reserved stok"}
+	ErrStockAdjustmentInvalidDelta    = &ErrBadRequest{Message: "delta of a restock or return must be positive"}
+	ErrReservationNotFound            = &ErrNotFound{Message: "reservation not found"}
+	ErrReservationNotActive           = &ErrBadRequest{Message: "reservation is not active"}
+	ErrReservationExpired             = &ErrBadRequest{Message: "reservation expired"}
+	ErrCheckoutProductsRequired       = &ErrBadRequest{Message: "products or reservation_id is required"}
+	ErrCheckoutReservationWithProduct = &ErrBadRequest{Message: "products must be empty when checking out a reservation"}
+	ErrReviewPurchaseNotFound         = &ErrNotFound{Message: "purchase of the product not found"}
+	ErrReviewPurchaseNotCompleted     = &ErrBadRequest{Message: "only completed purchases can be reviewed"}
+	ErrReviewAlreadyExists            = &ErrConflict{Message: "purchase already reviewed"}
+	ErrReviewNotFound                 = &ErrNotFound{Message: "review not found"}
+	ErrReviewAlreadyReplied           = &ErrConflict{Message: "review already replied"}
+	ErrCannotReplyNotOwner            = &ErrForbidden{Message: "cannot reply review, not owner of the product"}
+	ErrSaleNotFound                   = &ErrNotFound{Message: "sale not found"}
+	ErrSalePriceNotLower              = &ErrBadRequest{Message: "sale price must be lower than the price"}
+	ErrSaleEndsInPast                 = &ErrBadRequest{Message: "sale must end in the future"}
+	ErrSaleOverlap                    = &ErrConflict{Message: "sale overlaps another sale of the product"}
+	ErrSaleNotCancellable             = &ErrBadRequest{Message: "sale already ended or cancelled"}
+	ErrSaleQuotaExceeded              = &ErrBadRequest{Message: "flash sale quota sold out"}
+	ErrSaleUserLimitExceeded          = &ErrBadRequest{Message: "purchase limit of the sale exceeded"}
+	ErrProductVersionMismatch         = &ErrPreconditionFailed{Message: "product was modified, get the latest version and retry"}
+	ErrInvalidIfMatch                 = &ErrBadRequest{Message: "If-Match must be an etag of the product"}
+	ErrProductImageNotFound           = &ErrNotFound{Message: "product image not found"}
+	ErrProductImageTooLarge           = &ErrBadRequest{Message: "product image too large"}
+	ErrProductImageInvalidType        = &ErrBadRequest{Message: "product image must be a jpeg or png"}
+	ErrProductImageLimitExceeded      = &ErrBadRequest{Message: "product image limit exceeded"}
+	ErrProductImageOrderMismatch      = &ErrBadRequest{Message: "image_ids must contain every image of the product exactly once"}
 )
 
 type ErrBadRequest struct {
@@ -74,3 +140,29 @@ type ErrConflict struct {
 func (e *ErrConflict) Error() string {
 	return e.Message
 }
+
+type ErrTooManyRequests struct {
+	Message string
+}
+
+func (e *ErrTooManyRequests) Error() string {
+	return e.Message
+}
+
+type ErrPreconditionFailed struct {
+	Message string
+}
+
+func (e *ErrPreconditionFailed) Error() string {
+	return e.Message
+}
+
+// ErrLockedOut is returned while an action is locked out, RetryAfter is the time left of the lockout.
+type ErrLockedOut struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *ErrLockedOut) Error() string {
+	return e.Message
+}
@@ -4,6 +4,7 @@ type ContextKey string
 
 var (
 	JWTClaimsContextKey ContextKey
+	ClientIPContextKey  ContextKey = "client_ip"
 )
 
 const (
@@ -0,0 +1,97 @@
+package dbpostgres
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Tx runs hooks once the transaction is committed, e.g. to invalidate a cache only after
+// the written rows are visible to other connections. Hooks of a rolled back transaction never run.
+type Tx struct {
+	pgx.Tx
+	// root is the outermost transaction of a savepoint, nil for the outermost transaction
+	root  *Tx
+	mu    sync.Mutex
+	names []string
+	hooks map[string]func(ctx context.Context)
+}
+
+// AfterCommit registers fn to run after the outermost transaction is committed,
+// a hook registered again under the same name runs only once.
+func (tx *Tx) AfterCommit(name string, fn func(ctx context.Context)) {
+	if tx.root != nil {
+		tx.root.AfterCommit(name, fn)
+		return
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.hooks == nil {
+		tx.hooks = make(map[string]func(ctx context.Context))
+	}
+
+	if _, ok := tx.hooks[name]; !ok {
+		tx.names = append(tx.names, name)
+	}
+
+	tx.hooks[name] = fn
+}
+
+// Begin starts a savepoint, its hooks run after the outermost transaction is committed.
+func (tx *Tx) Begin(ctx context.Context) (pgx.Tx, error) {
+	nested, err := tx.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	root := tx
+	if tx.root != nil {
+		root = tx.root
+	}
+
+	return &Tx{Tx: nested, root: root}, nil
+}
+
+func (tx *Tx) Commit(ctx context.Context) (err error) {
+	err = tx.Tx.Commit(ctx)
+	if err != nil || tx.root != nil {
+		return
+	}
+
+	tx.mu.Lock()
+	names, hooks := tx.names, tx.hooks
+	tx.names, tx.hooks = nil, nil
+	tx.mu.Unlock()
+
+	for _, name := range names {
+		hooks[name](ctx)
+	}
+
+	return
+}
+
+type afterCommitRaw struct {
+	Raw
+}
+
+// WithAfterCommit makes every transaction begun through raw a *Tx, so writes through
+// any repository sharing the transaction can register hooks on it.
+func WithAfterCommit(raw Raw) Raw {
+	if _, ok := raw.(afterCommitRaw); ok {
+		return raw
+	}
+
+	return afterCommitRaw{Raw: raw}
+}
+
+func (r afterCommitRaw) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := r.Raw.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tx{Tx: tx}, nil
+}
@@ -0,0 +1,70 @@
+package dbpostgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxAfterCommitSavepointSuccess(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	raw := WithAfterCommit(WithAfterCommit(dbMock))
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectBegin()
+	dbMock.ExpectCommit()
+	dbMock.ExpectCommit()
+
+	var calls []string
+	hook := func(name string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			calls = append(calls, name)
+		}
+	}
+
+	tx, err := raw.Begin(ctx)
+	assert.NoError(t, err)
+	tx.(*Tx).AfterCommit("first", hook("first"))
+
+	savepoint, err := tx.Begin(ctx)
+	assert.NoError(t, err)
+	savepoint.(*Tx).AfterCommit("second", hook("second"))
+	savepoint.(*Tx).AfterCommit("first", hook("first"))
+
+	// committing a savepoint does not make the writes visible yet
+	assert.NoError(t, savepoint.Commit(ctx))
+	assert.Empty(t, calls)
+
+	assert.NoError(t, tx.Commit(ctx))
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
+
+func TestTxAfterCommitFailedRollback(t *testing.T) {
+	dbMock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	dbMock.ExpectBegin()
+	dbMock.ExpectRollback()
+
+	called := false
+
+	tx, err := WithAfterCommit(dbMock).Begin(ctx)
+	assert.NoError(t, err)
+	tx.(*Tx).AfterCommit("hook", func(ctx context.Context) { called = true })
+
+	assert.NoError(t, tx.Rollback(ctx))
+	assert.False(t, called)
+	assert.NoError(t, dbMock.ExpectationsWereMet())
+}
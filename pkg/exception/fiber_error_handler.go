@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/arfan21/vocagame/pkg/constant"
@@ -92,6 +94,38 @@ func FiberErrorHandler(ctx *fiber.Ctx, err error) error {
 		}
 	}
 
+	var preconditionFailedError *constant.ErrPreconditionFailed
+	if errors.As(err, &preconditionFailedError) {
+		defaultRes.Code = fiber.StatusPreconditionFailed
+		if preconditionFailedError.Message != "" {
+			defaultRes.Message = preconditionFailedError.Message
+		} else {
+			defaultRes.Message = "Precondition Failed"
+		}
+	}
+
+	var tooManyRequestsError *constant.ErrTooManyRequests
+	if errors.As(err, &tooManyRequestsError) {
+		defaultRes.Code = fiber.StatusTooManyRequests
+		if tooManyRequestsError.Message != "" {
+			defaultRes.Message = tooManyRequestsError.Message
+		} else {
+			defaultRes.Message = "Too Many Requests"
+		}
+	}
+
+	var lockedOutError *constant.ErrLockedOut
+	if errors.As(err, &lockedOutError) {
+		defaultRes.Code = fiber.StatusTooManyRequests
+		if lockedOutError.Message != "" {
+			defaultRes.Message = lockedOutError.Message
+		} else {
+			defaultRes.Message = "Too Many Requests"
+		}
+
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(lockedOutError.RetryAfter.Seconds()))))
+	}
+
 	var fiberError *fiber.Error
 	if errors.As(err, &fiberError) {
 		defaultRes.Code = fiberError.Code
@@ -0,0 +1,357 @@
+package jwtkey
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/arfan21/vocagame/config"
+	"github.com/arfan21/vocagame/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+
+	minRSABits = 2048
+)
+
+var ErrUnknownKey = errors.New("jwtkey: unknown key id")
+
+// Key is a key of the key set, only the signing key has Private.
+// ID is the RFC 7638 thumbprint of the public key, it is sent as the kid header of the tokens the key signs.
+type Key struct {
+	ID      string
+	Alg     string
+	Public  crypto.PublicKey
+	Private crypto.Signer
+}
+
+func (k Key) method() jwt.SigningMethod {
+	if k.Alg == AlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+
+	return jwt.SigningMethodRS256
+}
+
+// KeySet signs tokens with its newest key and verifies tokens of every key.
+// Rotating keeps the previous keys for verification until the tokens they signed expire.
+type KeySet struct {
+	signing Key
+	keys    map[string]Key
+	order   []string
+}
+
+// New loads the key set from JWT_SIGNING_KEY_FILE and JWT_VERIFY_KEY_FILES.
+// With ENV=dev a key is generated when there is no signing key file, its tokens are invalid once the process exits.
+func New() (*KeySet, error) {
+	cfg := config.GetConfig().JWT
+
+	var signing Key
+	var err error
+	if cfg.SigningKeyFile == "" {
+		// every restart or other instance would reject the tokens of a generated key
+		if config.GetConfig().Env != "dev" {
+			return nil, errors.New("jwtkey: JWT_SIGNING_KEY_FILE is required unless ENV is dev")
+		}
+
+		logger.Log(context.Background()).Warn().Msg("jwtkey: JWT_SIGNING_KEY_FILE is empty, tokens are signed with a generated key which is lost on exit")
+		signing, err = Generate(AlgEdDSA)
+	} else {
+		signing, err = LoadFile(cfg.SigningKeyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var verifying []Key
+	for _, path := range strings.Split(cfg.VerifyKeyFiles, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		key, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		verifying = append(verifying, key)
+	}
+
+	return NewKeySet(signing, verifying...)
+}
+
+// NewKeySet creates a key set which signs with signing, it needs a private key.
+func NewKeySet(signing Key, verifying ...Key) (*KeySet, error) {
+	if signing.Private == nil {
+		return nil, fmt.Errorf("jwtkey: signing key %s has no private key", signing.ID)
+	}
+
+	ks := &KeySet{
+		signing: signing,
+		keys:    map[string]Key{signing.ID: signing},
+		order:   []string{signing.ID},
+	}
+
+	for _, key := range verifying {
+		if _, ok := ks.keys[key.ID]; ok {
+			continue
+		}
+
+		// previous keys only verify
+		key.Private = nil
+		ks.keys[key.ID] = key
+		ks.order = append(ks.order, key.ID)
+	}
+
+	return ks, nil
+}
+
+// Sign signs claims with the signing key.
+func (ks *KeySet) Sign(claims jwt.Claims) (token string, err error) {
+	jwtToken := jwt.NewWithClaims(ks.signing.method(), claims)
+	jwtToken.Header["kid"] = ks.signing.ID
+
+	token, err = jwtToken.SignedString(ks.signing.Private)
+	if err != nil {
+		err = fmt.Errorf("jwtkey: failed to sign token: %w", err)
+		return
+	}
+
+	return
+}
+
+// Keyfunc finds the key of a token by its kid header, it is passed to jwt.Parse.
+func (ks *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if t.Method.Alg() != key.Alg {
+		return nil, fmt.Errorf("jwtkey: token algorithm %s does not match key %s", t.Method.Alg(), kid)
+	}
+
+	return key.Public, nil
+}
+
+// Algs are the algorithms of the keys, they are the only valid methods of a token.
+func (ks *KeySet) Algs() []string {
+	algs := make([]string, 0, 2)
+	for _, id := range ks.order {
+		alg := ks.keys[id].Alg
+		if !slices.Contains(algs, alg) {
+			algs = append(algs, alg)
+		}
+	}
+
+	return algs
+}
+
+// JWK is the RFC 7517 JSON Web Key of a public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the key set, the signing key first.
+func (ks *KeySet) JWKS() JWKS {
+	res := JWKS{Keys: make([]JWK, 0, len(ks.order))}
+	for _, id := range ks.order {
+		key := ks.keys[id]
+
+		jwk := publicJWK(key.Public)
+		jwk.Use = "sig"
+		jwk.Alg = key.Alg
+		jwk.Kid = key.ID
+		res.Keys = append(res.Keys, jwk)
+	}
+
+	return res
+}
+
+func publicJWK(public crypto.PublicKey) JWK {
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(public),
+		}
+	}
+
+	return JWK{}
+}
+
+// thumbprint is the RFC 7638 thumbprint of the public key, the hash of its required members in lexicographic order.
+func thumbprint(public crypto.PublicKey) string {
+	jwk := publicJWK(public)
+
+	var members any
+	if jwk.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// LoadFile loads a PEM RSA or Ed25519 key, a private key in PKCS #1 or PKCS #8 or a public key in PKIX or PKCS #1.
+func LoadFile(path string) (key Key, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		err = fmt.Errorf("jwtkey: failed to read key file: %w", err)
+		return
+	}
+
+	key, err = Parse(data)
+	if err != nil {
+		err = fmt.Errorf("%w: %s", err, path)
+		return
+	}
+
+	return
+}
+
+func Parse(data []byte) (key Key, err error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		err = errors.New("jwtkey: no PEM block")
+		return
+	}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("jwtkey: unsupported PEM block %q", block.Type)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("jwtkey: failed to parse key: %w", err)
+		return
+	}
+
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.Private = signer
+		parsed = signer.Public()
+	}
+
+	switch public := parsed.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSABits {
+			err = fmt.Errorf("jwtkey: RSA key has %d bits, at least %d are required", public.N.BitLen(), minRSABits)
+			return
+		}
+
+		key.Alg = AlgRS256
+	case ed25519.PublicKey:
+		key.Alg = AlgEdDSA
+	default:
+		err = fmt.Errorf("jwtkey: unsupported key type %T", parsed)
+		return
+	}
+
+	key.Public = parsed
+	key.ID = thumbprint(parsed)
+
+	return
+}
+
+// Generate creates a new RS256 or EdDSA key.
+func Generate(alg string) (key Key, err error) {
+	switch alg {
+	case AlgRS256:
+		var private *rsa.PrivateKey
+		private, err = rsa.GenerateKey(rand.Reader, minRSABits)
+		key.Private = private
+	case AlgEdDSA:
+		_, key.Private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		err = fmt.Errorf("jwtkey: unsupported algorithm %q", alg)
+		return
+	}
+	if err != nil {
+		err = fmt.Errorf("jwtkey: failed to generate key: %w", err)
+		return
+	}
+
+	key.Alg = alg
+	key.Public = key.Private.Public()
+	key.ID = thumbprint(key.Public)
+
+	return
+}
+
+// EncodePrivate encodes the private key of key as a PKCS #8 PEM block.
+func EncodePrivate(key Key) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	if err != nil {
+		return nil, fmt.Errorf("jwtkey: failed to encode private key: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// EncodePublic encodes the public key of key as a PKIX PEM block.
+func EncodePublic(key Key) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key.Public)
+	if err != nil {
+		return nil, fmt.Errorf("jwtkey: failed to encode public key: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
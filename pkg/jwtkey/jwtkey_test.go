@@ -0,0 +1,284 @@
+package jwtkey
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arfan21/vocagame/config"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKey(t *testing.T, alg string) Key {
+	key, err := Generate(alg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func encodePEM(t *testing.T, blockType string, der []byte, err error) []byte {
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+func TestParseRSASuccess(t *testing.T) {
+	key := generateKey(t, AlgRS256)
+	private := key.Private.(*rsa.PrivateKey)
+
+	pkcs8, err := EncodePrivate(key)
+	assert.NoError(t, err)
+
+	pkix, err := EncodePublic(key)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		private bool
+	}{
+		{name: "PKCS1 private key", data: encodePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(private), nil), private: true},
+		{name: "PKCS8 private key", data: pkcs8, private: true},
+		{name: "PKIX public key", data: pkix},
+		{name: "PKCS1 public key", data: encodePEM(t, "RSA PUBLIC KEY", x509.MarshalPKCS1PublicKey(&private.PublicKey), nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := Parse(tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, AlgRS256, parsed.Alg)
+			assert.Equal(t, key.ID, parsed.ID)
+			assert.True(t, private.PublicKey.Equal(parsed.Public))
+			assert.Equal(t, tt.private, parsed.Private != nil)
+		})
+	}
+}
+
+func TestParseEd25519Success(t *testing.T) {
+	key := generateKey(t, AlgEdDSA)
+
+	pkcs8, err := EncodePrivate(key)
+	assert.NoError(t, err)
+
+	pkix, err := EncodePublic(key)
+	assert.NoError(t, err)
+
+	for _, data := range [][]byte{pkcs8, pkix} {
+		parsed, err := Parse(data)
+		assert.NoError(t, err)
+		assert.Equal(t, AlgEdDSA, parsed.Alg)
+		assert.Equal(t, key.ID, parsed.ID)
+	}
+}
+
+func TestParseFailedShortRSAKey(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+
+	_, err = Parse(encodePEM(t, "PRIVATE KEY", der, err))
+	assert.ErrorContains(t, err, "1024 bits")
+
+	der, err = x509.MarshalPKIXPublicKey(&private.PublicKey)
+
+	_, err = Parse(encodePEM(t, "PUBLIC KEY", der, err))
+	assert.ErrorContains(t, err, "1024 bits")
+}
+
+func TestParseFailed(t *testing.T) {
+	_, err := Parse([]byte("not a pem"))
+	assert.Error(t, err)
+
+	_, err = Parse(encodePEM(t, "CERTIFICATE", []byte("der"), nil))
+	assert.Error(t, err)
+
+	_, err = Parse(encodePEM(t, "PRIVATE KEY", []byte("der"), nil))
+	assert.Error(t, err)
+}
+
+func decodeBase64URL(t *testing.T, s string) []byte {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+func TestThumbprintSuccess(t *testing.T) {
+	// the example of RFC 7638 section 3.1
+	rsaKey := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(decodeBase64URL(t, "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")),
+		E: 65537,
+	}
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint(rsaKey))
+
+	// the example of RFC 8037 appendix A.3
+	edKey := ed25519.PublicKey(decodeBase64URL(t, "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"))
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", thumbprint(edKey))
+
+	// the kid of a parsed key is its thumbprint
+	der, err := x509.MarshalPKIXPublicKey(rsaKey)
+
+	parsed, err := Parse(encodePEM(t, "PUBLIC KEY", der, err))
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", parsed.ID)
+}
+
+func newClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "user",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+}
+
+func TestKeyfuncSuccess(t *testing.T) {
+	previous := generateKey(t, AlgEdDSA)
+	signing := generateKey(t, AlgRS256)
+
+	previousSet, err := NewKeySet(previous)
+	assert.NoError(t, err)
+
+	// the key set after rotating from previous to signing
+	ks, err := NewKeySet(signing, previous)
+	assert.NoError(t, err)
+
+	newToken, err := ks.Sign(newClaims())
+	assert.NoError(t, err)
+
+	oldToken, err := previousSet.Sign(newClaims())
+	assert.NoError(t, err)
+
+	for kid, token := range map[string]string{signing.ID: newToken, previous.ID: oldToken} {
+		parsed, err := jwt.Parse(token, ks.Keyfunc, jwt.WithValidMethods(ks.Algs()))
+		assert.NoError(t, err)
+		assert.Equal(t, kid, parsed.Header["kid"])
+	}
+
+	// previous keys only verify
+	assert.Nil(t, ks.keys[previous.ID].Private)
+	assert.Equal(t, []string{AlgRS256, AlgEdDSA}, ks.Algs())
+}
+
+func TestKeyfuncFailed(t *testing.T) {
+	signing := generateKey(t, AlgRS256)
+	other := generateKey(t, AlgEdDSA)
+
+	ks, err := NewKeySet(signing)
+	assert.NoError(t, err)
+
+	otherSet, err := NewKeySet(other)
+	assert.NoError(t, err)
+
+	// a key which is not in the key set
+	token, err := otherSet.Sign(newClaims())
+	assert.NoError(t, err)
+
+	_, err = jwt.Parse(token, ks.Keyfunc)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// a token claiming the kid of a key of another algorithm
+	forged := jwt.NewWithClaims(jwt.SigningMethodEdDSA, newClaims())
+	forged.Header["kid"] = signing.ID
+
+	token, err = forged.SignedString(other.Private)
+	assert.NoError(t, err)
+
+	_, err = jwt.Parse(token, ks.Keyfunc)
+	assert.ErrorContains(t, err, "does not match key")
+
+	_, err = NewKeySet(Key{ID: "public only", Public: signing.Public})
+	assert.Error(t, err)
+}
+
+func TestJWKSSuccess(t *testing.T) {
+	signing := generateKey(t, AlgEdDSA)
+	previous := generateKey(t, AlgRS256)
+
+	ks, err := NewKeySet(signing, previous, signing)
+	assert.NoError(t, err)
+
+	jwks := ks.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+
+	ed := jwks.Keys[0]
+	assert.Equal(t, JWK{Kty: "OKP", Use: "sig", Alg: AlgEdDSA, Kid: signing.ID, Crv: "Ed25519", X: ed.X}, ed)
+	assert.Equal(t, []byte(signing.Public.(ed25519.PublicKey)), decodeBase64URL(t, ed.X))
+
+	rsaJWK := jwks.Keys[1]
+	public := previous.Public.(*rsa.PublicKey)
+	assert.Equal(t, "RSA", rsaJWK.Kty)
+	assert.Equal(t, "sig", rsaJWK.Use)
+	assert.Equal(t, AlgRS256, rsaJWK.Alg)
+	assert.Equal(t, previous.ID, rsaJWK.Kid)
+	assert.Equal(t, "AQAB", rsaJWK.E)
+	assert.Equal(t, public.N.Bytes(), decodeBase64URL(t, rsaJWK.N))
+	assert.Empty(t, rsaJWK.Crv)
+}
+
+func TestNewSuccess(t *testing.T) {
+	signing := generateKey(t, AlgRS256)
+	previous := generateKey(t, AlgEdDSA)
+
+	dir := t.TempDir()
+	signingFile := filepath.Join(dir, "signing.pem")
+	previousFile := filepath.Join(dir, "previous.pem")
+
+	data, err := EncodePrivate(signing)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(signingFile, data, 0o600))
+
+	data, err = EncodePublic(previous)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(previousFile, data, 0o600))
+
+	cfg := config.GetConfig()
+	cfg.Env = "production"
+	cfg.JWT.SigningKeyFile = signingFile
+	cfg.JWT.VerifyKeyFiles = " " + previousFile + ", "
+	defer func() {
+		cfg.Env = "dev"
+		cfg.JWT.SigningKeyFile = ""
+		cfg.JWT.VerifyKeyFiles = ""
+	}()
+
+	ks, err := New()
+	assert.NoError(t, err)
+	assert.Equal(t, signing.ID, ks.signing.ID)
+	assert.Equal(t, []string{signing.ID, previous.ID}, ks.order)
+}
+
+func TestNewFailedSigningKeyFileRequired(t *testing.T) {
+	cfg := config.GetConfig()
+	cfg.JWT.SigningKeyFile = ""
+	defer func() { cfg.Env = "dev" }()
+
+	cfg.Env = "production"
+
+	_, err := New()
+	assert.ErrorContains(t, err, "JWT_SIGNING_KEY_FILE")
+
+	// only development generates a key
+	cfg.Env = "dev"
+
+	ks, err := New()
+	assert.NoError(t, err)
+	assert.Equal(t, AlgEdDSA, ks.signing.Alg)
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// File writes every email as an .eml file below a directory, for local development.
+type File struct {
+	dir  string
+	from string
+}
+
+func NewFile(dir string, from string) (*File, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("mailer.NewFile: failed to create directory: %w", err)
+	}
+
+	return &File{dir: dir, from: from}, nil
+}
+
+func (f File) Send(ctx context.Context, msg Message) (err error) {
+	now := time.Now()
+
+	data, err := build(f.from, msg, now)
+	if err != nil {
+		return fmt.Errorf("mailer.File.Send: %w", err)
+	}
+
+	// sortable by time, unique within the same second
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405Z"), uuid.NewString())
+
+	err = os.WriteFile(filepath.Join(f.dir, name), data, 0o644)
+	if err != nil {
+		return fmt.Errorf("mailer.File.Send: failed to write file: %w", err)
+	}
+
+	return
+}
@@ -0,0 +1,88 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+
+	"github.com/arfan21/vocagame/config"
+	"github.com/google/uuid"
+)
+
+const (
+	DriverSMTP   = "smtp"
+	DriverFile   = "file"
+	DriverMemory = "memory"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) (err error)
+}
+
+// New creates the mailer of the configured mail driver.
+func New() (Mailer, error) {
+	cfg := config.GetConfig().Mail
+
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTP(SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		})
+	case DriverFile:
+		return NewFile(cfg.FileDir, cfg.From)
+	case DriverMemory:
+		return NewMemory(), nil
+	}
+
+	return nil, fmt.Errorf("mailer: unknown mail driver %q", cfg.Driver)
+}
+
+// build renders msg as an RFC 5322 message with a quoted-printable utf-8 body.
+func build(from string, msg Message, now time.Time) ([]byte, error) {
+	// a line break in a header would let the caller inject headers
+	for _, value := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("mailer: header contains a line break")
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@vocagame>\r\n", uuid.NewString())
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	_, err := body.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n")))
+	if err != nil {
+		return nil, fmt.Errorf("mailer: failed to encode body: %w", err)
+	}
+
+	err = body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("mailer: failed to encode body: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// Memory keeps sent emails in memory, for tests and local development.
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Send(ctx context.Context, msg Message) (err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, msg)
+	return
+}
+
+// Messages returns the emails sent so far, the oldest first.
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.messages)
+}
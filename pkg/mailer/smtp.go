@@ -0,0 +1,110 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	// From is the sender, e.g. "Vocagame <no-reply@vocagame.com>"
+	From string
+}
+
+// SMTP sends emails through an SMTP server, the connection is upgraded with STARTTLS when the server supports it.
+type SMTP struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+func NewSMTP(cfg SMTPConfig) (*SMTP, error) {
+	if cfg.Host == "" || cfg.Port == "" {
+		return nil, errors.New("mailer.NewSMTP: host and port are required")
+	}
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("mailer.NewSMTP: failed to parse sender: %w", err)
+	}
+
+	return &SMTP{cfg: cfg, from: from}, nil
+}
+
+func (s SMTP) Send(ctx context.Context, msg Message) (err error) {
+	data, err := build(s.from.String(), msg, time.Now())
+	if err != nil {
+		return fmt.Errorf("mailer.SMTP.Send: %w", err)
+	}
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("mailer.SMTP.Send: failed to parse recipient: %w", err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.cfg.Host, s.cfg.Port))
+	if err != nil {
+		return fmt.Errorf("mailer.SMTP.Send: failed to connect: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mailer.SMTP.Send: failed to greet server: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: s.cfg.Host})
+		if err != nil {
+			return fmt.Errorf("mailer.SMTP.Send: failed to start tls: %w", err)
+		}
+	}
+
+	if s.cfg.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host))
+		if err != nil {
+			return fmt.Errorf("mailer.SMTP.Send: failed to authenticate: %w", err)
+		}
+	}
+
+	err = client.Mail(s.from.Address)
+	if err != nil {
+		return fmt.Errorf("mailer.SMTP.Send: failed to set sender: %w", err)
+	}
+
+	err = client.Rcpt(to.Address)
+	if err != nil {
+		return fmt.Errorf("mailer.SMTP.Send: failed to set recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mailer.SMTP.Send: failed to start data: %w", err)
+	}
+
+	_, err = writer.Write(data)
+	if err != nil {
+		return fmt.Errorf("mailer.SMTP.Send: failed to write message: %w", err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return fmt.Errorf("mailer.SMTP.Send: failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
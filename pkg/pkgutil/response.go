@@ -30,11 +30,12 @@ func (h HTTPResponse) MarshalJSON() ([]byte, error) {
 }
 
 type PaginationResponse[T any] struct {
-	TotalData int `json:"total_data" example:"1"`
-	TotalPage int `json:"total_page" example:"1"`
-	Page      int `json:"page" example:"1"`
-	Limit     int `json:"limit" example:"10"`
-	Data      T   `json:"data" `
+	TotalData  int    `json:"total_data" example:"1"`
+	TotalPage  int    `json:"total_page" example:"1"`
+	Page       int    `json:"page" example:"1"`
+	Limit      int    `json:"limit" example:"10"`
+	NextCursor string `json:"next_cursor,omitempty" example:""`
+	Data       T      `json:"data" `
 }
 
 type ErrValidationResponse struct {
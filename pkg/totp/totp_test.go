@@ -0,0 +1,75 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Secret is the SHA1 key of the RFC 6238 test vectors.
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCodeSuccess(t *testing.T) {
+	// the last 6 digits of the 8 digit codes of RFC 6238 appendix B
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := Code(rfc6238Secret, Step(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, unix)
+	}
+}
+
+func TestCodeLowercaseSecretSuccess(t *testing.T) {
+	code, err := Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", Step(time.Unix(59, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+}
+
+func TestValidateSuccess(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	// a code of the step before or after is accepted for clock drift, and the matched step is returned
+	for _, step := range []int64{current - 1, current, current + 1} {
+		code, err := Code(rfc6238Secret, step)
+		assert.NoError(t, err)
+
+		matched, ok := Validate(rfc6238Secret, code, now, 1)
+		assert.True(t, ok)
+		assert.Equal(t, step, matched)
+	}
+}
+
+func TestValidateFailed(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	old, err := Code(rfc6238Secret, Step(now)-2)
+	assert.NoError(t, err)
+
+	for _, code := range []string{old, "", "05047", "0504710", "abcdef"} {
+		_, ok := Validate(rfc6238Secret, code, now, 1)
+		assert.False(t, ok, code)
+	}
+
+	_, ok := Validate("not base32!", "050471", now, 1)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecretSuccess(t *testing.T) {
+	secret, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	_, err = Code(secret, Step(time.Now()))
+	assert.NoError(t, err)
+}